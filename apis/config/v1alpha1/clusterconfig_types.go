@@ -16,6 +16,8 @@ limitations under the License.
 package v1alpha1
 
 import (
+	corev1 "k8s.io/api/core/v1"
+	networkingv1 "k8s.io/api/networking/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime/schema"
 	"k8s.io/client-go/kubernetes/scheme"
@@ -77,6 +79,108 @@ type BroadcasterConfig struct {
 	// resources.
 	// This will trigger the deletion of the virtual-kubelet and, after that, of the Advertisement,
 	EnableBroadcaster bool `json:"enableBroadcaster"`
+	// OfferUpdateThresholdPercentage defines the minimum variation of the shared resources, as a percentage of the
+	// offered ones, which triggers the update of the ResourceOffers before their periodic renewal.
+	// +kubebuilder:validation:Maximum=100
+	// +kubebuilder:validation:Minimum=0
+	// +kubebuilder:default=5
+	OfferUpdateThresholdPercentage int32 `json:"offerUpdateThresholdPercentage,omitempty"`
+	// PeersSharingConfig defines the priority and the weight of each foreign cluster, used to divide the shared
+	// resources when the demand of the foreign clusters exceeds them.
+	// Foreign clusters not listed here have priority 0 and weight 1.
+	PeersSharingConfig []PeerSharingConfig `json:"peersSharingConfig,omitempty"`
+	// Prices defines the price of each shared resource, published in the ResourceOffers.
+	// CPU is priced per core, memory per GiB, and the other resources per unit.
+	Prices corev1.ResourceList `json:"prices,omitempty"`
+	// PriceSchedules defines the prices to be applied in specific time ranges of the day, overriding the
+	// default ones. If more schedules include the same time instant, the first one is applied.
+	PriceSchedules []PriceSchedule `json:"priceSchedules,omitempty"`
+	// RemoteNamespaceQuota defines how the resources offered to each foreign cluster are enforced on the
+	// namespaces it creates in the home cluster.
+	RemoteNamespaceQuota RemoteNamespaceQuotaConfig `json:"remoteNamespaceQuota,omitempty"`
+	// RemoteNamespaceSecurity defines the security policies enforced on the namespaces created by the foreign
+	// clusters in the home cluster.
+	RemoteNamespaceSecurity RemoteNamespaceSecurityConfig `json:"remoteNamespaceSecurity,omitempty"`
+}
+
+// PodSecurityLevel is a level of the Kubernetes Pod Security Standards.
+// +kubebuilder:validation:Enum="privileged";"baseline";"restricted"
+type PodSecurityLevel string
+
+const (
+	// PrivilegedPodSecurityLevel -> unrestricted policy.
+	PrivilegedPodSecurityLevel PodSecurityLevel = "privileged"
+	// BaselinePodSecurityLevel -> minimally restrictive policy, preventing known privilege escalations.
+	BaselinePodSecurityLevel PodSecurityLevel = "baseline"
+	// RestrictedPodSecurityLevel -> heavily restricted policy, following the pod hardening best practices.
+	RestrictedPodSecurityLevel PodSecurityLevel = "restricted"
+)
+
+// RemoteNamespaceSecurityConfig defines the security policies enforced on the namespaces created by the foreign
+// clusters. Any change performed on the namespaces or on the NetworkPolicies is reverted.
+type RemoteNamespaceSecurityConfig struct {
+	// PodSecurityLevel is the Pod Security Standard level enforced by the Pod Security admission on the
+	// namespaces: "privileged", "baseline" or "restricted". If not set, the cluster defaults apply.
+	PodSecurityLevel PodSecurityLevel `json:"podSecurityLevel,omitempty"`
+	// NetworkPolicies are the templates of the NetworkPolicies created in each namespace (e.g. to deny the
+	// ingress traffic except the one coming from the Liqo gateway).
+	NetworkPolicies []networkingv1.NetworkPolicySpec `json:"networkPolicies,omitempty"`
+}
+
+// RemoteNamespaceQuotaMode defines how the offered resources are enforced on the namespaces of a foreign cluster.
+// +kubebuilder:validation:Enum="None";"Aggregate";"Split"
+type RemoteNamespaceQuotaMode string
+
+const (
+	// NoneRemoteNamespaceQuotaMode -> no quota is enforced on the namespaces of the foreign clusters.
+	NoneRemoteNamespaceQuotaMode RemoteNamespaceQuotaMode = "None"
+	// AggregateRemoteNamespaceQuotaMode -> the offered resources are enforced on the whole set of namespaces
+	// of a foreign cluster, which can use them freely.
+	AggregateRemoteNamespaceQuotaMode RemoteNamespaceQuotaMode = "Aggregate"
+	// SplitRemoteNamespaceQuotaMode -> the offered resources are evenly split among the namespaces of a
+	// foreign cluster.
+	SplitRemoteNamespaceQuotaMode RemoteNamespaceQuotaMode = "Split"
+)
+
+// RemoteNamespaceQuotaConfig defines the ResourceQuota and the LimitRange enforced on the namespaces created by
+// the foreign clusters, which are derived from the accepted ResourceOffers.
+type RemoteNamespaceQuotaConfig struct {
+	// Mode defines how the offered resources are enforced: "None" (i.e. no quota is enforced), "Aggregate" (i.e.
+	// the offered resources are shared by all the namespaces of a foreign cluster) or "Split" (i.e. the offered
+	// resources are evenly divided among the namespaces of a foreign cluster).
+	// +kubebuilder:default="None"
+	Mode RemoteNamespaceQuotaMode `json:"mode,omitempty"`
+	// DefaultRequests are the resource requests assigned to the containers not specifying them, which would
+	// otherwise be refused by the ResourceQuota.
+	DefaultRequests corev1.ResourceList `json:"defaultRequests,omitempty"`
+	// DefaultLimits are the resource limits assigned to the containers not specifying them.
+	DefaultLimits corev1.ResourceList `json:"defaultLimits,omitempty"`
+}
+
+// PriceSchedule defines the prices of the shared resources in a time range of the day.
+type PriceSchedule struct {
+	// Start is the beginning of the time range, in the HH:MM format (UTC).
+	// +kubebuilder:validation:Pattern="^([01][0-9]|2[0-3]):[0-5][0-9]$"
+	Start string `json:"start"`
+	// End is the end of the time range, in the HH:MM format (UTC). It may precede Start to cross midnight.
+	// +kubebuilder:validation:Pattern="^([01][0-9]|2[0-3]):[0-5][0-9]$"
+	End string `json:"end"`
+	// Prices defines the price of each resource in this time range. The resources not listed here keep the
+	// default price.
+	Prices corev1.ResourceList `json:"prices"`
+}
+
+// PeerSharingConfig defines how the shared resources are reserved to a foreign cluster.
+type PeerSharingConfig struct {
+	// ClusterID is the identifier of the foreign cluster.
+	ClusterID string `json:"clusterID"`
+	// Priority of the foreign cluster, the requests of clusters with higher priority are satisfied first.
+	// +kubebuilder:default=0
+	Priority int32 `json:"priority,omitempty"`
+	// Weight of the foreign cluster, used to compute its fair share among clusters with the same priority.
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:default=1
+	Weight int32 `json:"weight,omitempty"`
 }
 
 // AcceptPolicy defines the policy to accept/refuse an Advertisement.
@@ -106,6 +210,29 @@ type AdvOperatorConfig struct {
 	// updating its status.
 	// +kubebuilder:validation:Enum="AutoAcceptMax";"Manual"
 	AcceptPolicy AcceptPolicy `json:"acceptPolicy"`
+	// ExpiredOfferGracePeriod defines how long a ResourceOffer which has not been renewed before the end of its
+	// TimeToLive is tolerated before draining the corresponding virtual node. In the meanwhile, the virtual node is
+	// cordoned. If not set, the virtual node is only cordoned, waiting for the ResourceOffer renewal.
+	ExpiredOfferGracePeriod *metav1.Duration `json:"expiredOfferGracePeriod,omitempty"`
+	// AcceptanceRules defines the requirements a ResourceOffer has to satisfy to be accepted, regardless of the
+	// AcceptPolicy. The ResourceOffers not satisfying them are refused, while the ones already accepted are not revoked.
+	AcceptanceRules *OfferAcceptanceRules `json:"acceptanceRules,omitempty"`
+}
+
+// OfferAcceptanceRules defines the requirements a ResourceOffer has to satisfy to be accepted.
+type OfferAcceptanceRules struct {
+	// MinResources defines the minimum amount of each resource a ResourceOffer has to provide.
+	MinResources corev1.ResourceList `json:"minResources,omitempty"`
+	// MaxResources defines the maximum amount of each resource a ResourceOffer can provide.
+	MaxResources corev1.ResourceList `json:"maxResources,omitempty"`
+	// RequiredLabels defines the labels (e.g. region or provider) that the offering cluster has to expose.
+	RequiredLabels map[string]string `json:"requiredLabels,omitempty"`
+	// MaxPrices defines the maximum price accepted for each resource.
+	// The resources without a price in the ResourceOffer are considered free.
+	MaxPrices corev1.ResourceList `json:"maxPrices,omitempty"`
+	// TrustedClusterIDs defines the clusters whose ResourceOffers can be accepted.
+	// If empty, the ResourceOffers of every cluster can be accepted.
+	TrustedClusterIDs []string `json:"trustedClusterIDs,omitempty"`
 }
 
 // LabelPolicy define a key-value structure to indicate which keys have to be aggregated and with which policy.
@@ -179,6 +306,22 @@ type AuthConfig struct {
 
 	// Set the ClusterRoles to bind in the different peering stages
 	PeeringPermission *PeeringPermission `json:"peeringPermission,omitempty"`
+
+	// Constrain the names of the namespaces that remote clusters may create in this cluster
+	RemoteNamespacePolicy *RemoteNamespacePolicy `json:"remoteNamespacePolicy,omitempty"`
+}
+
+// RemoteNamespacePolicy defines the constraints on the names of the namespaces that remote clusters may create
+// when offloading their namespaces. It is communicated to the remote clusters during the peering.
+type RemoteNamespacePolicy struct {
+	// Prefix that the names of the namespaces must start with (e.g. a tenant prefix).
+	Prefix string `json:"prefix,omitempty"`
+	// Regular expression that the names of the namespaces must match.
+	Pattern string `json:"pattern,omitempty"`
+	// Maximum length of the names of the namespaces.
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:validation:Maximum=63
+	MaxLength int32 `json:"maxLength,omitempty"`
 }
 
 // LiqonetConfig defines the configuration of the Liqo Networking.
@@ -197,6 +340,10 @@ type LiqonetConfig struct {
 	// Set of additional user-defined network pools.
 	// Default set of network pools is: [192.168.0.0/16, 10.0.0.0/8, 172.16.0.0/12]
 	AdditionalPools []CIDR `json:"additionalPools"`
+	// WireguardKeyRotationInterval defines how often the key pair used by the WireGuard tunnels is rotated.
+	// The rotation is performed without tearing down the peerings. If not set or zero, the keys are rotated
+	// only when explicitly requested through the annotation on the secret containing them.
+	WireguardKeyRotationInterval *metav1.Duration `json:"wireguardKeyRotationInterval,omitempty"`
 }
 
 // Resource contains a list of resources identified by their GVR.
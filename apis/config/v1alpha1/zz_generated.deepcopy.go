@@ -20,6 +20,9 @@ limitations under the License.
 package v1alpha1
 
 import (
+	"k8s.io/api/core/v1"
+	networkingv1 "k8s.io/api/networking/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 )
 
@@ -41,6 +44,16 @@ func (in *APIServerConfig) DeepCopy() *APIServerConfig {
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *AdvOperatorConfig) DeepCopyInto(out *AdvOperatorConfig) {
 	*out = *in
+	if in.ExpiredOfferGracePeriod != nil {
+		in, out := &in.ExpiredOfferGracePeriod, &out.ExpiredOfferGracePeriod
+		*out = new(metav1.Duration)
+		**out = **in
+	}
+	if in.AcceptanceRules != nil {
+		in, out := &in.AcceptanceRules, &out.AcceptanceRules
+		*out = new(OfferAcceptanceRules)
+		(*in).DeepCopyInto(*out)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new AdvOperatorConfig.
@@ -56,8 +69,8 @@ func (in *AdvOperatorConfig) DeepCopy() *AdvOperatorConfig {
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *AdvertisementConfig) DeepCopyInto(out *AdvertisementConfig) {
 	*out = *in
-	out.OutgoingConfig = in.OutgoingConfig
-	out.IngoingConfig = in.IngoingConfig
+	in.OutgoingConfig.DeepCopyInto(&out.OutgoingConfig)
+	in.IngoingConfig.DeepCopyInto(&out.IngoingConfig)
 	if in.LabelPolicies != nil {
 		in, out := &in.LabelPolicies, &out.LabelPolicies
 		*out = make([]LabelPolicy, len(*in))
@@ -99,6 +112,11 @@ func (in *AuthConfig) DeepCopyInto(out *AuthConfig) {
 		*out = new(PeeringPermission)
 		(*in).DeepCopyInto(*out)
 	}
+	if in.RemoteNamespacePolicy != nil {
+		in, out := &in.RemoteNamespacePolicy, &out.RemoteNamespacePolicy
+		*out = new(RemoteNamespacePolicy)
+		**out = **in
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new AuthConfig.
@@ -114,6 +132,27 @@ func (in *AuthConfig) DeepCopy() *AuthConfig {
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *BroadcasterConfig) DeepCopyInto(out *BroadcasterConfig) {
 	*out = *in
+	if in.PeersSharingConfig != nil {
+		in, out := &in.PeersSharingConfig, &out.PeersSharingConfig
+		*out = make([]PeerSharingConfig, len(*in))
+		copy(*out, *in)
+	}
+	if in.Prices != nil {
+		in, out := &in.Prices, &out.Prices
+		*out = make(v1.ResourceList, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val.DeepCopy()
+		}
+	}
+	if in.PriceSchedules != nil {
+		in, out := &in.PriceSchedules, &out.PriceSchedules
+		*out = make([]PriceSchedule, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	in.RemoteNamespaceQuota.DeepCopyInto(&out.RemoteNamespaceQuota)
+	in.RemoteNamespaceSecurity.DeepCopyInto(&out.RemoteNamespaceSecurity)
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new BroadcasterConfig.
@@ -307,6 +346,11 @@ func (in *LiqonetConfig) DeepCopyInto(out *LiqonetConfig) {
 		*out = make([]CIDR, len(*in))
 		copy(*out, *in)
 	}
+	if in.WireguardKeyRotationInterval != nil {
+		in, out := &in.WireguardKeyRotationInterval, &out.WireguardKeyRotationInterval
+		*out = new(metav1.Duration)
+		**out = **in
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new LiqonetConfig.
@@ -319,6 +363,69 @@ func (in *LiqonetConfig) DeepCopy() *LiqonetConfig {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *OfferAcceptanceRules) DeepCopyInto(out *OfferAcceptanceRules) {
+	*out = *in
+	if in.MinResources != nil {
+		in, out := &in.MinResources, &out.MinResources
+		*out = make(v1.ResourceList, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val.DeepCopy()
+		}
+	}
+	if in.MaxResources != nil {
+		in, out := &in.MaxResources, &out.MaxResources
+		*out = make(v1.ResourceList, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val.DeepCopy()
+		}
+	}
+	if in.RequiredLabels != nil {
+		in, out := &in.RequiredLabels, &out.RequiredLabels
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.MaxPrices != nil {
+		in, out := &in.MaxPrices, &out.MaxPrices
+		*out = make(v1.ResourceList, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val.DeepCopy()
+		}
+	}
+	if in.TrustedClusterIDs != nil {
+		in, out := &in.TrustedClusterIDs, &out.TrustedClusterIDs
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new OfferAcceptanceRules.
+func (in *OfferAcceptanceRules) DeepCopy() *OfferAcceptanceRules {
+	if in == nil {
+		return nil
+	}
+	out := new(OfferAcceptanceRules)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PeerSharingConfig) DeepCopyInto(out *PeerSharingConfig) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PeerSharingConfig.
+func (in *PeerSharingConfig) DeepCopy() *PeerSharingConfig {
+	if in == nil {
+		return nil
+	}
+	out := new(PeerSharingConfig)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *PeeringPermission) DeepCopyInto(out *PeeringPermission) {
 	*out = *in
@@ -349,6 +456,94 @@ func (in *PeeringPermission) DeepCopy() *PeeringPermission {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PriceSchedule) DeepCopyInto(out *PriceSchedule) {
+	*out = *in
+	if in.Prices != nil {
+		in, out := &in.Prices, &out.Prices
+		*out = make(v1.ResourceList, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val.DeepCopy()
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PriceSchedule.
+func (in *PriceSchedule) DeepCopy() *PriceSchedule {
+	if in == nil {
+		return nil
+	}
+	out := new(PriceSchedule)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RemoteNamespacePolicy) DeepCopyInto(out *RemoteNamespacePolicy) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RemoteNamespacePolicy.
+func (in *RemoteNamespacePolicy) DeepCopy() *RemoteNamespacePolicy {
+	if in == nil {
+		return nil
+	}
+	out := new(RemoteNamespacePolicy)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RemoteNamespaceQuotaConfig) DeepCopyInto(out *RemoteNamespaceQuotaConfig) {
+	*out = *in
+	if in.DefaultRequests != nil {
+		in, out := &in.DefaultRequests, &out.DefaultRequests
+		*out = make(v1.ResourceList, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val.DeepCopy()
+		}
+	}
+	if in.DefaultLimits != nil {
+		in, out := &in.DefaultLimits, &out.DefaultLimits
+		*out = make(v1.ResourceList, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val.DeepCopy()
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RemoteNamespaceQuotaConfig.
+func (in *RemoteNamespaceQuotaConfig) DeepCopy() *RemoteNamespaceQuotaConfig {
+	if in == nil {
+		return nil
+	}
+	out := new(RemoteNamespaceQuotaConfig)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RemoteNamespaceSecurityConfig) DeepCopyInto(out *RemoteNamespaceSecurityConfig) {
+	*out = *in
+	if in.NetworkPolicies != nil {
+		in, out := &in.NetworkPolicies, &out.NetworkPolicies
+		*out = make([]networkingv1.NetworkPolicySpec, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RemoteNamespaceSecurityConfig.
+func (in *RemoteNamespaceSecurityConfig) DeepCopy() *RemoteNamespaceSecurityConfig {
+	if in == nil {
+		return nil
+	}
+	out := new(RemoteNamespaceSecurityConfig)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *Resource) DeepCopyInto(out *Resource) {
 	*out = *in
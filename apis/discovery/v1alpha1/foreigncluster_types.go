@@ -76,6 +76,9 @@ type ForeignClusterSpec struct {
 	// this ForeignCluster will be removed if no updates have been received.
 	// +kubebuilder:validation:Minimum=0
 	TTL int `json:"ttl,omitempty"`
+	// ResourceRequirements contains the resources to be requested to this remote cluster.
+	// If not set, we accept all the resources the remote cluster is sharing.
+	ResourceRequirements *ResourceRequirements `json:"resourceRequirements,omitempty"`
 }
 
 // ClusterIdentity contains the information about a remote cluster (ID and Name).
@@ -1,6 +1,7 @@
 package v1alpha1
 
 import (
+	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime/schema"
 	"k8s.io/client-go/kubernetes/scheme"
@@ -22,12 +23,48 @@ type ResourceRequestSpec struct {
 	AuthURL string `json:"authUrl"`
 	// WithdrawalTimestamp is set when a graceful deletion is requested by the user.
 	WithdrawalTimestamp *metav1.Time `json:"withdrawalTimestamp,omitempty"`
+	// ResourceRequirements contains the resources the requester would like to receive.
+	// If not set, the provider offers all the resources it is sharing.
+	ResourceRequirements *ResourceRequirements `json:"resourceRequirements,omitempty"`
 }
 
+// ResourceRequirements describes a specific amount of resources asked to the provider cluster.
+type ResourceRequirements struct {
+	// Resources contains the quantity of resources (e.g. cpu, memory, nvidia.com/gpu) requested.
+	// The resources not listed here are offered as they are available in the provider cluster.
+	Resources corev1.ResourceList `json:"resources,omitempty"`
+	// Duration is the time span the resources are requested for, it replaces the default
+	// TimeToLive of the resulting ResourceOffer.
+	Duration *metav1.Duration `json:"duration,omitempty"`
+	// RequiredLabels contains the labels that the provider cluster has to expose.
+	RequiredLabels map[string]string `json:"requiredLabels,omitempty"`
+	// Regions contains the list of acceptable regions, matched against the topology.kubernetes.io/region
+	// label of the provider cluster. An empty list accepts any region.
+	Regions []string `json:"regions,omitempty"`
+}
+
+// OfferStateType describes how the provider answered to a ResourceRequest.
+type OfferStateType string
+
+const (
+	// OfferStateAccepted indicates that the ResourceOffer satisfies the requested resources.
+	OfferStateAccepted OfferStateType = "Accepted"
+	// OfferStateCounterOffered indicates that the ResourceOffer contains less resources than the requested ones.
+	OfferStateCounterOffered OfferStateType = "CounterOffered"
+	// OfferStateRefused indicates that the provider is not able to satisfy the request,
+	// and no ResourceOffer has been generated.
+	OfferStateRefused OfferStateType = "Refused"
+)
+
 // ResourceRequestStatus defines the observed state of ResourceRequest.
 type ResourceRequestStatus struct {
 	// OfferWithdrawalTimestamp is the withdrawal timestamp of the child ResourceOffer resource.
 	OfferWithdrawalTimestamp *metav1.Time `json:"offerWithdrawalTimestamp,omitempty"`
+	// OfferState indicates how the provider answered to the ResourceRequirements.
+	// +kubebuilder:validation:Enum="Accepted";"CounterOffered";"Refused"
+	OfferState OfferStateType `json:"offerState,omitempty"`
+	// OfferMessage contains a human readable explanation of the OfferState.
+	OfferMessage string `json:"offerMessage,omitempty"`
 }
 
 // +kubebuilder:object:root=true
@@ -35,6 +72,7 @@ type ResourceRequestStatus struct {
 
 // ResourceRequest is the Schema for the ResourceRequests API.
 // +kubebuilder:printcolumn:name="Local",type=string,JSONPath=`.metadata.labels.liqo\.io/replication`
+// +kubebuilder:printcolumn:name="OfferState",type=string,JSONPath=`.status.offerState`
 // +kubebuilder:printcolumn:name="Age",type=date,JSONPath=`.metadata.creationTimestamp`
 type ResourceRequest struct {
 	metav1.TypeMeta   `json:",inline"`
@@ -35,6 +35,14 @@ type Subnets struct {
 	RemoteExternalCIDR string `json:"remoteExternalCIDR"`
 }
 
+// MeshSubnets type contains the networks a cluster of a multi-peer mesh is reached with by all the other clusters.
+type MeshSubnets struct {
+	// Network used for Pods of the cluster.
+	PodCIDR string `json:"podCIDR"`
+	// Network used for the service endpoints reflected by the cluster.
+	ExternalCIDR string `json:"externalCIDR"`
+}
+
 // ClusterMapping is an empty struct.
 type ClusterMapping struct{}
 
@@ -72,6 +80,10 @@ type IpamSpec struct {
 	PodCIDR string `json:"podCIDR"`
 	// ServiceCIDR
 	ServiceCIDR string `json:"serviceCIDR"`
+	// Networks negotiated for the clusters of a multi-peer mesh. Key is the cluster ID, value is the set of networks
+	// the cluster is reached with, used instead of remapping the networks of the cluster independently.
+	// +optional
+	MeshSubnets map[string]MeshSubnets `json:"meshSubnets,omitempty"`
 }
 
 // +kubebuilder:object:root=true
@@ -42,6 +42,9 @@ type NetworkConfigSpec struct {
 	BackendType string `json:"backendType"`
 	// Connection parameters
 	BackendConfig map[string]string `json:"backend_config"`
+	// Networks the sender cluster reaches the receiver cluster with, if both belong to a multi-peer mesh.
+	// The receiver cluster refuses the peering if they differ from the ones it has recorded for itself.
+	MeshSubnets *MeshSubnets `json:"meshSubnets,omitempty"`
 }
 
 // NetworkConfigStatus defines the observed state of NetworkConfig.
@@ -58,6 +61,9 @@ type NetworkConfigStatus struct {
 	// The new subnet used to NAT the externalCIDR of the remote cluster. The original ExternalCIDR may have been mapped
 	// to this network by the remote cluster.
 	ExternalCIDRNAT string `json:"externalCIDRNAT,omitempty"`
+	// The next public key announced by the sender cluster in the backend configuration, which has already been
+	// configured by the receiver cluster. It acknowledges that the sender cluster can start using the new key.
+	AcceptedNextPublicKey string `json:"acceptedNextPublicKey,omitempty"`
 }
 
 // +kubebuilder:object:root=true
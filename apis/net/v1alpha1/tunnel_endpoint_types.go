@@ -78,6 +78,10 @@ type Connection struct {
 	Status            ConnectionStatus  `json:"status,omitempty"`
 	StatusMessage     string            `json:"statusMessage,omitempty"`
 	PeerConfiguration map[string]string `json:"peerConfiguration,omitempty"`
+	// Latency is the round-trip time towards the remote gateway, as measured by the last probe which got a reply.
+	Latency *metav1.Duration `json:"latency,omitempty"`
+	// PacketLoss is the percentage of the probes towards the remote gateway lost over the last probing window.
+	PacketLoss *int32 `json:"packetLoss,omitempty"`
 }
 
 // ConnectionStatus type that describes the status of vpn connection with a remote cluster.
@@ -100,6 +104,8 @@ const (
 // +kubebuilder:printcolumn:name="Endpoint IP",type=string,JSONPath=`.spec.endpointIP`,priority=1
 // +kubebuilder:printcolumn:name="Backend type",type=string,JSONPath=`.spec.backendType`
 // +kubebuilder:printcolumn:name="Connection status",type=string,JSONPath=`.status.connection.status`
+// +kubebuilder:printcolumn:name="Latency",type=string,JSONPath=`.status.connection.latency`,priority=1
+// +kubebuilder:printcolumn:name="Packet loss",type=integer,JSONPath=`.status.connection.packetLoss`,priority=1
 // +kubebuilder:printcolumn:name="Age",type=date,JSONPath=`.metadata.creationTimestamp`
 type TunnelEndpoint struct {
 	metav1.TypeMeta   `json:",inline"`
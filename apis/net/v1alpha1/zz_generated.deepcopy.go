@@ -20,6 +20,7 @@ limitations under the License.
 package v1alpha1
 
 import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 )
 
@@ -63,6 +64,16 @@ func (in *Connection) DeepCopyInto(out *Connection) {
 			(*out)[key] = val
 		}
 	}
+	if in.Latency != nil {
+		in, out := &in.Latency, &out.Latency
+		*out = new(metav1.Duration)
+		**out = **in
+	}
+	if in.PacketLoss != nil {
+		in, out := &in.PacketLoss, &out.PacketLoss
+		*out = new(int32)
+		**out = **in
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Connection.
@@ -141,6 +152,13 @@ func (in *IpamSpec) DeepCopyInto(out *IpamSpec) {
 			(*out)[key] = val
 		}
 	}
+	if in.MeshSubnets != nil {
+		in, out := &in.MeshSubnets, &out.MeshSubnets
+		*out = make(map[string]MeshSubnets, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new IpamSpec.
@@ -232,6 +250,21 @@ func (in Mappings) DeepCopy() Mappings {
 	return *out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *MeshSubnets) DeepCopyInto(out *MeshSubnets) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MeshSubnets.
+func (in *MeshSubnets) DeepCopy() *MeshSubnets {
+	if in == nil {
+		return nil
+	}
+	out := new(MeshSubnets)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *NatMapping) DeepCopyInto(out *NatMapping) {
 	*out = *in
@@ -397,6 +430,11 @@ func (in *NetworkConfigSpec) DeepCopyInto(out *NetworkConfigSpec) {
 			(*out)[key] = val
 		}
 	}
+	if in.MeshSubnets != nil {
+		in, out := &in.MeshSubnets, &out.MeshSubnets
+		*out = new(MeshSubnets)
+		**out = **in
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NetworkConfigSpec.
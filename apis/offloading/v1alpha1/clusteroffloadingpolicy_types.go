@@ -0,0 +1,64 @@
+/*
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	rbacv1 "k8s.io/api/rbac/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// ClusterOffloadingPolicySpec defines the remote clusters the selected namespaces are allowed to be offloaded to.
+type ClusterOffloadingPolicySpec struct {
+	// NamespaceSelector selects the namespaces this policy applies to, either individually (e.g. by means of the
+	// "kubernetes.io/metadata.name" label) or as a group sharing a common label. An empty selector selects all
+	// the namespaces. Apart from the name one, the labels must be among the ones configured in the webhook.
+	NamespaceSelector metav1.LabelSelector `json:"namespaceSelector"`
+
+	// Subjects lists the users, groups and service accounts granted this policy, i.e. allowed to make a namespace
+	// match it by setting, changing or removing the labels its NamespaceSelector refers to. The changes to those
+	// labels are refused to anyone else, unless authorized to update the ClusterOffloadingPolicies.
+	// +optional
+	Subjects []rbacv1.Subject `json:"subjects,omitempty"`
+
+	// AllowedClusters lists the cluster IDs of the ForeignClusters the selected namespaces can be offloaded to.
+	// +kubebuilder:validation:MinItems=1
+	AllowedClusters []string `json:"allowedClusters"`
+}
+
+// +kubebuilder:object:root=true
+// +kubebuilder:resource:scope=Cluster,shortName="cop"
+// +kubebuilder:printcolumn:name="AllowedClusters",type=string,JSONPath=`.spec.allowedClusters`
+// +kubebuilder:printcolumn:name="Age",type=date,JSONPath=`.metadata.creationTimestamp`
+
+// ClusterOffloadingPolicy is the Schema for the clusteroffloadingpolicies API. When at least one policy exists,
+// each namespace can be offloaded only to the remote clusters allowed by the policies selecting it, while it
+// cannot be offloaded at all if no policy selects it. If no policy exists, the offloading is not restricted.
+type ClusterOffloadingPolicy struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec ClusterOffloadingPolicySpec `json:"spec"`
+}
+
+// +kubebuilder:object:root=true
+
+// ClusterOffloadingPolicyList contains a list of ClusterOffloadingPolicy.
+type ClusterOffloadingPolicyList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []ClusterOffloadingPolicy `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&ClusterOffloadingPolicy{}, &ClusterOffloadingPolicyList{})
+}
@@ -45,6 +45,9 @@ const (
 	// DefaultNameMappingStrategyType -> the remote namespace is assigned a default name which ensures uniqueness
 	// and avoids conflicts (localNamespaceName-localClusterID).
 	DefaultNameMappingStrategyType NamespaceMappingStrategyType = "DefaultName"
+	// TemplateNameMappingStrategyType -> the remote namespace name is generated from the NamespaceNameTemplate
+	// (the creation may fail in case of conflicts).
+	TemplateNameMappingStrategyType NamespaceMappingStrategyType = "Template"
 )
 
 // PodOffloadingStrategyType represents different strategies to offload pods in this Namespace.
@@ -62,6 +65,87 @@ const (
 	LocalAndRemotePodOffloadingStrategyType PodOffloadingStrategyType = "LocalAndRemote"
 )
 
+// DrainPolicyType represents different strategies to withdraw a namespace from the clusters that
+// are no longer selected by the ClusterSelector.
+type DrainPolicyType string
+
+const (
+	// ImmediateDrainPolicyType -> the remote namespace is deleted as soon as the cluster is no longer selected,
+	// together with all the pods running in it.
+	ImmediateDrainPolicyType DrainPolicyType = "Immediate"
+	// GracefulEvictionDrainPolicyType -> the pods offloaded on the cluster are evicted through the Eviction API
+	// (hence respecting the PodDisruptionBudgets), and the remote namespace is deleted once all of them terminated.
+	GracefulEvictionDrainPolicyType DrainPolicyType = "GracefulEviction"
+	// KeepUntilEmptyDrainPolicyType -> no new pods are offloaded on the cluster, and the remote namespace is
+	// deleted only once all the pods already running in it terminated.
+	KeepUntilEmptyDrainPolicyType DrainPolicyType = "KeepUntilEmpty"
+)
+
+// DefaultOffloadingProfileName is the name of the implicit offloading profile defined by the top-level fields of
+// the NamespaceOffloading, which applies to the pods not selected by any other profile.
+const DefaultOffloadingProfileName = "default"
+
+// OffloadingProfile defines how a subset of the pods in the namespace, selected by their labels, is offloaded.
+type OffloadingProfile struct {
+	// Name identifies the profile, and it is reported in the status for the clusters it selects.
+	// +kubebuilder:validation:Pattern="^[a-z0-9]([-a-z0-9]*[a-z0-9])?$"
+	Name string `json:"name"`
+
+	// PodSelector selects the pods this profile applies to. When multiple profiles match a pod,
+	// the most specific one (i.e. the one with more requirements) is chosen.
+	PodSelector metav1.LabelSelector `json:"podSelector"`
+
+	// PodOffloadingStrategy configures how the selected pods are offloaded: "Local", "Remote" or "LocalAndRemote".
+	// +kubebuilder:validation:Enum="Local";"Remote";"LocalAndRemote"
+	// +kubebuilder:default="LocalAndRemote"
+	// +kubebuilder:validation:Optional
+	PodOffloadingStrategy PodOffloadingStrategyType `json:"podOffloadingStrategy"`
+
+	// ClusterSelector selects the remote clusters where the selected pods can be offloaded.
+	// If empty, the ClusterSelector of the NamespaceOffloading is inherited.
+	// +kubebuilder:validation:Optional
+	ClusterSelector corev1.NodeSelector `json:"clusterSelector,omitempty"`
+
+	// CostAwareScheduling allows to prefer the cheapest clusters when scheduling the selected pods.
+	// +kubebuilder:default=false
+	// +kubebuilder:validation:Optional
+	CostAwareScheduling bool `json:"costAwareScheduling,omitempty"`
+
+	// Placement defines the preferences driving the placement of the selected pods.
+	// If not set, the Placement of the NamespaceOffloading is inherited.
+	// +kubebuilder:validation:Optional
+	Placement *PlacementPreferences `json:"placement,omitempty"`
+}
+
+// PlacementPreferences defines the soft constraints driving the placement of the pods among the local
+// and the remote clusters, which are enforced in addition to the PodOffloadingStrategy and the ClusterSelector.
+type PlacementPreferences struct {
+	// PreferredClusters allows to weight the remote clusters by means of the standard Kubernetes preferred
+	// node affinity terms, evaluated against the labels of the virtual nodes. Weights range from 1 to 100.
+	// +kubebuilder:validation:Optional
+	PreferredClusters []corev1.PreferredSchedulingTerm `json:"preferredClusters,omitempty"`
+
+	// PreferLocal makes the pods prefer the local cluster, bursting into the remote ones only when the local
+	// resources are exhausted. It can be enabled only along with the "LocalAndRemote" PodOffloadingStrategy.
+	// +kubebuilder:default=false
+	// +kubebuilder:validation:Optional
+	PreferLocal bool `json:"preferLocal,omitempty"`
+
+	// MaxClusterSkew is the maximum difference in the number of replicas of the same workload scheduled on
+	// any two clusters, to prevent a single remote cluster from receiving most of them. The local nodes
+	// all count as the local cluster, being labeled with its cluster ID.
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:validation:Optional
+	MaxClusterSkew *int32 `json:"maxClusterSkew,omitempty"`
+
+	// WhenUnsatisfiable defines how to deal with the pods not satisfying the MaxClusterSkew: "ScheduleAnyway"
+	// (i.e. the clusters reducing the skew are preferred) or "DoNotSchedule" (i.e. the constraint is enforced).
+	// +kubebuilder:validation:Enum="DoNotSchedule";"ScheduleAnyway"
+	// +kubebuilder:default="ScheduleAnyway"
+	// +kubebuilder:validation:Optional
+	WhenUnsatisfiable corev1.UnsatisfiableConstraintAction `json:"whenUnsatisfiable,omitempty"`
+}
+
 // RemoteNamespaceConditionType represents different conditions that a remote namespace could assume.
 type RemoteNamespaceConditionType string
 
@@ -71,6 +155,12 @@ const (
 	NamespaceOffloadingRequired RemoteNamespaceConditionType = "OffloadingRequired"
 	// NamespaceReady, remote Namespace is correctly created and ready to be used.
 	NamespaceReady RemoteNamespaceConditionType = "Ready"
+	// NamespaceDraining, the cluster is no longer selected and the remote Namespace is waiting for its pods
+	// to terminate before being deleted.
+	NamespaceDraining RemoteNamespaceConditionType = "Draining"
+	// NamespaceReflectionReady, the objects of the local Namespace have been reflected in the remote one,
+	// hence the pods can be offloaded there.
+	NamespaceReflectionReady RemoteNamespaceConditionType = "ReflectionReady"
 )
 
 // RemoteNamespaceConditions list of RemoteNamespaceCondition.
@@ -92,14 +182,21 @@ type RemoteNamespaceCondition struct {
 
 // NamespaceOffloadingSpec defines the desired state of NamespaceOffloading.
 type NamespaceOffloadingSpec struct {
-	//  NamespaceMappingStrategy allows users to map local and remote namespace names according to two
-	//  different strategies: "DefaultName", which ensures uniqueness and prevents conflicts, and "EnforceSameName",
-	//  which enforces the same name at the cost of possible conflicts.
-	// +kubebuilder:validation:Enum="EnforceSameName";"DefaultName"
+	//  NamespaceMappingStrategy allows users to map local and remote namespace names according to three
+	//  different strategies: "DefaultName", which ensures uniqueness and prevents conflicts, "EnforceSameName",
+	//  which enforces the same name at the cost of possible conflicts, and "Template", which generates the name
+	//  from the NamespaceNameTemplate field.
+	// +kubebuilder:validation:Enum="EnforceSameName";"DefaultName";"Template"
 	// +kubebuilder:default="DefaultName"
 	// +kubebuilder:validation:Optional
 	NamespaceMappingStrategy NamespaceMappingStrategyType `json:"namespaceMappingStrategy"`
 
+	// NamespaceNameTemplate is the Go template used to generate the remote namespace name when the "Template"
+	// NamespaceMappingStrategy is selected (e.g. "tenant-{{.Namespace}}-{{.LocalClusterName}}").
+	// The available fields are .Namespace, .LocalClusterID and .LocalClusterName.
+	// +kubebuilder:validation:Optional
+	NamespaceNameTemplate string `json:"namespaceNameTemplate,omitempty"`
+
 	// PodOffloadingStrategy allows users to configure how pods in this namespace are offloaded, according to three
 	// different strategies: "Local" (i.e. no pod offloading is performed), "Remote" (i.e. all pods are offloaded
 	// in remote clusters), "LocalAndRemote" (i.e. no constraints are enforced besides the ones
@@ -113,6 +210,79 @@ type NamespaceOffloadingSpec struct {
 	// pod offloading by means of the standard Kubernetes NodeSelector approach
 	// (https://kubernetes.io/docs/concepts/scheduling-eviction/assign-pod-node/#node-affinity).
 	ClusterSelector corev1.NodeSelector `json:"clusterSelector,omitempty"`
+
+	// DrainPolicy allows users to configure how the namespace is withdrawn from the clusters that are no longer
+	// selected by the ClusterSelector, according to three different strategies: "Immediate" (i.e. the remote
+	// namespace is deleted straight away), "GracefulEviction" (i.e. the offloaded pods are evicted before deleting
+	// the remote namespace) and "KeepUntilEmpty" (i.e. the remote namespace is deleted once all the offloaded
+	// pods terminated on their own).
+	// +kubebuilder:validation:Enum="Immediate";"GracefulEviction";"KeepUntilEmpty"
+	// +kubebuilder:default="Immediate"
+	// +kubebuilder:validation:Optional
+	DrainPolicy DrainPolicyType `json:"drainPolicy"`
+
+	// CostAwareScheduling allows users to prefer the cheapest clusters, according to the prices published
+	// in their ResourceOffers, when scheduling the pods in this namespace. The local cluster is considered free.
+	// +kubebuilder:default=false
+	// +kubebuilder:validation:Optional
+	CostAwareScheduling bool `json:"costAwareScheduling,omitempty"`
+
+	// Placement allows users to express soft preferences about the placement of the pods in this namespace,
+	// such as weighting the remote clusters, preferring the local cluster and bounding the share of replicas
+	// scheduled on each cluster.
+	// +kubebuilder:validation:Optional
+	Placement *PlacementPreferences `json:"placement,omitempty"`
+
+	// Profiles allows users to offload different workloads in this namespace according to different strategies,
+	// selecting the pods by means of their labels. The pods not selected by any profile are offloaded according
+	// to the top-level fields (i.e. the "default" profile). The remote namespace is created on all the clusters
+	// selected by at least one profile.
+	// +kubebuilder:validation:Optional
+	Profiles []OffloadingProfile `json:"profiles,omitempty"`
+
+	// Migration allows users to migrate the pods already running in this namespace when the offloading policies
+	// change, so that they are recreated according to the new ones. If not set, the new policies apply only to
+	// the pods created afterwards.
+	// +kubebuilder:validation:Optional
+	Migration *MigrationPolicy `json:"migration,omitempty"`
+}
+
+// MigrationPolicy defines how the running pods not complying with the offloading policies are migrated.
+// The pods are evicted, honouring their PodDisruptionBudgets, and recreated by their controllers, hence
+// the pods not managed by a controller are not migrated.
+type MigrationPolicy struct {
+	// BatchSize is the maximum number of pods being migrated at the same time.
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:default=1
+	// +kubebuilder:validation:Optional
+	BatchSize int32 `json:"batchSize,omitempty"`
+}
+
+// MigrationPhaseType represents the phase of the migration of the pods in a namespace.
+type MigrationPhaseType string
+
+const (
+	// MigrationInProgressPhaseType -> some pods do not comply with the offloading policies and are being migrated.
+	MigrationInProgressPhaseType MigrationPhaseType = "InProgress"
+	// MigrationCompletedPhaseType -> all the pods managed by a controller comply with the offloading policies.
+	MigrationCompletedPhaseType MigrationPhaseType = "Completed"
+)
+
+// MigrationStatus reports the progress of the migration of the pods in a namespace.
+type MigrationStatus struct {
+	// Phase of the migration, either InProgress or Completed.
+	Phase MigrationPhaseType `json:"phase"`
+	// PendingPods -> number of pods not complying with the offloading policies, which are still to be migrated.
+	PendingPods int32 `json:"pendingPods"`
+	// MigratingPods -> number of pods evicted and not yet terminated.
+	MigratingPods int32 `json:"migratingPods"`
+	// BlockedPods -> number of pods whose eviction is currently denied by a PodDisruptionBudget.
+	BlockedPods int32 `json:"blockedPods"`
+	// UnmanagedPods -> number of pods not complying with the offloading policies, which cannot be migrated
+	// since they are not managed by a controller.
+	UnmanagedPods int32 `json:"unmanagedPods"`
+	// LastTransitionTime -> timestamp for when the migration last transitioned from one phase to another.
+	LastTransitionTime metav1.Time `json:"lastTransitionTime,omitempty"`
 }
 
 // NamespaceOffloadingStatus defines the observed state of NamespaceOffloading.
@@ -130,6 +300,14 @@ type NamespaceOffloadingStatus struct {
 	// RemoteNamespacesConditions -> allows user to verify remote Namespaces' presence and status on all remote
 	// clusters through RemoteNamespaceCondition.
 	RemoteNamespacesConditions map[string]RemoteNamespaceConditions `json:"remoteNamespacesConditions,omitempty"`
+	// ClusterProfiles -> reports, for each remote cluster where the namespace is offloaded, the names of the
+	// offloading profiles selecting it.
+	ClusterProfiles map[string][]string `json:"clusterProfiles,omitempty"`
+	// Migration -> reports the progress of the migration of the pods not complying with the offloading policies.
+	Migration *MigrationStatus `json:"migration,omitempty"`
+	// DeniedClusters -> reports the remote clusters selected by the ClusterSelector where the namespace cannot be
+	// offloaded, since they are not allowed by the ClusterOffloadingPolicies.
+	DeniedClusters []string `json:"deniedClusters,omitempty"`
 }
 
 // +kubebuilder:object:root=true
@@ -53,6 +53,20 @@ const (
 	VirtualKubeletStatusDeleting VirtualKubeletStatus = "Deleting"
 )
 
+// OfferExpirationStatus indicates whether the ResourceOffer has been renewed before the end of its TimeToLive.
+type OfferExpirationStatus string
+
+const (
+	// ResourceOfferValid indicates that the ResourceOffer has not expired yet.
+	ResourceOfferValid OfferExpirationStatus = "Valid"
+	// ResourceOfferExpired indicates that the ResourceOffer has not been renewed before the end of its TimeToLive,
+	// the virtual node is cordoned until a new renewal.
+	ResourceOfferExpired OfferExpirationStatus = "Expired"
+	// ResourceOfferWithdrawn indicates that the ResourceOffer has not been renewed before the end of the grace period,
+	// and it is handled as if it was withdrawn by the remote cluster.
+	ResourceOfferWithdrawn OfferExpirationStatus = "Withdrawn"
+)
+
 // ResourceOfferStatus defines the observed state of ResourceOffer.
 type ResourceOfferStatus struct {
 	// Phase is the status of this ResourceOffer.
@@ -61,10 +75,18 @@ type ResourceOfferStatus struct {
 	// +kubebuilder:validation:Enum="Pending";"ManualActionRequired";"Accepted";"Refused"
 	// +kubebuilder:default="Pending"
 	Phase OfferPhase `json:"phase"`
+	// Reason is a brief CamelCase string describing the rule which determined the phase of this ResourceOffer.
+	Reason string `json:"reason,omitempty"`
+	// Message is a human readable explanation of the phase of this ResourceOffer.
+	Message string `json:"message,omitempty"`
 	// VirtualKubeletStatus indicates if the virtual-kubelet for this ResourceOffer has been created or not.
 	// +kubebuilder:validation:Enum="None";"Created";"Deleting"
 	// +kubebuilder:default="None"
 	VirtualKubeletStatus VirtualKubeletStatus `json:"virtualKubeletStatus,omitempty"`
+	// ExpirationStatus indicates if the ResourceOffer has been renewed before the end of its TimeToLive.
+	// +kubebuilder:validation:Enum="Valid";"Expired";"Withdrawn"
+	// +kubebuilder:default="Valid"
+	ExpirationStatus OfferExpirationStatus `json:"expirationStatus,omitempty"`
 }
 
 // +kubebuilder:object:root=true
@@ -74,6 +96,7 @@ type ResourceOfferStatus struct {
 // ResourceOffer is the Schema for the resourceOffers API.
 // +kubebuilder:printcolumn:name="Status",type=string,JSONPath=`.status.phase`
 // +kubebuilder:printcolumn:name="VirtualKubeletStatus",type=string,JSONPath=`.status.virtualKubeletStatus`
+// +kubebuilder:printcolumn:name="Expiration",type=string,JSONPath=`.status.expirationStatus`
 // +kubebuilder:printcolumn:name="Local",type=string,JSONPath=`.metadata.labels.liqo\.io/replication`
 // +kubebuilder:printcolumn:name="Age",type=date,JSONPath=`.metadata.creationTimestamp`
 type ResourceOffer struct {
@@ -37,6 +37,15 @@ type RemoteNamespaceStatus struct {
 	Phase MappingPhase `json:"phase,omitempty"`
 }
 
+// NamespaceReflectionStatus contains some information about the reflection of a local namespace in the remote cluster.
+type NamespaceReflectionStatus struct {
+	// Ready is true once the virtual kubelet has reflected in the remote namespace all the objects (i.e. configmaps,
+	// secrets and services) of the local namespace, hence the pods can be offloaded there.
+	Ready bool `json:"ready"`
+	// LastTransitionTime is the last time the reflection became ready.
+	LastTransitionTime metav1.Time `json:"lastTransitionTime,omitempty"`
+}
+
 // NamespaceMapSpec defines the desired state of NamespaceMap.
 type NamespaceMapSpec struct {
 
@@ -52,6 +61,10 @@ type NamespaceMapStatus struct {
 	// CurrentMapping is filled by NamespaceMap Controller, when a new remote namespace's creation is requested. The key
 	// is the local namespace name, while the value is a summary of new remote namespace's status.
 	CurrentMapping map[string]RemoteNamespaceStatus `json:"currentMapping,omitempty"`
+
+	// ReflectionStatus is filled by the virtual kubelet, once the reflection of a local namespace (the key) has
+	// completed its initial synchronization. The entry is removed when the reflection of the namespace is stopped.
+	ReflectionStatus map[string]NamespaceReflectionStatus `json:"reflectionStatus,omitempty"`
 }
 
 // +kubebuilder:object:root=true
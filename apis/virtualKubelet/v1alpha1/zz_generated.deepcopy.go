@@ -114,6 +114,13 @@ func (in *NamespaceMapStatus) DeepCopyInto(out *NamespaceMapStatus) {
 			(*out)[key] = val
 		}
 	}
+	if in.ReflectionStatus != nil {
+		in, out := &in.ReflectionStatus, &out.ReflectionStatus
+		*out = make(map[string]NamespaceReflectionStatus, len(*in))
+		for key, val := range *in {
+			(*out)[key] = *val.DeepCopy()
+		}
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NamespaceMapStatus.
@@ -229,6 +236,22 @@ func (in *NamespaceNattingTableStatus) DeepCopy() *NamespaceNattingTableStatus {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *NamespaceReflectionStatus) DeepCopyInto(out *NamespaceReflectionStatus) {
+	*out = *in
+	in.LastTransitionTime.DeepCopyInto(&out.LastTransitionTime)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NamespaceReflectionStatus.
+func (in *NamespaceReflectionStatus) DeepCopy() *NamespaceReflectionStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(NamespaceReflectionStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *RemoteNamespaceStatus) DeepCopyInto(out *RemoteNamespaceStatus) {
 	*out = *in
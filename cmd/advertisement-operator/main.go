@@ -38,6 +38,7 @@ import (
 	resourceRequestOperator "github.com/liqotech/liqo/internal/resource-request-operator"
 	"github.com/liqotech/liqo/pkg/clusterid"
 	crdclient "github.com/liqotech/liqo/pkg/crdClient"
+	localnodectrl "github.com/liqotech/liqo/pkg/liqo-controller-manager/localNode-controller"
 	namectrl "github.com/liqotech/liqo/pkg/liqo-controller-manager/namespace-controller"
 	mapsctrl "github.com/liqotech/liqo/pkg/liqo-controller-manager/namespaceMap-controller"
 	nsoffctrl "github.com/liqotech/liqo/pkg/liqo-controller-manager/namespaceOffloading-controller"
@@ -63,6 +64,7 @@ var (
 func init() {
 	_ = clientgoscheme.AddToScheme(scheme)
 
+	_ = configv1alpha1.AddToScheme(scheme)
 	_ = sharingv1alpha1.AddToScheme(scheme)
 	_ = netv1alpha1.AddToScheme(scheme)
 	_ = discoveryv1alpha1.AddToScheme(scheme)
@@ -195,6 +197,15 @@ func main() {
 		klog.Fatal(err)
 	}
 
+	localNodeReconciler := &localnodectrl.LocalNodeReconciler{
+		Client:         mgr.GetClient(),
+		LocalClusterID: clusterId,
+	}
+
+	if err = localNodeReconciler.SetupWithManager(mgr); err != nil {
+		klog.Fatal(err)
+	}
+
 	namespaceMapReconciler := &mapsctrl.NamespaceMapReconciler{
 		Client:                mgr.GetClient(),
 		RemoteClients:         make(map[string]kubernetes.Interface),
@@ -219,6 +230,7 @@ func main() {
 
 	namespaceOffloadingReconciler := &nsoffctrl.NamespaceOffloadingReconciler{
 		Client:         mgr.GetClient(),
+		ClientSet:      clientset,
 		Scheme:         mgr.GetScheme(),
 		LocalClusterID: clusterId,
 	}
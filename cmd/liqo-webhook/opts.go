@@ -2,6 +2,7 @@ package main
 
 import (
 	"os"
+	"strings"
 
 	"github.com/liqotech/liqo/pkg/mutate"
 )
@@ -19,4 +20,10 @@ func setOptions(c *mutate.MutationConfig) {
 	if c.CertFile = os.Getenv("LIQO_CERT"); c.CertFile == "" {
 		c.CertFile = defaultCertFile
 	}
+
+	for _, label := range strings.Split(os.Getenv("LIQO_OFFLOADING_POLICY_LABELS"), ",") {
+		if label = strings.TrimSpace(label); label != "" {
+			c.OffloadingPolicyLabels = append(c.OffloadingPolicyLabels, label)
+		}
+	}
 }
@@ -0,0 +1,155 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+
+	"k8s.io/client-go/dynamic"
+	"k8s.io/klog/v2"
+	ctrl "sigs.k8s.io/controller-runtime"
+
+	netv1alpha1 "github.com/liqotech/liqo/apis/net/v1alpha1"
+	liqonetIpam "github.com/liqotech/liqo/pkg/liqonet/ipam"
+	"github.com/liqotech/liqo/pkg/liqonet/meshplanner"
+)
+
+// Actions accepted by the liqo-ipam tool.
+const (
+	ipamActionCheck      = "check"
+	ipamActionExport     = "export"
+	ipamActionImport     = "import"
+	ipamActionRebuild    = "rebuild"
+	ipamActionMeshPlan   = "mesh-plan"
+	ipamActionMeshRecord = "mesh-record"
+)
+
+// runIPAMTool performs the given action on the IPAM state. The state is written to (export) or read from (import)
+// the given file, where "-" stands for the standard output and input respectively. The import and rebuild actions
+// are refused while the network manager running in the given namespace holds its lease. The mesh-plan action does not
+// access the cluster: it reads the description of the mesh from the file, and writes the plan computed using
+// the given mode, while the mesh-record action reads such a plan and records its networks in the IPAM.
+func runIPAMTool(action, stateFile, namespace, meshMode string) error {
+	switch action {
+	case ipamActionCheck:
+		inconsistencies, err := liqonetIpam.CheckConsistency(newDynamicClient())
+		if err != nil {
+			return err
+		}
+		for _, inconsistency := range inconsistencies {
+			klog.Warning(inconsistency.String())
+		}
+		if len(inconsistencies) > 0 {
+			return fmt.Errorf("%d inconsistencies found in the IPAM state", len(inconsistencies))
+		}
+		klog.Info("the IPAM state is consistent")
+	case ipamActionExport:
+		state, err := liqonetIpam.ExportState(newDynamicClient())
+		if err != nil {
+			return err
+		}
+		return writeToolFile(stateFile, state)
+	case ipamActionImport:
+		state, err := readToolFile(stateFile)
+		if err != nil {
+			return fmt.Errorf("cannot read the IPAM state: %w", err)
+		}
+		return liqonetIpam.ImportState(newDynamicClient(), namespace, state)
+	case ipamActionRebuild:
+		pools := append(append([]string{}, liqonetIpam.Pools...), liqonetIpam.IPv6Pools...)
+		if err := liqonetIpam.RebuildState(newDynamicClient(), namespace, pools); err != nil {
+			return err
+		}
+		klog.Info("the IPAM state has been rebuilt")
+	case ipamActionMeshPlan:
+		return planMesh(stateFile, meshplanner.Mode(meshMode))
+	case ipamActionMeshRecord:
+		return recordMesh(stateFile)
+	default:
+		return fmt.Errorf("unsupported action %q", action)
+	}
+	return nil
+}
+
+// planMesh computes and validates the plan of the mesh described in the given file, and writes it to the standard output.
+func planMesh(meshFile string, mode meshplanner.Mode) error {
+	data, err := readToolFile(meshFile)
+	if err != nil {
+		return fmt.Errorf("cannot read the mesh: %w", err)
+	}
+	var mesh meshplanner.Mesh
+	if err := json.Unmarshal(data, &mesh); err != nil {
+		return fmt.Errorf("cannot decode the mesh: %w", err)
+	}
+	pools := append(append([]string{}, liqonetIpam.Pools...), liqonetIpam.IPv6Pools...)
+	plan, err := meshplanner.ComputePlan(&mesh, mode, pools)
+	if err != nil {
+		return err
+	}
+	output, err := json.MarshalIndent(plan, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := writeToolFile("-", append(output, '\n')); err != nil {
+		return err
+	}
+	violations, err := meshplanner.Validate(&mesh, plan)
+	if err != nil {
+		return err
+	}
+	for _, violation := range violations {
+		klog.Warning(violation.String())
+	}
+	if len(violations) > 0 {
+		return fmt.Errorf("%d violations found in the plan", len(violations))
+	}
+	klog.Info("the plan is valid")
+	return nil
+}
+
+// recordMesh records in the IPAM the networks of the mesh plan read from the given file. The same plan has to be
+// recorded in every cluster of the mesh, since the peerings are refused if the clusters disagree on it.
+func recordMesh(planFile string) error {
+	data, err := readToolFile(planFile)
+	if err != nil {
+		return fmt.Errorf("cannot read the plan: %w", err)
+	}
+	var plan meshplanner.Plan
+	if err := json.Unmarshal(data, &plan); err != nil {
+		return fmt.Errorf("cannot decode the plan: %w", err)
+	}
+	if plan.Mode != meshplanner.MeshMode || len(plan.Networks) == 0 {
+		return fmt.Errorf("the plan does not contain the networks of a mesh")
+	}
+	meshSubnets := make(map[string]netv1alpha1.MeshSubnets, len(plan.Networks))
+	for clusterID, networks := range plan.Networks {
+		meshSubnets[clusterID] = netv1alpha1.MeshSubnets{PodCIDR: networks.PodCIDR, ExternalCIDR: networks.ExternalCIDR}
+	}
+	if err := liqonetIpam.RecordMeshSubnets(newDynamicClient(), meshSubnets); err != nil {
+		return err
+	}
+	klog.Infof("the networks of %d clusters have been recorded", len(meshSubnets))
+	return nil
+}
+
+func newDynamicClient() dynamic.Interface {
+	return dynamic.NewForConfigOrDie(ctrl.GetConfigOrDie())
+}
+
+// readToolFile reads the given file, where "-" stands for the standard input.
+func readToolFile(file string) ([]byte, error) {
+	if file == "-" {
+		return ioutil.ReadAll(os.Stdin)
+	}
+	return ioutil.ReadFile(file)
+}
+
+// writeToolFile writes the given file, where "-" stands for the standard output.
+func writeToolFile(file string, data []byte) error {
+	if file == "-" {
+		_, err := os.Stdout.Write(data)
+		return err
+	}
+	return ioutil.WriteFile(file, data, 0600)
+}
@@ -21,6 +21,7 @@ import (
 	"sync"
 	"time"
 
+	"github.com/containernetworking/plugins/pkg/ns"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/client-go/dynamic"
 	"k8s.io/client-go/kubernetes"
@@ -30,6 +31,7 @@ import (
 	"k8s.io/klog/v2"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/cache"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
 
 	clusterConfig "github.com/liqotech/liqo/apis/config/v1alpha1"
 	netv1alpha1 "github.com/liqotech/liqo/apis/net/v1alpha1"
@@ -38,9 +40,13 @@ import (
 	"github.com/liqotech/liqo/internal/liqonet/tunnelEndpointCreator"
 	liqoconst "github.com/liqotech/liqo/pkg/consts"
 	liqonetIpam "github.com/liqotech/liqo/pkg/liqonet/ipam"
+	"github.com/liqotech/liqo/pkg/liqonet/iptables"
+	"github.com/liqotech/liqo/pkg/liqonet/meshplanner"
 	liqonetns "github.com/liqotech/liqo/pkg/liqonet/netns"
 	"github.com/liqotech/liqo/pkg/liqonet/overlay"
 	liqorouting "github.com/liqotech/liqo/pkg/liqonet/routing"
+	"github.com/liqotech/liqo/pkg/liqonet/tunnel"
+	tunnelwg "github.com/liqotech/liqo/pkg/liqonet/tunnel/wireguard"
 	"github.com/liqotech/liqo/pkg/liqonet/utils"
 	"github.com/liqotech/liqo/pkg/mapperUtils"
 )
@@ -62,8 +68,8 @@ func init() {
 }
 
 func main() {
-	var metricsAddr, runAs string
-	var enableLeaderElection bool
+	var metricsAddr, runAs, natBackend, tunnelBackend, ipamAction, ipamStateFile, ipamNamespace, meshMode string
+	var enableLeaderElection, conntrackSync bool
 	leaseDuration := 7 * time.Second
 	renewDeadLine := 5 * time.Second
 	retryPeriod := 2 * time.Second
@@ -71,10 +77,41 @@ func main() {
 	flag.BoolVar(&enableLeaderElection, "leader-elect", false,
 		"Enable leader election for controller manager. Enabling this will ensure there is only one active controller manager.")
 	flag.StringVar(&runAs, "run-as", liqoconst.LiqoGatewayOperatorName,
-		"The accepted values are: liqo-gateway, liqo-route, tunnelEndpointCreator-operator. The default value is \"liqo-gateway\"")
+		"The accepted values are: liqo-gateway, liqo-route, tunnelEndpointCreator-operator, liqo-wireguard-userspace, liqo-ipam. "+
+			"The default value is \"liqo-gateway\"")
+	flag.StringVar(&natBackend, "nat-backend", string(iptables.AutoBackend),
+		"The backend used by the liqo-gateway to configure the NAT rules. The accepted values are: iptables, nftables, auto")
+	flag.BoolVar(&conntrackSync, "conntrack-sync", false,
+		"Synchronize the connection tracking state of the liqo-gateway with the standby replica through conntrackd, "+
+			"so that the NATed connections survive the failover")
+	flag.StringVar(&tunnelBackend, "tunnel-backend", tunnelwg.DriverName,
+		"The vpn technology used to interconnect the clusters, advertised to the remote ones. The accepted values are: wireguard, ipsec")
+	flag.StringVar(&ipamAction, "ipam-action", ipamActionCheck,
+		"The action performed on the IPAM state when running as liqo-ipam. "+
+			"The accepted values are: check, export, import, rebuild, mesh-plan, mesh-record")
+	flag.StringVar(&ipamStateFile, "ipam-state-file", "-",
+		"The file the IPAM state is exported to or imported from when running as liqo-ipam. \"-\" stands for stdout/stdin. "+
+			"The mesh-plan action reads the description of the mesh from it, while the mesh-record action reads the plan")
+	flag.StringVar(&ipamNamespace, "ipam-namespace", "liqo",
+		"The namespace where the network manager is running. The import and rebuild actions of liqo-ipam are refused "+
+			"as long as the network manager holds its lease in this namespace")
+	flag.StringVar(&meshMode, "mesh-mode", string(meshplanner.MeshMode),
+		"The mode used by the mesh-plan action to remap the networks of the clusters. The accepted values are: mesh, independent")
 	flag.Parse()
 
 	switch runAs {
+	case liqoconst.LiqoWireguardUserspaceName:
+		// Spawned by the liqo-gateway when the WireGuard kernel module is not available.
+		if err := tunnelwg.RunUserspaceDevice(tunnelwg.DeviceName, tunnelwg.MTU, ctrl.SetupSignalHandler().Done()); err != nil {
+			klog.Errorf("an error occurred while running the userspace wireguard implementation: %v", err)
+			os.Exit(1)
+		}
+	case liqoconst.LiqoIPAMToolName:
+		// The network manager has to be stopped before importing or rebuilding the IPAM state.
+		if err := runIPAMTool(ipamAction, ipamStateFile, ipamNamespace, meshMode); err != nil {
+			klog.Errorf("unable to %s the IPAM state: %v", ipamAction, err)
+			os.Exit(1)
+		}
 	case liqoconst.LiqoRouteOperatorName:
 		mutex := &sync.RWMutex{}
 		nodeMap := map[string]string{}
@@ -209,14 +246,32 @@ func main() {
 		}
 		klog.Infof("created custom network namespace {%s}", liqoconst.GatewayNetnsName)
 
-		labelController := tunneloperator.NewLabelerController(podIP.String(), labelMgr.GetClient())
+		// The NAT handler is shared by the tunnel-operator and the natmapping-operator,
+		// and the backend is detected in the namespace it configures.
+		backend, err := iptables.ParseBackend(natBackend)
+		if err != nil {
+			klog.Error(err)
+			os.Exit(1)
+		}
+		var natHandler iptables.NATHandler
+		if err = gatewayNetns.Do(func(netNamespace ns.NetNS) error {
+			natHandler, err = iptables.NewNATHandler(backend)
+			return err
+		}); err != nil {
+			klog.Errorf("an error occurred while creating the NAT handler: %v", err)
+			os.Exit(1)
+		}
+
+		// The label manager runs on every replica: the labeler controller moves the gateway service
+		// on the current replica once it is elected leader.
+		labelController := tunneloperator.NewLabelerController(podIP.String(), mainMgr.Elected(), labelMgr.GetClient())
 		if err = labelController.SetupWithManager(labelMgr); err != nil {
 			klog.Errorf("unable to setup labeler controller: %s", err)
 			os.Exit(1)
 		}
 		tunnelController, err := tunneloperator.NewTunnelController(podIP.String(),
 			podNamespace, eventRecorder, clientset, mainMgr.GetClient(), &readyClustersMutex,
-			readyClusters, gatewayNetns)
+			readyClusters, gatewayNetns, natHandler)
 		if err != nil {
 			klog.Errorf("an error occurred while creating the tunnel controller: %v", err)
 			_ = tunnelController.CleanUpConfiguration(liqoconst.GatewayNetnsName, liqoconst.HostVethName)
@@ -227,8 +282,34 @@ func main() {
 			klog.Errorf("unable to setup tunnel controller: %s", err)
 			os.Exit(1)
 		}
+		if err = mainMgr.Add(manager.RunnableFunc(tunnelController.RunConnectionChecks)); err != nil {
+			klog.Errorf("unable to add the connection checker to the main manager: %s", err)
+			os.Exit(1)
+		}
+		// The standby replicas prepare the gateway netns for the remote clusters, to speed up the failover.
+		if err = mainMgr.Add(tunneloperator.NewStandbyPreparer(tunnelController, mainMgr.Elected())); err != nil {
+			klog.Errorf("unable to add the standby preparer to the main manager: %s", err)
+			os.Exit(1)
+		}
+		// The connection tracking state is synchronized by every replica, acting as primary once elected leader.
+		if conntrackSync {
+			conntrackSyncer := tunneloperator.NewConntrackSyncer(podIP.String(), podNamespace, mainMgr.Elected(),
+				labelMgr.GetClient(), gatewayNetns)
+			if err = labelMgr.Add(conntrackSyncer); err != nil {
+				klog.Errorf("unable to add the conntrack syncer to the label manager: %s", err)
+				os.Exit(1)
+			}
+		}
+		// The keys controller runs on every replica, since each one has its own wireguard device.
+		keysController, err := tunneloperator.NewKeysController(labelMgr.GetClient(), tunnelController)
+		if err != nil {
+			klog.Warningf("wireguard keys rotation not available: %v", err)
+		} else if err = keysController.SetupWithManager(labelMgr); err != nil {
+			klog.Errorf("unable to setup keys controller: %s", err)
+			os.Exit(1)
+		}
 		natMappingController, err := tunneloperator.NewNatMappingController(mainMgr.GetClient(), &readyClustersMutex,
-			readyClusters, gatewayNetns)
+			readyClusters, gatewayNetns, natHandler)
 		if err != nil {
 			klog.Errorf("an error occurred while creating the natmapping controller: %v", err)
 			os.Exit(1)
@@ -248,10 +329,29 @@ func main() {
 			os.Exit(1)
 		}
 	case "tunnelEndpointCreator-operator":
+		if _, ok := tunnel.Drivers[tunnelBackend]; !ok {
+			klog.Errorf("unsupported tunnel backend %q", tunnelBackend)
+			os.Exit(1)
+		}
+		podNamespace, err := utils.GetPodNamespace()
+		if err != nil {
+			klog.Errorf("unable to get pod namespace: %v", err)
+			os.Exit(1)
+		}
+		// The lease is held as long as the network manager is running, and it is checked by the liqo-ipam tool
+		// before modifying the IPAM state.
 		mgr, err := ctrl.NewManager(ctrl.GetConfigOrDie(), ctrl.Options{
-			MapperProvider:     mapperUtils.LiqoMapperProvider(scheme),
-			Scheme:             scheme,
-			MetricsBindAddress: metricsAddr,
+			MapperProvider:                mapperUtils.LiqoMapperProvider(scheme),
+			Scheme:                        scheme,
+			MetricsBindAddress:            metricsAddr,
+			LeaderElection:                true,
+			LeaderElectionID:              liqoconst.NetworkManagerLeaderElectionID,
+			LeaderElectionNamespace:       podNamespace,
+			LeaderElectionReleaseOnCancel: true,
+			LeaderElectionResourceLock:    resourcelock.LeasesResourceLock,
+			LeaseDuration:                 &leaseDuration,
+			RenewDeadline:                 &renewDeadLine,
+			RetryPeriod:                   &retryPeriod,
 		})
 		if err != nil {
 			klog.Errorf("unable to get manager: %s", err)
@@ -260,7 +360,9 @@ func main() {
 		clientset := kubernetes.NewForConfigOrDie(mgr.GetConfig())
 		dynClient := dynamic.NewForConfigOrDie(mgr.GetConfig())
 		ipam := liqonetIpam.NewIPAM()
-		err = ipam.Init(liqonetIpam.Pools, dynClient, liqoconst.NetworkManagerIpamPort)
+		// The IPv6 pools are used only to remap the networks of the IPv6 clusters.
+		pools := append(append([]string{}, liqonetIpam.Pools...), liqonetIpam.IPv6Pools...)
+		err = ipam.Init(pools, dynClient, liqoconst.NetworkManagerIpamPort)
 		if err != nil {
 			klog.Errorf("cannot init IPAM:%w", err)
 		}
@@ -279,6 +381,7 @@ func main() {
 			ForeignClusterStopWatcher:  make(chan struct{}),
 			IPManager:                  ipam,
 			RetryTimeout:               30 * time.Second,
+			BackendType:                tunnelBackend,
 		}
 		r.WaitConfig.Add(3)
 		//starting configuration watcher
@@ -295,6 +398,7 @@ func main() {
 		go r.StartForeignClusterWatcher()
 		go r.StartServiceWatcher()
 		go r.StartSecretWatcher()
+		go r.StartKeyRotation()
 		klog.Info("starting manager as tunnelEndpointCreator-operator")
 		if err := mgr.Start(r.SetupSignalHandlerForTunEndCreator()); err != nil {
 			klog.Errorf("an error occurred while starting manager: %s", err)
@@ -0,0 +1,60 @@
+// Package main contains the scheduler extender, to be registered in the kube-scheduler configuration as
+// a prioritize extender with nodeCacheCapable set to false, e.g.:
+//
+//	extenders:
+//	- urlPrefix: "http://liqo-scheduler-extender.liqo.svc:8080"
+//	  prioritizeVerb: "prioritize"
+//	  weight: 1
+//	  nodeCacheCapable: false
+//
+// When deployed through the Helm chart (schedulerExtender.enable=true), the complete configuration is
+// available in the liqo-scheduler-extender ConfigMap.
+package main
+
+import (
+	"context"
+	"flag"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"k8s.io/klog/v2"
+
+	schedulerextender "github.com/liqotech/liqo/pkg/scheduler-extender"
+)
+
+const gracefulPeriod = 5 * time.Second
+
+func main() {
+	config := &schedulerextender.ExtenderConfig{}
+	flag.StringVar(&config.Address, "address", ":8080", "The address the scheduler extender listens to")
+	flag.StringVar(&config.CertFile, "cert-file", "", "The TLS certificate (if not set, the server uses plain HTTP)")
+	flag.StringVar(&config.KeyFile, "key-file", "", "The TLS key (if not set, the server uses plain HTTP)")
+	klog.InitFlags(nil)
+	flag.Parse()
+
+	klog.Info("Starting server ...")
+
+	ctx, cancel := context.WithCancel(context.Background())
+	ctxSignal, stop := signal.NotifyContext(ctx, syscall.SIGINT, syscall.SIGTERM)
+
+	s := schedulerextender.NewExtenderServer(config)
+
+	go func() {
+		defer cancel()
+
+		<-ctxSignal.Done()
+		// Restore default signal handler.
+		stop()
+
+		ctxShutdown, cancelShutdown := context.WithTimeout(ctx, gracefulPeriod)
+		defer cancelShutdown()
+
+		klog.Info("Received signal, shutting down")
+		s.Shutdown(ctxShutdown)
+	}()
+
+	s.Serve()
+	<-ctx.Done()
+	klog.Info("Liqo scheduler extender cleanly shutdown")
+}
@@ -10,6 +10,7 @@ import (
 	"k8s.io/client-go/dynamic"
 	"k8s.io/klog/v2"
 
+	liqonetIpam "github.com/liqotech/liqo/pkg/liqonet/ipam"
 	"github.com/liqotech/liqo/pkg/uninstaller"
 	"github.com/liqotech/liqo/pkg/utils"
 )
@@ -17,6 +18,8 @@ import (
 // cluster-role
 // +kubebuilder:rbac:groups=net.liqo.io,resources=tunnelendpoints,verbs=get;list;watch;
 // +kubebuilder:rbac:groups=net.liqo.io,resources=networkconfigs,verbs=get;list;watch;
+// +kubebuilder:rbac:groups=net.liqo.io,resources=ipamstorages,verbs=get;list;
+// +kubebuilder:rbac:groups=net.liqo.io,resources=natmappings,verbs=get;list;
 // +kubebuilder:rbac:groups=config.liqo.io,resources=clusterconfigs,verbs=get;list;watch;patch;update
 // +kubebuilder:rbac:groups=core,resources=events,verbs=create;patch
 // +kubebuilder:rbac:groups=discovery.liqo.io,resources=foreignclusters,verbs=get;list;watch;patch;update;delete;deletecollection;
@@ -44,6 +47,15 @@ func main() {
 	client := dynamic.NewForConfigOrDie(config)
 	klog.Infof("Loaded dynamic client: %s", kubeconfigPath)
 
+	// Report the IPAM inconsistencies before the unjoin, since the release of the networks relies on the IPAM state.
+	inconsistencies, err := liqonetIpam.CheckConsistency(client)
+	if err != nil {
+		klog.Warningf("Unable to check the consistency of the IPAM state: %s", err)
+	}
+	for _, inconsistency := range inconsistencies {
+		klog.Warningf("IPAM inconsistency: %s", inconsistency)
+	}
+
 	// Trigger unjoin clusters
 	err = uninstaller.UnjoinClusters(ctx, client)
 	if err != nil {
@@ -64,6 +64,9 @@ func main() {
 	}
 	klog.Infof("mutating webhook %s found", webhookName)
 
+	// the CA of the certificate written on disk, used also by the validating webhooks
+	var caPEM string
+
 	// iterate over all the webhooks in the mutatingWebhookConfiguration
 	for i, wh := range mutatingWebhook.Webhooks {
 		// generate tls secrets and CA
@@ -124,5 +127,32 @@ func main() {
 			klog.Fatal(err)
 		}
 		klog.Infof("webhook %s CaBundle patched", wh.Name)
+		caPEM = secrets.CAPEM()
+	}
+
+	// get the validatingWebhookConfiguration, which is served by the same backend
+	validatingWebhook, err := k8sClient.AdmissionregistrationV1().
+		ValidatingWebhookConfigurations().
+		Get(context.TODO(), webhookName, metav1.GetOptions{})
+	if err != nil {
+		klog.Fatal(err)
+	}
+	klog.Infof("validating webhook %s found", webhookName)
+
+	// patch all the webhooks in the validatingWebhookConfiguration with the same CaBundle
+	for i, wh := range validatingWebhook.Webhooks {
+		caBundlePatch := []byte(fmt.Sprintf(
+			`[{"op":"replace","path":"/webhooks/%d/clientConfig/caBundle","value":"%s"}]`,
+			i, caPEM))
+
+		_, err = k8sClient.AdmissionregistrationV1().ValidatingWebhookConfigurations().Patch(context.TODO(),
+			validatingWebhook.Name,
+			types.JSONPatchType,
+			caBundlePatch,
+			metav1.PatchOptions{})
+		if err != nil {
+			klog.Fatal(err)
+		}
+		klog.Infof("webhook %s CaBundle patched", wh.Name)
 	}
 }
@@ -110,6 +110,9 @@ func (authService *Controller) handleIdentity(
 		klog.Error(err)
 		return nil, err
 	}
+	if authConfig := authService.GetAuthConfig(); authConfig != nil {
+		response.NamespacePolicy = authConfig.RemoteNamespacePolicy
+	}
 
 	klog.Infof("Identity Request successfully validated for cluster %v", identityRequest.GetClusterID())
 	return response, nil
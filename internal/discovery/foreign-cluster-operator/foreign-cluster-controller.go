@@ -67,6 +67,9 @@ const (
 	resourceRequestCreatedReason  = "ResourceRequestCreated"
 	resourceRequestCreatedMessage = "The ResourceRequest has been created in the Tenant Namespace %v"
 
+	resourceRequestRefusedReason  = "ResourceRequestRefused"
+	resourceRequestRefusedMessage = "The ResourceRequest has been refused by the remote cluster: %v"
+
 	networkConfigNotFoundReason  = "NetworkConfigNotFound"
 	networkConfigNotFoundMessage = "The NetworkConfig has not been found in the Tenant Namespace %v"
 
@@ -307,6 +310,23 @@ func (r *ForeignClusterReconciler) peerNamespaced(ctx context.Context,
 			discoveryv1alpha1.OutgoingPeeringCondition,
 			discoveryv1alpha1.PeeringConditionStatusPending,
 			resourceRequestCreatedReason, fmt.Sprintf(resourceRequestCreatedMessage, foreignCluster.Status.TenantNamespace.Local))
+		return nil
+	}
+
+	// report if the remote cluster is not able to satisfy the requested resources
+	var resourceRequest discoveryv1alpha1.ResourceRequest
+	if err = r.Client.Get(ctx, types.NamespacedName{
+		Namespace: foreignCluster.Status.TenantNamespace.Local,
+		Name:      r.clusterID.GetClusterID(),
+	}, &resourceRequest); err != nil {
+		klog.Error(err)
+		return err
+	}
+	if resourceRequest.Status.OfferState == discoveryv1alpha1.OfferStateRefused {
+		peeringconditionsutils.EnsureStatus(foreignCluster,
+			discoveryv1alpha1.OutgoingPeeringCondition,
+			discoveryv1alpha1.PeeringConditionStatusPending,
+			resourceRequestRefusedReason, fmt.Sprintf(resourceRequestRefusedMessage, resourceRequest.Status.OfferMessage))
 	}
 	return nil
 }
@@ -50,7 +50,8 @@ func (r *ForeignClusterReconciler) createResourceRequest(ctx context.Context,
 				ClusterID:   localClusterID,
 				ClusterName: r.ConfigProvider.GetConfig().ClusterName,
 			},
-			AuthURL: authURL,
+			AuthURL:              authURL,
+			ResourceRequirements: foreignCluster.Spec.ResourceRequirements.DeepCopy(),
 		}
 
 		return controllerutil.SetControllerReference(foreignCluster, resourceRequest, r.Scheme)
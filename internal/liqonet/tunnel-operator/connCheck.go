@@ -0,0 +1,183 @@
+package tunneloperator
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"reflect"
+	"time"
+
+	"github.com/containernetworking/plugins/pkg/ns"
+	"github.com/vishvananda/netlink"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/klog"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+
+	netv1alpha1 "github.com/liqotech/liqo/apis/net/v1alpha1"
+	"github.com/liqotech/liqo/pkg/liqonet/conncheck"
+	"github.com/liqotech/liqo/pkg/liqonet/utils"
+)
+
+const (
+	// connectionStatusUpdateInterval is the interval between two consecutive updates of the connection status
+	// of the tunnelendpoints with the outcome of the probes.
+	connectionStatusUpdateInterval = 10 * time.Second
+	// reconnectionInterval is the minimum interval between two consecutive attempts to re-establish the
+	// connection towards a remote gateway which does not reply to the probes.
+	reconnectionInterval = time.Minute
+	// probesFailedMessage is the status message of the connections towards the remote gateways not replying to the probes.
+	probesFailedMessage = "the remote gateway does not reply to the probes"
+)
+
+// SetUpConnChecker initializes the connection checker of TunnelController, which probes the remote gateways
+// through the tunnels from the gateway netns.
+func (tc *TunnelController) SetUpConnChecker() error {
+	tc.connections = make(map[string]*netv1alpha1.Connection)
+	tc.reconnections = make(map[string]time.Time)
+	tc.pendingReconnections = make(map[string]struct{})
+	tc.connectionEvents = make(chan event.GenericEvent)
+	var init = func(netNamespace ns.NetNS) error {
+		var err error
+		tc.connChecker, err = conncheck.NewConnChecker()
+		return err
+	}
+	return tc.gatewayNetns.Do(init)
+}
+
+// RunConnectionChecks probes the remote gateways and reports the outcome in the status of the
+// tunnelendpoints, until the given context is cancelled.
+func (tc *TunnelController) RunConnectionChecks(ctx context.Context) error {
+	go wait.UntilWithContext(ctx, tc.updateConnectionStatuses, connectionStatusUpdateInterval)
+	return tc.connChecker.Run(ctx)
+}
+
+// ensureProbing configures the gateway to reply to the probes of the remote gateway described by the given tep,
+// and starts probing it.
+func (tc *TunnelController) ensureProbing(tep *netv1alpha1.TunnelEndpoint) error {
+	// Each gateway replies to the probes on the first IP of its PodCIDR, which it already uses to NAT the
+	// traffic towards the remote clusters. The remote gateway is probed on the corresponding address, as seen
+	// by the local cluster: the remote gateway takes care of translating it in case the PodCIDR has been remapped.
+	localProbeIP, err := utils.GetFirstIP(tep.Status.LocalPodCIDR)
+	if err != nil {
+		return err
+	}
+	addr := &netlink.Addr{IPNet: &net.IPNet{IP: net.ParseIP(localProbeIP), Mask: net.CIDRMask(32, 32)}}
+	if err := netlink.AddrReplace(tc.gatewayVeth, addr); err != nil {
+		klog.Errorf("%s -> unable to configure IP address {%s} on device {%s}: %v",
+			tep.Spec.ClusterID, localProbeIP, tc.gatewayVeth.Attrs().Name, err)
+		return err
+	}
+	_, remotePodCIDR := utils.GetPodCIDRS(tep)
+	remoteProbeIP, err := utils.GetFirstIP(remotePodCIDR)
+	if err != nil {
+		return err
+	}
+	tc.connChecker.AddPeer(tep.Spec.ClusterID, net.ParseIP(remoteProbeIP))
+	return nil
+}
+
+// stopProbing stops probing the remote gateway described by the given tep.
+func (tc *TunnelController) stopProbing(tep *netv1alpha1.TunnelEndpoint) {
+	tc.connChecker.RemovePeer(tep.Spec.ClusterID)
+	tc.connectionsMutex.Lock()
+	defer tc.connectionsMutex.Unlock()
+	delete(tc.connections, tep.Spec.ClusterID)
+	delete(tc.reconnections, tep.Spec.ClusterID)
+	delete(tc.pendingReconnections, tep.Spec.ClusterID)
+}
+
+// setConnection records the connection established by the driver towards the given cluster, and returns
+// it updated with the outcome of the probes.
+func (tc *TunnelController) setConnection(clusterID string, con *netv1alpha1.Connection) *netv1alpha1.Connection {
+	tc.connectionsMutex.Lock()
+	defer tc.connectionsMutex.Unlock()
+	tc.connections[clusterID] = con.DeepCopy()
+	return tc.getConnection(clusterID)
+}
+
+// getConnection returns the connection established by the driver towards the given cluster, updated with the
+// outcome of the probes. It has to be called with the connectionsMutex held.
+func (tc *TunnelController) getConnection(clusterID string) *netv1alpha1.Connection {
+	con, found := tc.connections[clusterID]
+	if !found {
+		return nil
+	}
+	con = con.DeepCopy()
+	status, found := tc.connChecker.GetStatus(clusterID)
+	if !found {
+		return con
+	}
+	if status.Latency > 0 {
+		con.Latency = &metav1.Duration{Duration: status.Latency.Round(time.Microsecond)}
+	}
+	con.PacketLoss = &status.PacketLoss
+	if !status.Connected && con.Status == netv1alpha1.Connected {
+		con.Status = netv1alpha1.ConnectionError
+		con.StatusMessage = probesFailedMessage
+	}
+	return con
+}
+
+// reconnectionRequested returns true if the connection towards the given cluster has to be re-established,
+// since the remote gateway does not reply to the probes, and resets the request.
+func (tc *TunnelController) reconnectionRequested(clusterID string) bool {
+	tc.connectionsMutex.Lock()
+	defer tc.connectionsMutex.Unlock()
+	if _, found := tc.pendingReconnections[clusterID]; !found {
+		return false
+	}
+	delete(tc.pendingReconnections, clusterID)
+	return true
+}
+
+// updateConnectionStatuses updates the connection status of the tunnelendpoints with the outcome of the probes.
+// In case a remote gateway does not reply to the probes, the reconciliation of the tunnelendpoint is triggered
+// to re-establish the connection, which also takes into account the possibly changed remote endpoint.
+func (tc *TunnelController) updateConnectionStatuses(ctx context.Context) {
+	teps := &netv1alpha1.TunnelEndpointList{}
+	if err := tc.List(ctx, teps); err != nil {
+		klog.Errorf("unable to list resources of type %s: %v", netv1alpha1.TunnelEndpointGroupVersionResource.String(), err)
+		return
+	}
+	for i := range teps.Items {
+		tep := &teps.Items[i]
+		clusterID := tep.Spec.ClusterID
+		tc.connectionsMutex.Lock()
+		con := tc.getConnection(clusterID)
+		reconnect := false
+		if con != nil && con.StatusMessage == probesFailedMessage && time.Since(tc.reconnections[clusterID]) >= reconnectionInterval {
+			tc.reconnections[clusterID] = time.Now()
+			tc.pendingReconnections[clusterID] = struct{}{}
+			reconnect = true
+		}
+		tc.connectionsMutex.Unlock()
+		if con == nil {
+			continue
+		}
+		if !reflect.DeepEqual(*con, tep.Status.Connection) {
+			if con.Status != tep.Status.Connection.Status {
+				klog.Infof("%s -> connection status changed to {%s}", clusterID, con.Status)
+				eventType := "Normal"
+				if con.Status != netv1alpha1.Connected {
+					eventType = "Warning"
+				}
+				tc.Eventf(tep, eventType, "ConnectionCheck", "connection status changed to %s", con.Status)
+			}
+			tep.Status.Connection = *con
+			if err := tc.Status().Update(ctx, tep); err != nil {
+				klog.Errorf("%s -> an error occurred while updating status of resource %s: %s", clusterID, tep.Name, err)
+				continue
+			}
+		}
+		if reconnect {
+			klog.Warningf("%s -> %s: re-establishing the connection", clusterID, probesFailedMessage)
+			tc.Event(tep, "Warning", "ConnectionCheck", fmt.Sprintf("%s: re-establishing the connection", probesFailedMessage))
+			select {
+			case tc.connectionEvents <- event.GenericEvent{Object: tep}:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}
+}
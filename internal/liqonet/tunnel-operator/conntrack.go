@@ -0,0 +1,359 @@
+package tunneloperator
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/containernetworking/plugins/pkg/ns"
+	"github.com/coreos/go-iptables/iptables"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/klog/v2"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	liqoconst "github.com/liqotech/liqo/pkg/consts"
+)
+
+const (
+	conntrackdBinary = "conntrackd"
+	// conntrackSyncPort is the UDP port used by conntrackd to synchronize the connection tracking state.
+	conntrackSyncPort = 3780
+	// conntrackResyncPeriod is the interval between two consecutive checks of the peer replica.
+	conntrackResyncPeriod = 10 * time.Second
+	// conntrackdStopTimeout is the maximum time waited for conntrackd to terminate.
+	conntrackdStopTimeout = 5 * time.Second
+)
+
+var (
+	// gatewayNetnsIP is the address of the gateway netns, used by conntrackd to exchange the synchronization messages.
+	gatewayNetnsIP       = strings.Split(liqoconst.GatewayVethIPAddr, "/")[0]
+	conntrackdConfigPath = "/etc/conntrackd/conntrackd.conf"
+	conntrackdSocketPath = "/var/run/conntrackd.ctl"
+	conntrackdLockPath   = "/var/run/conntrackd.lock"
+	// conntrackPrimaryCommands are executed once the current replica is elected leader: the connection tracking
+	// entries received from the previous leader are committed to the kernel, then the caches are flushed and
+	// refilled from the kernel table, and finally sent to the peer replica.
+	conntrackPrimaryCommands = []string{"-c", "-f", "-R", "-B"}
+	// conntrackBackupCommands are executed by the standby replicas: the timers of the entries committed to
+	// the kernel are shortened, and a full resynchronization is requested to the leader.
+	conntrackBackupCommands = []string{"-t", "-n"}
+)
+
+// ConntrackSyncer runs conntrackd in the gateway netns, to synchronize the connection tracking state between
+// the leader and the standby replica of the gateway. Once a standby replica is elected leader, it commits the
+// state received from the previous leader to the kernel, so that the connections established through the NAT
+// rules survive the failover. conntrackd works in primary-backup mode, hence at most two replicas are supported.
+// The synchronization messages are exchanged between the nodes of the replicas, and they are translated by the
+// host to and from the address of the gateway netns.
+type ConntrackSyncer struct {
+	client.Client
+	podIP     string
+	namespace string
+	elected   <-chan struct{}
+	netns     ns.NetNS
+
+	peerIP     string
+	conntrackd *exec.Cmd
+	exited     chan struct{}
+}
+
+// NewConntrackSyncer returns a new ConntrackSyncer for the gateway replica with the given IP, which acts
+// as primary once the elected channel is closed.
+func NewConntrackSyncer(podIP, namespace string, elected <-chan struct{}, cl client.Client, gatewayNetns ns.NetNS) *ConntrackSyncer {
+	return &ConntrackSyncer{
+		Client:    cl,
+		podIP:     podIP,
+		namespace: namespace,
+		elected:   elected,
+		netns:     gatewayNetns,
+	}
+}
+
+// NeedLeaderElection implements the manager.LeaderElectionRunnable interface, since conntrackd
+// has to run on every replica.
+func (cs *ConntrackSyncer) NeedLeaderElection() bool {
+	return false
+}
+
+// Start runs conntrackd towards the current peer replica, restarting it whenever the peer changes,
+// until the given context is cancelled.
+func (cs *ConntrackSyncer) Start(ctx context.Context) error {
+	if err := ensureConntrackHostRules(cs.podIP); err != nil {
+		return err
+	}
+	defer func() {
+		cs.stop()
+		if err := removeConntrackHostRules(cs.podIP); err != nil {
+			klog.Errorf("unable to remove the iptables rules for the connection tracking synchronization: %v", err)
+		}
+	}()
+
+	ticker := time.NewTicker(conntrackResyncPeriod)
+	defer ticker.Stop()
+	elected := cs.elected
+	cs.sync(ctx, false)
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-elected:
+			// The channel is closed, hence it is set to nil to be selected only once.
+			elected = nil
+			cs.runCommands(conntrackPrimaryCommands)
+			klog.Infof("connection tracking state committed, acting as primary towards gateway {%s}", cs.peerIP)
+		case <-ticker.C:
+			cs.sync(ctx, elected == nil)
+		}
+	}
+}
+
+// sync (re)starts conntrackd if the peer replica changed or the process terminated. If no peer is found,
+// conntrackd keeps running towards the previous one, to preserve the state received from a terminating leader.
+func (cs *ConntrackSyncer) sync(ctx context.Context, primary bool) {
+	pods := &corev1.PodList{}
+	if err := cs.List(ctx, pods, client.InNamespace(cs.namespace), client.MatchingLabels{
+		podComponentLabelKey: podComponentLabelValue,
+		podNameLabelKey:      podNameLabelValue,
+	}); err != nil {
+		klog.Errorf("unable to list the gateway pods: %v", err)
+		return
+	}
+	peerIP := getConntrackPeer(pods.Items, cs.podIP, primary)
+	if peerIP == "" {
+		peerIP = cs.peerIP
+	}
+	if peerIP == "" || (peerIP == cs.peerIP && cs.running()) {
+		return
+	}
+	cs.stop()
+	cs.peerIP = peerIP
+	if err := cs.start(); err != nil {
+		klog.Errorf("unable to start the synchronization of the connection tracking state with gateway {%s}: %v", peerIP, err)
+		return
+	}
+	klog.Infof("synchronizing the connection tracking state with gateway {%s}", peerIP)
+	if primary {
+		cs.runCommands(conntrackPrimaryCommands[1:])
+		return
+	}
+	cs.runCommands(conntrackBackupCommands)
+}
+
+// start writes the configuration of conntrackd and starts it in the gateway netns.
+func (cs *ConntrackSyncer) start() error {
+	if err := os.MkdirAll(filepath.Dir(conntrackdConfigPath), 0700); err != nil {
+		return err
+	}
+	config := forgeConntrackdConfig(gatewayNetnsIP, cs.peerIP)
+	if err := ioutil.WriteFile(conntrackdConfigPath, []byte(config), 0600); err != nil {
+		return err
+	}
+	// The lock file and the socket are left behind if conntrackd has not been terminated gracefully.
+	for _, path := range []string{conntrackdLockPath, conntrackdSocketPath} {
+		if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+	}
+
+	cmd := exec.Command(conntrackdBinary, "-C", conntrackdConfigPath)
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	// The process inherits the network namespace of the thread it is started from.
+	if err := cs.netns.Do(func(netNamespace ns.NetNS) error {
+		return cmd.Start()
+	}); err != nil {
+		return fmt.Errorf("failed to start %s: %w", conntrackdBinary, err)
+	}
+	exited := make(chan struct{})
+	go func() {
+		if err := cmd.Wait(); err != nil {
+			klog.Warningf("%s terminated: %v", conntrackdBinary, err)
+		}
+		close(exited)
+	}()
+	cs.conntrackd, cs.exited = cmd, exited
+	return cs.waitSocket()
+}
+
+// waitSocket waits for conntrackd to accept the commands through its UNIX socket.
+func (cs *ConntrackSyncer) waitSocket() error {
+	deadline := time.Now().Add(conntrackdStopTimeout)
+	for time.Now().Before(deadline) {
+		if _, err := os.Stat(conntrackdSocketPath); err == nil {
+			return nil
+		}
+		if !cs.running() {
+			return fmt.Errorf("%s terminated during the startup", conntrackdBinary)
+		}
+		time.Sleep(100 * time.Millisecond)
+	}
+	return fmt.Errorf("%s did not create the socket %s", conntrackdBinary, conntrackdSocketPath)
+}
+
+// running returns whether conntrackd is running.
+func (cs *ConntrackSyncer) running() bool {
+	if cs.conntrackd == nil {
+		return false
+	}
+	select {
+	case <-cs.exited:
+		return false
+	default:
+		return true
+	}
+}
+
+// stop terminates conntrackd, if running.
+func (cs *ConntrackSyncer) stop() {
+	if cs.conntrackd == nil {
+		return
+	}
+	cmd, exited := cs.conntrackd, cs.exited
+	cs.conntrackd, cs.exited = nil, nil
+	if err := cmd.Process.Signal(syscall.SIGTERM); err != nil {
+		klog.Warningf("failed to stop %s: %v", conntrackdBinary, err)
+	}
+	select {
+	case <-exited:
+	case <-time.After(conntrackdStopTimeout):
+		klog.Warningf("%s not terminated after %v, killing it", conntrackdBinary, conntrackdStopTimeout)
+		_ = cmd.Process.Kill()
+	}
+}
+
+// runCommands sends the given commands to conntrackd, if running.
+func (cs *ConntrackSyncer) runCommands(commands []string) {
+	if !cs.running() {
+		return
+	}
+	for _, command := range commands {
+		output, err := exec.Command(conntrackdBinary, "-C", conntrackdConfigPath, command).CombinedOutput()
+		if err != nil {
+			klog.Errorf("%s %s: %v (%s)", conntrackdBinary, command, err, strings.TrimSpace(string(output)))
+		}
+	}
+}
+
+// getConntrackPeer returns the IP of the gateway replica the state is synchronized with, among the given pods.
+// The primary selects a standby replica which is not terminating, while a standby one selects the leader,
+// i.e. the replica labeled as active. Since conntrackd supports a single peer, the one with the lowest IP is
+// selected if more are found.
+func getConntrackPeer(pods []corev1.Pod, podIP string, primary bool) string {
+	var peers []string
+	for i := range pods {
+		pod := &pods[i]
+		if pod.Status.PodIP == "" || pod.Status.PodIP == podIP {
+			continue
+		}
+		if primary && !pod.DeletionTimestamp.IsZero() {
+			continue
+		}
+		if !primary && pod.GetLabels()[gatewayLabelKey] != gatewayStatusActive {
+			continue
+		}
+		peers = append(peers, pod.Status.PodIP)
+	}
+	if len(peers) == 0 {
+		return ""
+	}
+	sort.Strings(peers)
+	if len(peers) > 1 {
+		klog.Warningf("found %d candidate gateway replicas, the connection tracking state is synchronized only with {%s}",
+			len(peers), peers[0])
+	}
+	return peers[0]
+}
+
+// forgeConntrackdConfig returns the configuration of conntrackd, which synchronizes the connection tracking state
+// through the FTFW protocol, from the given address of the gateway netns to the one of the peer replica.
+func forgeConntrackdConfig(localIP, peerIP string) string {
+	var config strings.Builder
+	config.WriteString("Sync {\n")
+	config.WriteString("\tMode FTFW {\n\t\tDisableExternalCache Off\n\t}\n")
+	config.WriteString("\tUDP {\n")
+	fmt.Fprintf(&config, "\t\tIPv4_address %s\n", localIP)
+	fmt.Fprintf(&config, "\t\tIPv4_Destination_Address %s\n", peerIP)
+	fmt.Fprintf(&config, "\t\tPort %d\n", conntrackSyncPort)
+	fmt.Fprintf(&config, "\t\tInterface %s\n", liqoconst.GatewayVethName)
+	config.WriteString("\t\tChecksum on\n\t}\n")
+	config.WriteString("}\n")
+	config.WriteString("General {\n")
+	config.WriteString("\tLogFile off\n\tSyslog off\n")
+	fmt.Fprintf(&config, "\tLockFile %s\n", conntrackdLockPath)
+	fmt.Fprintf(&config, "\tUNIX {\n\t\tPath %s\n\t}\n", conntrackdSocketPath)
+	config.WriteString("\tNetlinkBufferSize 2097152\n\tNetlinkBufferSizeMaxGrowth 8388608\n")
+	config.WriteString("\tFilter From Userspace {\n")
+	config.WriteString("\t\tProtocol Accept {\n\t\t\tTCP\n\t\t\tUDP\n\t\t\tICMP\n\t\t}\n")
+	// The synchronization messages are not tracked themselves.
+	fmt.Fprintf(&config, "\t\tAddress Ignore {\n\t\t\tIPv4_address 127.0.0.1\n\t\t\tIPv4_address %s\n\t\t}\n", localIP)
+	config.WriteString("\t}\n")
+	config.WriteString("}\n")
+	return config.String()
+}
+
+// iptablesRule is a rule inserted in the given table and chain of the host network namespace.
+type iptablesRule struct {
+	table, chain string
+	spec         []string
+}
+
+// getConntrackHostRules returns the rules translating the synchronization messages exchanged by the gateway
+// netns, whose address is not routable, to and from the given address of the replica.
+func getConntrackHostRules(podIP string) []iptablesRule {
+	port := strconv.Itoa(conntrackSyncPort)
+	return []iptablesRule{
+		{table: "nat", chain: "PREROUTING", spec: []string{"-d", podIP, "-p", "udp", "--dport", port,
+			"-j", "DNAT", "--to-destination", gatewayNetnsIP}},
+		{table: "nat", chain: "POSTROUTING", spec: []string{"-s", gatewayNetnsIP, "-p", "udp", "--dport", port,
+			"-j", "SNAT", "--to-source", podIP}},
+		{table: "filter", chain: "FORWARD", spec: []string{"-d", gatewayNetnsIP, "-p", "udp", "--dport", port, "-j", "ACCEPT"}},
+		{table: "filter", chain: "FORWARD", spec: []string{"-s", gatewayNetnsIP, "-p", "udp", "--dport", port, "-j", "ACCEPT"}},
+	}
+}
+
+// ensureConntrackHostRules inserts the rules returned by getConntrackHostRules in the host network namespace.
+func ensureConntrackHostRules(podIP string) error {
+	ipt, err := iptables.New()
+	if err != nil {
+		return fmt.Errorf("unable to initialize iptables: %w", err)
+	}
+	for _, rule := range getConntrackHostRules(podIP) {
+		exists, err := ipt.Exists(rule.table, rule.chain, rule.spec...)
+		if err != nil {
+			return fmt.Errorf("unable to check the existence of iptables rule \"%s\": %w", rule.spec, err)
+		}
+		if exists {
+			continue
+		}
+		if err := ipt.Insert(rule.table, rule.chain, 1, rule.spec...); err != nil {
+			return fmt.Errorf("unable to insert iptables rule \"%s\": %w", rule.spec, err)
+		}
+	}
+	return nil
+}
+
+// removeConntrackHostRules removes the rules returned by getConntrackHostRules from the host network namespace.
+func removeConntrackHostRules(podIP string) error {
+	ipt, err := iptables.New()
+	if err != nil {
+		return fmt.Errorf("unable to initialize iptables: %w", err)
+	}
+	for _, rule := range getConntrackHostRules(podIP) {
+		exists, err := ipt.Exists(rule.table, rule.chain, rule.spec...)
+		if err != nil || !exists {
+			continue
+		}
+		if err := ipt.Delete(rule.table, rule.chain, rule.spec...); err != nil {
+			return fmt.Errorf("unable to delete iptables rule \"%s\": %w", rule.spec, err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,76 @@
+package tunneloperator
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+var _ = Describe("Conntrack", func() {
+	Describe("testing getConntrackPeer function", func() {
+		var pods []corev1.Pod
+		now := metav1.Now()
+
+		forgePod := func(ip, status string) corev1.Pod {
+			pod := corev1.Pod{Status: corev1.PodStatus{PodIP: ip}}
+			if status != "" {
+				pod.SetLabels(map[string]string{gatewayLabelKey: status})
+			}
+			return pod
+		}
+
+		BeforeEach(func() {
+			pods = []corev1.Pod{
+				forgePod("10.0.0.1", gatewayStatusActive),
+				forgePod("10.0.0.3", gatewayStatusStandby),
+				forgePod("10.0.0.2", ""),
+				forgePod("", ""),
+			}
+		})
+
+		Context("when the current replica is the primary", func() {
+			It("should select the replica with the lowest IP, excluding itself", func() {
+				Expect(getConntrackPeer(pods, "10.0.0.1", true)).To(Equal("10.0.0.2"))
+			})
+
+			It("should ignore the terminating replicas", func() {
+				pods[2].SetDeletionTimestamp(&now)
+				Expect(getConntrackPeer(pods, "10.0.0.1", true)).To(Equal("10.0.0.3"))
+			})
+		})
+
+		Context("when the current replica is a standby one", func() {
+			It("should select the leader, also if terminating", func() {
+				pods[0].SetDeletionTimestamp(&now)
+				Expect(getConntrackPeer(pods, "10.0.0.3", false)).To(Equal("10.0.0.1"))
+			})
+
+			It("should return an empty string if the leader is not known", func() {
+				Expect(getConntrackPeer(pods[1:], "10.0.0.3", false)).To(BeEmpty())
+			})
+		})
+	})
+
+	Describe("testing forgeConntrackdConfig function", func() {
+		It("should synchronize the state with the peer and ignore the synchronization messages", func() {
+			config := forgeConntrackdConfig("169.254.100.1", "10.0.0.2")
+			Expect(config).To(ContainSubstring("Mode FTFW {"))
+			Expect(config).To(ContainSubstring("IPv4_address 169.254.100.1\n"))
+			Expect(config).To(ContainSubstring("IPv4_Destination_Address 10.0.0.2\n"))
+			Expect(config).To(ContainSubstring("Port 3780\n"))
+			Expect(config).To(ContainSubstring("Path " + conntrackdSocketPath + "\n"))
+			Expect(config).To(ContainSubstring("Address Ignore {\n\t\t\tIPv4_address 127.0.0.1\n\t\t\tIPv4_address 169.254.100.1\n"))
+		})
+	})
+
+	Describe("testing getConntrackHostRules function", func() {
+		It("should translate the synchronization messages to and from the gateway netns", func() {
+			rules := getConntrackHostRules("10.0.0.1")
+			Expect(rules).To(ContainElement(iptablesRule{table: "nat", chain: "PREROUTING", spec: []string{
+				"-d", "10.0.0.1", "-p", "udp", "--dport", "3780", "-j", "DNAT", "--to-destination", "169.254.100.1"}}))
+			Expect(rules).To(ContainElement(iptablesRule{table: "nat", chain: "POSTROUTING", spec: []string{
+				"-s", "169.254.100.1", "-p", "udp", "--dport", "3780", "-j", "SNAT", "--to-source", "10.0.0.1"}}))
+		})
+	})
+})
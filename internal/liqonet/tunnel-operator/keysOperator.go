@@ -0,0 +1,55 @@
+package tunneloperator
+
+import (
+	"context"
+	"fmt"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/klog/v2"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	tunnelwg "github.com/liqotech/liqo/pkg/liqonet/tunnel/wireguard"
+)
+
+// KeysController reconciles the secret containing the wireguard keys, and configures the
+// wireguard device of the current replica with the keys in use once they have been rotated.
+type KeysController struct {
+	client.Client
+	wg *tunnelwg.Wireguard
+}
+
+// NewKeysController returns a new controller ready to be setup and started with the controller manager.
+func NewKeysController(cl client.Client, tc *TunnelController) (*KeysController, error) {
+	wg, ok := tc.drivers[tunnelwg.DriverName].(*tunnelwg.Wireguard)
+	if !ok {
+		return nil, fmt.Errorf("no driver for tunnel of type %s is available", tunnelwg.DriverName)
+	}
+	return &KeysController{
+		Client: cl,
+		wg:     wg,
+	}, nil
+}
+
+// Reconcile configures the wireguard device with the keys contained in the secret, if they changed.
+func (kc *KeysController) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	if req.Name != tunnelwg.KeysName {
+		return ctrl.Result{}, nil
+	}
+	secret := new(corev1.Secret)
+	if err := kc.Get(ctx, req.NamespacedName, secret); err != nil {
+		klog.Errorf("an error occurred while getting secret {%s}: %v", req.NamespacedName, err)
+		return ctrl.Result{}, client.IgnoreNotFound(err)
+	}
+	if err := kc.wg.UpdateKeys(secret); err != nil {
+		klog.Errorf("an error occurred while updating the keys of the %s driver: %v", tunnelwg.DriverName, err)
+		return ctrl.Result{}, err
+	}
+	return ctrl.Result{}, nil
+}
+
+// SetupWithManager used to set up the controller with a given manager.
+func (kc *KeysController) SetupWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewControllerManagedBy(mgr).For(&corev1.Secret{}).
+		Complete(kc)
+}
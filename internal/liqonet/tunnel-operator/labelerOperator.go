@@ -10,7 +10,12 @@ import (
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/cache"
 	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
+	"sigs.k8s.io/controller-runtime/pkg/source"
 
+	tunnelwg "github.com/liqotech/liqo/pkg/liqonet/tunnel/wireguard"
 	liqoutils "github.com/liqotech/liqo/pkg/liqonet/utils"
 )
 
@@ -28,8 +33,8 @@ const (
 )
 
 var (
-	// LabelSelector instructs the informer to only cache the pod objects that satisfy the selector.
-	// Only the pod objects with the right labels will be reconciled.
+	// LabelSelector instructs the informer to only cache the pod and secret objects that satisfy the selectors.
+	// Only the pod objects with the right labels and the secrets containing the wireguard keys will be reconciled.
 	LabelSelector = cache.SelectorsByObject{
 		&corev1.Pod{}: {
 			Label: labels.SelectorFromSet(labels.Set{
@@ -37,6 +42,11 @@ var (
 				podNameLabelKey:      podNameLabelValue,
 			}),
 		},
+		&corev1.Secret{}: {
+			Label: labels.SelectorFromSet(labels.Set{
+				tunnelwg.KeysLabel: tunnelwg.DriverName,
+			}),
+		},
 	}
 )
 
@@ -44,20 +54,27 @@ var (
 type LabelerController struct {
 	client.Client
 	PodIP string
+	// Elected is closed when the current replica is elected leader. If nil, the replica is always considered the leader.
+	Elected <-chan struct{}
+	// electionEvents is used to trigger the reconciliation of the gateway pods once the current replica is elected leader.
+	electionEvents chan event.GenericEvent
 }
 
 // NewLabelerController  returns a new controller ready to be setup and started with the controller manager.
-func NewLabelerController(podIP string, cl client.Client) *LabelerController {
+func NewLabelerController(podIP string, elected <-chan struct{}, cl client.Client) *LabelerController {
 	return &LabelerController{
-		Client: cl,
-		PodIP:  podIP,
+		Client:         cl,
+		PodIP:          podIP,
+		Elected:        elected,
+		electionEvents: make(chan event.GenericEvent),
 	}
 }
 
 // Reconcile for a given pod, replica of the current operator, it checks if it is the current pod
-// meaning the pod where this code is running. If it is our pod, it checks that it is labels as the
-// active replica of the gateway. It ensures that the label "net.liqo.io/gateway=active" is present.
-// If the pod is not the current one, we make sure that the pod has the label "net.liqo.io/gateway=standby".
+// meaning the pod where this code is running. If it is our pod and the current replica is the leader, it checks
+// that it is labels as the active replica of the gateway. It ensures that the label "net.liqo.io/gateway=active"
+// is present. If the pod is not the current one, we make sure that the pod has the label "net.liqo.io/gateway=standby".
+// The replicas which are not the leader only label their own pod as "standby".
 func (lbc *LabelerController) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
 	pod := new(corev1.Pod)
 	err := lbc.Get(ctx, req.NamespacedName, pod)
@@ -65,6 +82,21 @@ func (lbc *LabelerController) Reconcile(ctx context.Context, req ctrl.Request) (
 		klog.Errorf("an error occurred while getting pod {%s}: %v", req.NamespacedName, err)
 		return ctrl.Result{}, client.IgnoreNotFound(err)
 	}
+	if !lbc.isLeader() {
+		if lbc.PodIP != pod.Status.PodIP {
+			return ctrl.Result{}, nil
+		}
+		if liqoutils.AddLabelToObj(pod, gatewayLabelKey, gatewayStatusStandby) {
+			if err := lbc.Update(ctx, pod); err != nil {
+				klog.Errorf("an error occurred while updating value of label {%s} to {%s} for pod {%s}: %v",
+					gatewayLabelKey, gatewayStatusStandby, req.String(), err)
+				return ctrl.Result{}, err
+			}
+			klog.Infof("successfully updated label {%s: %s} for pod {%s}",
+				gatewayLabelKey, gatewayStatusStandby, req.String())
+		}
+		return ctrl.Result{}, nil
+	}
 	// If it is our pod/current pod then ensure that the labels values is set to "active".
 	if lbc.PodIP == pod.Status.PodIP {
 		if liqoutils.AddLabelToObj(pod, gatewayLabelKey, gatewayStatusActive) {
@@ -128,8 +160,55 @@ func (lbc *LabelerController) annotateGatewayService(ctx context.Context) error
 	return nil
 }
 
+// isLeader returns true if the current replica is the leader of the gateway replicas.
+func (lbc *LabelerController) isLeader() bool {
+	if lbc.Elected == nil {
+		return true
+	}
+	select {
+	case <-lbc.Elected:
+		return true
+	default:
+		return false
+	}
+}
+
+// notifyElection waits for the current replica to be elected leader, and then triggers the reconciliation
+// of all the gateway pods, in order to move the selection of the gateway service on the current replica.
+func (lbc *LabelerController) notifyElection(ctx context.Context) error {
+	if lbc.Elected == nil {
+		return nil
+	}
+	select {
+	case <-ctx.Done():
+		return nil
+	case <-lbc.Elected:
+	}
+	podList := new(corev1.PodList)
+	if err := lbc.List(ctx, podList, client.MatchingLabels{
+		podComponentLabelKey: podComponentLabelValue,
+		podNameLabelKey:      podNameLabelValue,
+	}); err != nil {
+		klog.Errorf("an error occurred while listing the gateway pods: %v", err)
+		return err
+	}
+	klog.Info("the current replica has been elected leader: moving the gateway service on it")
+	for i := range podList.Items {
+		select {
+		case lbc.electionEvents <- event.GenericEvent{Object: &podList.Items[i]}:
+		case <-ctx.Done():
+			return nil
+		}
+	}
+	return nil
+}
+
 // SetupWithManager used to set up the controller with a given manager.
 func (lbc *LabelerController) SetupWithManager(mgr ctrl.Manager) error {
+	if err := mgr.Add(manager.RunnableFunc(lbc.notifyElection)); err != nil {
+		return err
+	}
 	return ctrl.NewControllerManagedBy(mgr).For(&corev1.Pod{}).
+		Watches(&source.Channel{Source: lbc.electionEvents}, &handler.EnqueueRequestForObject{}).
 		Complete(lbc)
 }
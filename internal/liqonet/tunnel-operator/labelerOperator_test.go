@@ -123,7 +123,7 @@ var _ = Describe("LabelerOperator", func() {
 	Describe("testing NewOverlayOperator function", func() {
 		Context("when input parameters are correct", func() {
 			It("should return labeler controller ", func() {
-				lbc1 := NewLabelerController(labelerCurrentPodIP, k8sClient)
+				lbc1 := NewLabelerController(labelerCurrentPodIP, nil, k8sClient)
 				Expect(lbc1).ShouldNot(BeNil())
 			})
 		})
@@ -300,6 +300,43 @@ var _ = Describe("LabelerOperator", func() {
 			})
 		})
 
+		Context("when the current replica is not the leader", func() {
+			It("pod is the current one and it is {active}, should set it to {standby}", func() {
+				lbc.Elected = make(chan struct{})
+				labelerTestPod.SetLabels(map[string]string{
+					gatewayLabelKey: gatewayStatusActive,
+				})
+				Eventually(func() error { return k8sClient.Create(context.TODO(), labelerTestPod) }).Should(BeNil())
+				newPod := &corev1.Pod{}
+				Eventually(func() error { return k8sClient.Get(context.TODO(), labelerReq.NamespacedName, newPod) }).Should(BeNil())
+				newPod.Status.PodIP = labelerCurrentPodIP
+				// Set IP address of the newly created pod.
+				Eventually(func() error { return k8sClient.Status().Update(context.TODO(), newPod) }).Should(BeNil())
+				// Check that the IP address has been set.
+				Eventually(func() error {
+					err := k8sClient.Get(context.TODO(), labelerReq.NamespacedName, newPod)
+					if err != nil {
+						return err
+					}
+					if newPod.Status.PodIP != labelerCurrentPodIP {
+						return fmt.Errorf("pod ip has not been set yet")
+					}
+					return nil
+				}).Should(BeNil())
+				Eventually(func() error { _, err := lbc.Reconcile(context.TODO(), labelerReq); return err }).Should(BeNil())
+				Eventually(func() error {
+					err := k8sClient.Get(context.TODO(), labelerReq.NamespacedName, newPod)
+					if err != nil {
+						return err
+					}
+					if newPod.GetLabels()[gatewayLabelKey] != gatewayStatusStandby {
+						return fmt.Errorf(" error: label %s is different than %s string", newPod.GetLabels()[gatewayLabelKey], gatewayStatusStandby)
+					}
+					return nil
+				}).Should(BeNil())
+			})
+		})
+
 		Context("pod does not exist", func() {
 			It("shold return nil", func() {
 				_, err := lbc.Reconcile(context.TODO(), labelerReq)
@@ -0,0 +1,182 @@
+package tunneloperator
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/containernetworking/plugins/pkg/ns"
+	"k8s.io/klog"
+
+	netv1alpha1 "github.com/liqotech/liqo/apis/net/v1alpha1"
+	liqoconst "github.com/liqotech/liqo/pkg/consts"
+)
+
+const (
+	// standbyResyncPeriod is the interval between two consecutive preparations of the gateway netns
+	// performed by the standby replicas.
+	standbyResyncPeriod = 30 * time.Second
+	// finalizerSuffix is the suffix of the finalizers set by the gateway replicas on the tunnelendpoints.
+	finalizerSuffix = "net.liqo.io"
+)
+
+// StandbyPreparer keeps the gateway netns of a standby replica configured for all the remote clusters, so that
+// in case of failover only the tunnels have to be established. The iptables rules and the routes in the
+// gateway netns are derived from the status of the tunnelendpoints, which is kept consistent by the leader.
+// The tunnels are not established in advance, since the standby replicas would otherwise announce themselves
+// to the remote gateways (e.g. through the wireguard keepalives), stealing the traffic from the leader.
+// The connection tracking state, which the NAT rules rely on, is synchronized by the ConntrackSyncer.
+type StandbyPreparer struct {
+	tc      *TunnelController
+	elected <-chan struct{}
+	// prepared contains the tunnelendpoints the gateway netns has been prepared for, keyed by the cluster ID.
+	prepared map[string]*netv1alpha1.TunnelEndpoint
+}
+
+// NewStandbyPreparer returns a new StandbyPreparer for the given controller, which stops preparing
+// the gateway netns once the elected channel is closed.
+func NewStandbyPreparer(tc *TunnelController, elected <-chan struct{}) *StandbyPreparer {
+	return &StandbyPreparer{
+		tc:       tc,
+		elected:  elected,
+		prepared: make(map[string]*netv1alpha1.TunnelEndpoint),
+	}
+}
+
+// NeedLeaderElection implements the manager.LeaderElectionRunnable interface, since the preparation
+// has to be performed by the standby replicas.
+func (sp *StandbyPreparer) NeedLeaderElection() bool {
+	return false
+}
+
+// Start prepares the gateway netns every standbyResyncPeriod, until the current replica is elected leader
+// or the given context is cancelled.
+func (sp *StandbyPreparer) Start(ctx context.Context) error {
+	ticker := time.NewTicker(standbyResyncPeriod)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-sp.elected:
+			sp.tc.leadingSince()
+			klog.Infof("the current replica has been elected leader, having prepared the gateway netns for %d remote clusters",
+				len(sp.prepared))
+			return nil
+		case <-ticker.C:
+			sp.prepare(ctx)
+		}
+	}
+}
+
+// prepare configures the gateway netns for the ready tunnelendpoints, and removes the configuration
+// of the ones which do not exist anymore.
+func (sp *StandbyPreparer) prepare(ctx context.Context) {
+	teps := &netv1alpha1.TunnelEndpointList{}
+	if err := sp.tc.List(ctx, teps); err != nil {
+		klog.Errorf("unable to list resources of type %s: %v", netv1alpha1.TunnelEndpointGroupVersionResource.String(), err)
+		return
+	}
+	sp.tc.standbyMutex.Lock()
+	defer sp.tc.standbyMutex.Unlock()
+	// The leader may have started reconciling the tunnelendpoints before the election has been observed.
+	if !sp.tc.electedAt.IsZero() {
+		return
+	}
+	current := make(map[string]*netv1alpha1.TunnelEndpoint)
+	for i := range teps.Items {
+		tep := &teps.Items[i]
+		if tep.Status.Phase == liqoconst.TepReady && tep.DeletionTimestamp.IsZero() {
+			current[tep.Spec.ClusterID] = tep
+		}
+	}
+	var configure = func(netNamespace ns.NetNS) error {
+		for clusterID, tep := range sp.prepared {
+			if _, found := current[clusterID]; found {
+				continue
+			}
+			if err := sp.tc.NATHandler.RemoveIPTablesConfigurationPerCluster(tep); err != nil {
+				klog.Errorf("%s -> unable to remove the standby iptables configuration: %v", clusterID, err)
+				continue
+			}
+			if _, err := sp.tc.RemoveRoutesPerCluster(tep); err != nil {
+				klog.Errorf("%s -> unable to remove the standby routes: %v", clusterID, err)
+				continue
+			}
+			delete(sp.prepared, clusterID)
+		}
+		for clusterID, tep := range current {
+			if err := sp.prepareCluster(tep); err != nil {
+				klog.Errorf("%s -> unable to prepare the gateway netns in standby mode: %v", clusterID, err)
+				continue
+			}
+			if _, found := sp.prepared[clusterID]; !found {
+				klog.Infof("%s -> gateway netns prepared in standby mode", clusterID)
+			}
+			sp.prepared[clusterID] = tep
+		}
+		return nil
+	}
+	_ = sp.tc.gatewayNetns.Do(configure)
+}
+
+// prepareCluster configures the iptables rules and the routes in the gateway netns for the given tunnelendpoint.
+// Differently from the leader, no event is recorded, since the operation is periodically repeated by each replica.
+func (sp *StandbyPreparer) prepareCluster(tep *netv1alpha1.TunnelEndpoint) error {
+	if err := sp.tc.EnsureChainsPerCluster(tep.Spec.ClusterID); err != nil {
+		return err
+	}
+	if err := sp.tc.EnsureChainRulesPerCluster(tep); err != nil {
+		return err
+	}
+	if err := sp.tc.EnsurePostroutingRules(tep); err != nil {
+		return err
+	}
+	if err := sp.tc.EnsurePreroutingRulesPerTunnelEndpoint(tep); err != nil {
+		return err
+	}
+	_, err := sp.tc.EnsureRoutesPerCluster(tep)
+	return err
+}
+
+// leadingSince returns the time the current replica has been elected leader, recording it if not yet known.
+func (tc *TunnelController) leadingSince() time.Time {
+	tc.standbyMutex.Lock()
+	defer tc.standbyMutex.Unlock()
+	if tc.electedAt.IsZero() {
+		tc.electedAt = time.Now()
+	}
+	return tc.electedAt
+}
+
+// takeOver records that the tunnel described by the given tep, previously managed by the gateway replica
+// with the given IP, has been taken over by the current replica, and reports how long it took since the election.
+func (tc *TunnelController) takeOver(tep *netv1alpha1.TunnelEndpoint, previousGatewayIP string) {
+	elapsed := time.Since(tc.leadingSince()).Round(time.Millisecond)
+	klog.Infof("%s -> tunnel taken over from gateway {%s} in %s since the leader election",
+		tep.Spec.ClusterID, previousGatewayIP, elapsed)
+	tc.Eventf(tep, "Normal", "Failover", "tunnel taken over from gateway %s in %s since the leader election",
+		previousGatewayIP, elapsed)
+}
+
+// isGatewayFinalizer returns true if the given finalizer has been set by a replica of the gateway.
+func isGatewayFinalizer(finalizer string) bool {
+	return strings.HasPrefix(finalizer, liqoconst.LiqoGatewayOperatorName+".") &&
+		strings.HasSuffix(finalizer, "."+finalizerSuffix)
+}
+
+// removeGatewayFinalizers removes from the given tep the finalizers set by the gateway replicas,
+// and returns true if at least one has been removed.
+func removeGatewayFinalizers(tep *netv1alpha1.TunnelEndpoint) bool {
+	var finalizers []string
+	for _, finalizer := range tep.GetFinalizers() {
+		if !isGatewayFinalizer(finalizer) {
+			finalizers = append(finalizers, finalizer)
+		}
+	}
+	if len(finalizers) == len(tep.GetFinalizers()) {
+		return false
+	}
+	tep.SetFinalizers(finalizers)
+	return true
+}
@@ -0,0 +1,38 @@
+package tunneloperator
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	netv1alpha1 "github.com/liqotech/liqo/apis/net/v1alpha1"
+)
+
+var _ = Describe("Standby", func() {
+	Describe("testing removeGatewayFinalizers function", func() {
+		var tep *netv1alpha1.TunnelEndpoint
+
+		BeforeEach(func() {
+			tep = &netv1alpha1.TunnelEndpoint{}
+		})
+
+		Context("when the finalizers of the gateway replicas are present", func() {
+			It("should remove them and keep the other ones", func() {
+				tep.SetFinalizers([]string{
+					"liqo-gateway.10.0.0.1.net.liqo.io",
+					"other.finalizer.io",
+					"liqo-gateway.10.0.0.2.net.liqo.io",
+				})
+				Expect(removeGatewayFinalizers(tep)).To(BeTrue())
+				Expect(tep.GetFinalizers()).To(Equal([]string{"other.finalizer.io"}))
+			})
+		})
+
+		Context("when no finalizer of the gateway replicas is present", func() {
+			It("should return false and leave the finalizers unchanged", func() {
+				tep.SetFinalizers([]string{"other.finalizer.io"})
+				Expect(removeGatewayFinalizers(tep)).To(BeFalse())
+				Expect(tep.GetFinalizers()).To(Equal([]string{"other.finalizer.io"}))
+			})
+		})
+	})
+})
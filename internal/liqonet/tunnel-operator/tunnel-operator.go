@@ -23,6 +23,7 @@ import (
 	"reflect"
 	"strings"
 	"sync"
+	"time"
 
 	"github.com/containernetworking/plugins/pkg/ns"
 	"github.com/vishvananda/netlink"
@@ -35,14 +36,19 @@ import (
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
 	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
 	"sigs.k8s.io/controller-runtime/pkg/predicate"
+	"sigs.k8s.io/controller-runtime/pkg/source"
 
 	netv1alpha1 "github.com/liqotech/liqo/apis/net/v1alpha1"
 	liqoconst "github.com/liqotech/liqo/pkg/consts"
+	"github.com/liqotech/liqo/pkg/liqonet/conncheck"
 	"github.com/liqotech/liqo/pkg/liqonet/iptables"
 	liqonetns "github.com/liqotech/liqo/pkg/liqonet/netns"
 	liqorouting "github.com/liqotech/liqo/pkg/liqonet/routing"
 	"github.com/liqotech/liqo/pkg/liqonet/tunnel"
+	// Registering the ipsec driver as available.
+	_ "github.com/liqotech/liqo/pkg/liqonet/tunnel/ipsec"
 	tunnelwg "github.com/liqotech/liqo/pkg/liqonet/tunnel/wireguard"
 	"github.com/liqotech/liqo/pkg/liqonet/utils"
 )
@@ -60,10 +66,10 @@ type TunnelController struct {
 	client.Client
 	record.EventRecorder
 	tunnel.Driver
-	liqorouting.Routing
-	iptables.IPTHandler
+	iptables.NATHandler
 	k8sClient          k8s.Interface
 	drivers            map[string]tunnel.Driver
+	routingManagers    map[string]liqorouting.Routing
 	namespace          string
 	podIP              string
 	hostNetns          ns.NetNS
@@ -72,6 +78,21 @@ type TunnelController struct {
 	gatewayVeth        netlink.Link
 	readyClustersMutex *sync.Mutex
 	readyClusters      map[string]struct{}
+	connChecker        *conncheck.ConnChecker
+	// connectionEvents is used to trigger the reconciliation of the tunnelendpoints whose connection has to be re-established.
+	connectionEvents chan event.GenericEvent
+	connectionsMutex sync.Mutex
+	// connections contains the connections established by the drivers, keyed by the cluster ID.
+	connections map[string]*netv1alpha1.Connection
+	// reconnections contains the time of the last attempt to re-establish each connection, keyed by the cluster ID.
+	reconnections map[string]time.Time
+	// pendingReconnections contains the cluster IDs of the connections to be re-established.
+	pendingReconnections map[string]struct{}
+	// standbyMutex serializes the preparation of the gateway netns performed while in standby mode
+	// with the reconciliation performed once elected leader.
+	standbyMutex sync.Mutex
+	// electedAt is the time the current replica has been elected leader, zero while in standby mode.
+	electedAt time.Time
 }
 
 // cluster-role
@@ -87,7 +108,7 @@ type TunnelController struct {
 // NewTunnelController instantiates and initializes the tunnel controller.
 func NewTunnelController(podIP, namespace string, er record.EventRecorder,
 	k8sClient k8s.Interface, cl client.Client, readyClustersMutex *sync.Mutex,
-	readyClusters map[string]struct{}, gatewayNetns ns.NetNS) (*TunnelController, error) {
+	readyClusters map[string]struct{}, gatewayNetns ns.NetNS, natHandler iptables.NATHandler) (*TunnelController, error) {
 	tc := &TunnelController{
 		Client:             cl,
 		EventRecorder:      er,
@@ -97,51 +118,30 @@ func NewTunnelController(podIP, namespace string, er record.EventRecorder,
 		readyClustersMutex: readyClustersMutex,
 		readyClusters:      readyClusters,
 		gatewayNetns:       gatewayNetns,
+		NATHandler:         natHandler,
 	}
 
 	err := tc.SetUpTunnelDrivers()
 	if err != nil {
 		return nil, err
 	}
-	link, err := netlink.LinkByName(tunnelwg.DeviceName)
-	if err != nil {
-		return nil, err
-	}
 	err = tc.setUpGWNetns(liqoconst.GatewayNetnsName, liqoconst.HostVethName,
 		liqoconst.GatewayVethName, liqoconst.GatewayVethIPAddr, tunnelwg.MTU)
 	if err != nil {
 		return nil, err
 	}
-	// Move wireguard interface in the gateway network namespace.
-	if err = netlink.LinkSetNsFd(link, int(tc.gatewayNetns.Fd())); err != nil {
-		return nil, fmt.Errorf("failed to move wireguard interfacte to gateway netns: %w", err)
-	}
-	// After the wireguard device has been moved to the new netns we need to:
-	// 1) set it up;
-	// 2) replace the wgctl.Client with a new client spawned in the new netns.
-	var configureWg = func(netnsNamespace ns.NetNS) error {
-		link, err = netlink.LinkByName(tunnelwg.DeviceName)
-		if err != nil {
-			return err
+	for tunnelType, driver := range tc.drivers {
+		if err := tc.moveTunnelLink(tunnelType, driver); err != nil {
+			return nil, err
 		}
-		err = netlink.LinkSetUp(link)
-		if err != nil {
-			return fmt.Errorf("failed to set wireguard iface up in gateway netns: %w", err)
-		}
-		w := tc.drivers[tunnelwg.DriverName]
-		wg := w.(*tunnelwg.Wireguard)
-		if err := wg.SetNewClient(); err != nil {
-			return fmt.Errorf("an error occurred while setting new client in tunnel driver")
-		}
-		return nil
-	}
-	if err := tc.gatewayNetns.Do(configureWg); err != nil {
-		return nil, err
 	}
 	err = tc.SetUpIPTablesHandler()
 	if err != nil {
 		return nil, err
 	}
+	if err := tc.SetUpConnChecker(); err != nil {
+		return nil, err
+	}
 	if err := tc.SetUpRouteManager(); err != nil {
 		return nil, err
 	}
@@ -159,13 +159,23 @@ func (tc *TunnelController) Reconcile(ctx context.Context, req ctrl.Request) (ct
 	var con *netv1alpha1.Connection
 
 	var configGWNetns = func(netNamespace ns.NetNS) error {
+		// The connection is closed first, in case the remote gateway does not reply to the probes.
+		if tc.reconnectionRequested(tep.Spec.ClusterID) {
+			if err = tc.disconnectFromPeer(tep); err != nil {
+				return err
+			}
+		}
 		con, err = tc.connectToPeer(tep)
 		if err != nil {
 			return err
 		}
+		con = tc.setConnection(tep.Spec.ClusterID, con)
 		if err = tc.EnsureIPTablesRulesPerCluster(tep); err != nil {
 			return err
 		}
+		if err = tc.ensureProbing(tep); err != nil {
+			return err
+		}
 		// Set cluster tunnel as ready
 		tc.readyClustersMutex.Lock()
 		defer tc.readyClustersMutex.Unlock()
@@ -183,7 +193,7 @@ func (tc *TunnelController) Reconcile(ctx context.Context, req ctrl.Request) (ct
 		return nil
 	}
 	var unconfigGWNetns = func(netNamespace ns.NetNS) error {
-		if err := tc.IPTHandler.RemoveIPTablesConfigurationPerCluster(tep); err != nil {
+		if err := tc.NATHandler.RemoveIPTablesConfigurationPerCluster(tep); err != nil {
 			klog.Errorf("%s -> unable to remove iptables configuration: %s",
 				tep.Spec.ClusterID, err.Error())
 			return err
@@ -191,6 +201,7 @@ func (tc *TunnelController) Reconcile(ctx context.Context, req ctrl.Request) (ct
 		if err := tc.disconnectFromPeer(tep); err != nil {
 			return err
 		}
+		tc.stopProbing(tep)
 		deleted, err := tc.RemoveRoutesPerCluster(tep)
 		if err != nil {
 			tc.Eventf(tep, "Warning", "Processing", "unable to remove route: %s", err.Error())
@@ -242,7 +253,10 @@ func (tc *TunnelController) Reconcile(ctx context.Context, req ctrl.Request) (ct
 		return nil
 	}
 	// Name of our finalizer.
-	tunnelEndpointFinalizer := strings.Join([]string{liqoconst.LiqoGatewayOperatorName, tc.podIP, "net.liqo.io"}, ".")
+	tunnelEndpointFinalizer := strings.Join([]string{liqoconst.LiqoGatewayOperatorName, tc.podIP, finalizerSuffix}, ".")
+	// The reconciliation is performed only by the leader: the preparation of the gateway netns
+	// performed while in standby mode is stopped, if still in progress.
+	tc.leadingSince()
 	if err = tc.Get(ctx, req.NamespacedName, tep); err != nil && !k8sApiErrors.IsNotFound(err) {
 		klog.Errorf("unable to fetch resource %s: %s", req.String(), err)
 		return ctrl.Result{}, err
@@ -265,7 +279,9 @@ func (tc *TunnelController) Reconcile(ctx context.Context, req ctrl.Request) (ct
 		if !controllerutil.ContainsFinalizer(tep, tunnelEndpointFinalizer) {
 			// The object is not being deleted, so if it does not have our finalizer,
 			// then lets add the finalizer and update the object. This is equivalent
-			// registering our finalizer.
+			// registering our finalizer. The finalizers of the other gateway replicas are removed,
+			// since the configuration they refer to has been taken over by the current one.
+			removeGatewayFinalizers(tep)
 			controllerutil.AddFinalizer(tep, tunnelEndpointFinalizer)
 			if err := tc.Update(ctx, tep); err != nil {
 				if k8sApiErrors.IsConflict(err) {
@@ -277,16 +293,16 @@ func (tc *TunnelController) Reconcile(ctx context.Context, req ctrl.Request) (ct
 			}
 		}
 	} else {
-		// The object is being deleted.
-		if controllerutil.ContainsFinalizer(tep, tunnelEndpointFinalizer) {
+		// The object is being deleted. The configuration is removed also in case only the finalizers
+		// of the previous leaders are present, since it may have been prepared while in standby mode.
+		if removeGatewayFinalizers(tep) {
 			if err = tc.gatewayNetns.Do(unconfigGWNetns); err != nil {
 				return result, err
 			}
 			if err = tc.hostNetns.Do(unconfigHNetns); err != nil {
 				return result, err
 			}
-			// Remove the finalizer from the list and update it.
-			controllerutil.RemoveFinalizer(tep, tunnelEndpointFinalizer)
+			// Update the object, now that the finalizers have been removed from the list.
 			if err := tc.Update(ctx, tep); err != nil {
 				if k8sApiErrors.IsConflict(err) {
 					klog.V(4).Infof("%s -> unable to add finalizers to resource %s: %s", clusterID, req.String(), err)
@@ -306,6 +322,7 @@ func (tc *TunnelController) Reconcile(ctx context.Context, req ctrl.Request) (ct
 		tep.Status.VethIFaceIndex == tc.hostVeth.Attrs().Index {
 		return result, nil
 	}
+	previousGatewayIP := tep.Status.GatewayIP
 	tep.Status.Connection = *con
 	tep.Status.GatewayIP = tc.podIP
 	tep.Status.VethIFaceIndex = tc.hostVeth.Attrs().Index
@@ -317,6 +334,9 @@ func (tc *TunnelController) Reconcile(ctx context.Context, req ctrl.Request) (ct
 		klog.Errorf("%s -> an error occurred while updating status of resource %s: %s", tep.Spec.ClusterID, tep.Name, err)
 		return result, err
 	}
+	if previousGatewayIP != "" && previousGatewayIP != tc.podIP {
+		tc.takeOver(tep, previousGatewayIP)
+	}
 	if err := tc.hostNetns.Do(configHNetns); err != nil {
 		return result, err
 	}
@@ -431,12 +451,32 @@ func (tc *TunnelController) SetupWithManager(mgr ctrl.Manager) error {
 			// we don't want to reconcile on the delete of a resource.
 			return false
 		},
+		UpdateFunc: func(e event.UpdateEvent) bool {
+			// The connection status is updated by the operator itself, hence there is no need to reconcile
+			// the resources when it is the only field which changed.
+			oldTep, okOld := e.ObjectOld.(*netv1alpha1.TunnelEndpoint)
+			newTep, okNew := e.ObjectNew.(*netv1alpha1.TunnelEndpoint)
+			return !okOld || !okNew || !onlyConnectionChanged(oldTep, newTep)
+		},
 	}
 	return ctrl.NewControllerManagedBy(mgr).
 		For(&netv1alpha1.TunnelEndpoint{}).WithEventFilter(resourceToBeProccesedPredicate).
+		Watches(&source.Channel{Source: tc.connectionEvents}, &handler.EnqueueRequestForObject{}).
 		Complete(tc)
 }
 
+// onlyConnectionChanged returns true if the two versions of a tunnelendpoint differ only in the connection status.
+func onlyConnectionChanged(oldTep, newTep *netv1alpha1.TunnelEndpoint) bool {
+	if reflect.DeepEqual(oldTep.Status.Connection, newTep.Status.Connection) {
+		return false
+	}
+	tep := oldTep.DeepCopy()
+	tep.Status.Connection = newTep.Status.Connection
+	tep.ResourceVersion = newTep.ResourceVersion
+	tep.ManagedFields = newTep.ManagedFields
+	return reflect.DeepEqual(tep, newTep)
+}
+
 // SetUpTunnelDrivers for each registered tunnel implementation it creates and initializes the driver.
 func (tc *TunnelController) SetUpTunnelDrivers() error {
 	tc.drivers = make(map[string]tunnel.Driver)
@@ -444,7 +484,9 @@ func (tc *TunnelController) SetUpTunnelDrivers() error {
 		klog.V(3).Infof("Creating driver for tunnel of type %s", tunnelType)
 		d, err := createDriverFunc(tc.k8sClient, tc.namespace)
 		if err != nil {
-			return err
+			// A driver may not be supported by the host (e.g. missing kernel features): the other ones are still usable.
+			klog.Errorf("unable to create driver for tunnel of type %s: %v", tunnelType, err)
+			continue
 		}
 		klog.V(3).Infof("Initializing driver for %s tunnel", tunnelType)
 		err = d.Init()
@@ -454,39 +496,92 @@ func (tc *TunnelController) SetUpTunnelDrivers() error {
 		klog.V(3).Infof("Driver for %s tunnel created and initialized", tunnelType)
 		tc.drivers[tunnelType] = d
 	}
+	if len(tc.drivers) == 0 {
+		return fmt.Errorf("no tunnel driver is available")
+	}
 	return nil
 }
 
-// SetUpIPTablesHandler initializes the IPTables handler of TunnelController.
+// SetUpIPTablesHandler initializes the NAT handler of TunnelController.
 func (tc *TunnelController) SetUpIPTablesHandler() error {
-	iptHandler, err := iptables.NewIPTHandler()
-	if err != nil {
-		return err
-	}
 	var init = func(netNamespace ns.NetNS) error {
-		if err = iptHandler.Init(); err != nil {
-			klog.Errorf("an error occurred while creating iptables handler: %v", err)
+		if err := tc.NATHandler.Init(); err != nil {
+			klog.Errorf("an error occurred while initializing the NAT handler: %v", err)
 			return err
 		}
 		return nil
 	}
-	if err := tc.gatewayNetns.Do(init); err != nil {
-		return err
-	}
-	tc.IPTHandler = iptHandler
-	return nil
+	return tc.gatewayNetns.Do(init)
 }
 
-// SetUpRouteManager initializes the Route manager of TunnelController.
+// SetUpRouteManager initializes a Route manager for each tunnel driver of TunnelController.
 func (tc *TunnelController) SetUpRouteManager() error {
-	// Todo make the gateway routing manager to support more than one vpn technology at the same time.
-	// Todo it should use the right tunnel based on the backend type set inside the tep.
-	grm, err := liqorouting.NewGatewayRoutingManager(unix.RT_TABLE_MAIN, tc.drivers[tunnelwg.DriverName].GetLink())
+	tc.routingManagers = make(map[string]liqorouting.Routing)
+	var setUp = func(netNamespace ns.NetNS) error {
+		for tunnelType, driver := range tc.drivers {
+			// The link is retrieved again, since its attributes may have changed once moved to the gateway netns.
+			link, err := netlink.LinkByName(driver.GetLink().Attrs().Name)
+			if err != nil {
+				return fmt.Errorf("failed to get %s interface in gateway netns: %w", tunnelType, err)
+			}
+			grm, err := liqorouting.NewGatewayRoutingManager(unix.RT_TABLE_MAIN, link)
+			if err != nil {
+				return err
+			}
+			tc.routingManagers[tunnelType] = grm
+		}
+		return nil
+	}
+	return tc.gatewayNetns.Do(setUp)
+}
+
+// EnsureRoutesPerCluster configures the routes for the given remote cluster through the tunnel of the backend type set in the tep.
+func (tc *TunnelController) EnsureRoutesPerCluster(tep *netv1alpha1.TunnelEndpoint) (bool, error) {
+	grm, ok := tc.routingManagers[tep.Spec.BackendType]
+	if !ok {
+		return false, fmt.Errorf("no routing manager for tunnel of type %s found", tep.Spec.BackendType)
+	}
+	return grm.EnsureRoutesPerCluster(tep)
+}
+
+// RemoveRoutesPerCluster removes the routes for the given remote cluster through the tunnel of the backend type set in the tep.
+func (tc *TunnelController) RemoveRoutesPerCluster(tep *netv1alpha1.TunnelEndpoint) (bool, error) {
+	grm, ok := tc.routingManagers[tep.Spec.BackendType]
+	if !ok {
+		return false, fmt.Errorf("no routing manager for tunnel of type %s found", tep.Spec.BackendType)
+	}
+	return grm.RemoveRoutesPerCluster(tep)
+}
+
+// moveTunnelLink moves the interface of the given driver in the gateway network namespace and sets it up.
+func (tc *TunnelController) moveTunnelLink(tunnelType string, driver tunnel.Driver) error {
+	name := driver.GetLink().Attrs().Name
+	link, err := netlink.LinkByName(name)
 	if err != nil {
 		return err
 	}
-	tc.Routing = grm
-	return nil
+	if err = netlink.LinkSetNsFd(link, int(tc.gatewayNetns.Fd())); err != nil {
+		return fmt.Errorf("failed to move %s interface to gateway netns: %w", tunnelType, err)
+	}
+	// After the interface has been moved to the new netns we need to:
+	// 1) set it up;
+	// 2) replace the wgctl.Client with a new client spawned in the new netns, in case of wireguard.
+	var configure = func(netNamespace ns.NetNS) error {
+		link, err := netlink.LinkByName(name)
+		if err != nil {
+			return err
+		}
+		if err = netlink.LinkSetUp(link); err != nil {
+			return fmt.Errorf("failed to set %s iface up in gateway netns: %w", tunnelType, err)
+		}
+		if wg, ok := driver.(*tunnelwg.Wireguard); ok {
+			if err := wg.SetNewClient(); err != nil {
+				return fmt.Errorf("an error occurred while setting new client in tunnel driver")
+			}
+		}
+		return nil
+	}
+	return tc.gatewayNetns.Do(configure)
 }
 
 func (tc *TunnelController) setUpGWNetns(netnsName, hostVethName, gatewayVethName, gatewayVethIPAddr string, vethMtu int) error {
@@ -109,7 +109,9 @@ var _ = BeforeSuite(func() {
 		MetricsBindAddress: "0",
 	})
 
-	controller, err = NewNatMappingController(mgr.GetClient(), &readyClustersMutex, readyClusters, iptNetns)
+	natHandler, err := iptables.NewIPTHandler()
+	Expect(err).To(BeNil())
+	controller, err = NewNatMappingController(mgr.GetClient(), &readyClustersMutex, readyClusters, iptNetns, natHandler)
 	Expect(err).To(BeNil())
 	go func() {
 		if err = mgr.Start(context.Background()); err != nil {
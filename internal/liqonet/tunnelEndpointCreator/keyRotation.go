@@ -0,0 +1,121 @@
+package tunnelEndpointCreator
+
+import (
+	"context"
+	"time"
+
+	"golang.zx2c4.com/wireguard/wgctrl/wgtypes"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/client-go/util/retry"
+	"k8s.io/klog/v2"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	netv1alpha1 "github.com/liqotech/liqo/apis/net/v1alpha1"
+	crdreplicator "github.com/liqotech/liqo/internal/crdReplicator"
+	"github.com/liqotech/liqo/pkg/liqonet/tunnel/wireguard"
+)
+
+// StartKeyRotation periodically checks whether the wireguard keys have to be rotated, and drives the rotation.
+// A rotation is performed in two steps, in order to not tear down the existing tunnels:
+// first the next keys are generated and announced to the remote clusters through the networkconfigs,
+// then, once all the remote clusters acknowledged the next public key, the next keys replace the current ones.
+func (tec *TunnelEndpointCreator) StartKeyRotation() {
+	if tec.BackendType != wireguard.DriverName {
+		return
+	}
+	wait.Until(func() {
+		if err := tec.rotateKeys(context.Background()); err != nil {
+			klog.Errorf("an error occurred while rotating the keys of the %s backend: %v", tec.BackendType, err)
+		}
+	}, ResyncPeriod, tec.ForeignClusterStopWatcher)
+}
+
+func (tec *TunnelEndpointCreator) rotateKeys(ctx context.Context) error {
+	tec.Mutex.Lock()
+	interval := tec.KeyRotationInterval
+	tec.Mutex.Unlock()
+	return retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		s, err := tec.ClientSet.CoreV1().Secrets(tec.Namespace).Get(ctx, wireguard.KeysName, metav1.GetOptions{})
+		if err != nil {
+			return client.IgnoreNotFound(err)
+		}
+		if _, found := s.Data[wireguard.NextPrivateKey]; !found {
+			if !rotationRequested(s, interval) {
+				return nil
+			}
+			return tec.generateNextKeys(ctx, s)
+		}
+		accepted, err := tec.nextPublicKeyAccepted(ctx, string(s.Data[wireguard.NextPublicKey]))
+		if err != nil || !accepted {
+			return err
+		}
+		return tec.promoteNextKeys(ctx, s)
+	})
+}
+
+// rotationRequested returns true if the rotation of the keys has been requested through the annotation,
+// or if the keys have been generated more than interval ago.
+func rotationRequested(s *corev1.Secret, interval time.Duration) bool {
+	if _, found := s.Annotations[wireguard.RotateKeysAnnotation]; found {
+		return true
+	}
+	if interval <= 0 {
+		return false
+	}
+	rotatedAt, err := time.Parse(time.RFC3339, s.Annotations[wireguard.KeysRotatedAtAnnotation])
+	if err != nil {
+		// The keys have been generated before the rotation has been introduced, hence we use the secret age.
+		rotatedAt = s.CreationTimestamp.Time
+	}
+	return time.Since(rotatedAt) >= interval
+}
+
+// generateNextKeys generates the next keys and saves them into the secret, to start a new rotation.
+func (tec *TunnelEndpointCreator) generateNextKeys(ctx context.Context, s *corev1.Secret) error {
+	priv, err := wgtypes.GeneratePrivateKey()
+	if err != nil {
+		return err
+	}
+	s.Data[wireguard.NextPrivateKey] = []byte(priv.String())
+	s.Data[wireguard.NextPublicKey] = []byte(priv.PublicKey().String())
+	delete(s.Annotations, wireguard.RotateKeysAnnotation)
+	if _, err := tec.ClientSet.CoreV1().Secrets(tec.Namespace).Update(ctx, s, metav1.UpdateOptions{}); err != nil {
+		return err
+	}
+	klog.Infof("generated the next keys for the %s backend, waiting for the remote clusters to accept them", tec.BackendType)
+	return nil
+}
+
+// nextPublicKeyAccepted returns true if all the remote clusters acknowledged the next public key.
+func (tec *TunnelEndpointCreator) nextPublicKeyAccepted(ctx context.Context, nextPubKey string) (bool, error) {
+	netConfigList := &netv1alpha1.NetworkConfigList{}
+	labels := client.MatchingLabels{crdreplicator.LocalLabelSelector: "true"}
+	if err := tec.List(ctx, netConfigList, labels); err != nil {
+		return false, err
+	}
+	for i := range netConfigList.Items {
+		if netConfigList.Items[i].Status.AcceptedNextPublicKey != nextPubKey {
+			return false, nil
+		}
+	}
+	return true, nil
+}
+
+// promoteNextKeys replaces the current keys with the next ones, completing the rotation.
+func (tec *TunnelEndpointCreator) promoteNextKeys(ctx context.Context, s *corev1.Secret) error {
+	s.Data[wireguard.PrivateKey] = s.Data[wireguard.NextPrivateKey]
+	s.Data[wireguard.PublicKey] = s.Data[wireguard.NextPublicKey]
+	delete(s.Data, wireguard.NextPrivateKey)
+	delete(s.Data, wireguard.NextPublicKey)
+	if s.Annotations == nil {
+		s.Annotations = map[string]string{}
+	}
+	s.Annotations[wireguard.KeysRotatedAtAnnotation] = time.Now().UTC().Format(time.RFC3339)
+	if _, err := tec.ClientSet.CoreV1().Secrets(tec.Namespace).Update(ctx, s, metav1.UpdateOptions{}); err != nil {
+		return err
+	}
+	klog.Infof("the keys of the %s backend have been rotated", tec.BackendType)
+	return nil
+}
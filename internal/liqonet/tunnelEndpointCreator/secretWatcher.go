@@ -2,6 +2,7 @@ package tunnelEndpointCreator
 
 import (
 	"context"
+	"reflect"
 	"strings"
 
 	"golang.zx2c4.com/wireguard/wgctrl/wgtypes"
@@ -17,6 +18,7 @@ import (
 
 	netv1alpha1 "github.com/liqotech/liqo/apis/net/v1alpha1"
 	crdreplicator "github.com/liqotech/liqo/internal/crdReplicator"
+	"github.com/liqotech/liqo/pkg/liqonet/tunnel/ipsec"
 	"github.com/liqotech/liqo/pkg/liqonet/tunnel/wireguard"
 )
 
@@ -31,7 +33,7 @@ func (tec *TunnelEndpointCreator) StartSecretWatcher() {
 		klog.Errorf("unable to sync caches")
 		return
 	}
-	dynFactory := dynamicinformer.NewFilteredDynamicSharedInformerFactory(tec.DynClient, ResyncPeriod, tec.Namespace, setSecretFilteringLabel)
+	dynFactory := dynamicinformer.NewFilteredDynamicSharedInformerFactory(tec.DynClient, ResyncPeriod, tec.Namespace, tec.setSecretFilteringLabel)
 	go tec.Watcher(dynFactory, corev1.SchemeGroupVersion.WithResource(secretResource), cache.ResourceEventHandlerFuncs{
 		AddFunc:    tec.secretHandlerAdd,
 		UpdateFunc: tec.secretHandlerUpdate,
@@ -59,14 +61,35 @@ func (tec *TunnelEndpointCreator) secretHandlerAdd(obj interface{}) {
 	}
 	pubKey, err := wgtypes.ParseKey(string(pubKeyByte))
 	if err != nil {
-		klog.Errorf("secret named %s: publicKey for wireguard backend has not been set yet", s.Name)
+		klog.Errorf("secret named %s: publicKey for %s backend has not been set yet", s.Name, tec.BackendType)
 		return
 	}
 
-	if pubKey.String() == tec.wgPubKey {
+	// the next public key is present only while a key rotation is in progress.
+	nextPubKey := ""
+	if nextPubKeyByte, found := s.Data[wireguard.NextPublicKey]; found {
+		key, err := wgtypes.ParseKey(string(nextPubKeyByte))
+		if err != nil {
+			klog.Errorf("secret named %s: unable to parse the next publicKey for %s backend: %v", s.Name, tec.BackendType, err)
+			return
+		}
+		nextPubKey = key.String()
+	}
+
+	// the nonces are present only for the ipsec backend, one for each remote cluster.
+	nonces := map[string]string{}
+	for k, v := range s.Data {
+		if strings.HasPrefix(k, ipsec.NoncePrefix) {
+			nonces[strings.TrimPrefix(k, ipsec.NoncePrefix)] = string(v)
+		}
+	}
+
+	if pubKey.String() == tec.wgPubKey && nextPubKey == tec.wgNextPubKey && reflect.DeepEqual(nonces, tec.ipsecNonces) {
 		return
 	}
 	tec.wgPubKey = pubKey.String()
+	tec.wgNextPubKey = nextPubKey
+	tec.ipsecNonces = nonces
 	if !tec.wgConfigured {
 		tec.WaitConfig.Done()
 		klog.Infof("called done on waitgroup")
@@ -92,6 +115,16 @@ func (tec *TunnelEndpointCreator) secretHandlerAdd(obj interface{}) {
 				return err
 			}
 			netConfig.Spec.BackendConfig[wireguard.PublicKey] = pubKey.String()
+			if nextPubKey != "" {
+				netConfig.Spec.BackendConfig[wireguard.NextPublicKey] = nextPubKey
+			} else {
+				delete(netConfig.Spec.BackendConfig, wireguard.NextPublicKey)
+			}
+			if nonce, found := nonces[netConfig.Spec.ClusterID]; found {
+				netConfig.Spec.BackendConfig[ipsec.Nonce] = nonce
+			} else {
+				delete(netConfig.Spec.BackendConfig, ipsec.Nonce)
+			}
 			err = tec.Update(context.Background(), &netConfig)
 			return err
 		})
@@ -105,13 +138,13 @@ func (tec *TunnelEndpointCreator) secretHandlerUpdate(oldObj interface{}, newObj
 	tec.secretHandlerAdd(newObj)
 }
 
-func setSecretFilteringLabel(options *metav1.ListOptions) {
-	//we want to watch only the resources that should be replicated on a remote cluster
+func (tec *TunnelEndpointCreator) setSecretFilteringLabel(options *metav1.ListOptions) {
+	//we want to watch only the secrets containing the keys of the configured vpn backend
 	if options.LabelSelector == "" {
-		newLabelSelector := []string{wireguard.KeysLabel, "=", wireguard.DriverName}
+		newLabelSelector := []string{wireguard.KeysLabel, "=", tec.BackendType}
 		options.LabelSelector = strings.Join(newLabelSelector, "")
 	} else {
-		newLabelSelector := []string{options.LabelSelector, wireguard.KeysLabel, "=", wireguard.DriverName}
+		newLabelSelector := []string{options.LabelSelector, wireguard.KeysLabel, "=", tec.BackendType}
 		options.LabelSelector = strings.Join(newLabelSelector, "")
 	}
 }
@@ -73,9 +73,9 @@ func (tec *TunnelEndpointCreator) serviceHandlerAdd(obj interface{}) {
 			return
 		}
 		endpointIP = nodeIP
-		//check if the nodePort for wireguard has been set
+		//check if the nodePort for the vpn backend has been set
 		for _, port := range s.Spec.Ports {
-			if port.Name == wireguard.DriverName {
+			if port.Name == tec.BackendType {
 				if port.NodePort == 0 {
 					klog.Infof("the nodePort for service %s in namespace %s not set yet", s.GetName(), s.GetNamespace())
 					return
@@ -95,7 +95,7 @@ func (tec *TunnelEndpointCreator) serviceHandlerAdd(obj interface{}) {
 		endpointIP = s.Status.LoadBalancer.Ingress[0].IP
 
 		for _, port := range s.Spec.Ports {
-			if port.Name == wireguard.DriverName {
+			if port.Name == tec.BackendType {
 				if port.Port == 0 {
 					klog.Infof("the nodePort for service %s in namespace %s not set yet", s.GetName(), s.GetNamespace())
 					return
@@ -106,7 +106,7 @@ func (tec *TunnelEndpointCreator) serviceHandlerAdd(obj interface{}) {
 		}
 	}
 	if !portFound {
-		klog.Infof("the service %s of type %s with label %s set to %s does not have a port named %s", s.Name, s.Spec.Type, serviceLabelKey, serviceLabelValue, wireguard.DriverName)
+		klog.Infof("the service %s of type %s with label %s set to %s does not have a port named %s", s.Name, s.Spec.Type, serviceLabelKey, serviceLabelValue, tec.BackendType)
 		return
 	}
 	if endpointIP != tec.EndpointIP || endpointPort != tec.EndpointPort {
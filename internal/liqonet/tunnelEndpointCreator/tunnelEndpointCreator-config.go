@@ -3,6 +3,7 @@ package tunnelEndpointCreator
 import (
 	"os"
 	"reflect"
+	"time"
 
 	"k8s.io/apimachinery/pkg/runtime/schema"
 	"k8s.io/client-go/kubernetes/scheme"
@@ -41,6 +42,16 @@ func (tec *TunnelEndpointCreator) setNetParameters(config *configv1alpha1.Cluste
 		klog.Infof("ExternalCIDR set to %s", externalCIDR)
 		tec.ExternalCIDR = externalCIDR
 	}
+	var keyRotationInterval time.Duration
+	if interval := config.Spec.LiqonetConfig.WireguardKeyRotationInterval; interval != nil {
+		keyRotationInterval = interval.Duration
+	}
+	tec.Mutex.Lock()
+	if tec.KeyRotationInterval != keyRotationInterval {
+		klog.Infof("KeyRotationInterval set to %s", keyRotationInterval)
+		tec.KeyRotationInterval = keyRotationInterval
+	}
+	tec.Mutex.Unlock()
 }
 
 // Helper func that returns a true if the subnet slice passed as first parameter
@@ -29,6 +29,7 @@ import (
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/client-go/dynamic"
 	"k8s.io/client-go/dynamic/dynamicinformer"
 	k8s "k8s.io/client-go/kubernetes"
@@ -39,12 +40,16 @@ import (
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+	"sigs.k8s.io/controller-runtime/pkg/source"
 
 	discoveryv1alpha1 "github.com/liqotech/liqo/apis/discovery/v1alpha1"
 	netv1alpha1 "github.com/liqotech/liqo/apis/net/v1alpha1"
 	crdreplicator "github.com/liqotech/liqo/internal/crdReplicator"
 	liqoconst "github.com/liqotech/liqo/pkg/consts"
 	liqonetIpam "github.com/liqotech/liqo/pkg/liqonet/ipam"
+	"github.com/liqotech/liqo/pkg/liqonet/tunnel/ipsec"
 	"github.com/liqotech/liqo/pkg/liqonet/tunnel/wireguard"
 	liqonetutils "github.com/liqotech/liqo/pkg/liqonet/utils"
 	"github.com/liqotech/liqo/pkg/utils"
@@ -90,6 +95,7 @@ type TunnelEndpointCreator struct {
 	DynClient                  dynamic.Interface
 	EndpointIP                 string
 	EndpointPort               string
+	BackendType                string
 	PodCIDR                    string
 	ServiceCIDR                string
 	ExternalCIDR               string
@@ -100,6 +106,8 @@ type TunnelEndpointCreator struct {
 	WaitConfig                 *sync.WaitGroup
 	IpamConfigured             bool
 	wgPubKey                   string
+	wgNextPubKey               string
+	ipsecNonces                map[string]string
 	IsConfigured               bool
 	Configured                 chan bool
 	ForeignClusterStartWatcher chan bool
@@ -112,6 +120,7 @@ type TunnelEndpointCreator struct {
 	svcConfigured              bool
 	cfgConfigured              bool
 	RetryTimeout               time.Duration
+	KeyRotationInterval        time.Duration
 }
 
 // rbac for the net.liqo.io api
@@ -128,9 +137,10 @@ type TunnelEndpointCreator struct {
 // +kubebuilder:rbac:groups=core,resources=nodes,verbs=get;list;watch
 // +kubebuilder:rbac:groups=core,resources=events,verbs=get;list;watch;create;update;patch;delete
 // role
-// +kubebuilder:rbac:groups=core,namespace="do-not-care",resources=secrets,verbs=get;list;watch
+// +kubebuilder:rbac:groups=core,namespace="do-not-care",resources=secrets,verbs=get;list;watch;update
 // +kubebuilder:rbac:groups=core,namespace="do-not-care",resources=services,verbs=get;list;watch;update
 // +kubebuilder:rbac:groups=core,namespace="do-not-care",resources=pods,verbs=get;list;watch
+// +kubebuilder:rbac:groups=coordination.k8s.io,namespace="do-not-care",resources=leases,verbs=get;create;update
 
 // Reconciler method.
 func (tec *TunnelEndpointCreator) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
@@ -210,9 +220,33 @@ func (tec *TunnelEndpointCreator) Reconcile(ctx context.Context, req ctrl.Reques
 func (tec *TunnelEndpointCreator) SetupWithManager(mgr ctrl.Manager) error {
 	return ctrl.NewControllerManagedBy(mgr).
 		For(&netv1alpha1.NetworkConfig{}).
+		// The tunnelendpoints are watched to acknowledge the keys configured by the gateway.
+		Watches(&source.Kind{Type: &netv1alpha1.TunnelEndpoint{}}, handler.EnqueueRequestsFromMapFunc(tec.mapTunnelEndpointToRequests)).
 		Complete(tec)
 }
 
+// mapTunnelEndpointToRequests maps a tunnelendpoint to the remote networkconfig of the same cluster.
+func (tec *TunnelEndpointCreator) mapTunnelEndpointToRequests(obj client.Object) []reconcile.Request {
+	tep, ok := obj.(*netv1alpha1.TunnelEndpoint)
+	if !ok {
+		return nil
+	}
+	netConfigList := &netv1alpha1.NetworkConfigList{}
+	labels := client.MatchingLabels{crdreplicator.RemoteLabelSelector: tep.Spec.ClusterID}
+	if err := tec.List(context.Background(), netConfigList, labels); err != nil {
+		klog.Errorf("an error occurred while listing resources: %s", err)
+		return nil
+	}
+	requests := make([]reconcile.Request, 0, len(netConfigList.Items))
+	for i := range netConfigList.Items {
+		requests = append(requests, reconcile.Request{NamespacedName: types.NamespacedName{
+			Namespace: netConfigList.Items[i].Namespace,
+			Name:      netConfigList.Items[i].Name,
+		}})
+	}
+	return requests
+}
+
 // SetupSignalHandlerForTunEndCreator registers for SIGTERM, SIGINT, SIGKILL. A stop channel is returned
 // which is closed on one of these signals.
 func (tec *TunnelEndpointCreator) SetupSignalHandlerForTunEndCreator() context.Context {
@@ -269,7 +303,7 @@ func (tec *TunnelEndpointCreator) createNetConfig(fc *discoveryv1alpha1.ForeignC
 			PodCIDR:      tec.PodCIDR,
 			ExternalCIDR: tec.ExternalCIDR,
 			EndpointIP:   tec.EndpointIP,
-			BackendType:  wireguard.DriverName,
+			BackendType:  tec.BackendType,
 			BackendConfig: map[string]string{
 				wireguard.PublicKey:     tec.wgPubKey,
 				wireguard.ListeningPort: tec.EndpointPort,
@@ -277,13 +311,26 @@ func (tec *TunnelEndpointCreator) createNetConfig(fc *discoveryv1alpha1.ForeignC
 		},
 		Status: netv1alpha1.NetworkConfigStatus{},
 	}
+	if tec.wgNextPubKey != "" {
+		netConfig.Spec.BackendConfig[wireguard.NextPublicKey] = tec.wgNextPubKey
+	}
+	if nonce, found := tec.ipsecNonces[clusterID]; found {
+		netConfig.Spec.BackendConfig[ipsec.Nonce] = nonce
+	}
+	// Advertise the networks the remote cluster is reached with, if it belongs to the same mesh.
+	meshSubnets, err := tec.IPManager.GetMeshSubnets(clusterID)
+	if err != nil {
+		return err
+	}
+	netConfig.Spec.MeshSubnets = meshSubnets
 	// check if the resource for the remote cluster already exists
-	_, exists, err := tec.GetNetworkConfig(clusterID, fc.Status.TenantNamespace.Local)
+	existing, exists, err := tec.GetNetworkConfig(clusterID, fc.Status.TenantNamespace.Local)
 	if err != nil {
 		return err
 	}
 	if exists {
-		return nil
+		// The mesh plan may have been recorded after the creation of the resource.
+		return tec.updateMeshSubnets(existing, meshSubnets)
 	}
 	err = tec.Create(context.TODO(), &netConfig)
 	if err != nil {
@@ -295,6 +342,28 @@ func (tec *TunnelEndpointCreator) createNetConfig(fc *discoveryv1alpha1.ForeignC
 	return nil
 }
 
+// updateMeshSubnets updates the networks advertised in an existing NetworkConfig, if they differ from the given ones.
+func (tec *TunnelEndpointCreator) updateMeshSubnets(nc *netv1alpha1.NetworkConfig, meshSubnets *netv1alpha1.MeshSubnets) error {
+	if reflect.DeepEqual(nc.Spec.MeshSubnets, meshSubnets) {
+		return nil
+	}
+	retryError := retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		var netConfig netv1alpha1.NetworkConfig
+		if err := tec.Get(context.TODO(), client.ObjectKeyFromObject(nc), &netConfig); err != nil {
+			return err
+		}
+		netConfig.Spec.MeshSubnets = meshSubnets
+		return tec.Update(context.TODO(), &netConfig)
+	})
+	if retryError != nil {
+		klog.Errorf("an error occurred while updating the mesh networks of resource %s of type %s: %s",
+			nc.Name, netv1alpha1.GroupVersion.String(), retryError)
+		return retryError
+	}
+	klog.Infof("mesh networks of resource %s of type %s updated", nc.Name, netv1alpha1.GroupVersion.String())
+	return nil
+}
+
 func (tec *TunnelEndpointCreator) deleteNetConfig(fc *discoveryv1alpha1.ForeignCluster) error {
 	clusterID := fc.Spec.ClusterIdentity.ClusterID
 	netConfigList := &netv1alpha1.NetworkConfigList{}
@@ -364,6 +433,15 @@ func (tec *TunnelEndpointCreator) processRemoteNetConfig(netConfig *netv1alpha1.
 	// Networkconfigs resource have a Spec.ClusterID field that contains
 	// the clusterid of the destination cluster(the local cluster in this case)
 	// In order to take the ClusterID of the sender we need to retrieve it from the labels.
+	// The peering is refused if the two clusters disagree on the networks the local one is reached with in the mesh.
+	localMeshSubnets, err := tec.IPManager.GetMeshSubnets(netConfig.Spec.ClusterID)
+	if err != nil {
+		return err
+	}
+	if err := liqonetIpam.CheckMeshSubnets(localMeshSubnets, netConfig.Spec.MeshSubnets); err != nil {
+		klog.Errorf("refusing the peering with cluster %s: %s", netConfig.Labels[crdreplicator.RemoteLabelSelector], err)
+		return err
+	}
 	podCIDR, externalCIDR, err := tec.IPManager.GetSubnetsPerCluster(netConfig.Spec.PodCIDR,
 		netConfig.Spec.ExternalCIDR, netConfig.Labels[crdreplicator.RemoteLabelSelector])
 	if err != nil {
@@ -381,8 +459,8 @@ func (tec *TunnelEndpointCreator) processRemoteNetConfig(netConfig *netv1alpha1.
 		// Local cluster has not remapped the PodCIDR
 		if netConfig.Status.PodCIDRNAT != liqoconst.DefaultCIDRValue {
 			toBeUpdated = true
-			podCIDR = liqoconst.DefaultCIDRValue
 		}
+		podCIDR = liqoconst.DefaultCIDRValue
 	}
 	if externalCIDR != netConfig.Spec.ExternalCIDR {
 		// Local cluster has remapped the ExternalCIDR of the remote cluster because of conflicts
@@ -393,8 +471,16 @@ func (tec *TunnelEndpointCreator) processRemoteNetConfig(netConfig *netv1alpha1.
 		// Local cluster has not remapped the ExternalCIDR
 		if netConfig.Status.ExternalCIDRNAT != liqoconst.DefaultCIDRValue {
 			toBeUpdated = true
-			externalCIDR = liqoconst.DefaultCIDRValue
 		}
+		externalCIDR = liqoconst.DefaultCIDRValue
+	}
+	// Acknowledge the next public key announced by the remote cluster, once configured by the local gateway.
+	acceptedNextPubKey, err := tec.getAcceptedNextPublicKey(netConfig)
+	if err != nil {
+		return err
+	}
+	if acceptedNextPubKey != netConfig.Status.AcceptedNextPublicKey {
+		toBeUpdated = true
 	}
 	if utils.GetOwnerByKind(&netConfig.OwnerReferences, "ForeignCluster") == nil {
 		// if it has no owner of kind ForeignCluster, add it
@@ -418,6 +504,7 @@ func (tec *TunnelEndpointCreator) processRemoteNetConfig(netConfig *netv1alpha1.
 		netConfig.Status.Processed = true
 		netConfig.Status.PodCIDRNAT = podCIDR
 		netConfig.Status.ExternalCIDRNAT = externalCIDR
+		netConfig.Status.AcceptedNextPublicKey = acceptedNextPubKey
 		err := tec.Status().Update(context.Background(), netConfig)
 		if err != nil {
 			klog.Errorf("an error occurred while updating the status of resource %s: %s", netConfig.Name, err)
@@ -427,6 +514,23 @@ func (tec *TunnelEndpointCreator) processRemoteNetConfig(netConfig *netv1alpha1.
 	return nil
 }
 
+// getAcceptedNextPublicKey returns the next public key announced by the remote cluster through the given remote
+// networkconfig, if it has already been configured by the local gateway, as reported by the tunnelendpoint status.
+func (tec *TunnelEndpointCreator) getAcceptedNextPublicKey(netConfig *netv1alpha1.NetworkConfig) (string, error) {
+	nextPubKey, found := netConfig.Spec.BackendConfig[wireguard.NextPublicKey]
+	if !found {
+		return "", nil
+	}
+	tep, found, err := tec.GetTunnelEndpoint(netConfig.Labels[crdreplicator.RemoteLabelSelector], netConfig.GetNamespace())
+	if err != nil || !found {
+		return "", err
+	}
+	if tep.Status.Connection.PeerConfiguration[wireguard.NextPublicKey] != nextPubKey {
+		return "", nil
+	}
+	return nextPubKey, nil
+}
+
 func (tec *TunnelEndpointCreator) processLocalNetConfig(netConfig *netv1alpha1.NetworkConfig) error {
 	// first check that this is the only resource for the remote cluster
 	netConfigList := &netv1alpha1.NetworkConfigList{}
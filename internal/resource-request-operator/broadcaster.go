@@ -2,6 +2,7 @@ package resourcerequestoperator
 
 import (
 	"context"
+	"reflect"
 	"sync"
 	"time"
 
@@ -21,20 +22,29 @@ import (
 
 // Broadcaster is an object which is used to get resources of the cluster.
 type Broadcaster struct {
-	allocatable    corev1.ResourceList
-	resourcePodMap map[string]corev1.ResourceList
-	clusterConfig  configv1alpha1.ClusterConfig
-	nodeMutex      sync.RWMutex
-	podMutex       sync.RWMutex
-	configMutex    sync.RWMutex
-	nodeInformer   cache.SharedIndexInformer
-	podInformer    cache.SharedIndexInformer
+	allocatable      corev1.ResourceList
+	resourcePodMap   map[string]corev1.ResourceList
+	reservations     map[string]*reservation
+	clusterConfig    configv1alpha1.ClusterConfig
+	nodeMutex        sync.RWMutex
+	podMutex         sync.RWMutex
+	configMutex      sync.RWMutex
+	reservationMutex sync.RWMutex
+	nodeInformer     cache.SharedIndexInformer
+	podInformer      cache.SharedIndexInformer
+
+	// notifier is called when the shared resources change more than the update threshold, or when the
+	// policies enforced on the namespaces of the foreign clusters change.
+	notifier      func()
+	lastNotified  corev1.ResourceList
+	notifierMutex sync.Mutex
 }
 
 // SetupBroadcaster create the informer e run it to signal node changes updating Offers.
 func (b *Broadcaster) SetupBroadcaster(clientset kubernetes.Interface, resyncPeriod time.Duration) error {
 	b.allocatable = corev1.ResourceList{}
 	b.resourcePodMap = map[string]corev1.ResourceList{}
+	b.reservations = map[string]*reservation{}
 	factory := informers.NewSharedInformerFactory(clientset, resyncPeriod)
 	b.nodeInformer = factory.Core().V1().Nodes().Informer()
 	b.nodeInformer.AddEventHandler(cache.ResourceEventHandlerFuncs{
@@ -78,8 +88,18 @@ func (b *Broadcaster) WatchConfiguration(localKubeconfig string, crdClient *crdc
 
 func (b *Broadcaster) setConfig(configuration *configv1alpha1.ClusterConfig) {
 	b.configMutex.Lock()
-	defer b.configMutex.Unlock()
+	previous := b.clusterConfig.Spec.AdvertisementConfig.OutgoingConfig
 	b.clusterConfig = *configuration
+	b.configMutex.Unlock()
+	// the sharing percentage may have changed.
+	b.checkThreshold()
+
+	// the policies enforced on the namespaces of the foreign clusters may have changed.
+	current := &configuration.Spec.AdvertisementConfig.OutgoingConfig
+	if !reflect.DeepEqual(previous.RemoteNamespaceQuota, current.RemoteNamespaceQuota) ||
+		!reflect.DeepEqual(previous.RemoteNamespaceSecurity, current.RemoteNamespaceSecurity) {
+		b.notify()
+	}
 }
 
 func (b *Broadcaster) getConfig() *configv1alpha1.ClusterConfig {
@@ -98,6 +118,7 @@ func (b *Broadcaster) onNodeAdd(obj interface{}) {
 		currentResources := b.readClusterResources()
 		addResources(currentResources, *toAdd)
 		b.writeClusterResources(currentResources)
+		b.checkThreshold()
 	}
 }
 
@@ -122,6 +143,7 @@ func (b *Broadcaster) onNodeUpdate(oldObj, newObj interface{}) {
 		subResources(currentResources, oldNodeResources)
 	}
 	b.writeClusterResources(currentResources)
+	b.checkThreshold()
 }
 
 // react to a Node Delete.
@@ -133,6 +155,7 @@ func (b *Broadcaster) onNodeDelete(obj interface{}) {
 		klog.V(4).Infof("Deleting Node %s\n", node.Name)
 		subResources(currentResources, *toDelete)
 		b.writeClusterResources(currentResources)
+		b.checkThreshold()
 	}
 }
 
@@ -152,6 +175,7 @@ func (b *Broadcaster) onPodAdd(obj interface{}) {
 		addResources(currentPodsResources, podResources)
 		b.writePodsResources(clusterID, currentPodsResources)
 	}
+	b.checkThreshold()
 }
 
 func (b *Broadcaster) onPodDelete(obj interface{}) {
@@ -168,6 +192,41 @@ func (b *Broadcaster) onPodDelete(obj interface{}) {
 		subResources(currentPodsResources, podResources)
 		b.writePodsResources(clusterID, currentPodsResources)
 	}
+	b.checkThreshold()
+}
+
+// setNotifier sets the function to be called when the shared resources change more than the update threshold.
+func (b *Broadcaster) setNotifier(notifier func()) {
+	b.notifierMutex.Lock()
+	defer b.notifierMutex.Unlock()
+	b.notifier = notifier
+}
+
+// notify calls the notifier, regardless of the variation of the shared resources.
+func (b *Broadcaster) notify() {
+	b.notifierMutex.Lock()
+	defer b.notifierMutex.Unlock()
+	if b.notifier != nil {
+		go b.notifier()
+	}
+}
+
+// checkThreshold calls the notifier if the shared resources changed more than the update threshold
+// since the last notification.
+func (b *Broadcaster) checkThreshold() {
+	b.notifierMutex.Lock()
+	defer b.notifierMutex.Unlock()
+	if b.notifier == nil {
+		return
+	}
+
+	budget := b.readSharedBudget()
+	threshold := b.getConfig().Spec.AdvertisementConfig.OutgoingConfig.OfferUpdateThresholdPercentage
+	if !resourcesChanged(b.lastNotified, budget, threshold) {
+		return
+	}
+	b.lastNotified = budget
+	go b.notifier()
 }
 
 // write nodes resources in thread safe mode.
@@ -184,19 +243,6 @@ func (b *Broadcaster) writePodsResources(clusterID string, newResources corev1.R
 	b.resourcePodMap[clusterID] = newResources.DeepCopy()
 }
 
-// ReadResources return in thread safe mode a scaled value of the resources.
-func (b *Broadcaster) ReadResources(clusterID string) corev1.ResourceList {
-	toRead := b.readClusterResources()
-	podsResources := b.readPodResources(clusterID)
-	addResources(toRead, podsResources)
-	for resourceName, quantity := range toRead {
-		scaled := quantity
-		b.scaleResources(resourceName, &scaled)
-		toRead[resourceName] = scaled
-	}
-	return toRead
-}
-
 func (b *Broadcaster) readClusterResources() corev1.ResourceList {
 	b.nodeMutex.RLock()
 	defer b.nodeMutex.RUnlock()
@@ -0,0 +1,83 @@
+package resourcerequestoperator
+
+import (
+	"fmt"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/klog/v2"
+
+	configv1alpha1 "github.com/liqotech/liqo/apis/config/v1alpha1"
+)
+
+const minutesPerDay = 24 * 60
+
+// currentPrices returns the prices of the shared resources at the given time instant, according to the price
+// schedules, and the time after which they will change (zero if they never change).
+func currentPrices(config *configv1alpha1.BroadcasterConfig, now time.Time) (corev1.ResourceList, time.Duration) {
+	prices := config.Prices.DeepCopy()
+	if len(config.PriceSchedules) == 0 {
+		return prices, 0
+	}
+
+	now = now.UTC()
+	minute := now.Hour()*60 + now.Minute()
+	applied := false
+	nextChange := minutesPerDay
+
+	for i := range config.PriceSchedules {
+		schedule := &config.PriceSchedules[i]
+		start, err := parseTimeOfDay(schedule.Start)
+		if err != nil {
+			klog.Error(err)
+			continue
+		}
+		end, err := parseTimeOfDay(schedule.End)
+		if err != nil {
+			klog.Error(err)
+			continue
+		}
+
+		if !applied && inTimeRange(minute, start, end) {
+			if prices == nil {
+				prices = corev1.ResourceList{}
+			}
+			for resourceName, price := range schedule.Prices {
+				prices[resourceName] = price.DeepCopy()
+			}
+			applied = true
+		}
+
+		for _, boundary := range []int{start, end} {
+			if distance := (boundary - minute + minutesPerDay) % minutesPerDay; distance > 0 && distance < nextChange {
+				nextChange = distance
+			}
+		}
+	}
+
+	// subtract the time already elapsed in the current minute.
+	elapsed := time.Duration(now.Second())*time.Second + time.Duration(now.Nanosecond())
+	return prices, time.Duration(nextChange)*time.Minute - elapsed
+}
+
+// parseTimeOfDay converts a time of the day in the HH:MM format to the number of minutes since midnight.
+func parseTimeOfDay(value string) (int, error) {
+	parsed, err := time.Parse("15:04", value)
+	if err != nil {
+		return 0, fmt.Errorf("invalid time of the day %q: %w", value, err)
+	}
+	return parsed.Hour()*60 + parsed.Minute(), nil
+}
+
+// inTimeRange checks if the given minute of the day is included in the [start, end) range, which may cross midnight.
+// A range with the same start and end includes the whole day.
+func inTimeRange(minute, start, end int) bool {
+	switch {
+	case start < end:
+		return minute >= start && minute < end
+	case start > end:
+		return minute >= start || minute < end
+	default:
+		return true
+	}
+}
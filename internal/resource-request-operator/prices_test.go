@@ -0,0 +1,67 @@
+package resourcerequestoperator
+
+import (
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/ginkgo/extensions/table"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+
+	configv1alpha1 "github.com/liqotech/liqo/apis/config/v1alpha1"
+)
+
+var _ = Describe("Prices", func() {
+
+	config := &configv1alpha1.BroadcasterConfig{
+		Prices: corev1.ResourceList{
+			corev1.ResourceCPU:    resource.MustParse("2"),
+			corev1.ResourceMemory: resource.MustParse("1"),
+		},
+		PriceSchedules: []configv1alpha1.PriceSchedule{{
+			Start:  "22:00",
+			End:    "06:00",
+			Prices: corev1.ResourceList{corev1.ResourceCPU: resource.MustParse("1")},
+		}},
+	}
+
+	type pricesTestcase struct {
+		now                time.Time
+		expectedCPUPrice   string
+		expectedNextChange time.Duration
+	}
+
+	DescribeTable("compute the current prices",
+		func(c pricesTestcase) {
+			prices, nextChange := currentPrices(config, c.now)
+			Expect(prices.Cpu().Cmp(resource.MustParse(c.expectedCPUPrice))).To(BeZero())
+			Expect(prices.Memory().Cmp(resource.MustParse("1"))).To(BeZero())
+			Expect(nextChange).To(Equal(c.expectedNextChange))
+		},
+
+		Entry("default prices", pricesTestcase{
+			now:                time.Date(2021, 6, 1, 12, 0, 0, 0, time.UTC),
+			expectedCPUPrice:   "2",
+			expectedNextChange: 10 * time.Hour,
+		}),
+
+		Entry("scheduled prices before midnight", pricesTestcase{
+			now:                time.Date(2021, 6, 1, 23, 30, 30, 0, time.UTC),
+			expectedCPUPrice:   "1",
+			expectedNextChange: 6*time.Hour + 29*time.Minute + 30*time.Second,
+		}),
+
+		Entry("scheduled prices after midnight", pricesTestcase{
+			now:                time.Date(2021, 6, 2, 5, 0, 0, 0, time.UTC),
+			expectedCPUPrice:   "1",
+			expectedNextChange: time.Hour,
+		}),
+	)
+
+	It("should not change the prices without schedules", func() {
+		prices, nextChange := currentPrices(&configv1alpha1.BroadcasterConfig{Prices: config.Prices}, time.Now())
+		Expect(prices).To(Equal(config.Prices))
+		Expect(nextChange).To(BeZero())
+	})
+})
@@ -0,0 +1,232 @@
+package resourcerequestoperator
+
+import (
+	"context"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/klog/v2"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	configv1alpha1 "github.com/liqotech/liqo/apis/config/v1alpha1"
+	discoveryv1alpha1 "github.com/liqotech/liqo/apis/discovery/v1alpha1"
+	sharingv1alpha1 "github.com/liqotech/liqo/apis/sharing/v1alpha1"
+	crdreplicator "github.com/liqotech/liqo/internal/crdReplicator"
+	liqoconst "github.com/liqotech/liqo/pkg/consts"
+)
+
+const (
+	// remoteNamespaceQuotaName is the name of the ResourceQuota created in each namespace of a foreign cluster,
+	// when the offered resources are split among them.
+	remoteNamespaceQuotaName = "liqo-remote-namespace-quota"
+	// remoteNamespaceQuotaLabel is the label identifying the ResourceQuotas created by this operator.
+	remoteNamespaceQuotaLabel = "liqo.io/remote-namespace-quota"
+)
+
+// limitedResources are the resources bounded by the LimitRange of the namespaces of the foreign clusters.
+var limitedResources = []corev1.ResourceName{corev1.ResourceCPU, corev1.ResourceMemory, corev1.ResourceEphemeralStorage}
+
+// getAcceptedResources returns the resources offered to the foreign cluster, if the ResourceOffer has been accepted.
+func (r *ResourceRequestReconciler) getAcceptedResources(ctx context.Context,
+	request *discoveryv1alpha1.ResourceRequest) (corev1.ResourceList, error) {
+	var offer sharingv1alpha1.ResourceOffer
+	err := r.Client.Get(ctx, types.NamespacedName{
+		Namespace: request.GetNamespace(),
+		Name:      offerPrefix + r.ClusterID,
+	}, &offer)
+	if apierrors.IsNotFound(err) {
+		return nil, nil
+	}
+	if err != nil {
+		klog.Error(err)
+		return nil, err
+	}
+
+	if offer.Status.Phase != sharingv1alpha1.ResourceOfferAccepted {
+		return nil, nil
+	}
+	return quotaResources(offer.Spec.ResourceQuota.Hard), nil
+}
+
+// quotaResources converts the offered resources to the names accepted by the ResourceQuotas, which allow
+// the extended resources and the hugepages to be bounded only in terms of requests.
+func quotaResources(offered corev1.ResourceList) corev1.ResourceList {
+	resources := corev1.ResourceList{}
+	for name, quantity := range offered {
+		if strings.Contains(string(name), "/") || strings.HasPrefix(string(name), corev1.ResourceHugePagesPrefix) {
+			name = corev1.ResourceName(corev1.DefaultResourceRequestsPrefix + string(name))
+		}
+		resources[name] = quantity.DeepCopy()
+	}
+	return resources
+}
+
+// forgeTenantQuotas returns the ResourceQuotas enforced by the Tenant on the whole set of namespaces of the
+// foreign cluster, which are present only in the Aggregate mode.
+func forgeTenantQuotas(config *configv1alpha1.RemoteNamespaceQuotaConfig,
+	accepted corev1.ResourceList) []corev1.ResourceQuotaSpec {
+	if config.Mode != configv1alpha1.AggregateRemoteNamespaceQuotaMode || len(accepted) == 0 {
+		return nil
+	}
+	return []corev1.ResourceQuotaSpec{{Hard: accepted}}
+}
+
+// forgeLimitRanges returns the LimitRanges enforced by the Tenant on the namespaces of the foreign cluster.
+// No container can exceed the accepted resources, and the default values are capped accordingly.
+func forgeLimitRanges(config *configv1alpha1.RemoteNamespaceQuotaConfig,
+	accepted corev1.ResourceList) []corev1.LimitRangeSpec {
+	if config.Mode == configv1alpha1.NoneRemoteNamespaceQuotaMode || config.Mode == "" || len(accepted) == 0 {
+		return nil
+	}
+
+	item := corev1.LimitRangeItem{
+		Type:           corev1.LimitTypeContainer,
+		Max:            corev1.ResourceList{},
+		Default:        corev1.ResourceList{},
+		DefaultRequest: corev1.ResourceList{},
+	}
+	for _, name := range limitedResources {
+		upperBound, found := accepted[name]
+		if !found {
+			continue
+		}
+		item.Max[name] = upperBound.DeepCopy()
+
+		// The default requests cannot exceed the default limits either.
+		if limit, found := config.DefaultLimits[name]; found {
+			upperBound = minQuantity(upperBound, limit)
+			item.Default[name] = upperBound.DeepCopy()
+		}
+		if request, found := config.DefaultRequests[name]; found {
+			item.DefaultRequest[name] = minQuantity(upperBound, request)
+		}
+	}
+
+	if len(item.Max) == 0 {
+		return nil
+	}
+	return []corev1.LimitRangeSpec{{Limits: []corev1.LimitRangeItem{item}}}
+}
+
+// splitResources evenly divides the given resources in the given number of parts. Only the CPU is divided in
+// fractions, since the other resources (e.g. pods) may be required to be integers.
+func splitResources(resources corev1.ResourceList, parts int) corev1.ResourceList {
+	split := corev1.ResourceList{}
+	for name, quantity := range resources {
+		switch name {
+		case corev1.ResourceCPU, corev1.ResourceRequestsCPU, corev1.ResourceLimitsCPU:
+			split[name] = *resource.NewMilliQuantity(quantity.MilliValue()/int64(parts), quantity.Format)
+		default:
+			split[name] = *resource.NewQuantity(quantity.Value()/int64(parts), quantity.Format)
+		}
+	}
+	return split
+}
+
+// minQuantity returns the smallest of the given quantities.
+func minQuantity(a, b resource.Quantity) resource.Quantity {
+	if a.Cmp(b) <= 0 {
+		return a.DeepCopy()
+	}
+	return b.DeepCopy()
+}
+
+// ensureSplitQuotas enforces the ResourceQuotas on the namespaces of the foreign cluster in the Split mode, dividing
+// the accepted resources among them. Otherwise, the ResourceQuotas previously created by this operator are removed.
+func (r *ResourceRequestReconciler) ensureSplitQuotas(ctx context.Context, remoteClusterID string,
+	config *configv1alpha1.RemoteNamespaceQuotaConfig, accepted corev1.ResourceList) error {
+	var namespaces corev1.NamespaceList
+	if err := r.Client.List(ctx, &namespaces); err != nil {
+		klog.Errorf("%s -> unable to list the namespaces of the foreign cluster %s: %s", r.ClusterID, remoteClusterID, err)
+		return err
+	}
+
+	var remoteNamespaces []string
+	for i := range namespaces.Items {
+		if namespaces.Items[i].Annotations[liqoconst.RemoteNamespaceAnnotationKey] == remoteClusterID &&
+			namespaces.Items[i].DeletionTimestamp.IsZero() {
+			remoteNamespaces = append(remoteNamespaces, namespaces.Items[i].Name)
+		}
+	}
+
+	if config.Mode != configv1alpha1.SplitRemoteNamespaceQuotaMode || len(accepted) == 0 {
+		for _, namespace := range remoteNamespaces {
+			quota := &corev1.ResourceQuota{ObjectMeta: metav1.ObjectMeta{Name: remoteNamespaceQuotaName, Namespace: namespace}}
+			if err := client.IgnoreNotFound(r.Client.Delete(ctx, quota)); err != nil {
+				klog.Errorf("%s -> unable to delete the ResourceQuota of the namespace %s: %s", r.ClusterID, namespace, err)
+				return err
+			}
+		}
+		return nil
+	}
+
+	if len(remoteNamespaces) == 0 {
+		return nil
+	}
+	hard := splitResources(accepted, len(remoteNamespaces))
+	for _, namespace := range remoteNamespaces {
+		quota := &corev1.ResourceQuota{ObjectMeta: metav1.ObjectMeta{Name: remoteNamespaceQuotaName, Namespace: namespace}}
+		op, err := controllerutil.CreateOrUpdate(ctx, r.Client, quota, func() error {
+			quota.Labels = map[string]string{remoteNamespaceQuotaLabel: remoteClusterID}
+			quota.Spec = corev1.ResourceQuotaSpec{Hard: hard.DeepCopy()}
+			return nil
+		})
+		if err != nil {
+			klog.Errorf("%s -> unable to enforce the ResourceQuota of the namespace %s: %s", r.ClusterID, namespace, err)
+			return err
+		}
+		klog.V(4).Infof("%s -> %s ResourceQuota: %s/%s", r.ClusterID, op, quota.Namespace, quota.Name)
+	}
+	return nil
+}
+
+// mapNamespaceToRequests returns the ResourceRequest of the foreign cluster owning the given namespace,
+// to divide again the accepted resources when its namespaces change.
+func (r *ResourceRequestReconciler) mapNamespaceToRequests(obj client.Object) []reconcile.Request {
+	remoteClusterID, found := obj.GetAnnotations()[liqoconst.RemoteNamespaceAnnotationKey]
+	if !found {
+		return nil
+	}
+
+	var resourceRequests discoveryv1alpha1.ResourceRequestList
+	if err := r.Client.List(context.TODO(), &resourceRequests, client.HasLabels{
+		crdreplicator.RemoteLabelSelector, crdreplicator.ReplicationStatuslabel,
+	}); err != nil {
+		klog.Error(err)
+		return nil
+	}
+
+	var requests []reconcile.Request
+	for i := range resourceRequests.Items {
+		if resourceRequests.Items[i].Spec.ClusterIdentity.ClusterID == remoteClusterID {
+			requests = append(requests, reconcile.Request{NamespacedName: types.NamespacedName{
+				Namespace: resourceRequests.Items[i].Namespace,
+				Name:      resourceRequests.Items[i].Name,
+			}})
+		}
+	}
+	return requests
+}
+
+// remoteNamespacePredicate selects the creation and the deletion of the namespaces of the foreign clusters,
+// which change the number of namespaces the accepted resources are split among.
+func remoteNamespacePredicate() predicate.Predicate {
+	isRemoteNamespace := func(obj client.Object) bool {
+		_, found := obj.GetAnnotations()[liqoconst.RemoteNamespaceAnnotationKey]
+		return found
+	}
+	return predicate.Funcs{
+		CreateFunc:  func(e event.CreateEvent) bool { return isRemoteNamespace(e.Object) },
+		UpdateFunc:  func(e event.UpdateEvent) bool { return false },
+		DeleteFunc:  func(e event.DeleteEvent) bool { return isRemoteNamespace(e.Object) },
+		GenericFunc: func(e event.GenericEvent) bool { return false },
+	}
+}
@@ -0,0 +1,85 @@
+package resourcerequestoperator
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/ginkgo/extensions/table"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+
+	configv1alpha1 "github.com/liqotech/liqo/apis/config/v1alpha1"
+)
+
+var _ = Describe("Quotas", func() {
+
+	accepted := corev1.ResourceList{
+		corev1.ResourceCPU:    resource.MustParse("2"),
+		corev1.ResourceMemory: resource.MustParse("4Gi"),
+		corev1.ResourcePods:   resource.MustParse("110"),
+	}
+
+	It("converts the offered resources to quota resources", func() {
+		resources := quotaResources(corev1.ResourceList{
+			corev1.ResourceCPU:                     resource.MustParse("2"),
+			"nvidia.com/gpu":                       resource.MustParse("1"),
+			corev1.ResourceHugePagesPrefix + "2Mi": resource.MustParse("1Gi"),
+		})
+		Expect(resources).To(HaveLen(3))
+		Expect(resources).To(HaveKey(corev1.ResourceCPU))
+		Expect(resources).To(HaveKey(corev1.ResourceName("requests.nvidia.com/gpu")))
+		Expect(resources).To(HaveKey(corev1.ResourceName("requests.hugepages-2Mi")))
+	})
+
+	DescribeTable("forge the Tenant quotas",
+		func(mode configv1alpha1.RemoteNamespaceQuotaMode, resources corev1.ResourceList, expected int) {
+			config := &configv1alpha1.RemoteNamespaceQuotaConfig{Mode: mode}
+			Expect(forgeTenantQuotas(config, resources)).To(HaveLen(expected))
+		},
+
+		Entry("no quota mode", configv1alpha1.NoneRemoteNamespaceQuotaMode, accepted, 0),
+		Entry("aggregate mode", configv1alpha1.AggregateRemoteNamespaceQuotaMode, accepted, 1),
+		Entry("aggregate mode without accepted offer", configv1alpha1.AggregateRemoteNamespaceQuotaMode, nil, 0),
+		Entry("split mode", configv1alpha1.SplitRemoteNamespaceQuotaMode, accepted, 0),
+	)
+
+	It("does not forge LimitRanges if no quota is enforced", func() {
+		Expect(forgeLimitRanges(&configv1alpha1.RemoteNamespaceQuotaConfig{}, accepted)).To(BeEmpty())
+		Expect(forgeLimitRanges(&configv1alpha1.RemoteNamespaceQuotaConfig{
+			Mode: configv1alpha1.SplitRemoteNamespaceQuotaMode}, nil)).To(BeEmpty())
+	})
+
+	It("forges LimitRanges bounded by the accepted resources", func() {
+		config := &configv1alpha1.RemoteNamespaceQuotaConfig{
+			Mode: configv1alpha1.AggregateRemoteNamespaceQuotaMode,
+			DefaultRequests: corev1.ResourceList{
+				corev1.ResourceCPU:    resource.MustParse("500m"),
+				corev1.ResourceMemory: resource.MustParse("8Gi"),
+			},
+			DefaultLimits: corev1.ResourceList{
+				corev1.ResourceMemory: resource.MustParse("1Gi"),
+			},
+		}
+
+		limitRanges := forgeLimitRanges(config, accepted)
+		Expect(limitRanges).To(HaveLen(1))
+		Expect(limitRanges[0].Limits).To(HaveLen(1))
+
+		item := limitRanges[0].Limits[0]
+		Expect(item.Type).To(Equal(corev1.LimitTypeContainer))
+		Expect(item.Max).To(HaveLen(2))
+		Expect(item.Max.Cpu().Cmp(resource.MustParse("2"))).To(BeZero())
+		Expect(item.Max.Memory().Cmp(resource.MustParse("4Gi"))).To(BeZero())
+		Expect(item.Default).To(HaveLen(1))
+		Expect(item.Default.Memory().Cmp(resource.MustParse("1Gi"))).To(BeZero())
+		Expect(item.DefaultRequest.Cpu().Cmp(resource.MustParse("500m"))).To(BeZero())
+		// The default memory request is capped to the default memory limit.
+		Expect(item.DefaultRequest.Memory().Cmp(resource.MustParse("1Gi"))).To(BeZero())
+	})
+
+	It("splits the accepted resources", func() {
+		split := splitResources(accepted, 3)
+		Expect(split.Cpu().Cmp(resource.MustParse("666m"))).To(BeZero())
+		Expect(split.Memory().Value()).To(BeNumerically("==", 4*1024*1024*1024/3))
+		Expect(split.Pods().Cmp(resource.MustParse("36"))).To(BeZero())
+	})
+})
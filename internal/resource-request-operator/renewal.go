@@ -0,0 +1,56 @@
+package resourcerequestoperator
+
+import (
+	"math/big"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+
+	sharingv1alpha1 "github.com/liqotech/liqo/apis/sharing/v1alpha1"
+)
+
+// renewalFraction is the fraction of the validity of a ResourceOffer after which it is renewed,
+// to make sure that the remote cluster receives the new one before the expiration of the current one.
+const renewalFraction = 2.0 / 3.0
+
+// requireOfferUpdate checks if the spec of an existing ResourceOffer has to be generated again, either because
+// it is going to expire, or because the resources to be offered changed more than the update threshold.
+func requireOfferUpdate(spec *sharingv1alpha1.ResourceOfferSpec,
+	resources corev1.ResourceList, thresholdPercentage int32, now time.Time) bool {
+	if spec.Timestamp.IsZero() || spec.TimeToLive.IsZero() {
+		return true
+	}
+	if !now.Before(offerRenewalTime(spec)) {
+		return true
+	}
+	return resourcesChanged(spec.ResourceQuota.Hard, resources, thresholdPercentage)
+}
+
+// offerRenewalTime returns the time instant when the given ResourceOffer has to be renewed.
+func offerRenewalTime(spec *sharingv1alpha1.ResourceOfferSpec) time.Time {
+	validity := spec.TimeToLive.Sub(spec.Timestamp.Time)
+	return spec.Timestamp.Add(time.Duration(float64(validity) * renewalFraction))
+}
+
+// resourcesChanged checks if the updated resources differ from the current ones more than the given percentage.
+func resourcesChanged(current, updated corev1.ResourceList, thresholdPercentage int32) bool {
+	if len(current) != len(updated) {
+		return true
+	}
+
+	for resourceName, updatedQuantity := range updated {
+		currentQuantity, ok := current[resourceName]
+		if !ok {
+			return true
+		}
+
+		// |updated - current| * 100 > threshold * current
+		diff := big.NewInt(updatedQuantity.MilliValue() - currentQuantity.MilliValue())
+		diff.Abs(diff).Mul(diff, big.NewInt(100))
+		limit := new(big.Int).Mul(big.NewInt(currentQuantity.MilliValue()), big.NewInt(int64(thresholdPercentage)))
+		if diff.Cmp(limit.Abs(limit)) > 0 {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,90 @@
+package resourcerequestoperator
+
+import (
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/ginkgo/extensions/table"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	sharingv1alpha1 "github.com/liqotech/liqo/apis/sharing/v1alpha1"
+)
+
+var _ = Describe("ResourceOffer renewal", func() {
+
+	type offerUpdateTestcase struct {
+		elapsed   time.Duration
+		resources corev1.ResourceList
+		expected  bool
+	}
+
+	offered := corev1.ResourceList{
+		corev1.ResourceCPU:    resource.MustParse("10"),
+		corev1.ResourceMemory: resource.MustParse("10Gi"),
+	}
+
+	DescribeTable("check if the ResourceOffer has to be updated",
+		func(c offerUpdateTestcase) {
+			now := time.Now()
+			creationTime := now.Add(-c.elapsed)
+			spec := &sharingv1alpha1.ResourceOfferSpec{
+				ResourceQuota: corev1.ResourceQuotaSpec{Hard: offered},
+				Timestamp:     metav1.NewTime(creationTime),
+				TimeToLive:    metav1.NewTime(creationTime.Add(30 * time.Minute)),
+			}
+			Expect(requireOfferUpdate(spec, c.resources, 5, now)).To(Equal(c.expected))
+		},
+
+		Entry("unchanged resources", offerUpdateTestcase{
+			elapsed:   time.Minute,
+			resources: offered,
+			expected:  false,
+		}),
+
+		Entry("resources changed within the threshold", offerUpdateTestcase{
+			elapsed: time.Minute,
+			resources: corev1.ResourceList{
+				corev1.ResourceCPU:    resource.MustParse("10.4"),
+				corev1.ResourceMemory: resource.MustParse("10Gi"),
+			},
+			expected: false,
+		}),
+
+		Entry("resources changed beyond the threshold", offerUpdateTestcase{
+			elapsed: time.Minute,
+			resources: corev1.ResourceList{
+				corev1.ResourceCPU:    resource.MustParse("9"),
+				corev1.ResourceMemory: resource.MustParse("10Gi"),
+			},
+			expected: true,
+		}),
+
+		Entry("new resource offered", offerUpdateTestcase{
+			elapsed: time.Minute,
+			resources: corev1.ResourceList{
+				corev1.ResourceCPU:    resource.MustParse("10"),
+				corev1.ResourceMemory: resource.MustParse("10Gi"),
+				corev1.ResourcePods:   resource.MustParse("110"),
+			},
+			expected: true,
+		}),
+
+		Entry("ResourceOffer to be renewed", offerUpdateTestcase{
+			elapsed:   25 * time.Minute,
+			resources: offered,
+			expected:  true,
+		}),
+	)
+
+	It("should renew the ResourceOffer before its expiration", func() {
+		creationTime := time.Now()
+		spec := &sharingv1alpha1.ResourceOfferSpec{
+			Timestamp:  metav1.NewTime(creationTime),
+			TimeToLive: metav1.NewTime(creationTime.Add(30 * time.Minute)),
+		}
+		Expect(offerRenewalTime(spec)).To(BeTemporally("~", creationTime.Add(20*time.Minute), time.Second))
+	})
+})
@@ -0,0 +1,83 @@
+package resourcerequestoperator
+
+import (
+	"fmt"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/kubernetes/pkg/util/slice"
+
+	discoveryv1alpha1 "github.com/liqotech/liqo/apis/discovery/v1alpha1"
+)
+
+// offerDecision contains the answer of the provider to a ResourceRequest.
+type offerDecision struct {
+	state     discoveryv1alpha1.OfferStateType
+	message   string
+	resources corev1.ResourceList
+}
+
+// evaluateRequirements compares the resources requested by a remote cluster with the available ones,
+// and returns the resources to be offered, or the reason why the request cannot be satisfied.
+func evaluateRequirements(requirements *discoveryv1alpha1.ResourceRequirements,
+	available corev1.ResourceList, clusterLabels map[string]string) *offerDecision {
+	if requirements == nil {
+		return &offerDecision{
+			state:     discoveryv1alpha1.OfferStateAccepted,
+			resources: available,
+		}
+	}
+
+	for key, value := range requirements.RequiredLabels {
+		if current, ok := clusterLabels[key]; !ok || current != value {
+			return &offerDecision{
+				state:   discoveryv1alpha1.OfferStateRefused,
+				message: fmt.Sprintf("the cluster does not expose the required label %v=%v", key, value),
+			}
+		}
+	}
+
+	if len(requirements.Regions) > 0 {
+		region := clusterLabels[corev1.LabelTopologyRegion]
+		if !slice.ContainsString(requirements.Regions, region, nil) {
+			return &offerDecision{
+				state:   discoveryv1alpha1.OfferStateRefused,
+				message: fmt.Sprintf("the cluster region %q is not in the requested ones %v", region, requirements.Regions),
+			}
+		}
+	}
+
+	offered := available.DeepCopy()
+	decision := &offerDecision{
+		state:     discoveryv1alpha1.OfferStateAccepted,
+		resources: offered,
+	}
+	for resourceName, requested := range requirements.Resources {
+		quantity, ok := available[resourceName]
+		if !ok || quantity.Sign() <= 0 {
+			return &offerDecision{
+				state:   discoveryv1alpha1.OfferStateRefused,
+				message: fmt.Sprintf("the resource %v is not available", resourceName),
+			}
+		}
+
+		if quantity.Cmp(requested) < 0 {
+			// we are not able to provide the whole amount, counter-offer with what we have.
+			decision.state = discoveryv1alpha1.OfferStateCounterOffered
+			decision.message = fmt.Sprintf("only %v of the %v %v requested are available",
+				quantity.String(), requested.String(), resourceName)
+			continue
+		}
+		offered[resourceName] = requested.DeepCopy()
+	}
+
+	return decision
+}
+
+// offerTimeToLive returns the validity of the ResourceOffer generated for the given requirements.
+func offerTimeToLive(requirements *discoveryv1alpha1.ResourceRequirements) time.Duration {
+	if requirements == nil || requirements.Duration == nil || requirements.Duration.Duration <= 0 {
+		return timeToLive
+	}
+	return requirements.Duration.Duration
+}
@@ -0,0 +1,118 @@
+package resourcerequestoperator
+
+import (
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/ginkgo/extensions/table"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	discoveryv1alpha1 "github.com/liqotech/liqo/apis/discovery/v1alpha1"
+)
+
+var _ = Describe("ResourceRequirements", func() {
+
+	type requirementsTestcase struct {
+		requirements      *discoveryv1alpha1.ResourceRequirements
+		clusterLabels     map[string]string
+		expectedState     discoveryv1alpha1.OfferStateType
+		expectedResources corev1.ResourceList
+	}
+
+	available := corev1.ResourceList{
+		corev1.ResourceCPU:    resource.MustParse("4"),
+		corev1.ResourceMemory: resource.MustParse("8Gi"),
+	}
+
+	DescribeTable("evaluate the requirements of a ResourceRequest",
+		func(c requirementsTestcase) {
+			decision := evaluateRequirements(c.requirements, available, c.clusterLabels)
+			Expect(decision.state).To(Equal(c.expectedState))
+			if c.expectedResources == nil {
+				Expect(decision.resources).To(BeNil())
+				return
+			}
+			Expect(decision.resources).To(HaveLen(len(c.expectedResources)))
+			for resourceName, quantity := range c.expectedResources {
+				offered := decision.resources[resourceName]
+				Expect(offered.Cmp(quantity)).To(BeZero())
+			}
+		},
+
+		Entry("no requirements", requirementsTestcase{
+			expectedState:     discoveryv1alpha1.OfferStateAccepted,
+			expectedResources: available,
+		}),
+
+		Entry("satisfiable requirements", requirementsTestcase{
+			requirements: &discoveryv1alpha1.ResourceRequirements{
+				Resources: corev1.ResourceList{
+					corev1.ResourceCPU: resource.MustParse("2"),
+				},
+			},
+			expectedState: discoveryv1alpha1.OfferStateAccepted,
+			expectedResources: corev1.ResourceList{
+				corev1.ResourceCPU:    resource.MustParse("2"),
+				corev1.ResourceMemory: resource.MustParse("8Gi"),
+			},
+		}),
+
+		Entry("partially satisfiable requirements", requirementsTestcase{
+			requirements: &discoveryv1alpha1.ResourceRequirements{
+				Resources: corev1.ResourceList{
+					corev1.ResourceCPU:    resource.MustParse("8"),
+					corev1.ResourceMemory: resource.MustParse("1Gi"),
+				},
+			},
+			expectedState: discoveryv1alpha1.OfferStateCounterOffered,
+			expectedResources: corev1.ResourceList{
+				corev1.ResourceCPU:    resource.MustParse("4"),
+				corev1.ResourceMemory: resource.MustParse("1Gi"),
+			},
+		}),
+
+		Entry("not available resource", requirementsTestcase{
+			requirements: &discoveryv1alpha1.ResourceRequirements{
+				Resources: corev1.ResourceList{
+					"nvidia.com/gpu": resource.MustParse("1"),
+				},
+			},
+			expectedState: discoveryv1alpha1.OfferStateRefused,
+		}),
+
+		Entry("missing required label", requirementsTestcase{
+			requirements: &discoveryv1alpha1.ResourceRequirements{
+				RequiredLabels: map[string]string{"provider": "aws"},
+			},
+			clusterLabels: map[string]string{"provider": "gcp"},
+			expectedState: discoveryv1alpha1.OfferStateRefused,
+		}),
+
+		Entry("matching region", requirementsTestcase{
+			requirements: &discoveryv1alpha1.ResourceRequirements{
+				Regions: []string{"eu-west-1", "eu-south-1"},
+			},
+			clusterLabels:     map[string]string{corev1.LabelTopologyRegion: "eu-south-1"},
+			expectedState:     discoveryv1alpha1.OfferStateAccepted,
+			expectedResources: available,
+		}),
+
+		Entry("not matching region", requirementsTestcase{
+			requirements: &discoveryv1alpha1.ResourceRequirements{
+				Regions: []string{"eu-west-1"},
+			},
+			clusterLabels: map[string]string{corev1.LabelTopologyRegion: "us-east-1"},
+			expectedState: discoveryv1alpha1.OfferStateRefused,
+		}),
+	)
+
+	It("should use the requested duration as offer time to live", func() {
+		Expect(offerTimeToLive(nil)).To(Equal(timeToLive))
+		Expect(offerTimeToLive(&discoveryv1alpha1.ResourceRequirements{
+			Duration: &metav1.Duration{Duration: 2 * time.Hour},
+		})).To(Equal(2 * time.Hour))
+	})
+})
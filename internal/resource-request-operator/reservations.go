@@ -0,0 +1,220 @@
+package resourcerequestoperator
+
+import (
+	"math/big"
+	"reflect"
+	"sort"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+
+	configv1alpha1 "github.com/liqotech/liqo/apis/config/v1alpha1"
+)
+
+const (
+	defaultPeerPriority = 0
+	defaultPeerWeight   = 1
+)
+
+// unlimitedDemand marks a resource requested without an explicit amount.
+const unlimitedDemand int64 = -1
+
+// reservation contains the resources promised to a remote cluster by its outstanding ResourceOffer.
+type reservation struct {
+	// requested contains the amount explicitly requested by the remote cluster,
+	// the resources not included are demanded without limits.
+	requested corev1.ResourceList
+}
+
+// peerShare contains the parameters used to compute the fair share of a remote cluster.
+type peerShare struct {
+	clusterID string
+	priority  int32
+	weight    int64
+	demand    int64
+}
+
+// SetReservation records the resources requested by a remote cluster. It returns true if the reservation changed,
+// meaning that the offers generated for the other clusters have to be recomputed.
+func (b *Broadcaster) SetReservation(clusterID string, requested corev1.ResourceList) bool {
+	b.reservationMutex.Lock()
+	defer b.reservationMutex.Unlock()
+
+	if b.reservations == nil {
+		b.reservations = map[string]*reservation{}
+	}
+	if current, exists := b.reservations[clusterID]; exists && reflect.DeepEqual(current.requested, requested) {
+		return false
+	}
+	b.reservations[clusterID] = &reservation{requested: requested.DeepCopy()}
+	return true
+}
+
+// RemoveReservation releases the resources reserved to a remote cluster. It returns true if a reservation existed,
+// meaning that the offers generated for the other clusters have to be recomputed.
+func (b *Broadcaster) RemoveReservation(clusterID string) bool {
+	b.reservationMutex.Lock()
+	defer b.reservationMutex.Unlock()
+
+	if _, exists := b.reservations[clusterID]; !exists {
+		return false
+	}
+	delete(b.reservations, clusterID)
+	return true
+}
+
+// readReservations returns a copy of the current reservations, making sure that the given cluster is included.
+func (b *Broadcaster) readReservations(clusterID string) map[string]*reservation {
+	b.reservationMutex.RLock()
+	defer b.reservationMutex.RUnlock()
+
+	reservations := make(map[string]*reservation, len(b.reservations)+1)
+	for id, r := range b.reservations {
+		reservations[id] = &reservation{requested: r.requested.DeepCopy()}
+	}
+	if _, exists := reservations[clusterID]; !exists {
+		reservations[clusterID] = &reservation{}
+	}
+	return reservations
+}
+
+// readSharedBudget returns the total amount of resources shared with all the remote clusters, that are the free
+// resources of the cluster plus the ones already used by offloaded pods, scaled by the sharing percentage.
+func (b *Broadcaster) readSharedBudget() corev1.ResourceList {
+	budget := b.readClusterResources()
+
+	b.podMutex.RLock()
+	for _, podsResources := range b.resourcePodMap {
+		addResources(budget, podsResources)
+	}
+	b.podMutex.RUnlock()
+
+	for resourceName, quantity := range budget {
+		scaled := quantity
+		b.scaleResources(resourceName, &scaled)
+		budget[resourceName] = scaled
+	}
+	return budget
+}
+
+// ReadResources returns the fair share of the shared resources which can be offered to the given cluster,
+// given the reservations of all the remote clusters.
+func (b *Broadcaster) ReadResources(clusterID string) corev1.ResourceList {
+	budget := b.readSharedBudget()
+	reservations := b.readReservations(clusterID)
+	peersConfig := b.getConfig().Spec.AdvertisementConfig.OutgoingConfig.PeersSharingConfig
+
+	offered := corev1.ResourceList{}
+	for resourceName, quantity := range budget {
+		peers := make([]peerShare, 0, len(reservations))
+		for id, r := range reservations {
+			peer := newPeerShare(id, peersConfig)
+			peer.demand = unlimitedDemand
+			if requested, ok := r.requested[resourceName]; ok {
+				peer.demand = requested.MilliValue()
+			}
+			peers = append(peers, peer)
+		}
+
+		shares := computeFairShares(quantity.MilliValue(), peers)
+		offered[resourceName] = *resource.NewMilliQuantity(shares[clusterID], quantity.Format)
+	}
+	return offered
+}
+
+// newPeerShare returns the share parameters of a remote cluster, as defined in the ClusterConfig.
+func newPeerShare(clusterID string, peersConfig []configv1alpha1.PeerSharingConfig) peerShare {
+	peer := peerShare{
+		clusterID: clusterID,
+		priority:  defaultPeerPriority,
+		weight:    defaultPeerWeight,
+	}
+	for i := range peersConfig {
+		if peersConfig[i].ClusterID != clusterID {
+			continue
+		}
+		peer.priority = peersConfig[i].Priority
+		if peersConfig[i].Weight > 0 {
+			peer.weight = int64(peersConfig[i].Weight)
+		}
+	}
+	return peer
+}
+
+// computeFairShares divides the budget among the peers. Peers with higher priority are served first, while the
+// ones with the same priority receive a weighted max-min fair share: no peer receives more than its demand, and
+// the amount not used by a peer is divided among the others proportionally to their weights.
+func computeFairShares(budget int64, peers []peerShare) map[string]int64 {
+	shares := make(map[string]int64, len(peers))
+	if budget < 0 {
+		budget = 0
+	}
+
+	sort.SliceStable(peers, func(i, j int) bool {
+		if peers[i].priority != peers[j].priority {
+			return peers[i].priority > peers[j].priority
+		}
+		return peers[i].clusterID < peers[j].clusterID
+	})
+
+	for start := 0; start < len(peers); {
+		end := start
+		for end < len(peers) && peers[end].priority == peers[start].priority {
+			end++
+		}
+		budget -= waterFill(budget, peers[start:end], shares)
+		start = end
+	}
+	return shares
+}
+
+// waterFill assigns the budget to peers with the same priority, and returns the amount assigned.
+func waterFill(budget int64, peers []peerShare, shares map[string]int64) int64 {
+	active := append([]peerShare{}, peers...)
+	remaining := budget
+
+	for len(active) > 0 {
+		var totalWeight int64
+		for i := range active {
+			totalWeight += active[i].weight
+		}
+
+		// assign their whole demand to the peers requesting less than their fair share,
+		// and repeat with the remaining budget.
+		var unsatisfied []peerShare
+		for i := range active {
+			share := proportion(remaining, active[i].weight, totalWeight)
+			if active[i].demand != unlimitedDemand && active[i].demand <= share {
+				shares[active[i].clusterID] = active[i].demand
+				continue
+			}
+			unsatisfied = append(unsatisfied, active[i])
+		}
+
+		if len(unsatisfied) == len(active) {
+			for i := range active {
+				shares[active[i].clusterID] = proportion(remaining, active[i].weight, totalWeight)
+			}
+			break
+		}
+
+		for i := range active {
+			if _, satisfied := shares[active[i].clusterID]; satisfied {
+				remaining -= shares[active[i].clusterID]
+			}
+		}
+		active = unsatisfied
+	}
+
+	var assigned int64
+	for i := range peers {
+		assigned += shares[peers[i].clusterID]
+	}
+	return assigned
+}
+
+// proportion returns value*weight/totalWeight, avoiding overflows of the intermediate result.
+func proportion(value, weight, totalWeight int64) int64 {
+	result := new(big.Int).Mul(big.NewInt(value), big.NewInt(weight))
+	return result.Quo(result, big.NewInt(totalWeight)).Int64()
+}
@@ -0,0 +1,98 @@
+package resourcerequestoperator
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/ginkgo/extensions/table"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+
+	configv1alpha1 "github.com/liqotech/liqo/apis/config/v1alpha1"
+)
+
+var _ = Describe("Reservations", func() {
+
+	type fairSharesTestcase struct {
+		budget         int64
+		peers          []peerShare
+		expectedShares map[string]int64
+	}
+
+	DescribeTable("divide the budget among the peers",
+		func(c fairSharesTestcase) {
+			Expect(computeFairShares(c.budget, c.peers)).To(Equal(c.expectedShares))
+		},
+
+		Entry("single peer", fairSharesTestcase{
+			budget: 1000,
+			peers: []peerShare{
+				{clusterID: "a", weight: 1, demand: unlimitedDemand},
+			},
+			expectedShares: map[string]int64{"a": 1000},
+		}),
+
+		Entry("peers with the same weight", fairSharesTestcase{
+			budget: 1000,
+			peers: []peerShare{
+				{clusterID: "a", weight: 1, demand: unlimitedDemand},
+				{clusterID: "b", weight: 1, demand: unlimitedDemand},
+			},
+			expectedShares: map[string]int64{"a": 500, "b": 500},
+		}),
+
+		Entry("peers with different weights", fairSharesTestcase{
+			budget: 1000,
+			peers: []peerShare{
+				{clusterID: "a", weight: 3, demand: unlimitedDemand},
+				{clusterID: "b", weight: 1, demand: unlimitedDemand},
+			},
+			expectedShares: map[string]int64{"a": 750, "b": 250},
+		}),
+
+		Entry("peer demanding less than its share", fairSharesTestcase{
+			budget: 1000,
+			peers: []peerShare{
+				{clusterID: "a", weight: 1, demand: 200},
+				{clusterID: "b", weight: 1, demand: unlimitedDemand},
+				{clusterID: "c", weight: 1, demand: unlimitedDemand},
+			},
+			expectedShares: map[string]int64{"a": 200, "b": 400, "c": 400},
+		}),
+
+		Entry("peer with higher priority", fairSharesTestcase{
+			budget: 1000,
+			peers: []peerShare{
+				{clusterID: "a", weight: 1, demand: unlimitedDemand},
+				{clusterID: "b", priority: 10, weight: 1, demand: 600},
+			},
+			expectedShares: map[string]int64{"a": 400, "b": 600},
+		}),
+
+		Entry("budget exhausted by higher priorities", fairSharesTestcase{
+			budget: 1000,
+			peers: []peerShare{
+				{clusterID: "a", weight: 1, demand: unlimitedDemand},
+				{clusterID: "b", priority: 10, weight: 1, demand: unlimitedDemand},
+			},
+			expectedShares: map[string]int64{"a": 0, "b": 1000},
+		}),
+	)
+
+	It("should read the share parameters from the configuration", func() {
+		peersConfig := []configv1alpha1.PeerSharingConfig{
+			{ClusterID: "a", Priority: 5, Weight: 2},
+		}
+		Expect(newPeerShare("a", peersConfig)).To(Equal(peerShare{clusterID: "a", priority: 5, weight: 2}))
+		Expect(newPeerShare("b", peersConfig)).To(Equal(peerShare{
+			clusterID: "b", priority: defaultPeerPriority, weight: defaultPeerWeight}))
+	})
+
+	It("should report the changes of the reservations", func() {
+		b := &Broadcaster{}
+		requested := corev1.ResourceList{corev1.ResourceCPU: resource.MustParse("1")}
+		Expect(b.SetReservation("a", requested)).To(BeTrue())
+		Expect(b.SetReservation("a", requested)).To(BeFalse())
+		Expect(b.RemoveReservation("a")).To(BeTrue())
+		Expect(b.RemoveReservation("a")).To(BeFalse())
+	})
+})
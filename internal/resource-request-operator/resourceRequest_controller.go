@@ -4,12 +4,16 @@ import (
 	"context"
 	"time"
 
+	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/klog/v2"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/builder"
 	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
 	"sigs.k8s.io/controller-runtime/pkg/predicate"
+	"sigs.k8s.io/controller-runtime/pkg/source"
 
 	discoveryv1alpha1 "github.com/liqotech/liqo/apis/discovery/v1alpha1"
 	sharingv1alpha1 "github.com/liqotech/liqo/apis/sharing/v1alpha1"
@@ -22,6 +26,10 @@ type ResourceRequestReconciler struct {
 	Scheme      *runtime.Scheme
 	ClusterID   string
 	Broadcaster *Broadcaster
+
+	// reservationEvents is used to enqueue the ResourceRequests whose offer has to be recomputed
+	// after a change in the reservations of another cluster.
+	reservationEvents chan event.GenericEvent
 }
 
 const (
@@ -35,6 +43,8 @@ const (
 // +kubebuilder:rbac:groups=sharing.liqo.io,resources=resourceOffers/status,verbs=get;update;patch
 
 // +kubebuilder:rbac:groups=capsule.clastix.io,resources=tenants,verbs=get;list;watch;create;update;patch;delete;
+// +kubebuilder:rbac:groups=core,resources=namespaces,verbs=get;list;watch
+// +kubebuilder:rbac:groups=core,resources=resourcequotas,verbs=get;list;watch;create;update;patch;delete
 
 // Reconcile is the main function of the controller which reconciles ResourceRequest resources.
 func (r *ResourceRequestReconciler) Reconcile(ctx context.Context, req ctrl.Request) (result ctrl.Result, err error) {
@@ -57,6 +67,7 @@ func (r *ResourceRequestReconciler) Reconcile(ctx context.Context, req ctrl.Requ
 	}
 
 	if requireTenantDeletion(&resourceRequest) {
+		r.releaseReservation(ctx, remoteClusterID)
 		if err = r.ensureTenantDeletion(ctx, &resourceRequest); err != nil {
 			klog.Errorf("%s -> Error deleting Tenant: %s", remoteClusterID, err)
 			return ctrl.Result{}, err
@@ -89,12 +100,18 @@ func (r *ResourceRequestReconciler) Reconcile(ctx context.Context, req ctrl.Requ
 	}()
 
 	if resourceRequest.Spec.WithdrawalTimestamp.IsZero() {
-		err = r.generateResourceOffer(ctx, &resourceRequest)
+		var renewal time.Duration
+		renewal, err = r.generateResourceOffer(ctx, &resourceRequest)
 		if err != nil {
 			klog.Errorf("%s -> Error generating resourceOffer: %s", remoteClusterID, err)
 			return ctrl.Result{}, err
 		}
+		if renewal > 0 {
+			// renew the ResourceOffer before its expiration.
+			return ctrl.Result{RequeueAfter: renewal}, nil
+		}
 	} else {
+		r.releaseReservation(ctx, remoteClusterID)
 		err = r.invalidateResourceOffer(ctx, &resourceRequest)
 		if err != nil {
 			klog.Errorf("%s -> Error invalidating resourceOffer: %s", remoteClusterID, err)
@@ -114,8 +131,13 @@ func (r *ResourceRequestReconciler) SetupWithManager(mgr ctrl.Manager) error {
 		return err
 	}
 
+	r.reservationEvents = make(chan event.GenericEvent)
+	r.Broadcaster.setNotifier(r.enqueueAllRequests)
 	return ctrl.NewControllerManagedBy(mgr).
 		For(&discoveryv1alpha1.ResourceRequest{}, builder.WithPredicates(p)).
 		Owns(&sharingv1alpha1.ResourceOffer{}).
+		Watches(&source.Channel{Source: r.reservationEvents}, &handler.EnqueueRequestForObject{}).
+		Watches(&source.Kind{Type: &corev1.Namespace{}}, handler.EnqueueRequestsFromMapFunc(r.mapNamespaceToRequests),
+			builder.WithPredicates(remoteNamespacePredicate())).
 		Complete(r)
 }
@@ -11,7 +11,6 @@ import (
 	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
 
 	discoveryv1alpha1 "github.com/liqotech/liqo/apis/discovery/v1alpha1"
-	liqoconst "github.com/liqotech/liqo/pkg/consts"
 )
 
 const tenantFinalizer = "liqo.io/tenant"
@@ -23,6 +22,14 @@ func requireTenantDeletion(resourceRequest *discoveryv1alpha1.ResourceRequest) b
 func (r *ResourceRequestReconciler) ensureTenant(ctx context.Context,
 	resourceRequest *discoveryv1alpha1.ResourceRequest) (requireUpdate bool, err error) {
 	remoteClusterID := resourceRequest.Spec.ClusterIdentity.ClusterID
+	accepted, err := r.getAcceptedResources(ctx, resourceRequest)
+	if err != nil {
+		return false, err
+	}
+	outgoingConfig := &r.Broadcaster.getConfig().Spec.AdvertisementConfig.OutgoingConfig
+	quotaConfig := &outgoingConfig.RemoteNamespaceQuota
+	securityConfig := &outgoingConfig.RemoteNamespaceSecurity
+
 	tenant := &capsulev1alpha1.Tenant{
 		ObjectMeta: metav1.ObjectMeta{
 			Name: fmt.Sprintf("tenant-%v", remoteClusterID),
@@ -30,11 +37,8 @@ func (r *ResourceRequestReconciler) ensureTenant(ctx context.Context,
 	}
 	_, err = controllerutil.CreateOrUpdate(ctx, r.Client, tenant, func() error {
 		tenant.Spec = capsulev1alpha1.TenantSpec{
-			NamespacesMetadata: capsulev1alpha1.AdditionalMetadata{
-				AdditionalAnnotations: map[string]string{
-					liqoconst.RemoteNamespaceAnnotationKey: resourceRequest.Spec.ClusterIdentity.ClusterID,
-				},
-			},
+			// The Tenant controller reverts any change performed on the namespace metadata and on the owned resources.
+			NamespacesMetadata: forgeNamespacesMetadata(remoteClusterID, securityConfig),
 			Owner: capsulev1alpha1.OwnerSpec{
 				Name: remoteClusterID,
 				Kind: rbacv1.UserKind,
@@ -50,6 +54,11 @@ func (r *ResourceRequestReconciler) ensureTenant(ctx context.Context,
 					},
 				},
 			},
+			// The resources offered to the remote cluster are enforced on its namespaces.
+			ResourceQuota: forgeTenantQuotas(quotaConfig, accepted),
+			LimitRanges:   forgeLimitRanges(quotaConfig, accepted),
+			// The security policies are enforced on its namespaces.
+			NetworkPolicies: forgeNetworkPolicies(securityConfig),
 		}
 		return nil
 	})
@@ -58,6 +67,10 @@ func (r *ResourceRequestReconciler) ensureTenant(ctx context.Context,
 		return false, err
 	}
 
+	if err = r.ensureSplitQuotas(ctx, remoteClusterID, quotaConfig, accepted); err != nil {
+		return false, err
+	}
+
 	if !controllerutil.ContainsFinalizer(resourceRequest, tenantFinalizer) {
 		klog.Infof("%s -> adding %s finalizer", remoteClusterID, tenantFinalizer)
 		controllerutil.AddFinalizer(resourceRequest, tenantFinalizer)
@@ -0,0 +1,46 @@
+package resourcerequestoperator
+
+import (
+	capsulev1alpha1 "github.com/clastix/capsule/api/v1alpha1"
+	networkingv1 "k8s.io/api/networking/v1"
+
+	configv1alpha1 "github.com/liqotech/liqo/apis/config/v1alpha1"
+	liqoconst "github.com/liqotech/liqo/pkg/consts"
+)
+
+// podSecurityEnforceLabel is the namespace label defining the level enforced by the Pod Security admission.
+const podSecurityEnforceLabel = "pod-security.kubernetes.io/enforce"
+
+// forgeNamespaceLabels returns the labels set by the Tenant on the namespaces of the foreign cluster. The remote
+// namespace label is always set, so that the namespaces not labeled at creation are validated by the webhook as well.
+func forgeNamespaceLabels(remoteClusterID string, config *configv1alpha1.RemoteNamespaceSecurityConfig) map[string]string {
+	labels := map[string]string{liqoconst.RemoteNamespaceLabelKey: remoteClusterID}
+	if config.PodSecurityLevel != "" {
+		labels[podSecurityEnforceLabel] = string(config.PodSecurityLevel)
+	}
+	return labels
+}
+
+// forgeNetworkPolicies returns the NetworkPolicies created by the Tenant in the namespaces of the foreign cluster.
+func forgeNetworkPolicies(config *configv1alpha1.RemoteNamespaceSecurityConfig) []networkingv1.NetworkPolicySpec {
+	if len(config.NetworkPolicies) == 0 {
+		return nil
+	}
+
+	policies := make([]networkingv1.NetworkPolicySpec, len(config.NetworkPolicies))
+	for i := range config.NetworkPolicies {
+		config.NetworkPolicies[i].DeepCopyInto(&policies[i])
+	}
+	return policies
+}
+
+// forgeNamespacesMetadata returns the metadata set by the Tenant on the namespaces of the foreign cluster.
+func forgeNamespacesMetadata(remoteClusterID string,
+	config *configv1alpha1.RemoteNamespaceSecurityConfig) capsulev1alpha1.AdditionalMetadata {
+	return capsulev1alpha1.AdditionalMetadata{
+		AdditionalLabels: forgeNamespaceLabels(remoteClusterID, config),
+		AdditionalAnnotations: map[string]string{
+			liqoconst.RemoteNamespaceAnnotationKey: remoteClusterID,
+		},
+	}
+}
@@ -0,0 +1,40 @@
+package resourcerequestoperator
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	networkingv1 "k8s.io/api/networking/v1"
+
+	configv1alpha1 "github.com/liqotech/liqo/apis/config/v1alpha1"
+	liqoconst "github.com/liqotech/liqo/pkg/consts"
+)
+
+var _ = Describe("Security", func() {
+
+	const remoteClusterID = "remote-cluster-id"
+
+	It("does not enforce any policy by default", func() {
+		config := &configv1alpha1.RemoteNamespaceSecurityConfig{}
+		metadata := forgeNamespacesMetadata(remoteClusterID, config)
+		Expect(metadata.AdditionalLabels).To(Equal(map[string]string{liqoconst.RemoteNamespaceLabelKey: remoteClusterID}))
+		Expect(metadata.AdditionalAnnotations).To(HaveKeyWithValue(liqoconst.RemoteNamespaceAnnotationKey, remoteClusterID))
+		Expect(forgeNetworkPolicies(config)).To(BeEmpty())
+	})
+
+	It("enforces the Pod Security level and the NetworkPolicies", func() {
+		config := &configv1alpha1.RemoteNamespaceSecurityConfig{
+			PodSecurityLevel: configv1alpha1.RestrictedPodSecurityLevel,
+			NetworkPolicies: []networkingv1.NetworkPolicySpec{{
+				PolicyTypes: []networkingv1.PolicyType{networkingv1.PolicyTypeIngress},
+			}},
+		}
+		metadata := forgeNamespacesMetadata(remoteClusterID, config)
+		Expect(metadata.AdditionalLabels).To(HaveKeyWithValue(podSecurityEnforceLabel, "restricted"))
+
+		policies := forgeNetworkPolicies(config)
+		Expect(policies).To(Equal(config.NetworkPolicies))
+		// The templates must not be modified by the Tenant.
+		policies[0].PolicyTypes[0] = networkingv1.PolicyTypeEgress
+		Expect(config.NetworkPolicies[0].PolicyTypes[0]).To(Equal(networkingv1.PolicyTypeIngress))
+	})
+})
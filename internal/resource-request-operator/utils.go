@@ -12,6 +12,7 @@ import (
 	"k8s.io/klog/v2"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/event"
 
 	discoveryv1alpha1 "github.com/liqotech/liqo/apis/discovery/v1alpha1"
 	sharingv1alpha1 "github.com/liqotech/liqo/apis/sharing/v1alpha1"
@@ -19,9 +20,36 @@ import (
 	"github.com/liqotech/liqo/pkg/discovery"
 )
 
-// generateResourceOffer generates a new local ResourceOffer.
-func (r *ResourceRequestReconciler) generateResourceOffer(ctx context.Context, request *discoveryv1alpha1.ResourceRequest) error {
-	resources := r.Broadcaster.ReadResources(request.Spec.ClusterIdentity.ClusterID)
+// generateResourceOffer generates a new local ResourceOffer, if the requested resources can be provided.
+// It returns the time after which the ResourceOffer has to be renewed.
+func (r *ResourceRequestReconciler) generateResourceOffer(ctx context.Context,
+	request *discoveryv1alpha1.ResourceRequest) (time.Duration, error) {
+	remoteClusterID := request.Spec.ClusterIdentity.ClusterID
+	var requested corev1.ResourceList
+	if request.Spec.ResourceRequirements != nil {
+		requested = request.Spec.ResourceRequirements.Resources
+	}
+	if r.Broadcaster.SetReservation(remoteClusterID, requested) {
+		// the fair share of the other clusters may have changed.
+		r.enqueueOtherRequests(ctx, remoteClusterID)
+	}
+
+	clusterLabels := r.Broadcaster.getConfig().Spec.DiscoveryConfig.ClusterLabels
+	decision := evaluateRequirements(request.Spec.ResourceRequirements,
+		r.Broadcaster.ReadResources(remoteClusterID), clusterLabels)
+	request.Status.OfferState = decision.state
+	request.Status.OfferMessage = decision.message
+
+	if decision.state == discoveryv1alpha1.OfferStateRefused {
+		klog.Infof("%s -> ResourceRequest %s/%s refused: %s", r.ClusterID, request.Namespace, request.Name, decision.message)
+		r.releaseReservation(ctx, remoteClusterID)
+		return 0, r.invalidateResourceOffer(ctx, request)
+	}
+
+	now := time.Now()
+	outgoingConfig := &r.Broadcaster.getConfig().Spec.AdvertisementConfig.OutgoingConfig
+	prices, pricesChange := currentPrices(outgoingConfig, now)
+
 	offer := &sharingv1alpha1.ResourceOffer{
 		ObjectMeta: metav1.ObjectMeta{
 			Namespace: request.GetNamespace(),
@@ -35,27 +63,35 @@ func (r *ResourceRequestReconciler) generateResourceOffer(ctx context.Context, r
 			crdreplicator.LocalLabelSelector: "true",
 			crdreplicator.DestinationLabel:   request.Spec.ClusterIdentity.ClusterID,
 		}
-		creationTime := metav1.NewTime(time.Now())
-		spec := sharingv1alpha1.ResourceOfferSpec{
-			ClusterId: r.ClusterID,
-			Images:    []corev1.ContainerImage{},
-			ResourceQuota: corev1.ResourceQuotaSpec{
-				Hard: resources,
-			},
-			Labels:     r.Broadcaster.clusterConfig.Spec.DiscoveryConfig.ClusterLabels,
-			Timestamp:  creationTime,
-			TimeToLive: metav1.NewTime(creationTime.Add(timeToLive)),
+		threshold := outgoingConfig.OfferUpdateThresholdPercentage
+		if requireOfferUpdate(&offer.Spec, decision.resources, threshold, now) {
+			creationTime := metav1.NewTime(now)
+			offer.Spec.ResourceQuota = corev1.ResourceQuotaSpec{
+				Hard: decision.resources,
+			}
+			offer.Spec.Timestamp = creationTime
+			offer.Spec.TimeToLive = metav1.NewTime(creationTime.Add(offerTimeToLive(request.Spec.ResourceRequirements)))
 		}
-		offer.Spec = spec
+		offer.Spec.ClusterId = r.ClusterID
+		offer.Spec.Images = []corev1.ContainerImage{}
+		offer.Spec.Labels = clusterLabels
+		offer.Spec.Prices = prices
+		offer.Spec.WithdrawalTimestamp = nil
 		return controllerutil.SetControllerReference(request, offer, r.Scheme)
 	})
 
 	if err != nil {
 		klog.Error(err)
-		return err
+		return 0, err
 	}
 	klog.Infof("%s -> %s Offer: %s/%s", r.ClusterID, op, offer.Namespace, offer.Name)
-	return nil
+
+	renewal := time.Until(offerRenewalTime(&offer.Spec))
+	if pricesChange > 0 && pricesChange < renewal {
+		// update the ResourceOffer as soon as the prices change.
+		renewal = pricesChange
+	}
+	return renewal, nil
 }
 
 // ensureForeignCluster ensures the ForeignCluster existence, if not exists we have to add a new one
@@ -175,3 +211,57 @@ func (r *ResourceRequestReconciler) invalidateResourceOffer(ctx context.Context,
 		return err
 	}
 }
+
+// releaseReservation removes the resources reserved to a remote cluster, and triggers the computation
+// of the offers for the other clusters, which can now receive a greater share.
+func (r *ResourceRequestReconciler) releaseReservation(ctx context.Context, remoteClusterID string) {
+	if r.Broadcaster.RemoveReservation(remoteClusterID) {
+		klog.V(4).Infof("%s -> released the resources reserved to %s", r.ClusterID, remoteClusterID)
+		r.enqueueOtherRequests(ctx, remoteClusterID)
+	}
+}
+
+// enqueueOtherRequests enqueues for reconciliation the ResourceRequests of all the clusters except the given one.
+func (r *ResourceRequestReconciler) enqueueOtherRequests(ctx context.Context, remoteClusterID string) {
+	r.enqueueRequests(ctx, func(request *discoveryv1alpha1.ResourceRequest) bool {
+		return request.Spec.ClusterIdentity.ClusterID != remoteClusterID
+	})
+}
+
+// enqueueAllRequests enqueues for reconciliation the ResourceRequests of all the clusters,
+// to update their offers after a change of the shared resources.
+func (r *ResourceRequestReconciler) enqueueAllRequests() {
+	r.enqueueRequests(context.TODO(), func(*discoveryv1alpha1.ResourceRequest) bool {
+		return true
+	})
+}
+
+// enqueueRequests enqueues for reconciliation the ResourceRequests selected by the filter function.
+func (r *ResourceRequestReconciler) enqueueRequests(ctx context.Context,
+	filter func(request *discoveryv1alpha1.ResourceRequest) bool) {
+	if r.reservationEvents == nil {
+		return
+	}
+
+	var resourceRequests discoveryv1alpha1.ResourceRequestList
+	if err := r.Client.List(ctx, &resourceRequests, client.HasLabels{
+		crdreplicator.RemoteLabelSelector, crdreplicator.ReplicationStatuslabel,
+	}); err != nil {
+		klog.Error(err)
+		return
+	}
+
+	events := make([]event.GenericEvent, 0, len(resourceRequests.Items))
+	for i := range resourceRequests.Items {
+		if filter(&resourceRequests.Items[i]) {
+			events = append(events, event.GenericEvent{Object: &resourceRequests.Items[i]})
+		}
+	}
+
+	// do not block the current reconciliation while the events are consumed.
+	go func() {
+		for i := range events {
+			r.reservationEvents <- events[i]
+		}
+	}()
+}
@@ -8,6 +8,7 @@ import (
 	"k8s.io/client-go/rest"
 	"k8s.io/klog/v2"
 
+	configv1alpha1 "github.com/liqotech/liqo/apis/config/v1alpha1"
 	"github.com/liqotech/liqo/pkg/kubeconfig"
 	"github.com/liqotech/liqo/pkg/utils"
 )
@@ -18,6 +19,8 @@ type CertificateIdentityResponse struct {
 	Certificate  string `json:"certificate"`
 	APIServerURL string `json:"apiServerUrl"`
 	APIServerCA  string `json:"apiServerCA,omitempty"`
+	// NamespacePolicy constrains the names of the namespaces that the remote cluster may create.
+	NamespacePolicy *configv1alpha1.RemoteNamespacePolicy `json:"namespacePolicy,omitempty"`
 }
 
 // NewCertificateIdentityResponse makes a new CertificateIdentityResponse.
@@ -11,4 +11,7 @@ const (
 	ClusterIDLabelName = "clusterID"
 	// ClusterIDConfigMapKey is the key of the configmap where the cluster-id is stored.
 	ClusterIDConfigMapKey = "cluster-id"
+	// ClusterIDNodeLabel is the label identifying the cluster a node belongs to: the virtual nodes have the ID of the
+	// remote cluster they represent, while the local nodes have the ID of the local cluster.
+	ClusterIDNodeLabel = "liqo.io/cluster-id"
 )
@@ -37,8 +37,15 @@ const (
 	LiqoRouteOperatorName = "liqo-route"
 	// LiqoGatewayOperatorName name of the operator.
 	LiqoGatewayOperatorName = "liqo-gateway"
+	// LiqoWireguardUserspaceName name of the process running the userspace WireGuard implementation,
+	// spawned by liqo-gateway when the WireGuard kernel module is not available.
+	LiqoWireguardUserspaceName = "liqo-wireguard-userspace"
+	// LiqoIPAMToolName name of the one-shot command used to check, export, import and rebuild the IPAM state.
+	LiqoIPAMToolName = "liqo-ipam"
 	// GatewayLeaderElectionID used as name for the lease.coordination.k8s.io resource.
 	GatewayLeaderElectionID = "1d5hml1.gateway.net.liqo.io"
+	// NetworkManagerLeaderElectionID used as name for the lease.coordination.k8s.io resource held by the network manager.
+	NetworkManagerLeaderElectionID = "1d5hml1.network-manager.net.liqo.io"
 	// GatewayNetnsName name of the custom network namespace used by liqo-gateway.
 	GatewayNetnsName = "liqo-netns"
 	// HostVethName name of the veth device living in the host network namespace,
@@ -32,4 +32,10 @@ const (
 	// RemoteNamespaceAnnotationKey is the annotation that all remote namespaces created by the NamespaceMap controller
 	// must have.
 	RemoteNamespaceAnnotationKey = "liqo.io/remote-namespace"
+	// RemoteNamespaceOriginAnnotationKey is the annotation that records the local namespace associated with a remote
+	// namespace, to detect collisions among local namespaces mapped to the same remote name.
+	RemoteNamespaceOriginAnnotationKey = "liqo.io/remote-namespace-origin"
+	// RemoteNamespaceLabelKey is the label that identifies the namespaces created by the remote clusters, whose value
+	// is the ID of the cluster. It restricts the webhook enforcing the remote namespace policy to those namespaces.
+	RemoteNamespaceLabelKey = "liqo.io/remote-namespace"
 )
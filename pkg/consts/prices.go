@@ -0,0 +1,10 @@
+package consts
+
+const (
+	// ResourcePricesAnnotation is the annotation set on the virtual nodes, containing the JSON encoded prices
+	// of the resources offered by the corresponding remote cluster.
+	ResourcePricesAnnotation = "liqo.io/resource-prices"
+	// CostAwareSchedulingAnnotation is the annotation set on the pods whose scheduling has to prefer
+	// the cheapest nodes.
+	CostAwareSchedulingAnnotation = "liqo.io/cost-aware-scheduling"
+)
@@ -4,4 +4,10 @@ const (
 	// VirtualNodeTolerationKey all Pods that have to be scheduled on virtual nodes must have this toleration
 	// to Liqo taint.
 	VirtualNodeTolerationKey = "virtual-node.liqo.io/not-allowed"
+	// OffloadingProfileAnnotation is the annotation set on the mutated pods, containing the name of the
+	// offloading profile selected for them.
+	OffloadingProfileAnnotation = "liqo.io/offloading-profile"
+	// ReflectionReadyConditionType is the type of the pod readiness gate added to the pods offloaded in the remote
+	// clusters, which is satisfied by the virtual kubelet once the reflection of the pod namespace is ready.
+	ReflectionReadyConditionType = "liqo.io/reflection-ready"
 )
@@ -8,6 +8,7 @@ import (
 	"crypto/x509/pkix"
 	"encoding/asn1"
 	"encoding/base64"
+	"encoding/json"
 	"encoding/pem"
 	"fmt"
 	"sort"
@@ -89,6 +90,18 @@ func (certManager *certificateIdentityManager) StoreCertificate(remoteClusterID
 	secret.Data[namespaceSecretKey] = []byte(identityResponse.Namespace)
 	secret.Data[certificateSecretKey] = certificate
 
+	// The NamespacePolicy is stored only if the remote cluster enforces it.
+	if identityResponse.NamespacePolicy != nil {
+		policy, err := json.Marshal(identityResponse.NamespacePolicy)
+		if err != nil {
+			klog.Error(err)
+			return err
+		}
+		secret.Data[namespacePolicySecretKey] = policy
+	} else {
+		delete(secret.Data, namespacePolicySecretKey)
+	}
+
 	if _, err = certManager.client.CoreV1().Secrets(secret.Namespace).Update(context.TODO(), secret, metav1.UpdateOptions{}); err != nil {
 		klog.Error(err)
 		return err
@@ -154,7 +167,7 @@ func (certManager *certificateIdentityManager) createCSR() (keyBytes []byte, csr
 
 	subj := pkix.Name{
 		CommonName:   certManager.localClusterID.GetClusterID(),
-		Organization: []string{defaultOrganization},
+		Organization: []string{RemoteClusterGroup},
 	}
 	rawSubj := subj.ToRDNSequence()
 
@@ -1,11 +1,15 @@
 package identitymanager
 
 import (
+	"encoding/json"
+
 	v1 "k8s.io/api/core/v1"
 	kerrors "k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/runtime/schema"
 	"k8s.io/client-go/rest"
 	"k8s.io/klog/v2"
+
+	configv1alpha1 "github.com/liqotech/liqo/apis/config/v1alpha1"
 )
 
 // GetConfig gets a rest config from the secret, given the remote clusterID and (optionally) the namespace.
@@ -102,3 +106,33 @@ func (certManager *certificateIdentityManager) GetRemoteTenantNamespace(
 	}
 	return string(remoteNamespace), nil
 }
+
+// GetRemoteNamespacePolicy returns the policy that the remote cluster enforces on the names of the namespaces
+// created by this cluster. A nil policy is returned if the remote cluster does not enforce any policy.
+func (certManager *certificateIdentityManager) GetRemoteNamespacePolicy(
+	remoteClusterID, localTenantNamespaceName string) (*configv1alpha1.RemoteNamespacePolicy, error) {
+	var secret *v1.Secret
+	var err error
+
+	if localTenantNamespaceName == "" {
+		secret, err = certManager.getSecret(remoteClusterID)
+	} else {
+		secret, err = certManager.getSecretInNamespace(remoteClusterID, localTenantNamespaceName)
+	}
+	if err != nil {
+		klog.Error(err)
+		return nil, err
+	}
+
+	policyData, ok := secret.Data[namespacePolicySecretKey]
+	if !ok {
+		return nil, nil
+	}
+
+	policy := &configv1alpha1.RemoteNamespacePolicy{}
+	if err = json.Unmarshal(policyData, policy); err != nil {
+		klog.Errorf("%s -> unable to decode the key %v in secret %v/%v", err, namespacePolicySecretKey, secret.Namespace, secret.Name)
+		return nil, err
+	}
+	return policy, nil
+}
@@ -2,7 +2,9 @@ package identitymanager
 
 const keyLength = 2048
 
-const defaultOrganization = "liqo.io"
+// RemoteClusterGroup is the organization of the certificates issued to the remote clusters, hence the group
+// their requests are authenticated with.
+const RemoteClusterGroup = "liqo.io"
 
 const (
 	localIdentitySecretLabel  = "discovery.liqo.io/local-identity"
@@ -18,10 +20,11 @@ const (
 	identitySecretRoot      = "liqo-identity"
 	remoteCertificateSecret = "liqo-remote-certificate"
 
-	privateKeySecretKey   = "private-key"
-	csrSecretKey          = "csr"
-	certificateSecretKey  = "certificate"
-	apiServerURLSecretKey = "apiServerUrl"
-	apiServerCaSecretKey  = "apiServerCa"
-	namespaceSecretKey    = "namespace"
+	privateKeySecretKey      = "private-key"
+	csrSecretKey             = "csr"
+	certificateSecretKey     = "certificate"
+	apiServerURLSecretKey    = "apiServerUrl"
+	apiServerCaSecretKey     = "apiServerCa"
+	namespaceSecretKey       = "namespace"
+	namespacePolicySecretKey = "namespacePolicy"
 )
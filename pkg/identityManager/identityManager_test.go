@@ -236,6 +236,29 @@ var _ = Describe("IdentityManager", func() {
 			remoteNamespace, err := identityManager.GetRemoteTenantNamespace(remoteClusterID, "")
 			Expect(err).To(BeNil())
 			Expect(remoteNamespace).To(Equal("remoteNamespace"))
+
+			// no namespace policy is enforced by the remote cluster
+			policy, err := identityManager.GetRemoteNamespacePolicy(remoteClusterID, "")
+			Expect(err).To(BeNil())
+			Expect(policy).To(BeNil())
+		})
+
+		It("StoreCertificate with a NamespacePolicy", func() {
+			apiServerConfig := newMockApiServerConfigProvider("127.0.0.1", "6443", false)
+
+			identityResponse, err := auth.NewCertificateIdentityResponse(
+				"remoteNamespace", []byte("cert"), apiServerConfig, client, restConfig)
+			Expect(err).To(BeNil())
+			identityResponse.NamespacePolicy = &configv1alpha1.RemoteNamespacePolicy{Prefix: "tenant-", MaxLength: 30}
+
+			// store the certificate in the secret
+			err = identityManager.StoreCertificate(remoteClusterID, *identityResponse)
+			Expect(err).To(BeNil())
+
+			// retrieve the namespace policy enforced by the remote cluster
+			policy, err := identityManager.GetRemoteNamespacePolicy(remoteClusterID, "")
+			Expect(err).To(BeNil())
+			Expect(policy).To(Equal(identityResponse.NamespacePolicy))
 		})
 
 	})
@@ -4,6 +4,7 @@ import (
 	v1 "k8s.io/api/core/v1"
 	"k8s.io/client-go/rest"
 
+	configv1alpha1 "github.com/liqotech/liqo/apis/config/v1alpha1"
 	"github.com/liqotech/liqo/pkg/auth"
 )
 
@@ -21,6 +22,7 @@ type localManager interface {
 
 	GetConfig(remoteClusterID string, namespace string) (*rest.Config, error)
 	GetRemoteTenantNamespace(remoteClusterID string, namespace string) (string, error)
+	GetRemoteNamespacePolicy(remoteClusterID string, namespace string) (*configv1alpha1.RemoteNamespacePolicy, error)
 }
 
 // interface that allows to manage the identity in the target cluster, where this identity has to be used.
@@ -0,0 +1,3 @@
+// Package localnodectrl contains the LocalNode Controller logic, which labels the local nodes with the local
+// cluster ID, to make them part of the same topology domain when the replicas are spread among clusters.
+package localnodectrl
@@ -0,0 +1,84 @@
+package localnodectrl
+
+import (
+	"context"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/klog/v2"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+
+	liqoconst "github.com/liqotech/liqo/pkg/consts"
+)
+
+// LocalNodeReconciler sets the cluster ID label on the local nodes.
+type LocalNodeReconciler struct {
+	client.Client
+	LocalClusterID string
+}
+
+// cluster-role
+// +kubebuilder:rbac:groups=core,resources=nodes,verbs=get;list;watch;patch;update
+
+// Reconcile sets the local cluster ID label on the node, if not already present.
+func (r *LocalNodeReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	node := &corev1.Node{}
+	if err := r.Get(ctx, req.NamespacedName, node); err != nil {
+		if apierrors.IsNotFound(err) {
+			klog.V(4).Infof("The node '%s' does not exist anymore", req.Name)
+			return ctrl.Result{}, nil
+		}
+		klog.Errorf("%s --> Unable to get the node '%s'", err, req.Name)
+		return ctrl.Result{}, err
+	}
+
+	if isVirtualNode(node) || node.GetLabels()[liqoconst.ClusterIDNodeLabel] == r.LocalClusterID {
+		return ctrl.Result{}, nil
+	}
+
+	original := node.DeepCopy()
+	if node.Labels == nil {
+		node.Labels = map[string]string{}
+	}
+	node.Labels[liqoconst.ClusterIDNodeLabel] = r.LocalClusterID
+	if err := r.Patch(ctx, node, client.MergeFrom(original)); err != nil {
+		klog.Errorf("%s --> Unable to set the cluster ID label on the node '%s'", err, node.Name)
+		return ctrl.Result{}, err
+	}
+	klog.Infof("Cluster ID label correctly set on the node '%s'", node.Name)
+	return ctrl.Result{}, nil
+}
+
+func isVirtualNode(node client.Object) bool {
+	value, ok := node.GetLabels()[liqoconst.TypeLabel]
+	return ok && value == liqoconst.TypeNode
+}
+
+// Only the creation of the local nodes and the updates changing their cluster ID label are monitored.
+func filterLocalNodes() predicate.Predicate {
+	return predicate.Funcs{
+		CreateFunc: func(e event.CreateEvent) bool {
+			return !isVirtualNode(e.Object)
+		},
+		UpdateFunc: func(e event.UpdateEvent) bool {
+			return !isVirtualNode(e.ObjectNew) &&
+				e.ObjectNew.GetLabels()[liqoconst.ClusterIDNodeLabel] != e.ObjectOld.GetLabels()[liqoconst.ClusterIDNodeLabel]
+		},
+		DeleteFunc: func(e event.DeleteEvent) bool {
+			return false
+		},
+	}
+}
+
+// SetupWithManager monitors the local nodes. The controller is named explicitly, since the one managing
+// the virtual nodes watches the same kind.
+func (r *LocalNodeReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewControllerManagedBy(mgr).
+		Named("localnode").
+		For(&corev1.Node{}).
+		WithEventFilter(filterLocalNodes()).
+		Complete(r)
+}
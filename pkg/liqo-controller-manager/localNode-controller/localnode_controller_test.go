@@ -0,0 +1,44 @@
+package localnodectrl
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/ginkgo/extensions/table"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes/scheme"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	liqoconst "github.com/liqotech/liqo/pkg/consts"
+)
+
+var _ = Describe("LocalNode controller", func() {
+
+	const localClusterID = "local-cluster-id"
+
+	DescribeTable("The cluster ID label is set only on the local nodes",
+		func(labels map[string]string, expectedClusterID string) {
+			node := &corev1.Node{ObjectMeta: metav1.ObjectMeta{Name: "node", Labels: labels}}
+			reconciler := &LocalNodeReconciler{
+				Client:         fake.NewClientBuilder().WithScheme(scheme.Scheme).WithObjects(node).Build(),
+				LocalClusterID: localClusterID,
+			}
+
+			_, err := reconciler.Reconcile(context.TODO(), ctrl.Request{NamespacedName: types.NamespacedName{Name: "node"}})
+			Expect(err).ToNot(HaveOccurred())
+
+			Expect(reconciler.Get(context.TODO(), types.NamespacedName{Name: "node"}, node)).To(Succeed())
+			Expect(node.Labels[liqoconst.ClusterIDNodeLabel]).To(Equal(expectedClusterID))
+		},
+		Entry("Local node", nil, localClusterID),
+		Entry("Local node with a wrong cluster ID", map[string]string{liqoconst.ClusterIDNodeLabel: "foo"}, localClusterID),
+		Entry("Virtual node", map[string]string{
+			liqoconst.TypeLabel:          liqoconst.TypeNode,
+			liqoconst.ClusterIDNodeLabel: "remote-cluster-id",
+		}, "remote-cluster-id"),
+	)
+})
@@ -0,0 +1,13 @@
+package localnodectrl
+
+import (
+	"testing"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+func TestLocalNodeController(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "LocalNode Controller Suite")
+}
@@ -4,6 +4,7 @@ import (
 	"k8s.io/client-go/kubernetes"
 	"k8s.io/klog/v2"
 
+	configv1alpha1 "github.com/liqotech/liqo/apis/config/v1alpha1"
 	"github.com/liqotech/liqo/pkg/clusterid"
 	identitymanager "github.com/liqotech/liqo/pkg/identityManager"
 	tenantnamespace "github.com/liqotech/liqo/pkg/tenantNamespace"
@@ -33,3 +34,17 @@ func (r *NamespaceMapReconciler) checkRemoteClientPresence(remoteClusterID strin
 	}
 	return nil
 }
+
+// getRemoteNamespacePolicy returns the policy enforced by the remote cluster on the namespace names. It is not cached,
+// but read every time from the identity of the remote cluster, which is updated when the peering is established again.
+func (r *NamespaceMapReconciler) getRemoteNamespacePolicy(remoteClusterID string) (*configv1alpha1.RemoteNamespacePolicy, error) {
+	clusterID := clusterid.NewStaticClusterID(r.LocalClusterID)
+	tenantNamespaceManager := tenantnamespace.NewTenantNamespaceManager(r.IdentityManagerClient)
+	identityManager := identitymanager.NewCertificateIdentityManager(r.IdentityManagerClient, clusterID, tenantNamespaceManager)
+	policy, err := identityManager.GetRemoteNamespacePolicy(remoteClusterID, "")
+	if err != nil {
+		klog.Errorf("%s -> unable to get the namespace policy of the cluster '%s'", err, remoteClusterID)
+		return nil, err
+	}
+	return policy, nil
+}
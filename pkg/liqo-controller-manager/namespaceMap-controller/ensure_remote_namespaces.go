@@ -15,28 +15,45 @@ import (
 
 	mapsv1alpha1 "github.com/liqotech/liqo/apis/virtualKubelet/v1alpha1"
 	liqoconst "github.com/liqotech/liqo/pkg/consts"
+	namespacenaming "github.com/liqotech/liqo/pkg/namespaceNaming"
 )
 
 // This function creates a remote Namespace inside the remote cluster, if it doesn't exist yet.
 // The right client to use is chosen by means of NamespaceMap's cluster-id.
-func (r *NamespaceMapReconciler) createRemoteNamespace(ctx context.Context, remoteClusterID, remoteNamespaceName string) error {
+func (r *NamespaceMapReconciler) createRemoteNamespace(ctx context.Context,
+	remoteClusterID, localNamespaceName, remoteNamespaceName string) error {
 	if err := r.checkRemoteClientPresence(remoteClusterID); err != nil {
 		return err
 	}
 
+	// Check if the remote cluster allows this cluster to create a namespace with this name. The policy is enforced
+	// by the remote cluster as well, this check only avoids a request doomed to fail.
+	policy, err := r.getRemoteNamespacePolicy(remoteClusterID)
+	if err != nil {
+		return err
+	}
+	if err = namespacenaming.CheckPolicy(policy, remoteNamespaceName); err != nil {
+		klog.Errorf("%s -> the remote namespace name is refused by the policy of the remote cluster '%s'", err, remoteClusterID)
+		return err
+	}
+
 	// Todo: at the moment the capsule controller removes this annotation, so the Tenant will create directly
 	//       this annotation on its namespaces.
 	// This annotation is used to recognize the remote namespaces that have been created by this controller.
+	// The label makes the remote cluster validate the namespace name against its policy already at creation.
 	remoteNamespace := &corev1.Namespace{
 		ObjectMeta: metav1.ObjectMeta{
 			Name: remoteNamespaceName,
+			Labels: map[string]string{
+				liqoconst.RemoteNamespaceLabelKey: r.LocalClusterID,
+			},
 			Annotations: map[string]string{
-				liqoconst.RemoteNamespaceAnnotationKey: r.LocalClusterID,
+				liqoconst.RemoteNamespaceAnnotationKey:       r.LocalClusterID,
+				liqoconst.RemoteNamespaceOriginAnnotationKey: localNamespaceName,
 			},
 		},
 	}
 
-	var err error
 	// Trying to create the remote namespace.
 	if _, err = r.RemoteClients[remoteClusterID].CoreV1().Namespaces().Create(ctx,
 		remoteNamespace, metav1.CreateOptions{}); err != nil && !apierrors.IsAlreadyExists(err) {
@@ -58,7 +75,15 @@ func (r *NamespaceMapReconciler) createRemoteNamespace(ctx context.Context, remo
 		klog.Error(err)
 		return err
 	}
-	// 3 - Check if the virtual kubelet will have the right privileges on the remote namespace.
+	// 3 - Check if the remote namespace is not already associated with a different local namespace, which may happen
+	// when multiple local namespaces are mapped to the same remote name.
+	if value, ok := remoteNamespace.Annotations[liqoconst.RemoteNamespaceOriginAnnotationKey]; ok && value != localNamespaceName {
+		err = fmt.Errorf("the remote namespace '%s', inside the remote cluster '%s', is already associated with the local namespace '%s'",
+			remoteNamespaceName, remoteClusterID, value)
+		klog.Error(err)
+		return err
+	}
+	// 4 - Check if the virtual kubelet will have the right privileges on the remote namespace.
 	if err = checkRemoteNamespaceRoleBindings(ctx, r.RemoteClients[remoteClusterID], remoteNamespaceName, r.LocalClusterID); err != nil {
 		return err
 	}
@@ -72,7 +97,7 @@ func (r *NamespaceMapReconciler) createRemoteNamespace(ctx context.Context, remo
 func (r *NamespaceMapReconciler) ensureRemoteNamespacesExistence(ctx context.Context, nm *mapsv1alpha1.NamespaceMap) bool {
 	errorCondition := false
 	for localName, remoteName := range nm.Spec.DesiredMapping {
-		if err := r.createRemoteNamespace(ctx, nm.Labels[liqoconst.RemoteClusterID], remoteName); err != nil {
+		if err := r.createRemoteNamespace(ctx, nm.Labels[liqoconst.RemoteClusterID], localName, remoteName); err != nil {
 			nm.Status.CurrentMapping[localName] = mapsv1alpha1.RemoteNamespaceStatus{
 				RemoteNamespace: remoteName,
 				Phase:           mapsv1alpha1.MappingCreationLoopBackOff,
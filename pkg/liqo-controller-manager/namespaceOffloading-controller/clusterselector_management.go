@@ -3,37 +3,44 @@ package namespaceoffloadingctrl
 import (
 	"context"
 	"fmt"
+	"reflect"
 
 	corev1 "k8s.io/api/core/v1"
-	k8shelper "k8s.io/component-helpers/scheduling/corev1"
 	"k8s.io/klog/v2"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 
 	offv1alpha1 "github.com/liqotech/liqo/apis/offloading/v1alpha1"
 	mapsv1alpha1 "github.com/liqotech/liqo/apis/virtualKubelet/v1alpha1"
 	liqoconst "github.com/liqotech/liqo/pkg/consts"
+	liqoutils "github.com/liqotech/liqo/pkg/utils"
 )
 
+// enforceClusterSelector computes the set of clusters selected by the offloading profiles, adds the DesiredMapping to
+// the NamespaceMaps of the selected clusters and withdraws the namespace from the ones no longer selected, according
+// to the DrainPolicy. The clusters not allowed by the ClusterOffloadingPolicies are reported as denied. It returns
+// the number of selected clusters and whether some clusters are still draining.
 func (r *NamespaceOffloadingReconciler) enforceClusterSelector(ctx context.Context, noff *offv1alpha1.NamespaceOffloading,
-	clusterIDMap map[string]*mapsv1alpha1.NamespaceMap) error {
+	clusterIDMap map[string]*mapsv1alpha1.NamespaceMap) (selected int, draining bool, err error) {
 	virtualNodes := &corev1.NodeList{}
-	if err := r.List(ctx, virtualNodes,
+	if err = r.List(ctx, virtualNodes,
 		client.MatchingLabels{liqoconst.TypeLabel: liqoconst.TypeNode}); err != nil {
 		klog.Error(err, " --> Unable to List all virtual nodes")
-		return err
+		return 0, false, err
 	}
 
 	// If here there are no virtual nodes is an error because it means that in the cluster there are NamespaceMap
 	// but not their associated virtual nodes
 	if len(virtualNodes.Items) != len(clusterIDMap) {
-		err := fmt.Errorf(" No VirtualNodes at the moment in the cluster")
+		err = fmt.Errorf(" No VirtualNodes at the moment in the cluster")
 		klog.Error(err)
-		return err
+		return 0, false, err
 	}
 
-	errorCondition := false
+	// The whole set of selected clusters is computed before performing any change, to avoid withdrawing
+	// the namespace from some clusters in case of an invalid ClusterSelector.
+	matches := make(map[string][]string, len(virtualNodes.Items))
 	for i := range virtualNodes.Items {
-		match, err := k8shelper.MatchNodeSelectorTerms(&virtualNodes.Items[i], &noff.Spec.ClusterSelector)
+		profiles, err := matchingProfiles(noff, &virtualNodes.Items[i])
 		if err != nil {
 			klog.Infof("%s -> Unable to offload the namespace '%s', there is an error in ClusterSelectorField",
 				err, noff.Namespace)
@@ -45,27 +52,86 @@ func (r *NamespaceOffloadingReconciler) enforceClusterSelector(ctx context.Conte
 			if err = r.Patch(ctx, noff, client.MergeFrom(patch)); err != nil {
 				klog.Errorf("%s -> unable to add the liqo scheduling annotation to the NamespaceOffloading in the namespace '%s'",
 					err, noff.Namespace)
-				return err
+				return 0, false, err
 			}
 			klog.Infof("The liqo scheduling annotation is correctly added to the NamespaceOffloading in the namespace '%s'",
 				noff.Namespace)
-			break
+			return 0, false, nil
 		}
-		if match {
-			if err = addDesiredMapping(ctx, r.Client, noff.Namespace, noff.Status.RemoteNamespaceName,
-				clusterIDMap[virtualNodes.Items[i].Annotations[liqoconst.RemoteClusterID]]); err != nil {
+		matches[virtualNodes.Items[i].Name] = profiles
+	}
+
+	// The clusters not allowed by the ClusterOffloadingPolicies are treated as not selected.
+	allowed, restricted, err := liqoutils.GetAllowedClusters(ctx, r.Client, noff.Namespace)
+	if err != nil {
+		klog.Errorf("%s --> Unable to retrieve the clusters the namespace '%s' is allowed to be offloaded to",
+			err, noff.Namespace)
+		return 0, false, err
+	}
+
+	original := noff.DeepCopy()
+	denied := map[string]struct{}{}
+	errorCondition := false
+	for i := range virtualNodes.Items {
+		clusterID := virtualNodes.Items[i].Annotations[liqoconst.RemoteClusterID]
+		nm, ok := clusterIDMap[clusterID]
+		if !ok {
+			klog.Infof("No NamespaceMap at the moment for the cluster '%s'", clusterID)
+			continue
+		}
+
+		profiles := matches[virtualNodes.Items[i].Name]
+		if _, found := allowed[clusterID]; restricted && !found && len(profiles) > 0 {
+			klog.Infof("The namespace '%s' is not allowed to be offloaded to the cluster '%s'", noff.Namespace, clusterID)
+			denied[clusterID] = struct{}{}
+			profiles = nil
+		}
+		setClusterProfiles(noff, clusterID, profiles)
+		if len(profiles) > 0 {
+			selected++
+			if err = addDesiredMapping(ctx, r.Client, noff.Namespace, noff.Status.RemoteNamespaceName, nm); err != nil {
 				errorCondition = true
 				continue
 			}
-			delete(clusterIDMap, virtualNodes.Items[i].Annotations[liqoconst.RemoteClusterID])
+			clearDrainingCondition(noff, clusterID)
+			continue
 		}
+
+		// The cluster is no longer selected, hence the namespace has to be withdrawn from it.
+		if _, ok := nm.Spec.DesiredMapping[noff.Namespace]; !ok {
+			clearDrainingCondition(noff, clusterID)
+			continue
+		}
+		stillDraining, err := r.withdrawFromCluster(ctx, noff, nm, clusterID, virtualNodes.Items[i].Name)
+		if err != nil {
+			errorCondition = true
+			continue
+		}
+		draining = draining || stillDraining
+	}
+
+	noff.Status.DeniedClusters = nil
+	if len(denied) > 0 {
+		noff.Status.DeniedClusters = liqoutils.SortedClusterIDs(denied)
 	}
+
+	// Patch the draining conditions, the cluster profiles and the denied clusters just one time at the end of the logic.
+	if !reflect.DeepEqual(original.Status.RemoteNamespacesConditions, noff.Status.RemoteNamespacesConditions) ||
+		!reflect.DeepEqual(original.Status.ClusterProfiles, noff.Status.ClusterProfiles) ||
+		!reflect.DeepEqual(original.Status.DeniedClusters, noff.Status.DeniedClusters) {
+		if err = r.Patch(ctx, noff, client.MergeFrom(original)); err != nil {
+			klog.Errorf("%s --> Unable to update the remote conditions of the NamespaceOffloading in the namespace '%s'",
+				err, noff.Namespace)
+			return selected, draining, err
+		}
+	}
+
 	if errorCondition {
-		err := fmt.Errorf("some desiredMappings has not been added")
+		err = fmt.Errorf("some desiredMappings have not been added or removed")
 		klog.Error(err)
-		return err
+		return selected, draining, err
 	}
-	return nil
+	return selected, draining, nil
 }
 
 func (r *NamespaceOffloadingReconciler) getClusterIDMap(ctx context.Context) (map[string]*mapsv1alpha1.NamespaceMap, error) {
@@ -0,0 +1,139 @@
+package namespaceoffloadingctrl
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	policyv1beta1 "k8s.io/api/policy/v1beta1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/fields"
+	"k8s.io/klog/v2"
+
+	offv1alpha1 "github.com/liqotech/liqo/apis/offloading/v1alpha1"
+	mapsv1alpha1 "github.com/liqotech/liqo/apis/virtualKubelet/v1alpha1"
+	liqoutils "github.com/liqotech/liqo/pkg/utils"
+)
+
+// drainCheckPeriod is the period after which the clusters being drained are checked again.
+const drainCheckPeriod = 10 * time.Second
+
+// withdrawFromCluster removes the namespace from a cluster no longer selected by the ClusterSelector, according to
+// the DrainPolicy. It returns true if the remote namespace is still draining, hence it has not been removed yet.
+func (r *NamespaceOffloadingReconciler) withdrawFromCluster(ctx context.Context, noff *offv1alpha1.NamespaceOffloading,
+	nm *mapsv1alpha1.NamespaceMap, clusterID, virtualNodeName string) (bool, error) {
+	remaining, err := r.drainVirtualNode(ctx, noff, virtualNodeName)
+	if err != nil {
+		return false, err
+	}
+
+	if remaining > 0 {
+		klog.Infof("Waiting for %d pods of the namespace '%s' to terminate on the cluster '%s'",
+			remaining, noff.Namespace, clusterID)
+		setDrainingCondition(noff, clusterID, remaining)
+		return true, nil
+	}
+
+	if err := removeDesiredMapping(ctx, r.Client, noff.Namespace, nm); err != nil {
+		return false, err
+	}
+	clearDrainingCondition(noff, clusterID)
+	return false, nil
+}
+
+// drainVirtualNode enforces the DrainPolicy on the pods of the namespace running on the given virtual node,
+// and returns the number of pods which have not terminated yet.
+func (r *NamespaceOffloadingReconciler) drainVirtualNode(ctx context.Context,
+	noff *offv1alpha1.NamespaceOffloading, virtualNodeName string) (int, error) {
+	if noff.Spec.DrainPolicy != offv1alpha1.GracefulEvictionDrainPolicyType &&
+		noff.Spec.DrainPolicy != offv1alpha1.KeepUntilEmptyDrainPolicyType {
+		return 0, nil
+	}
+
+	podList, err := r.ClientSet.CoreV1().Pods(noff.Namespace).List(ctx, metav1.ListOptions{
+		FieldSelector: fields.SelectorFromSet(fields.Set{
+			"spec.nodeName": virtualNodeName,
+		}).String()})
+	if err != nil {
+		klog.Errorf("%s --> Unable to list the pods of the namespace '%s' running on the virtual node '%s'",
+			err, noff.Namespace, virtualNodeName)
+		return 0, err
+	}
+
+	remaining := 0
+	for i := range podList.Items {
+		pod := &podList.Items[i]
+		if pod.Status.Phase == corev1.PodSucceeded || pod.Status.Phase == corev1.PodFailed {
+			continue
+		}
+		remaining++
+
+		if noff.Spec.DrainPolicy == offv1alpha1.GracefulEvictionDrainPolicyType && pod.DeletionTimestamp.IsZero() {
+			if _, err := r.evictPod(ctx, pod); err != nil {
+				return 0, err
+			}
+		}
+	}
+	return remaining, nil
+}
+
+// evictPod requests the eviction of the given pod through the Eviction API, and returns whether it has been evicted.
+// Evictions currently denied by a PodDisruptionBudget are not considered errors, since they will be retried at
+// the next check.
+func (r *NamespaceOffloadingReconciler) evictPod(ctx context.Context, pod *corev1.Pod) (bool, error) {
+	eviction := &policyv1beta1.Eviction{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      pod.Name,
+			Namespace: pod.Namespace,
+		},
+		DeleteOptions: &metav1.DeleteOptions{},
+	}
+
+	err := r.ClientSet.PolicyV1beta1().Evictions(pod.Namespace).Evict(ctx, eviction)
+	switch {
+	case apierrors.IsNotFound(err):
+		return true, nil
+	case apierrors.IsTooManyRequests(err):
+		klog.Infof("The eviction of the pod '%s/%s' is currently denied by a PodDisruptionBudget", pod.Namespace, pod.Name)
+		return false, nil
+	case err != nil:
+		klog.Errorf("%s --> Unable to evict the pod '%s/%s'", err, pod.Namespace, pod.Name)
+		return false, err
+	}
+	klog.Infof("The pod '%s/%s' is correctly evicted", pod.Namespace, pod.Name)
+	return true, nil
+}
+
+// setDrainingCondition sets the Draining remote condition for the given cluster.
+func setDrainingCondition(noff *offv1alpha1.NamespaceOffloading, clusterID string, remaining int) {
+	if noff.Status.RemoteNamespacesConditions == nil {
+		noff.Status.RemoteNamespacesConditions = map[string]offv1alpha1.RemoteNamespaceConditions{}
+	}
+
+	conditions := []offv1alpha1.RemoteNamespaceCondition(noff.Status.RemoteNamespacesConditions[clusterID])
+	liqoutils.AddRemoteNamespaceCondition(&conditions, &offv1alpha1.RemoteNamespaceCondition{
+		Type:    offv1alpha1.NamespaceDraining,
+		Status:  corev1.ConditionTrue,
+		Reason:  string(noff.Spec.DrainPolicy),
+		Message: fmt.Sprintf("The cluster is no longer selected, waiting for %d pods to terminate", remaining),
+	})
+	noff.Status.RemoteNamespacesConditions[clusterID] = conditions
+}
+
+// clearDrainingCondition removes the Draining remote condition for the given cluster, if present.
+func clearDrainingCondition(noff *offv1alpha1.NamespaceOffloading, clusterID string) {
+	conditions, ok := noff.Status.RemoteNamespacesConditions[clusterID]
+	if !ok || liqoutils.FindRemoteNamespaceCondition(conditions, offv1alpha1.NamespaceDraining) == nil {
+		return
+	}
+
+	remoteConditions := []offv1alpha1.RemoteNamespaceCondition(conditions)
+	liqoutils.RemoveRemoteNamespaceCondition(&remoteConditions, offv1alpha1.NamespaceDraining)
+	if len(remoteConditions) == 0 {
+		delete(noff.Status.RemoteNamespacesConditions, clusterID)
+		return
+	}
+	noff.Status.RemoteNamespacesConditions[clusterID] = remoteConditions
+}
@@ -0,0 +1,222 @@
+package namespaceoffloadingctrl
+
+import (
+	"context"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	k8shelper "k8s.io/component-helpers/scheduling/corev1"
+	"k8s.io/klog/v2"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	offv1alpha1 "github.com/liqotech/liqo/apis/offloading/v1alpha1"
+	liqoconst "github.com/liqotech/liqo/pkg/consts"
+	liqoutils "github.com/liqotech/liqo/pkg/utils"
+)
+
+// migrationCheckPeriod is the period after which the progress of the migration is checked again.
+const migrationCheckPeriod = 10 * time.Second
+
+// podCompliance is a function returning whether a pod complies with the offloading policies.
+type podCompliance func(pod *corev1.Pod) (bool, error)
+
+// migratePods recreates, in batches, the pods of the namespace not complying with the offloading policies, or not
+// running in the local cluster if toLocal is true, and records the progress of the migration in the NamespaceOffloading
+// status. It returns true if the migration is still ongoing.
+func (r *NamespaceOffloadingReconciler) migratePods(ctx context.Context, noff *offv1alpha1.NamespaceOffloading,
+	toLocal bool) (bool, error) {
+	if noff.Spec.Migration == nil {
+		return false, r.clearMigrationStatus(ctx, noff)
+	}
+
+	var compliant podCompliance
+	var err error
+	if toLocal {
+		compliant, err = r.localCompliance(ctx)
+	} else {
+		compliant, err = r.offloadingCompliance(ctx, noff)
+	}
+	if err != nil {
+		return false, err
+	}
+
+	podList, err := r.ClientSet.CoreV1().Pods(noff.Namespace).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		klog.Errorf("%s --> Unable to list the pods of the namespace '%s'", err, noff.Namespace)
+		return false, err
+	}
+
+	status := offv1alpha1.MigrationStatus{}
+	var candidates []*corev1.Pod
+	for i := range podList.Items {
+		pod := &podList.Items[i]
+		// The pods not yet scheduled will be placed according to the current policies.
+		if pod.Spec.NodeName == "" || pod.Status.Phase == corev1.PodSucceeded || pod.Status.Phase == corev1.PodFailed {
+			continue
+		}
+
+		ok, err := compliant(pod)
+		if err != nil {
+			klog.Errorf("%s --> Unable to check whether the pod '%s/%s' complies with the offloading policies",
+				err, pod.Namespace, pod.Name)
+			return false, err
+		}
+
+		switch {
+		case ok:
+			continue
+		case !isMigratable(pod):
+			status.UnmanagedPods++
+		case !pod.DeletionTimestamp.IsZero():
+			status.MigratingPods++
+		default:
+			status.PendingPods++
+			candidates = append(candidates, pod)
+		}
+	}
+
+	batchSize := noff.Spec.Migration.BatchSize
+	if batchSize < 1 {
+		batchSize = 1
+	}
+
+	// Evict the pending pods, without exceeding the batch size.
+	for _, pod := range candidates {
+		if status.MigratingPods >= batchSize {
+			break
+		}
+		evicted, err := r.evictPod(ctx, pod)
+		if err != nil {
+			return false, err
+		}
+		if !evicted {
+			status.BlockedPods++
+			continue
+		}
+		status.PendingPods--
+		status.MigratingPods++
+	}
+
+	migrating := status.PendingPods > 0 || status.MigratingPods > 0
+	if err := r.setMigrationStatus(ctx, noff, &status, migrating); err != nil {
+		return false, err
+	}
+	return migrating, nil
+}
+
+// setMigrationStatus patches the migration status of the NamespaceOffloading, if changed.
+func (r *NamespaceOffloadingReconciler) setMigrationStatus(ctx context.Context, noff *offv1alpha1.NamespaceOffloading,
+	status *offv1alpha1.MigrationStatus, migrating bool) error {
+	status.Phase = offv1alpha1.MigrationCompletedPhaseType
+	if migrating {
+		status.Phase = offv1alpha1.MigrationInProgressPhaseType
+	}
+
+	current := noff.Status.Migration
+	switch {
+	case current == nil || current.Phase != status.Phase:
+		status.LastTransitionTime = metav1.Now()
+	default:
+		status.LastTransitionTime = current.LastTransitionTime
+		if *current == *status {
+			return nil
+		}
+	}
+
+	original := noff.DeepCopy()
+	noff.Status.Migration = status
+	if err := r.Patch(ctx, noff, client.MergeFrom(original)); err != nil {
+		klog.Errorf("%s --> Unable to patch the migration status of the NamespaceOffloading in the namespace '%s'",
+			err, noff.Namespace)
+		return err
+	}
+	klog.Infof("Migration of the pods of the namespace '%s': %s (pending: %d, migrating: %d, blocked: %d, unmanaged: %d)",
+		noff.Namespace, status.Phase, status.PendingPods, status.MigratingPods, status.BlockedPods, status.UnmanagedPods)
+	return nil
+}
+
+// clearMigrationStatus removes the migration status of the NamespaceOffloading, if the migration is no longer enabled.
+func (r *NamespaceOffloadingReconciler) clearMigrationStatus(ctx context.Context, noff *offv1alpha1.NamespaceOffloading) error {
+	if noff.Status.Migration == nil {
+		return nil
+	}
+
+	original := noff.DeepCopy()
+	noff.Status.Migration = nil
+	if err := r.Patch(ctx, noff, client.MergeFrom(original)); err != nil {
+		klog.Errorf("%s --> Unable to patch the migration status of the NamespaceOffloading in the namespace '%s'",
+			err, noff.Namespace)
+		return err
+	}
+	return nil
+}
+
+// isMigratable returns whether the pod is managed by a controller which recreates it once evicted. The pods managed by
+// DaemonSets and the static pods are excluded, since they would be recreated on the same node.
+func isMigratable(pod *corev1.Pod) bool {
+	owner := metav1.GetControllerOf(pod)
+	return owner != nil && owner.Kind != "DaemonSet" && owner.Kind != "Node"
+}
+
+// offloadingCompliance returns a podCompliance function checking whether the pods are running in accordance with
+// the PodOffloadingStrategy and the ClusterSelector of the offloading profile selecting them.
+func (r *NamespaceOffloadingReconciler) offloadingCompliance(ctx context.Context,
+	noff *offv1alpha1.NamespaceOffloading) (podCompliance, error) {
+	virtualNodes, err := r.getVirtualNodes(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return func(pod *corev1.Pod) (bool, error) {
+		profile, err := liqoutils.SelectOffloadingProfile(noff, pod)
+		if err != nil {
+			return false, err
+		}
+		effective := liqoutils.EffectiveNamespaceOffloading(noff, profile)
+
+		virtualNode, remote := virtualNodes[pod.Spec.NodeName]
+		switch effective.Spec.PodOffloadingStrategy {
+		case offv1alpha1.LocalPodOffloadingStrategyType:
+			return !remote, nil
+		case offv1alpha1.RemotePodOffloadingStrategyType:
+			if !remote {
+				return false, nil
+			}
+		default:
+			if !remote {
+				return true, nil
+			}
+		}
+		return k8shelper.MatchNodeSelectorTerms(virtualNode, &effective.Spec.ClusterSelector)
+	}, nil
+}
+
+// localCompliance returns a podCompliance function checking whether the pods are running in the local cluster, which
+// is used to bring the pods back when the namespace is no longer offloaded.
+func (r *NamespaceOffloadingReconciler) localCompliance(ctx context.Context) (podCompliance, error) {
+	virtualNodes, err := r.getVirtualNodes(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return func(pod *corev1.Pod) (bool, error) {
+		_, remote := virtualNodes[pod.Spec.NodeName]
+		return !remote, nil
+	}, nil
+}
+
+// getVirtualNodes returns the virtual nodes in the cluster, indexed by name.
+func (r *NamespaceOffloadingReconciler) getVirtualNodes(ctx context.Context) (map[string]*corev1.Node, error) {
+	virtualNodes := &corev1.NodeList{}
+	if err := r.List(ctx, virtualNodes, client.MatchingLabels{liqoconst.TypeLabel: liqoconst.TypeNode}); err != nil {
+		klog.Error(err, " --> Unable to List all virtual nodes")
+		return nil, err
+	}
+
+	nodes := make(map[string]*corev1.Node, len(virtualNodes.Items))
+	for i := range virtualNodes.Items {
+		nodes[virtualNodes.Items[i].Name] = &virtualNodes.Items[i]
+	}
+	return nodes, nil
+}
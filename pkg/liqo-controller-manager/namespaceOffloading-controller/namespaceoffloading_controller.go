@@ -16,25 +16,33 @@ package namespaceoffloadingctrl
 
 import (
 	"context"
+	"reflect"
 
+	corev1 "k8s.io/api/core/v1"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes"
 	"k8s.io/klog/v2"
 	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	ctrlutils "sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
 	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
 	"sigs.k8s.io/controller-runtime/pkg/predicate"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+	"sigs.k8s.io/controller-runtime/pkg/source"
 
 	offv1alpha1 "github.com/liqotech/liqo/apis/offloading/v1alpha1"
 	liqoconst "github.com/liqotech/liqo/pkg/consts"
 )
 
 // NamespaceOffloadingReconciler adds/removes DesiredMapping to/from NamespaceMaps in according with
-// ClusterSelector field.
+// ClusterSelector field, draining the clusters no longer selected according to the DrainPolicy.
 type NamespaceOffloadingReconciler struct {
 	client.Client
+	ClientSet      kubernetes.Interface
 	Scheme         *runtime.Scheme
 	LocalClusterID string
 }
@@ -48,11 +56,18 @@ const (
 // +kubebuilder:rbac:groups=virtualKubelet.liqo.io,resources=namespacemaps,verbs=get;list;watch;patch;update
 // +kubebuilder:rbac:groups=core,resources=namespaces,verbs=get;list;watch;update;patch
 // +kubebuilder:rbac:groups=core,resources=nodes,verbs=get;list;watch
+// +kubebuilder:rbac:groups=core,resources=pods,verbs=get;list
+// +kubebuilder:rbac:groups=core,resources=pods/eviction,verbs=create
+// +kubebuilder:rbac:groups=offloading.liqo.io,resources=clusteroffloadingpolicies,verbs=get;list;watch
+// +kubebuilder:rbac:groups=config.liqo.io,resources=clusterconfigs,verbs=get;list;watch
 
 // NamespaceOffloadingReconciler ownership:
 // --> NamespaceOffloading.Spec.
 // --> NamespaceOffloading.Annotation.
 // --> NamespaceOffloading.Status.RemoteNamespaceName.
+// --> NamespaceOffloading.Status.RemoteNamespacesConditions, only for the Draining conditions.
+// --> NamespaceOffloading.Status.ClusterProfiles.
+// --> NamespaceOffloading.Status.DeniedClusters.
 // --> NamespaceOffloadingController finalizer.
 // --> NamespaceMap.Spec.DesiredMapping, only for my namespace entries.
 
@@ -73,12 +88,11 @@ func (r *NamespaceOffloadingReconciler) Reconcile(ctx context.Context, req ctrl.
 
 	// Get all NamespaceMaps in the cluster and create a Map 'cluster-id : *NamespaceMap'
 	clusterIDMap, err := r.getClusterIDMap(ctx)
-	namespaceMapNumber := len(clusterIDMap)
 	if err != nil {
 		return ctrl.Result{}, err
 	}
 	// There are no NamespaceMap in the cluster
-	if namespaceMapNumber == 0 {
+	if len(clusterIDMap) == 0 {
 		return ctrl.Result{}, nil
 	}
 
@@ -93,19 +107,34 @@ func (r *NamespaceOffloadingReconciler) Reconcile(ctx context.Context, req ctrl.
 			return ctrl.Result{}, err
 		}
 	}
-	// Request creation of remote Namespaces according to the ClusterSelector field.
-	if err := r.enforceClusterSelector(ctx, namespaceOffloading, clusterIDMap); err != nil {
+	// Request creation and deletion of remote Namespaces according to the ClusterSelector field.
+	selected, draining, err := r.enforceClusterSelector(ctx, namespaceOffloading, clusterIDMap)
+	if err != nil {
 		return ctrl.Result{}, err
 	}
 
 	// If there is at least one remote Namespace add liqo scheduling label to the Namespace.
-	if len(clusterIDMap) != namespaceMapNumber {
+	if selected > 0 {
 		// this label will trigger the liqo webhook.
 		if err := addLiqoSchedulingLabel(ctx, r.Client, namespaceOffloading.Namespace); err != nil {
 			return ctrl.Result{}, err
 		}
 	}
 
+	// Recreate the running pods not complying with the offloading policies, if requested.
+	migrating, err := r.migratePods(ctx, namespaceOffloading, false)
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+
+	// Check again the clusters being drained, until all their pods terminated.
+	if draining {
+		return ctrl.Result{RequeueAfter: drainCheckPeriod}, nil
+	}
+	// Check again the progress of the migration, until all the pods comply with the offloading policies.
+	if migrating {
+		return ctrl.Result{RequeueAfter: migrationCheckPeriod}, nil
+	}
 	return ctrl.Result{}, nil
 }
 
@@ -123,10 +152,88 @@ func namespaceOffloadingPredicate() predicate.Predicate {
 	}
 }
 
-// SetupWithManager reconciles NamespaceOffloading Resources.
+// virtualNodePredicate selects the events concerning virtual nodes which may change the set of clusters
+// selected by the ClusterSelectors, i.e. creations, deletions and labels updates.
+func virtualNodePredicate() predicate.Predicate {
+	isVirtualNode := func(obj client.Object) bool {
+		return obj.GetLabels()[liqoconst.TypeLabel] == liqoconst.TypeNode
+	}
+	return predicate.Funcs{
+		UpdateFunc: func(e event.UpdateEvent) bool {
+			return (isVirtualNode(e.ObjectOld) || isVirtualNode(e.ObjectNew)) &&
+				!reflect.DeepEqual(e.ObjectOld.GetLabels(), e.ObjectNew.GetLabels())
+		},
+		CreateFunc: func(e event.CreateEvent) bool {
+			return isVirtualNode(e.Object)
+		},
+		DeleteFunc: func(e event.DeleteEvent) bool {
+			return isVirtualNode(e.Object)
+		},
+		GenericFunc: func(e event.GenericEvent) bool {
+			return false
+		},
+	}
+}
+
+// enqueueNamespaceOffloadings returns a reconcile request for every NamespaceOffloading in the cluster,
+// since any of them could be affected by a change of a virtual node or of a ClusterOffloadingPolicy.
+func (r *NamespaceOffloadingReconciler) enqueueNamespaceOffloadings(_ client.Object) []reconcile.Request {
+	namespaceOffloadings := &offv1alpha1.NamespaceOffloadingList{}
+	if err := r.List(context.TODO(), namespaceOffloadings); err != nil {
+		klog.Errorf("%s --> Unable to list the NamespaceOffloadings", err)
+		return nil
+	}
+
+	requests := make([]reconcile.Request, 0, len(namespaceOffloadings.Items))
+	for i := range namespaceOffloadings.Items {
+		if namespaceOffloadings.Items[i].Name != liqoconst.DefaultNamespaceOffloadingName {
+			continue
+		}
+		requests = append(requests, reconcile.Request{NamespacedName: types.NamespacedName{
+			Namespace: namespaceOffloadings.Items[i].Namespace,
+			Name:      namespaceOffloadings.Items[i].Name,
+		}})
+	}
+	return requests
+}
+
+// namespaceLabelsPredicate selects the updates of the namespace labels, which may change the ClusterOffloadingPolicies
+// the namespaces are selected by.
+func namespaceLabelsPredicate() predicate.Predicate {
+	return predicate.Funcs{
+		UpdateFunc: func(e event.UpdateEvent) bool {
+			return !reflect.DeepEqual(e.ObjectOld.GetLabels(), e.ObjectNew.GetLabels())
+		},
+		CreateFunc: func(e event.CreateEvent) bool {
+			return false
+		},
+		DeleteFunc: func(e event.DeleteEvent) bool {
+			return false
+		},
+		GenericFunc: func(e event.GenericEvent) bool {
+			return false
+		},
+	}
+}
+
+// enqueueNamespaceOffloading returns a reconcile request for the NamespaceOffloading of the given namespace.
+func enqueueNamespaceOffloading(obj client.Object) []reconcile.Request {
+	return []reconcile.Request{{NamespacedName: types.NamespacedName{
+		Namespace: obj.GetName(),
+		Name:      liqoconst.DefaultNamespaceOffloadingName,
+	}}}
+}
+
+// SetupWithManager reconciles NamespaceOffloading Resources, and all of them when a virtual node or a
+// ClusterOffloadingPolicy changes.
 func (r *NamespaceOffloadingReconciler) SetupWithManager(mgr ctrl.Manager) error {
 	return ctrl.NewControllerManagedBy(mgr).
-		For(&offv1alpha1.NamespaceOffloading{}).
-		WithEventFilter(namespaceOffloadingPredicate()).
+		For(&offv1alpha1.NamespaceOffloading{}, builder.WithPredicates(namespaceOffloadingPredicate())).
+		Watches(&source.Kind{Type: &corev1.Node{}}, handler.EnqueueRequestsFromMapFunc(r.enqueueNamespaceOffloadings),
+			builder.WithPredicates(virtualNodePredicate())).
+		Watches(&source.Kind{Type: &offv1alpha1.ClusterOffloadingPolicy{}},
+			handler.EnqueueRequestsFromMapFunc(r.enqueueNamespaceOffloadings)).
+		Watches(&source.Kind{Type: &corev1.Namespace{}}, handler.EnqueueRequestsFromMapFunc(enqueueNamespaceOffloading),
+			builder.WithPredicates(namespaceLabelsPredicate())).
 		Complete(r)
 }
@@ -32,6 +32,7 @@ import (
 
 	offv1alpha1 "github.com/liqotech/liqo/apis/offloading/v1alpha1"
 	liqoconst "github.com/liqotech/liqo/pkg/consts"
+	liqoutils "github.com/liqotech/liqo/pkg/utils"
 )
 
 var _ = Describe("Namespace controller", func() {
@@ -694,6 +695,380 @@ var _ = Describe("Namespace controller", func() {
 
 		})
 
+		It(" TEST 6: Change the ClusterSelector and check that the namespace is withdrawn from the non-matching clusters", func() {
+
+			namespace8Name := "namespace8"
+			namespace8 := &corev1.Namespace{
+				ObjectMeta: metav1.ObjectMeta{
+					Name: namespace8Name,
+				},
+			}
+
+			namespaceOffloading8 := &offv1alpha1.NamespaceOffloading{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      liqoconst.DefaultNamespaceOffloadingName,
+					Namespace: namespace8Name,
+				},
+				Spec: offv1alpha1.NamespaceOffloadingSpec{
+					NamespaceMappingStrategy: offv1alpha1.EnforceSameNameMappingStrategyType,
+					PodOffloadingStrategy:    offv1alpha1.LocalAndRemotePodOffloadingStrategyType,
+					DrainPolicy:              offv1alpha1.KeepUntilEmptyDrainPolicyType,
+					ClusterSelector: corev1.NodeSelector{NodeSelectorTerms: []corev1.NodeSelectorTerm{{
+						MatchExpressions: []corev1.NodeSelectorRequirement{{
+							Key:      regionLabel,
+							Operator: corev1.NodeSelectorOpIn,
+							Values:   []string{regionA},
+						}},
+					}}},
+				},
+			}
+
+			offloadedPod := &corev1.Pod{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "offloaded-pod",
+					Namespace: namespace8Name,
+				},
+				Spec: corev1.PodSpec{
+					NodeName:   virtualNode1Name,
+					Containers: []corev1.Container{{Name: "container", Image: "nginx"}},
+				},
+			}
+
+			hasDesiredMapping := func(clusterID string) bool {
+				Expect(homeClient.List(context.TODO(), nms, client.MatchingLabels{liqoconst.RemoteClusterID: clusterID})).To(Succeed())
+				Expect(len(nms.Items) == 1).To(BeTrue())
+				_, ok := nms.Items[0].Spec.DesiredMapping[namespace8Name]
+				return ok
+			}
+
+			By(fmt.Sprintf(" 1 - Create NamespaceOffloading resource and a pod on the virtual node 1 in Namespace '%s'", namespace8Name))
+			Expect(homeClient.Create(context.TODO(), namespace8)).To(Succeed())
+			Expect(homeClient.Create(context.TODO(), offloadedPod)).To(Succeed())
+			Eventually(func() bool {
+				err := homeClient.Create(context.TODO(), namespaceOffloading8)
+				return err == nil
+			}, timeout, interval).Should(BeTrue())
+
+			By(" 2 - Check that the clusters in region A are selected")
+			Eventually(func() bool {
+				return hasDesiredMapping(remoteClusterId1) && !hasDesiredMapping(remoteClusterId2) && hasDesiredMapping(remoteClusterId3)
+			}, timeout, interval).Should(BeTrue())
+
+			By(" 3 - Change the ClusterSelector to select the GKE clusters")
+			Eventually(func() bool {
+				if err := homeClient.Get(context.TODO(), types.NamespacedName{
+					Name:      liqoconst.DefaultNamespaceOffloadingName,
+					Namespace: namespace8Name}, namespaceOffloading8); err != nil {
+					return false
+				}
+				namespaceOffloading8.Spec.ClusterSelector.NodeSelectorTerms[0].MatchExpressions[0].Key = providerLabel
+				namespaceOffloading8.Spec.ClusterSelector.NodeSelectorTerms[0].MatchExpressions[0].Values = []string{providerGKE}
+				return homeClient.Update(context.TODO(), namespaceOffloading8) == nil
+			}, timeout, interval).Should(BeTrue())
+
+			By(" 4 - Check that the cluster 1 is kept until the pod terminates")
+			Eventually(func() bool {
+				if err := homeClient.Get(context.TODO(), types.NamespacedName{
+					Name:      liqoconst.DefaultNamespaceOffloadingName,
+					Namespace: namespace8Name}, namespaceOffloading8); err != nil {
+					return false
+				}
+				draining := liqoutils.IsStatusConditionTrue(namespaceOffloading8.Status.RemoteNamespacesConditions[remoteClusterId1],
+					offv1alpha1.NamespaceDraining)
+				return draining && hasDesiredMapping(remoteClusterId1) && hasDesiredMapping(remoteClusterId2) &&
+					hasDesiredMapping(remoteClusterId3)
+			}, timeout, interval).Should(BeTrue())
+
+			By(" 5 - Delete the pod and check that the namespace is withdrawn from the cluster 1")
+			Expect(homeClient.Delete(context.TODO(), offloadedPod, client.GracePeriodSeconds(0))).To(Succeed())
+			Eventually(func() bool {
+				if err := homeClient.Get(context.TODO(), types.NamespacedName{
+					Name:      liqoconst.DefaultNamespaceOffloadingName,
+					Namespace: namespace8Name}, namespaceOffloading8); err != nil {
+					return false
+				}
+				_, ok := namespaceOffloading8.Status.RemoteNamespacesConditions[remoteClusterId1]
+				return !ok && !hasDesiredMapping(remoteClusterId1) && hasDesiredMapping(remoteClusterId2) &&
+					hasDesiredMapping(remoteClusterId3)
+			}, timeout, interval).Should(BeTrue())
+
+			By(" 6 - Delete NamespaceOffloading resource")
+			Expect(homeClient.Delete(context.TODO(), namespaceOffloading8)).To(Succeed())
+
+			By(" 7 - Check if there are no DesiredMapping")
+			Eventually(func() bool {
+				return !hasDesiredMapping(remoteClusterId1) && !hasDesiredMapping(remoteClusterId2) &&
+					!hasDesiredMapping(remoteClusterId3)
+			}, timeout, interval).Should(BeTrue())
+
+		})
+
+		It(" TEST 7: Create a NamespaceOffloading resource with offloading profiles and check the cluster profiles", func() {
+
+			namespace9Name := "namespace9"
+			namespace9 := &corev1.Namespace{
+				ObjectMeta: metav1.ObjectMeta{
+					Name: namespace9Name,
+				},
+			}
+
+			namespaceOffloading9 := &offv1alpha1.NamespaceOffloading{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      liqoconst.DefaultNamespaceOffloadingName,
+					Namespace: namespace9Name,
+				},
+				Spec: offv1alpha1.NamespaceOffloadingSpec{
+					NamespaceMappingStrategy: offv1alpha1.EnforceSameNameMappingStrategyType,
+					PodOffloadingStrategy:    offv1alpha1.LocalAndRemotePodOffloadingStrategyType,
+					ClusterSelector: corev1.NodeSelector{NodeSelectorTerms: []corev1.NodeSelectorTerm{{
+						MatchExpressions: []corev1.NodeSelectorRequirement{{
+							Key:      regionLabel,
+							Operator: corev1.NodeSelectorOpIn,
+							Values:   []string{regionA},
+						}},
+					}}},
+					Profiles: []offv1alpha1.OffloadingProfile{{
+						Name:                  "gke",
+						PodSelector:           metav1.LabelSelector{MatchLabels: map[string]string{"tier": "gke"}},
+						PodOffloadingStrategy: offv1alpha1.RemotePodOffloadingStrategyType,
+						ClusterSelector: corev1.NodeSelector{NodeSelectorTerms: []corev1.NodeSelectorTerm{{
+							MatchExpressions: []corev1.NodeSelectorRequirement{{
+								Key:      providerLabel,
+								Operator: corev1.NodeSelectorOpIn,
+								Values:   []string{providerGKE},
+							}},
+						}}},
+					}, {
+						Name:                  "local",
+						PodSelector:           metav1.LabelSelector{MatchLabels: map[string]string{"tier": "local"}},
+						PodOffloadingStrategy: offv1alpha1.LocalPodOffloadingStrategyType,
+					}},
+				},
+			}
+
+			hasDesiredMapping := func(clusterID string) bool {
+				Expect(homeClient.List(context.TODO(), nms, client.MatchingLabels{liqoconst.RemoteClusterID: clusterID})).To(Succeed())
+				Expect(len(nms.Items) == 1).To(BeTrue())
+				_, ok := nms.Items[0].Spec.DesiredMapping[namespace9Name]
+				return ok
+			}
+
+			By(fmt.Sprintf(" 1 - Create NamespaceOffloading resource in Namespace '%s'", namespace9Name))
+			Expect(homeClient.Create(context.TODO(), namespace9)).To(Succeed())
+			Eventually(func() bool {
+				err := homeClient.Create(context.TODO(), namespaceOffloading9)
+				return err == nil
+			}, timeout, interval).Should(BeTrue())
+
+			By(" 2 - Check that the clusters selected by at least one profile have a DesiredMapping")
+			Eventually(func() bool {
+				return hasDesiredMapping(remoteClusterId1) && hasDesiredMapping(remoteClusterId2) && hasDesiredMapping(remoteClusterId3)
+			}, timeout, interval).Should(BeTrue())
+
+			By(" 3 - Check the profiles each cluster belongs to")
+			Eventually(func() map[string][]string {
+				if err := homeClient.Get(context.TODO(), types.NamespacedName{
+					Name:      liqoconst.DefaultNamespaceOffloadingName,
+					Namespace: namespace9Name}, namespaceOffloading9); err != nil {
+					return nil
+				}
+				return namespaceOffloading9.Status.ClusterProfiles
+			}, timeout, interval).Should(Equal(map[string][]string{
+				remoteClusterId1: {offv1alpha1.DefaultOffloadingProfileName},
+				remoteClusterId2: {"gke"},
+				remoteClusterId3: {offv1alpha1.DefaultOffloadingProfileName, "gke"},
+			}))
+
+			By(" 4 - Delete NamespaceOffloading resource")
+			Expect(homeClient.Delete(context.TODO(), namespaceOffloading9)).To(Succeed())
+
+			By(" 5 - Check if there are no DesiredMapping")
+			Eventually(func() bool {
+				return !hasDesiredMapping(remoteClusterId1) && !hasDesiredMapping(remoteClusterId2) &&
+					!hasDesiredMapping(remoteClusterId3)
+			}, timeout, interval).Should(BeTrue())
+
+		})
+
+		It(" TEST 8: Enable the migration and check that the pods not complying with the strategy are recreated", func() {
+
+			namespace10Name := "namespace10"
+			namespace10 := &corev1.Namespace{
+				ObjectMeta: metav1.ObjectMeta{
+					Name: namespace10Name,
+				},
+			}
+
+			namespaceOffloading10 := &offv1alpha1.NamespaceOffloading{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      liqoconst.DefaultNamespaceOffloadingName,
+					Namespace: namespace10Name,
+				},
+				Spec: offv1alpha1.NamespaceOffloadingSpec{
+					NamespaceMappingStrategy: offv1alpha1.EnforceSameNameMappingStrategyType,
+					PodOffloadingStrategy:    offv1alpha1.RemotePodOffloadingStrategyType,
+					Migration:                &offv1alpha1.MigrationPolicy{BatchSize: 1},
+				},
+			}
+
+			isController := true
+			managedPod := &corev1.Pod{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "managed-pod",
+					Namespace: namespace10Name,
+					OwnerReferences: []metav1.OwnerReference{{
+						APIVersion: "apps/v1",
+						Kind:       "ReplicaSet",
+						Name:       "replicaset",
+						UID:        "a4b0d3f4-6a2b-4a36-9d1c-3e1b6a8b1c2d",
+						Controller: &isController,
+					}},
+				},
+				Spec: corev1.PodSpec{
+					NodeName:   "local-node",
+					Containers: []corev1.Container{{Name: "container", Image: "nginx"}},
+				},
+			}
+
+			unmanagedPod := &corev1.Pod{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "unmanaged-pod",
+					Namespace: namespace10Name,
+				},
+				Spec: corev1.PodSpec{
+					NodeName:   "local-node",
+					Containers: []corev1.Container{{Name: "container", Image: "nginx"}},
+				},
+			}
+
+			getMigrationStatus := func() *offv1alpha1.MigrationStatus {
+				if err := homeClient.Get(context.TODO(), types.NamespacedName{
+					Name:      liqoconst.DefaultNamespaceOffloadingName,
+					Namespace: namespace10Name}, namespaceOffloading10); err != nil {
+					return nil
+				}
+				return namespaceOffloading10.Status.Migration
+			}
+
+			By(fmt.Sprintf(" 1 - Create the pods and the NamespaceOffloading resource in Namespace '%s'", namespace10Name))
+			Expect(homeClient.Create(context.TODO(), namespace10)).To(Succeed())
+			Expect(homeClient.Create(context.TODO(), managedPod)).To(Succeed())
+			Expect(homeClient.Create(context.TODO(), unmanagedPod)).To(Succeed())
+			Eventually(func() bool {
+				err := homeClient.Create(context.TODO(), namespaceOffloading10)
+				return err == nil
+			}, timeout, interval).Should(BeTrue())
+
+			By(" 2 - Check that the managed pod is evicted and the unmanaged one is reported")
+			Eventually(func() bool {
+				status := getMigrationStatus()
+				return status != nil && status.Phase == offv1alpha1.MigrationInProgressPhaseType &&
+					status.MigratingPods == 1 && status.UnmanagedPods == 1
+			}, timeout, interval).Should(BeTrue())
+			Expect(homeClient.Get(context.TODO(), client.ObjectKeyFromObject(managedPod), managedPod)).To(Succeed())
+			Expect(managedPod.DeletionTimestamp.IsZero()).To(BeFalse())
+
+			By(" 3 - Terminate the evicted pod and check that the migration completes")
+			Expect(homeClient.Delete(context.TODO(), managedPod, client.GracePeriodSeconds(0))).To(Succeed())
+			Eventually(func() bool {
+				status := getMigrationStatus()
+				return status != nil && status.Phase == offv1alpha1.MigrationCompletedPhaseType &&
+					status.MigratingPods == 0 && status.PendingPods == 0 && status.UnmanagedPods == 1
+			}, timeout, interval).Should(BeTrue())
+
+			By(" 4 - Delete NamespaceOffloading resource")
+			Expect(homeClient.Delete(context.TODO(), unmanagedPod, client.GracePeriodSeconds(0))).To(Succeed())
+			Expect(homeClient.Delete(context.TODO(), namespaceOffloading10)).To(Succeed())
+			Eventually(func() bool {
+				err := homeClient.Get(context.TODO(), client.ObjectKeyFromObject(namespaceOffloading10), namespaceOffloading10)
+				return apierrors.IsNotFound(err)
+			}, timeout, interval).Should(BeTrue())
+
+		})
+
+		It(" TEST 9: Create a ClusterOffloadingPolicy and check that the namespace is offloaded only to the allowed clusters", func() {
+
+			namespace11Name := "namespace11"
+			namespace11 := &corev1.Namespace{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:   namespace11Name,
+					Labels: map[string]string{"team": "a"},
+				},
+			}
+
+			namespaceOffloading11 := &offv1alpha1.NamespaceOffloading{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      liqoconst.DefaultNamespaceOffloadingName,
+					Namespace: namespace11Name,
+				},
+				Spec: offv1alpha1.NamespaceOffloadingSpec{
+					NamespaceMappingStrategy: offv1alpha1.EnforceSameNameMappingStrategyType,
+					PodOffloadingStrategy:    offv1alpha1.LocalAndRemotePodOffloadingStrategyType,
+				},
+			}
+
+			policy := &offv1alpha1.ClusterOffloadingPolicy{
+				ObjectMeta: metav1.ObjectMeta{
+					Name: "team-a",
+				},
+				Spec: offv1alpha1.ClusterOffloadingPolicySpec{
+					NamespaceSelector: metav1.LabelSelector{MatchLabels: map[string]string{"team": "a"}},
+					AllowedClusters:   []string{remoteClusterId1},
+				},
+			}
+
+			hasDesiredMapping := func(clusterID string) bool {
+				Expect(homeClient.List(context.TODO(), nms, client.MatchingLabels{liqoconst.RemoteClusterID: clusterID})).To(Succeed())
+				Expect(len(nms.Items) == 1).To(BeTrue())
+				_, ok := nms.Items[0].Spec.DesiredMapping[namespace11Name]
+				return ok
+			}
+
+			getDeniedClusters := func() []string {
+				Expect(homeClient.Get(context.TODO(), client.ObjectKeyFromObject(namespaceOffloading11),
+					namespaceOffloading11)).To(Succeed())
+				return namespaceOffloading11.Status.DeniedClusters
+			}
+
+			By(fmt.Sprintf(" 1 - Create the ClusterOffloadingPolicy and the NamespaceOffloading in Namespace '%s'", namespace11Name))
+			Expect(homeClient.Create(context.TODO(), policy)).To(Succeed())
+			Expect(homeClient.Create(context.TODO(), namespace11)).To(Succeed())
+			Eventually(func() bool {
+				err := homeClient.Create(context.TODO(), namespaceOffloading11)
+				return err == nil
+			}, timeout, interval).Should(BeTrue())
+
+			By(" 2 - Check that the namespace is offloaded only to the allowed cluster")
+			Eventually(func() bool {
+				return hasDesiredMapping(remoteClusterId1) && !hasDesiredMapping(remoteClusterId2) && !hasDesiredMapping(remoteClusterId3)
+			}, timeout, interval).Should(BeTrue())
+			Eventually(getDeniedClusters, timeout, interval).Should(ConsistOf(remoteClusterId2, remoteClusterId3))
+
+			By(" 3 - Allow another cluster and check that the namespace is offloaded also there")
+			Expect(homeClient.Get(context.TODO(), client.ObjectKeyFromObject(policy), policy)).To(Succeed())
+			policy.Spec.AllowedClusters = append(policy.Spec.AllowedClusters, remoteClusterId2)
+			Expect(homeClient.Update(context.TODO(), policy)).To(Succeed())
+			Eventually(func() bool {
+				return hasDesiredMapping(remoteClusterId1) && hasDesiredMapping(remoteClusterId2) && !hasDesiredMapping(remoteClusterId3)
+			}, timeout, interval).Should(BeTrue())
+			Eventually(getDeniedClusters, timeout, interval).Should(ConsistOf(remoteClusterId3))
+
+			By(" 4 - Delete the ClusterOffloadingPolicy and check that the offloading is no longer restricted")
+			Expect(homeClient.Delete(context.TODO(), policy)).To(Succeed())
+			Eventually(func() bool {
+				return hasDesiredMapping(remoteClusterId1) && hasDesiredMapping(remoteClusterId2) && hasDesiredMapping(remoteClusterId3)
+			}, timeout, interval).Should(BeTrue())
+			Eventually(getDeniedClusters, timeout, interval).Should(BeEmpty())
+
+			By(" 5 - Delete NamespaceOffloading resource")
+			Expect(homeClient.Delete(context.TODO(), namespaceOffloading11)).To(Succeed())
+			Eventually(func() bool {
+				err := homeClient.Get(context.TODO(), client.ObjectKeyFromObject(namespaceOffloading11), namespaceOffloading11)
+				return apierrors.IsNotFound(err)
+			}, timeout, interval).Should(BeTrue())
+
+		})
+
 	})
 
 })
@@ -9,9 +9,11 @@ import (
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	ctrlutils "sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
 
+	configv1alpha1 "github.com/liqotech/liqo/apis/config/v1alpha1"
 	offv1alpha1 "github.com/liqotech/liqo/apis/offloading/v1alpha1"
 	mapsv1alpha1 "github.com/liqotech/liqo/apis/virtualKubelet/v1alpha1"
 	liqoconst "github.com/liqotech/liqo/pkg/consts"
+	namespacenaming "github.com/liqotech/liqo/pkg/namespaceNaming"
 )
 
 func (r *NamespaceOffloadingReconciler) deletionLogic(ctx context.Context,
@@ -21,17 +23,27 @@ func (r *NamespaceOffloadingReconciler) deletionLogic(ctx context.Context,
 	if err := removeLiqoSchedulingLabel(ctx, r.Client, noff.Namespace); err != nil {
 		return err
 	}
-	// 2 - remove the involved DesiredMapping from the NamespaceMap.
+	// 2 - bring the offloaded pods back to the local cluster, if the migration is requested.
+	migrating, err := r.migratePods(ctx, noff, true)
+	if err != nil {
+		return err
+	}
+	if migrating {
+		err = fmt.Errorf("waiting for the pods to be migrated back to the local cluster")
+		klog.Info(err)
+		return err
+	}
+	// 3 - remove the involved DesiredMapping from the NamespaceMap.
 	if err := removeDesiredMappings(ctx, r.Client, noff.Namespace, clusterIDMap); err != nil {
 		return err
 	}
-	// 3 - check if all remote namespaces associated with this NamespaceOffloading resource are really deleted.
+	// 4 - check if all remote namespaces associated with this NamespaceOffloading resource are really deleted.
 	if len(noff.Status.RemoteNamespacesConditions) != 0 {
 		err := fmt.Errorf("waiting for remote namespaces deletion")
 		klog.Info(err)
 		return err
 	}
-	// 4 - remove NamespaceOffloading controller finalizer; all remote namespaces associated with this resource
+	// 5 - remove NamespaceOffloading controller finalizer; all remote namespaces associated with this resource
 	// have been deleted.
 	original := noff.DeepCopy()
 	ctrlutils.RemoveFinalizer(noff, namespaceOffloadingControllerFinalizer)
@@ -64,11 +76,19 @@ func (r *NamespaceOffloadingReconciler) initialConfiguration(ctx context.Context
 		}}
 	}
 	// 3 - Add NamespaceOffloading.Status.RemoteNamespaceName.
-	if noff.Spec.NamespaceMappingStrategy == offv1alpha1.EnforceSameNameMappingStrategyType {
-		noff.Status.RemoteNamespaceName = noff.Namespace
-	} else {
-		noff.Status.RemoteNamespaceName = fmt.Sprintf("%s-%s", noff.Namespace, r.LocalClusterID)
+	var localClusterName string
+	if noff.Spec.NamespaceMappingStrategy == offv1alpha1.TemplateNameMappingStrategyType {
+		var err error
+		if localClusterName, err = r.getLocalClusterName(ctx); err != nil {
+			return err
+		}
 	}
+	remoteNamespaceName, err := namespacenaming.RemoteNamespaceName(noff, r.LocalClusterID, localClusterName)
+	if err != nil {
+		klog.Errorf("%s --> Unable to generate the remote namespace name for the namespace '%s'", err, noff.Namespace)
+		return err
+	}
+	noff.Status.RemoteNamespaceName = remoteNamespaceName
 	// 4 - Patch the NamespaceOffloading resource.
 	if err := r.Patch(ctx, noff, client.MergeFrom(patch)); err != nil {
 		klog.Errorf("%s --> Unable to update NamespaceOffloading in namespace '%s'",
@@ -77,3 +97,18 @@ func (r *NamespaceOffloadingReconciler) initialConfiguration(ctx context.Context
 	}
 	return nil
 }
+
+// getLocalClusterName returns the name of the local cluster, as set in the ClusterConfig.
+func (r *NamespaceOffloadingReconciler) getLocalClusterName(ctx context.Context) (string, error) {
+	var clusterConfigs configv1alpha1.ClusterConfigList
+	if err := r.List(ctx, &clusterConfigs); err != nil {
+		klog.Errorf("%s --> Unable to get the ClusterConfig", err)
+		return "", err
+	}
+	if len(clusterConfigs.Items) == 0 {
+		err := fmt.Errorf("no ClusterConfig found")
+		klog.Error(err)
+		return "", err
+	}
+	return clusterConfigs.Items[0].Spec.DiscoveryConfig.ClusterName, nil
+}
@@ -0,0 +1,54 @@
+package namespaceoffloadingctrl
+
+import (
+	corev1 "k8s.io/api/core/v1"
+	k8shelper "k8s.io/component-helpers/scheduling/corev1"
+
+	offv1alpha1 "github.com/liqotech/liqo/apis/offloading/v1alpha1"
+)
+
+// matchingProfiles returns the names of the offloading profiles selecting the given virtual node. The default profile
+// is defined by the top-level ClusterSelector, while the profiles keeping their pods local are not considered.
+func matchingProfiles(noff *offv1alpha1.NamespaceOffloading, virtualNode *corev1.Node) ([]string, error) {
+	var profiles []string
+
+	match, err := k8shelper.MatchNodeSelectorTerms(virtualNode, &noff.Spec.ClusterSelector)
+	if err != nil {
+		return nil, err
+	}
+	if match {
+		profiles = append(profiles, offv1alpha1.DefaultOffloadingProfileName)
+	}
+
+	for i := range noff.Spec.Profiles {
+		profile := &noff.Spec.Profiles[i]
+		if profile.PodOffloadingStrategy == offv1alpha1.LocalPodOffloadingStrategyType {
+			continue
+		}
+
+		// The profiles without ClusterSelector inherit the top-level one.
+		clusterSelector := &profile.ClusterSelector
+		if len(clusterSelector.NodeSelectorTerms) == 0 {
+			clusterSelector = &noff.Spec.ClusterSelector
+		}
+		if match, err = k8shelper.MatchNodeSelectorTerms(virtualNode, clusterSelector); err != nil {
+			return nil, err
+		}
+		if match {
+			profiles = append(profiles, profile.Name)
+		}
+	}
+	return profiles, nil
+}
+
+// setClusterProfiles records the offloading profiles selecting the given cluster, removing the entry if none.
+func setClusterProfiles(noff *offv1alpha1.NamespaceOffloading, clusterID string, profiles []string) {
+	if len(profiles) == 0 {
+		delete(noff.Status.ClusterProfiles, clusterID)
+		return
+	}
+	if noff.Status.ClusterProfiles == nil {
+		noff.Status.ClusterProfiles = map[string][]string{}
+	}
+	noff.Status.ClusterProfiles[clusterID] = profiles
+}
@@ -23,6 +23,7 @@ import (
 	. "github.com/onsi/gomega"
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
 	"k8s.io/client-go/kubernetes/scheme"
 	"k8s.io/client-go/rest"
 	"k8s.io/klog/v2"
@@ -125,6 +126,7 @@ var _ = BeforeSuite(func(done Done) {
 
 	err = (&NamespaceOffloadingReconciler{
 		Client:         homeClient,
+		ClientSet:      kubernetes.NewForConfigOrDie(homeCfg),
 		Scheme:         k8sManager.GetScheme(),
 		LocalClusterID: localClusterId,
 	}).SetupWithManager(k8sManager)
@@ -48,6 +48,48 @@ func mapPhaseToRemoteNamespaceCondition(phase mapsv1alpha1.MappingPhase) offv1al
 	return remoteCondition
 }
 
+// mapReflectionToRemoteNamespaceCondition returns the ReflectionReady remote condition according to the
+// reflection status signalled by the virtual kubelet by means of NamespaceMap.Status.ReflectionStatus.
+func mapReflectionToRemoteNamespaceCondition(ready bool) offv1alpha1.RemoteNamespaceCondition {
+	if ready {
+		return offv1alpha1.RemoteNamespaceCondition{
+			Type:    offv1alpha1.NamespaceReflectionReady,
+			Status:  corev1.ConditionTrue,
+			Reason:  "ReflectionReady",
+			Message: "The resources of the Namespace have been reflected on this cluster",
+		}
+	}
+	return offv1alpha1.RemoteNamespaceCondition{
+		Type:    offv1alpha1.NamespaceReflectionReady,
+		Status:  corev1.ConditionFalse,
+		Reason:  "ReflectionInProgress",
+		Message: "The resources of the Namespace are being reflected on this cluster",
+	}
+}
+
+// assignClusterReflectionCondition sets the ReflectionReady remote namespace condition, which is present only while
+// the remote namespace is correctly created and the virtual kubelet has signalled the status of its reflection.
+func assignClusterReflectionCondition(noff *offv1alpha1.NamespaceOffloading, phase mapsv1alpha1.MappingPhase,
+	reflection *mapsv1alpha1.NamespaceReflectionStatus, clusterID string) {
+	var remoteConditions []offv1alpha1.RemoteNamespaceCondition = noff.Status.RemoteNamespacesConditions[clusterID]
+	if phase != mapsv1alpha1.MappingAccepted || reflection == nil {
+		if liqoutils.FindRemoteNamespaceCondition(remoteConditions, offv1alpha1.NamespaceReflectionReady) != nil {
+			liqoutils.RemoveRemoteNamespaceCondition(&remoteConditions, offv1alpha1.NamespaceReflectionReady)
+			noff.Status.RemoteNamespacesConditions[clusterID] = remoteConditions
+		}
+		return
+	}
+
+	newCondition := mapReflectionToRemoteNamespaceCondition(reflection.Ready)
+	if liqoutils.IsStatusConditionPresentAndEqual(remoteConditions, newCondition.Type, newCondition.Status) {
+		return
+	}
+	liqoutils.AddRemoteNamespaceCondition(&remoteConditions, &newCondition)
+	noff.Status.RemoteNamespacesConditions[clusterID] = remoteConditions
+	klog.Infof("Remote condition of type '%s' with Status '%s' for the remote namespace '%s' associated with the cluster '%s'",
+		newCondition.Type, newCondition.Status, noff.Namespace, clusterID)
+}
+
 // assignClusterRemoteCondition sets the right remote namespace condition according to the remote namespace phase
 // written in NamespaceMap.Status.CurrentMapping.
 // If phase==nil the remote namespace condition OffloadingRequired=False is set.
@@ -63,6 +105,11 @@ func assignClusterRemoteCondition(noff *offv1alpha1.NamespaceOffloading, phase m
 	}
 	var remoteConditions []offv1alpha1.RemoteNamespaceCondition
 	liqoutils.AddRemoteNamespaceCondition(&remoteConditions, &newCondition)
+	// The Draining condition is owned by the NamespaceOffloading controller, hence it is preserved.
+	if draining := liqoutils.FindRemoteNamespaceCondition(noff.Status.RemoteNamespacesConditions[clusterID],
+		offv1alpha1.NamespaceDraining); draining != nil {
+		remoteConditions = append(remoteConditions, *draining)
+	}
 	noff.Status.RemoteNamespacesConditions[clusterID] = remoteConditions
 	klog.Infof("Remote condition of type '%s' with Status '%s' for the remote namespace '%s' associated with the cluster '%s'",
 		remoteConditions[0].Type, remoteConditions[0].Status, noff.Namespace, clusterID)
@@ -76,8 +123,14 @@ func assignClusterRemoteCondition(noff *offv1alpha1.NamespaceOffloading, phase m
 // is not requested to be offloaded inside this cluster.
 func setRemoteConditionsForEveryCluster(noff *offv1alpha1.NamespaceOffloading, nml *mapsv1alpha1.NamespaceMapList) {
 	for i := range nml.Items {
+		clusterID := nml.Items[i].Labels[liqoconst.RemoteClusterID]
 		if remoteNamespaceStatus, ok := nml.Items[i].Status.CurrentMapping[noff.Namespace]; ok {
-			assignClusterRemoteCondition(noff, remoteNamespaceStatus.Phase, nml.Items[i].Labels[liqoconst.RemoteClusterID])
+			assignClusterRemoteCondition(noff, remoteNamespaceStatus.Phase, clusterID)
+			var reflection *mapsv1alpha1.NamespaceReflectionStatus
+			if status, found := nml.Items[i].Status.ReflectionStatus[noff.Namespace]; found {
+				reflection = &status
+			}
+			assignClusterReflectionCondition(noff, remoteNamespaceStatus.Phase, reflection, clusterID)
 			continue
 		}
 		// Two cases in which there are no entry in NamespaceMap Status:
@@ -0,0 +1,102 @@
+package resourceoffercontroller
+
+import (
+	"fmt"
+
+	"k8s.io/kubernetes/pkg/util/slice"
+
+	configv1alpha1 "github.com/liqotech/liqo/apis/config/v1alpha1"
+	sharingv1alpha1 "github.com/liqotech/liqo/apis/sharing/v1alpha1"
+)
+
+// Reasons set in the status of the ResourceOffers, describing the acceptance decision.
+const (
+	reasonAutoAccepted          = "AutoAccepted"
+	reasonManualActionRequired  = "ManualActionRequired"
+	reasonUntrustedCluster      = "UntrustedCluster"
+	reasonMissingRequiredLabel  = "MissingRequiredLabel"
+	reasonInsufficientResources = "InsufficientResources"
+	reasonExceedingResources    = "ExceedingResources"
+	reasonPriceTooHigh          = "PriceTooHigh"
+)
+
+// acceptanceDecision contains the outcome of the evaluation of the acceptance rules.
+type acceptanceDecision struct {
+	accepted bool
+	reason   string
+	message  string
+}
+
+// evaluateAcceptanceRules checks if the ResourceOffer satisfies the acceptance rules,
+// returning the rule that it failed otherwise.
+func evaluateAcceptanceRules(rules *configv1alpha1.OfferAcceptanceRules,
+	resourceOffer *sharingv1alpha1.ResourceOffer) *acceptanceDecision {
+	if rules == nil {
+		return &acceptanceDecision{accepted: true}
+	}
+	clusterID := resourceOffer.Spec.ClusterId
+
+	if len(rules.TrustedClusterIDs) > 0 && !slice.ContainsString(rules.TrustedClusterIDs, clusterID, nil) {
+		return &acceptanceDecision{
+			reason:  reasonUntrustedCluster,
+			message: fmt.Sprintf("the cluster %v is not in the trusted ones", clusterID),
+		}
+	}
+
+	for key, value := range rules.RequiredLabels {
+		if current, ok := resourceOffer.Spec.Labels[key]; !ok || current != value {
+			return &acceptanceDecision{
+				reason:  reasonMissingRequiredLabel,
+				message: fmt.Sprintf("the cluster %v does not expose the required label %v=%v", clusterID, key, value),
+			}
+		}
+	}
+
+	offered := resourceOffer.Spec.ResourceQuota.Hard
+	for resourceName, minQuantity := range rules.MinResources {
+		if quantity, ok := offered[resourceName]; !ok || quantity.Cmp(minQuantity) < 0 {
+			return &acceptanceDecision{
+				reason: reasonInsufficientResources,
+				message: fmt.Sprintf("the offered %v (%v) is lower than the minimum required (%v)",
+					resourceName, quantity.String(), minQuantity.String()),
+			}
+		}
+	}
+
+	for resourceName, maxQuantity := range rules.MaxResources {
+		if quantity, ok := offered[resourceName]; ok && quantity.Cmp(maxQuantity) > 0 {
+			return &acceptanceDecision{
+				reason: reasonExceedingResources,
+				message: fmt.Sprintf("the offered %v (%v) is greater than the maximum allowed (%v)",
+					resourceName, quantity.String(), maxQuantity.String()),
+			}
+		}
+	}
+
+	for resourceName, maxPrice := range rules.MaxPrices {
+		if price, ok := resourceOffer.Spec.Prices[resourceName]; ok && price.Cmp(maxPrice) > 0 {
+			return &acceptanceDecision{
+				reason: reasonPriceTooHigh,
+				message: fmt.Sprintf("the price of %v (%v) is greater than the maximum allowed (%v)",
+					resourceName, price.String(), maxPrice.String()),
+			}
+		}
+	}
+
+	return &acceptanceDecision{accepted: true}
+}
+
+// isRefusedByAcceptanceRules checks if a ResourceOffer has been refused since it did not satisfy the acceptance rules,
+// as opposed to the ones manually refused.
+func isRefusedByAcceptanceRules(resourceOffer *sharingv1alpha1.ResourceOffer) bool {
+	if resourceOffer.Status.Phase != sharingv1alpha1.ResourceOfferRefused {
+		return false
+	}
+	switch resourceOffer.Status.Reason {
+	case reasonUntrustedCluster, reasonMissingRequiredLabel, reasonInsufficientResources,
+		reasonExceedingResources, reasonPriceTooHigh:
+		return true
+	default:
+		return false
+	}
+}
@@ -0,0 +1,85 @@
+package resourceoffercontroller
+
+import (
+	"fmt"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/klog/v2"
+
+	sharingv1alpha1 "github.com/liqotech/liqo/apis/sharing/v1alpha1"
+)
+
+// setExpirationStatus checks if the ResourceOffer has been renewed before the end of its TimeToLive and sets its
+// expiration status accordingly. It returns the time after which the status has to be checked again, or zero if no
+// change is expected until a new renewal.
+func (r *ResourceOfferReconciler) setExpirationStatus(resourceOffer *sharingv1alpha1.ResourceOffer) time.Duration {
+	gracePeriod := r.getConfig().Spec.AdvertisementConfig.IngoingConfig.ExpiredOfferGracePeriod
+	status, nextCheck := getExpirationStatus(resourceOffer, gracePeriod, time.Now())
+
+	if status != resourceOffer.Status.ExpirationStatus {
+		var msg string
+		switch status {
+		case sharingv1alpha1.ResourceOfferValid:
+			msg = fmt.Sprintf("[%v] ResourceOffer renewed", resourceOffer.Spec.ClusterId)
+		case sharingv1alpha1.ResourceOfferExpired:
+			msg = fmt.Sprintf("[%v] ResourceOffer expired at %v, cordoning the virtual node",
+				resourceOffer.Spec.ClusterId, resourceOffer.Spec.TimeToLive)
+		case sharingv1alpha1.ResourceOfferWithdrawn:
+			msg = fmt.Sprintf("[%v] ResourceOffer not renewed within the grace period, draining the virtual node",
+				resourceOffer.Spec.ClusterId)
+		}
+		klog.Info(msg)
+		r.eventsRecorder.Event(resourceOffer, "Normal", "Offer"+string(status), msg)
+		resourceOffer.Status.ExpirationStatus = status
+	}
+	return nextCheck
+}
+
+// getExpirationStatus returns the expiration status of the ResourceOffer at the given time instant, and the time
+// after which it will change. The status depends on the current TimeToLive, hence a withdrawn offer which is
+// renewed later becomes valid again. Yet, the withdrawal is kept as long as the VirtualKubelet Deployment exists,
+// since the virtual node is being drained and it cannot be reused: the VirtualKubelet is recreated from scratch
+// once the previous one has been deleted.
+func getExpirationStatus(resourceOffer *sharingv1alpha1.ResourceOffer,
+	gracePeriod *metav1.Duration, now time.Time) (sharingv1alpha1.OfferExpirationStatus, time.Duration) {
+	status, nextCheck := getTimeToLiveStatus(resourceOffer, gracePeriod, now)
+	if status != sharingv1alpha1.ResourceOfferWithdrawn &&
+		resourceOffer.Status.ExpirationStatus == sharingv1alpha1.ResourceOfferWithdrawn &&
+		isVirtualKubeletDeployed(resourceOffer) {
+		return sharingv1alpha1.ResourceOfferWithdrawn, 0
+	}
+	return status, nextCheck
+}
+
+// isVirtualKubeletDeployed returns whether the VirtualKubelet Deployment of the ResourceOffer has been created
+// and not yet deleted.
+func isVirtualKubeletDeployed(resourceOffer *sharingv1alpha1.ResourceOffer) bool {
+	switch resourceOffer.Status.VirtualKubeletStatus {
+	case sharingv1alpha1.VirtualKubeletStatusCreated, sharingv1alpha1.VirtualKubeletStatusDeleting:
+		return true
+	default:
+		return false
+	}
+}
+
+// getTimeToLiveStatus returns the expiration status of the ResourceOffer at the given time instant, only depending
+// on its TimeToLive, and the time after which it will change.
+func getTimeToLiveStatus(resourceOffer *sharingv1alpha1.ResourceOffer,
+	gracePeriod *metav1.Duration, now time.Time) (sharingv1alpha1.OfferExpirationStatus, time.Duration) {
+	expiration := resourceOffer.Spec.TimeToLive.Time
+	if expiration.IsZero() {
+		return sharingv1alpha1.ResourceOfferValid, 0
+	}
+	if now.Before(expiration) {
+		return sharingv1alpha1.ResourceOfferValid, expiration.Sub(now)
+	}
+
+	if gracePeriod == nil {
+		return sharingv1alpha1.ResourceOfferExpired, 0
+	}
+	if withdrawal := expiration.Add(gracePeriod.Duration); now.Before(withdrawal) {
+		return sharingv1alpha1.ResourceOfferExpired, withdrawal.Sub(now)
+	}
+	return sharingv1alpha1.ResourceOfferWithdrawn, 0
+}
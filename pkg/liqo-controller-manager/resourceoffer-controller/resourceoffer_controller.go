@@ -64,6 +64,8 @@ type ResourceOfferReconciler struct {
 	resyncPeriod       time.Duration
 	configuration      *configv1alpha1.ClusterConfig
 	configurationMutex sync.RWMutex
+	// configEvents is used to enqueue the ResourceOffers to be evaluated again after a change of the ingoing configuration.
+	configEvents chan event.GenericEvent
 }
 
 //+kubebuilder:rbac:groups=sharing.liqo.io,resources=resourceoffers,verbs=get;list;watch;create;update;patch;delete
@@ -117,6 +119,11 @@ func (r *ResourceOfferReconciler) Reconcile(ctx context.Context, req ctrl.Reques
 		return ctrl.Result{}, err
 	}
 
+	// treat the ResourceOffers not renewed in time as withdrawn
+	if nextCheck := r.setExpirationStatus(&resourceOffer); nextCheck > 0 && nextCheck < result.RequeueAfter {
+		result.RequeueAfter = nextCheck
+	}
+
 	// check the virtual kubelet deployment
 	if err = r.checkVirtualKubeletDeployment(ctx, &resourceOffer); err != nil {
 		klog.Error(err)
@@ -179,10 +186,12 @@ func (r *ResourceOfferReconciler) SetupWithManager(mgr ctrl.Manager) error {
 		return err
 	}
 
+	r.configEvents = make(chan event.GenericEvent)
 	return ctrl.NewControllerManagedBy(mgr).
 		For(&sharingv1alpha1.ResourceOffer{}, builder.WithPredicates(p)).
 		Watches(&source.Kind{Type: &v1.Deployment{}},
 			getVirtualKubeletEventHandler(), builder.WithPredicates(deployPredicate)).
+		Watches(&source.Channel{Source: r.configEvents}, &handler.EnqueueRequestForObject{}).
 		Complete(r)
 }
 
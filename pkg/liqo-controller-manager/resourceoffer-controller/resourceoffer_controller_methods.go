@@ -11,9 +11,11 @@ import (
 	"k8s.io/klog/v2"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/event"
 
 	configv1alpha1 "github.com/liqotech/liqo/apis/config/v1alpha1"
 	sharingv1alpha1 "github.com/liqotech/liqo/apis/sharing/v1alpha1"
+	crdreplicator "github.com/liqotech/liqo/internal/crdReplicator"
 	"github.com/liqotech/liqo/pkg/consts"
 	crdclient "github.com/liqotech/liqo/pkg/crdClient"
 	"github.com/liqotech/liqo/pkg/utils"
@@ -46,10 +48,38 @@ func (r *ResourceOfferReconciler) setConfig(config *configv1alpha1.ClusterConfig
 		return
 	}
 	if !reflect.DeepEqual(r.configuration, config) {
+		ingoingChanged := !reflect.DeepEqual(r.configuration.Spec.AdvertisementConfig.IngoingConfig,
+			config.Spec.AdvertisementConfig.IngoingConfig)
 		r.configuration = config
+		if ingoingChanged {
+			// the acceptance decisions have to be evaluated again with the new rules.
+			r.enqueueResourceOffers()
+		}
 	}
 }
 
+// enqueueResourceOffers enqueues for reconciliation all the ResourceOffers received from the remote clusters.
+func (r *ResourceOfferReconciler) enqueueResourceOffers() {
+	if r.configEvents == nil {
+		return
+	}
+
+	var resourceOffers sharingv1alpha1.ResourceOfferList
+	if err := r.Client.List(context.TODO(), &resourceOffers, client.HasLabels{
+		crdreplicator.RemoteLabelSelector, crdreplicator.ReplicationStatuslabel,
+	}); err != nil {
+		klog.Error(err)
+		return
+	}
+
+	// do not block the configuration watcher while the events are consumed.
+	go func() {
+		for i := range resourceOffers.Items {
+			r.configEvents <- event.GenericEvent{Object: &resourceOffers.Items[i]}
+		}
+	}()
+}
+
 // setControllerReference sets owner reference to the related ForeignCluster.
 func (r *ResourceOfferReconciler) setControllerReference(
 	ctx context.Context, resourceOffer *sharingv1alpha1.ResourceOffer) error {
@@ -69,20 +99,52 @@ func (r *ResourceOfferReconciler) setControllerReference(
 	return nil
 }
 
-// setResourceOfferPhase checks if the resource request can be accepted and set its phase accordingly.
+// setResourceOfferPhase checks if the resource offer can be accepted and set its phase accordingly.
+// The acceptance rules decide the phase of the pending offers, and of the ones previously refused by the rules,
+// which are evaluated again once they satisfy them. An accepted offer is never revoked by the rules, since that
+// would tear down a virtual node in use: if it no longer satisfies them, a warning event is raised instead.
 func (r *ResourceOfferReconciler) setResourceOfferPhase(
 	ctx context.Context, resourceOffer *sharingv1alpha1.ResourceOffer) error {
-	// we want only to care about resource offers with a pending status
-	if resourceOffer.Status.Phase != "" && resourceOffer.Status.Phase != sharingv1alpha1.ResourceOfferPending {
+	ingoingConfig := r.getConfig().Spec.AdvertisementConfig.IngoingConfig
+	pending := resourceOffer.Status.Phase == "" || resourceOffer.Status.Phase == sharingv1alpha1.ResourceOfferPending ||
+		isRefusedByAcceptanceRules(resourceOffer)
+
+	decision := evaluateAcceptanceRules(ingoingConfig.AcceptanceRules, resourceOffer)
+	if !decision.accepted {
+		switch {
+		case pending:
+			// refuse the resource offers not satisfying the acceptance rules, whatever the policy
+			if resourceOffer.Status.Phase != sharingv1alpha1.ResourceOfferRefused || resourceOffer.Status.Reason != decision.reason {
+				klog.Infof("[%v] ResourceOffer %v/%v refused: %v", resourceOffer.Spec.ClusterId,
+					resourceOffer.Namespace, resourceOffer.Name, decision.message)
+			}
+			resourceOffer.Status.Phase = sharingv1alpha1.ResourceOfferRefused
+			resourceOffer.Status.Reason = decision.reason
+			resourceOffer.Status.Message = decision.message
+		case isAccepted(resourceOffer):
+			msg := fmt.Sprintf("[%v] accepted ResourceOffer no longer satisfying the acceptance rules: %v",
+				resourceOffer.Spec.ClusterId, decision.message)
+			klog.Warning(msg)
+			r.eventsRecorder.Event(resourceOffer, "Warning", decision.reason, msg)
+		}
+		return nil
+	}
+
+	// we want only to care about resource offers with a pending status, or refused by the acceptance rules
+	if !pending {
 		return nil
 	}
 
-	switch r.getConfig().Spec.AdvertisementConfig.IngoingConfig.AcceptPolicy {
+	switch ingoingConfig.AcceptPolicy {
 	case configv1alpha1.AutoAcceptMax:
 		resourceOffer.Status.Phase = sharingv1alpha1.ResourceOfferAccepted
+		resourceOffer.Status.Reason = reasonAutoAccepted
+		resourceOffer.Status.Message = "the ResourceOffer satisfies the acceptance rules"
 	case configv1alpha1.ManualAccept:
 		// require a manual accept/refuse
 		resourceOffer.Status.Phase = sharingv1alpha1.ResourceOfferManualActionRequired
+		resourceOffer.Status.Reason = reasonManualActionRequired
+		resourceOffer.Status.Message = "the ResourceOffer satisfies the acceptance rules, waiting for a manual action"
 	}
 	return nil
 }
@@ -239,12 +301,14 @@ const (
 func getDeleteVirtualKubeletPhase(resourceOffer *sharingv1alpha1.ResourceOffer) kubeletDeletePhase {
 	notAccepted := !isAccepted(resourceOffer)
 	deleting := !resourceOffer.DeletionTimestamp.IsZero()
-	desiredDelete := !resourceOffer.Spec.WithdrawalTimestamp.IsZero()
+	desiredDelete := !resourceOffer.Spec.WithdrawalTimestamp.IsZero() ||
+		resourceOffer.Status.ExpirationStatus == sharingv1alpha1.ResourceOfferWithdrawn
 	nodeDrained := !controllerutil.ContainsFinalizer(resourceOffer, consts.NodeFinalizer)
 
 	// if the ResourceRequest has not been accepted by the local cluster,
 	// or it has a DeletionTimestamp not equal to zero (the resource has been deleted),
 	// or it has a WithdrawalTimestamp not equal to zero (the remote cluster asked for its graceful deletion),
+	// or it has not been renewed within the grace period after its expiration,
 	// the VirtualKubelet is in a terminating phase, otherwise return the None phase.
 	if notAccepted || deleting || desiredDelete {
 		// if the liqo.io/node finalizer is not set, the remote cluster has been drained and the node has been deleted,
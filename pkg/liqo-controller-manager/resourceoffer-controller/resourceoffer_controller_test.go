@@ -12,9 +12,12 @@ import (
 	. "github.com/onsi/ginkgo/extensions/table"
 	. "github.com/onsi/gomega"
 	v1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
 	rbacv1 "k8s.io/api/rbac/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/manager"
 	"sigs.k8s.io/controller-runtime/pkg/reconcile"
@@ -422,6 +425,22 @@ var _ = Describe("ResourceOffer Operator util functions", func() {
 				expected: Equal(kubeletDeletePhaseDrainingNode),
 			}),
 
+			Entry("ResourceOffer withdrawn after expiration", getDeleteVirtualKubeletPhaseTestcase{
+				resourceOffer: &sharingv1alpha1.ResourceOffer{
+					ObjectMeta: metav1.ObjectMeta{
+						Finalizers: []string{
+							consts.NodeFinalizer,
+						},
+					},
+					Spec: sharingv1alpha1.ResourceOfferSpec{},
+					Status: sharingv1alpha1.ResourceOfferStatus{
+						Phase:            sharingv1alpha1.ResourceOfferAccepted,
+						ExpirationStatus: sharingv1alpha1.ResourceOfferWithdrawn,
+					},
+				},
+				expected: Equal(kubeletDeletePhaseDrainingNode),
+			}),
+
 			Entry("desired deletion of ResourceOffer without finalizer", getDeleteVirtualKubeletPhaseTestcase{
 				resourceOffer: &sharingv1alpha1.ResourceOffer{
 					ObjectMeta: metav1.ObjectMeta{
@@ -440,6 +459,259 @@ var _ = Describe("ResourceOffer Operator util functions", func() {
 
 	})
 
+	Context("evaluateAcceptanceRules", func() {
+
+		type acceptanceRulesTestcase struct {
+			rules          *configv1alpha1.OfferAcceptanceRules
+			expectedReason string
+		}
+
+		resourceOffer := &sharingv1alpha1.ResourceOffer{
+			Spec: sharingv1alpha1.ResourceOfferSpec{
+				ClusterId: clusterID,
+				Labels: map[string]string{
+					"topology.kubernetes.io/region": "eu-west-1",
+				},
+				ResourceQuota: corev1.ResourceQuotaSpec{
+					Hard: corev1.ResourceList{
+						corev1.ResourceCPU:    resource.MustParse("4"),
+						corev1.ResourceMemory: resource.MustParse("8Gi"),
+					},
+				},
+				Prices: corev1.ResourceList{
+					corev1.ResourceCPU: resource.MustParse("2"),
+				},
+			},
+		}
+
+		DescribeTable("evaluateAcceptanceRules table",
+
+			func(c acceptanceRulesTestcase) {
+				decision := evaluateAcceptanceRules(c.rules, resourceOffer)
+				Expect(decision.accepted).To(Equal(c.expectedReason == ""))
+				Expect(decision.reason).To(Equal(c.expectedReason))
+			},
+
+			Entry("no rules", acceptanceRulesTestcase{}),
+
+			Entry("satisfied rules", acceptanceRulesTestcase{
+				rules: &configv1alpha1.OfferAcceptanceRules{
+					MinResources:      corev1.ResourceList{corev1.ResourceCPU: resource.MustParse("2")},
+					MaxResources:      corev1.ResourceList{corev1.ResourceMemory: resource.MustParse("16Gi")},
+					RequiredLabels:    map[string]string{"topology.kubernetes.io/region": "eu-west-1"},
+					MaxPrices:         corev1.ResourceList{corev1.ResourceCPU: resource.MustParse("3")},
+					TrustedClusterIDs: []string{clusterID},
+				},
+			}),
+
+			Entry("untrusted cluster", acceptanceRulesTestcase{
+				rules: &configv1alpha1.OfferAcceptanceRules{
+					TrustedClusterIDs: []string{"other-cluster-id"},
+				},
+				expectedReason: reasonUntrustedCluster,
+			}),
+
+			Entry("missing required label", acceptanceRulesTestcase{
+				rules: &configv1alpha1.OfferAcceptanceRules{
+					RequiredLabels: map[string]string{"provider": "aws"},
+				},
+				expectedReason: reasonMissingRequiredLabel,
+			}),
+
+			Entry("insufficient resources", acceptanceRulesTestcase{
+				rules: &configv1alpha1.OfferAcceptanceRules{
+					MinResources: corev1.ResourceList{corev1.ResourceCPU: resource.MustParse("8")},
+				},
+				expectedReason: reasonInsufficientResources,
+			}),
+
+			Entry("missing resource", acceptanceRulesTestcase{
+				rules: &configv1alpha1.OfferAcceptanceRules{
+					MinResources: corev1.ResourceList{"nvidia.com/gpu": resource.MustParse("1")},
+				},
+				expectedReason: reasonInsufficientResources,
+			}),
+
+			Entry("exceeding resources", acceptanceRulesTestcase{
+				rules: &configv1alpha1.OfferAcceptanceRules{
+					MaxResources: corev1.ResourceList{corev1.ResourceMemory: resource.MustParse("4Gi")},
+				},
+				expectedReason: reasonExceedingResources,
+			}),
+
+			Entry("price too high", acceptanceRulesTestcase{
+				rules: &configv1alpha1.OfferAcceptanceRules{
+					MaxPrices: corev1.ResourceList{corev1.ResourceCPU: resource.MustParse("1")},
+				},
+				expectedReason: reasonPriceTooHigh,
+			}),
+		)
+
+	})
+
+	Context("setResourceOfferPhase", func() {
+
+		type setResourceOfferPhaseTestcase struct {
+			rules          *configv1alpha1.OfferAcceptanceRules
+			currentPhase   sharingv1alpha1.OfferPhase
+			currentReason  string
+			expectedPhase  sharingv1alpha1.OfferPhase
+			expectedReason string
+			expectedEvents int
+		}
+
+		DescribeTable("setResourceOfferPhase table",
+
+			func(c setResourceOfferPhaseTestcase) {
+				recorder := record.NewFakeRecorder(10)
+				reconciler := &ResourceOfferReconciler{eventsRecorder: recorder}
+				reconciler.setConfig(&configv1alpha1.ClusterConfig{
+					Spec: configv1alpha1.ClusterConfigSpec{
+						AdvertisementConfig: configv1alpha1.AdvertisementConfig{
+							IngoingConfig: configv1alpha1.AdvOperatorConfig{
+								AcceptPolicy:    configv1alpha1.AutoAcceptMax,
+								AcceptanceRules: c.rules,
+							},
+						},
+					},
+				})
+				resourceOffer := &sharingv1alpha1.ResourceOffer{
+					Spec: sharingv1alpha1.ResourceOfferSpec{
+						ClusterId: clusterID,
+					},
+					Status: sharingv1alpha1.ResourceOfferStatus{
+						Phase:  c.currentPhase,
+						Reason: c.currentReason,
+					},
+				}
+				Expect(reconciler.setResourceOfferPhase(context.TODO(), resourceOffer)).To(Succeed())
+				Expect(resourceOffer.Status.Phase).To(Equal(c.expectedPhase))
+				Expect(resourceOffer.Status.Reason).To(Equal(c.expectedReason))
+				Expect(recorder.Events).To(HaveLen(c.expectedEvents))
+			},
+
+			Entry("pending ResourceOffer", setResourceOfferPhaseTestcase{
+				expectedPhase:  sharingv1alpha1.ResourceOfferAccepted,
+				expectedReason: reasonAutoAccepted,
+			}),
+
+			Entry("pending ResourceOffer not satisfying the rules", setResourceOfferPhaseTestcase{
+				rules:          &configv1alpha1.OfferAcceptanceRules{TrustedClusterIDs: []string{"other-cluster-id"}},
+				currentPhase:   sharingv1alpha1.ResourceOfferPending,
+				expectedPhase:  sharingv1alpha1.ResourceOfferRefused,
+				expectedReason: reasonUntrustedCluster,
+			}),
+
+			Entry("accepted ResourceOffer no longer satisfying the rules", setResourceOfferPhaseTestcase{
+				rules:          &configv1alpha1.OfferAcceptanceRules{TrustedClusterIDs: []string{"other-cluster-id"}},
+				currentPhase:   sharingv1alpha1.ResourceOfferAccepted,
+				currentReason:  reasonAutoAccepted,
+				expectedPhase:  sharingv1alpha1.ResourceOfferAccepted,
+				expectedReason: reasonAutoAccepted,
+				expectedEvents: 1,
+			}),
+
+			Entry("ResourceOffer refused by the rules satisfying them again", setResourceOfferPhaseTestcase{
+				currentPhase:   sharingv1alpha1.ResourceOfferRefused,
+				currentReason:  reasonUntrustedCluster,
+				expectedPhase:  sharingv1alpha1.ResourceOfferAccepted,
+				expectedReason: reasonAutoAccepted,
+			}),
+
+			Entry("manually refused ResourceOffer", setResourceOfferPhaseTestcase{
+				currentPhase:  sharingv1alpha1.ResourceOfferRefused,
+				expectedPhase: sharingv1alpha1.ResourceOfferRefused,
+			}),
+		)
+
+	})
+
+	Context("getExpirationStatus", func() {
+
+		type getExpirationStatusTestcase struct {
+			timeToLive        time.Time
+			currentStatus     sharingv1alpha1.OfferExpirationStatus
+			vkStatus          sharingv1alpha1.VirtualKubeletStatus
+			gracePeriod       *metav1.Duration
+			expectedStatus    sharingv1alpha1.OfferExpirationStatus
+			expectedNextCheck time.Duration
+		}
+
+		checkTime := time.Now()
+
+		DescribeTable("getExpirationStatus table",
+
+			func(c getExpirationStatusTestcase) {
+				resourceOffer := &sharingv1alpha1.ResourceOffer{
+					Spec: sharingv1alpha1.ResourceOfferSpec{
+						TimeToLive: metav1.NewTime(c.timeToLive),
+					},
+					Status: sharingv1alpha1.ResourceOfferStatus{
+						ExpirationStatus:     c.currentStatus,
+						VirtualKubeletStatus: c.vkStatus,
+					},
+				}
+				status, nextCheck := getExpirationStatus(resourceOffer, c.gracePeriod, checkTime)
+				Expect(status).To(Equal(c.expectedStatus))
+				Expect(nextCheck).To(Equal(c.expectedNextCheck))
+			},
+
+			Entry("valid ResourceOffer", getExpirationStatusTestcase{
+				timeToLive:        checkTime.Add(time.Minute),
+				expectedStatus:    sharingv1alpha1.ResourceOfferValid,
+				expectedNextCheck: time.Minute,
+			}),
+
+			Entry("expired ResourceOffer without grace period", getExpirationStatusTestcase{
+				timeToLive:     checkTime.Add(-time.Minute),
+				expectedStatus: sharingv1alpha1.ResourceOfferExpired,
+			}),
+
+			Entry("expired ResourceOffer within the grace period", getExpirationStatusTestcase{
+				timeToLive:        checkTime.Add(-time.Minute),
+				gracePeriod:       &metav1.Duration{Duration: 5 * time.Minute},
+				expectedStatus:    sharingv1alpha1.ResourceOfferExpired,
+				expectedNextCheck: 4 * time.Minute,
+			}),
+
+			Entry("expired ResourceOffer after the grace period", getExpirationStatusTestcase{
+				timeToLive:     checkTime.Add(-10 * time.Minute),
+				gracePeriod:    &metav1.Duration{Duration: 5 * time.Minute},
+				expectedStatus: sharingv1alpha1.ResourceOfferWithdrawn,
+			}),
+
+			Entry("renewed ResourceOffer", getExpirationStatusTestcase{
+				timeToLive:        checkTime.Add(time.Minute),
+				currentStatus:     sharingv1alpha1.ResourceOfferExpired,
+				expectedStatus:    sharingv1alpha1.ResourceOfferValid,
+				expectedNextCheck: time.Minute,
+			}),
+
+			Entry("renewed ResourceOffer already withdrawn", getExpirationStatusTestcase{
+				timeToLive:        checkTime.Add(time.Minute),
+				currentStatus:     sharingv1alpha1.ResourceOfferWithdrawn,
+				vkStatus:          sharingv1alpha1.VirtualKubeletStatusNone,
+				expectedStatus:    sharingv1alpha1.ResourceOfferValid,
+				expectedNextCheck: time.Minute,
+			}),
+
+			Entry("ResourceOffer renewed while draining the virtual node", getExpirationStatusTestcase{
+				timeToLive:     checkTime.Add(time.Minute),
+				currentStatus:  sharingv1alpha1.ResourceOfferWithdrawn,
+				vkStatus:       sharingv1alpha1.VirtualKubeletStatusDeleting,
+				expectedStatus: sharingv1alpha1.ResourceOfferWithdrawn,
+			}),
+
+			Entry("ResourceOffer renewed before the VirtualKubelet starts the drain", getExpirationStatusTestcase{
+				timeToLive:     checkTime.Add(time.Minute),
+				currentStatus:  sharingv1alpha1.ResourceOfferWithdrawn,
+				vkStatus:       sharingv1alpha1.VirtualKubeletStatusCreated,
+				expectedStatus: sharingv1alpha1.ResourceOfferWithdrawn,
+			}),
+		)
+
+	})
+
 	Context("getRequestFromObject", func() {
 
 		type getRequestFromObjectTestcase struct {
@@ -0,0 +1,157 @@
+package conncheck
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"os"
+	"sync"
+	"time"
+
+	"golang.org/x/net/icmp"
+	"golang.org/x/net/ipv4"
+	"k8s.io/klog/v2"
+)
+
+const (
+	// PingInterval is the interval between two consecutive probes sent to a remote gateway.
+	// A probe which did not get a reply by the time the next one is sent is considered lost.
+	PingInterval = 2 * time.Second
+	// PingLossThreshold is the number of consecutive probes without reply after which the connection is considered down.
+	PingLossThreshold = 5
+	// WindowSize is the number of the last probes considered to compute the packet loss.
+	WindowSize = 30
+	// protocolICMP is the IANA number of the ICMP protocol.
+	protocolICMP = 1
+	// maxMessageSize is the size of the buffer used to receive the ICMP messages.
+	maxMessageSize = 1500
+)
+
+// ConnChecker probes the remote gateways through the tunnels, sending them ICMP echo requests.
+// It has to be created in the network namespace where the tunnels live.
+type ConnChecker struct {
+	conn *icmp.PacketConn
+	// id is the identifier of the echo requests sent by the current checker.
+	id    int
+	mutex sync.Mutex
+	// peers contains the remote gateways being probed, keyed by the cluster ID.
+	peers map[string]*peer
+}
+
+// NewConnChecker returns a new ConnChecker, listening for the ICMP messages in the current network namespace.
+func NewConnChecker() (*ConnChecker, error) {
+	conn, err := icmp.ListenPacket("ip4:icmp", "0.0.0.0")
+	if err != nil {
+		return nil, fmt.Errorf("failed to listen for ICMP messages: %w", err)
+	}
+	return &ConnChecker{
+		conn:  conn,
+		id:    os.Getpid() & 0xffff,
+		peers: make(map[string]*peer),
+	}, nil
+}
+
+// AddPeer starts probing the remote gateway of the given cluster, reachable at the given address.
+// Adding an already existing peer with a different address resets its probing state.
+func (cc *ConnChecker) AddPeer(clusterID string, target net.IP) {
+	cc.mutex.Lock()
+	defer cc.mutex.Unlock()
+	if p, found := cc.peers[clusterID]; found && p.target.Equal(target) {
+		return
+	}
+	klog.Infof("%s -> probing remote gateway at address {%s}", clusterID, target)
+	cc.peers[clusterID] = newPeer(target)
+}
+
+// RemovePeer stops probing the remote gateway of the given cluster.
+func (cc *ConnChecker) RemovePeer(clusterID string) {
+	cc.mutex.Lock()
+	defer cc.mutex.Unlock()
+	delete(cc.peers, clusterID)
+}
+
+// GetStatus returns the status of the connection towards the remote gateway of the given cluster.
+// The second return value is false if the peer is not probed, or no probe outcome is known yet.
+func (cc *ConnChecker) GetStatus(clusterID string) (Status, bool) {
+	cc.mutex.Lock()
+	defer cc.mutex.Unlock()
+	p, found := cc.peers[clusterID]
+	if !found || !p.probed() {
+		return Status{}, false
+	}
+	return p.status, true
+}
+
+// Run probes the remote gateways every PingInterval, until the given context is cancelled.
+func (cc *ConnChecker) Run(ctx context.Context) error {
+	go cc.receive()
+	ticker := time.NewTicker(PingInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			// Closing the connection unblocks the receiver.
+			return cc.conn.Close()
+		case <-ticker.C:
+			cc.probe()
+		}
+	}
+}
+
+// probe sends an echo request to each remote gateway.
+func (cc *ConnChecker) probe() {
+	cc.mutex.Lock()
+	defer cc.mutex.Unlock()
+	for clusterID, p := range cc.peers {
+		now := time.Now()
+		msg := icmp.Message{
+			Type: ipv4.ICMPTypeEcho,
+			Body: &icmp.Echo{ID: cc.id, Seq: p.sent(now)},
+		}
+		b, err := msg.Marshal(nil)
+		if err != nil {
+			klog.Errorf("%s -> unable to marshal ICMP echo request: %v", clusterID, err)
+			continue
+		}
+		if _, err := cc.conn.WriteTo(b, &net.IPAddr{IP: p.target}); err != nil {
+			// The probe is accounted as lost.
+			klog.V(4).Infof("%s -> unable to send ICMP echo request to {%s}: %v", clusterID, p.target, err)
+		}
+	}
+}
+
+// receive processes the echo replies, until the connection is closed.
+func (cc *ConnChecker) receive() {
+	buf := make([]byte, maxMessageSize)
+	for {
+		n, addr, err := cc.conn.ReadFrom(buf)
+		if err != nil {
+			klog.V(4).Infof("stopped receiving ICMP messages: %v", err)
+			return
+		}
+		now := time.Now()
+		msg, err := icmp.ParseMessage(protocolICMP, buf[:n])
+		if err != nil || msg.Type != ipv4.ICMPTypeEchoReply {
+			continue
+		}
+		echo, ok := msg.Body.(*icmp.Echo)
+		if !ok || echo.ID != cc.id {
+			continue
+		}
+		ipAddr, ok := addr.(*net.IPAddr)
+		if !ok {
+			continue
+		}
+		cc.handleReply(ipAddr.IP, echo.Seq, now)
+	}
+}
+
+func (cc *ConnChecker) handleReply(source net.IP, seq int, now time.Time) {
+	cc.mutex.Lock()
+	defer cc.mutex.Unlock()
+	for _, p := range cc.peers {
+		if p.target.Equal(source) {
+			p.received(seq, now)
+		}
+	}
+}
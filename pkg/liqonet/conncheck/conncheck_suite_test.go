@@ -0,0 +1,13 @@
+package conncheck
+
+import (
+	"testing"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+func TestConnCheck(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "ConnCheck Suite")
+}
@@ -0,0 +1,3 @@
+// Package conncheck implements the periodic probing of the tunnels connecting the local gateway to the remote ones,
+// measuring their latency and packet loss, and detecting the ones that are not working anymore.
+package conncheck
@@ -0,0 +1,91 @@
+package conncheck
+
+import (
+	"net"
+	"time"
+)
+
+// Status is the status of the connection towards a remote gateway, as observed by the probes.
+type Status struct {
+	// Connected is false if the last PingLossThreshold probes did not get a reply.
+	Connected bool
+	// Latency is the round-trip time measured by the last probe which got a reply, zero if none did.
+	Latency time.Duration
+	// PacketLoss is the percentage of the probes lost over the last WindowSize ones.
+	PacketLoss int32
+	// ConsecutiveLosses is the number of the last probes which did not get a reply.
+	ConsecutiveLosses int
+}
+
+// peer holds the probing state of a remote gateway.
+type peer struct {
+	target net.IP
+	// seq is the sequence number of the last probe sent.
+	seq int
+	// sentAt is the time the last probe has been sent, zero if it already got a reply.
+	sentAt time.Time
+	// window contains the outcome of the last WindowSize probes, true for the ones which got a reply.
+	window []bool
+	status Status
+}
+
+func newPeer(target net.IP) *peer {
+	// The connection is considered up until PingLossThreshold probes have been lost.
+	return &peer{
+		target: target,
+		window: make([]bool, 0, WindowSize),
+		status: Status{Connected: true},
+	}
+}
+
+// sent records a new probe, marking the previous one as lost if it did not get a reply. It returns the sequence
+// number of the new probe.
+func (p *peer) sent(now time.Time) int {
+	if !p.sentAt.IsZero() {
+		p.record(false)
+	}
+	p.seq = (p.seq + 1) & 0xffff
+	p.sentAt = now
+	return p.seq
+}
+
+// received records the reply to the probe with the given sequence number. Late replies, received
+// once the probe has already been marked as lost, are discarded.
+func (p *peer) received(seq int, now time.Time) {
+	if seq != p.seq || p.sentAt.IsZero() {
+		return
+	}
+	p.status.Latency = now.Sub(p.sentAt)
+	p.sentAt = time.Time{}
+	p.record(true)
+}
+
+// probed returns true if the outcome of at least one probe is known.
+func (p *peer) probed() bool {
+	return len(p.window) > 0
+}
+
+func (p *peer) record(replied bool) {
+	if len(p.window) == WindowSize {
+		p.window = p.window[1:]
+	}
+	p.window = append(p.window, replied)
+
+	if replied {
+		p.status.ConsecutiveLosses = 0
+		p.status.Connected = true
+	} else {
+		p.status.ConsecutiveLosses++
+		if p.status.ConsecutiveLosses >= PingLossThreshold {
+			p.status.Connected = false
+		}
+	}
+
+	lost := 0
+	for _, r := range p.window {
+		if !r {
+			lost++
+		}
+	}
+	p.status.PacketLoss = int32(lost * 100 / len(p.window))
+}
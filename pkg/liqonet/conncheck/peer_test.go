@@ -0,0 +1,101 @@
+package conncheck
+
+import (
+	"net"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Peer", func() {
+	var (
+		p   *peer
+		now time.Time
+	)
+
+	BeforeEach(func() {
+		p = newPeer(net.ParseIP("10.0.0.0"))
+		now = time.Now()
+	})
+
+	// probe sends a probe and, if replied, receives the reply after the given latency.
+	probe := func(replied bool, latency time.Duration) {
+		seq := p.sent(now)
+		if replied {
+			p.received(seq, now.Add(latency))
+		}
+		now = now.Add(PingInterval)
+	}
+
+	Context("before the outcome of the first probe is known", func() {
+		It("should not be probed", func() {
+			Expect(p.probed()).To(BeFalse())
+			p.sent(now)
+			Expect(p.probed()).To(BeFalse())
+		})
+	})
+
+	Context("when the probes get a reply", func() {
+		It("should be connected and record the latency", func() {
+			probe(true, 10*time.Millisecond)
+			probe(true, 20*time.Millisecond)
+			Expect(p.probed()).To(BeTrue())
+			Expect(p.status).To(Equal(Status{Connected: true, Latency: 20 * time.Millisecond}))
+		})
+	})
+
+	Context("when some probes are lost", func() {
+		It("should account them in the packet loss", func() {
+			probe(true, time.Millisecond)
+			probe(false, 0)
+			probe(true, time.Millisecond)
+			probe(true, time.Millisecond)
+			// the outcome of the last probe is known only when the next one is sent.
+			probe(false, 0)
+			p.sent(now)
+			Expect(p.status.Connected).To(BeTrue())
+			Expect(p.status.PacketLoss).To(BeNumerically("==", 40))
+			Expect(p.status.ConsecutiveLosses).To(Equal(1))
+		})
+
+		It("should consider only the last WindowSize probes", func() {
+			for i := 0; i < WindowSize; i++ {
+				probe(false, 0)
+			}
+			for i := 0; i < WindowSize; i++ {
+				probe(true, time.Millisecond)
+			}
+			Expect(p.status.PacketLoss).To(BeNumerically("==", 0))
+		})
+	})
+
+	Context("when PingLossThreshold consecutive probes are lost", func() {
+		BeforeEach(func() {
+			for i := 0; i <= PingLossThreshold; i++ {
+				probe(false, 0)
+			}
+		})
+
+		It("should be disconnected", func() {
+			Expect(p.status.Connected).To(BeFalse())
+			Expect(p.status.ConsecutiveLosses).To(Equal(PingLossThreshold))
+		})
+
+		It("should be connected again as soon as a probe gets a reply", func() {
+			probe(true, time.Millisecond)
+			Expect(p.status.Connected).To(BeTrue())
+			Expect(p.status.ConsecutiveLosses).To(BeZero())
+		})
+	})
+
+	Context("when a reply is received after the probe has been marked as lost", func() {
+		It("should discard it", func() {
+			seq := p.sent(now)
+			p.sent(now.Add(PingInterval))
+			p.received(seq, now.Add(PingInterval+time.Millisecond))
+			Expect(p.status.Latency).To(BeZero())
+			Expect(p.status.PacketLoss).To(BeNumerically("==", 100))
+		})
+	})
+})
@@ -0,0 +1,247 @@
+package ipam
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"sync"
+	"time"
+
+	grpc "google.golang.org/grpc"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/klog"
+
+	"github.com/liqotech/liqo/pkg/consts"
+	"github.com/liqotech/liqo/pkg/liqonet/utils"
+)
+
+// watchRetryPeriod is the interval between two attempts to open the WatchMappings stream.
+const watchRetryPeriod = 5 * time.Second
+
+// CachedClient is an IpamClient which serves the endpoint mappings and the home pod IPs of a remote cluster
+// from a local cache, kept up-to-date through the WatchMappings RPC. The requests which cannot be served from
+// the cache, as well as the ones performed while the stream is down, are forwarded to the IPAM.
+type CachedClient struct {
+	IpamClient
+	clusterID string
+
+	mutex  sync.RWMutex
+	synced bool
+	// subnets are the networks of the remote cluster, received when the stream is opened.
+	subnets *ClusterSubnets
+	// mappings contains the IPs the endpoints are reached with from the remote cluster, keyed by the endpoint IP.
+	mappings map[string]string
+	// unmapped contains the endpoints unmapped through this client whose deletion has not been observed yet,
+	// to ignore the events generated before the unmap.
+	unmapped map[string]struct{}
+}
+
+// NewCachedClient returns a CachedClient for the given remote cluster, which forwards the requests
+// to the given client until Start is called.
+func NewCachedClient(client IpamClient, clusterID string) *CachedClient {
+	return &CachedClient{
+		IpamClient: client,
+		clusterID:  clusterID,
+		mappings:   make(map[string]string),
+		unmapped:   make(map[string]struct{}),
+	}
+}
+
+// Start keeps the cache in sync with the IPAM, reopening the stream in case of failures, until ctx is done.
+func (c *CachedClient) Start(ctx context.Context) {
+	wait.UntilWithContext(ctx, c.watch, watchRetryPeriod)
+}
+
+// watch consumes the WatchMappings stream, until it fails or ctx is done.
+func (c *CachedClient) watch(ctx context.Context) {
+	defer c.reset(false)
+	stream, err := c.IpamClient.WatchMappings(ctx, &WatchMappingsRequest{ClusterID: c.clusterID})
+	if err != nil {
+		klog.Warningf("%s -> unable to watch the endpoint mappings: %v", c.clusterID, err)
+		return
+	}
+	for {
+		event, err := stream.Recv()
+		if err != nil {
+			if ctx.Err() == nil {
+				klog.Warningf("%s -> the endpoint mappings watch has been interrupted: %v", c.clusterID, err)
+			}
+			return
+		}
+		c.handle(event)
+	}
+}
+
+// reset clears the cache, setting whether it is in sync with the IPAM.
+func (c *CachedClient) reset(synced bool) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	c.synced = synced
+	c.subnets = nil
+	c.mappings = make(map[string]string)
+	c.unmapped = make(map[string]struct{})
+}
+
+func (c *CachedClient) handle(event *MappingEvent) {
+	if event.GetType() == MappingEvent_SUBNETS {
+		// The stream starts with the subnets, followed by the snapshot of the mappings.
+		c.reset(true)
+		c.mutex.Lock()
+		c.subnets = event.GetSubnets()
+		c.mutex.Unlock()
+		return
+	}
+	mapping := event.GetMapping()
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	switch event.GetType() {
+	case MappingEvent_ADDED:
+		if _, found := c.unmapped[mapping.GetIp()]; !found {
+			c.mappings[mapping.GetIp()] = mapping.GetMappedIP()
+		}
+	case MappingEvent_DELETED:
+		delete(c.mappings, mapping.GetIp())
+		delete(c.unmapped, mapping.GetIp())
+	}
+}
+
+// MapEndpointIP returns the cached mapping of the endpoint, if any, otherwise it forwards the request.
+func (c *CachedClient) MapEndpointIP(ctx context.Context, in *MapRequest, opts ...grpc.CallOption) (*MapResponse, error) {
+	if in.GetClusterID() == c.clusterID {
+		c.mutex.RLock()
+		mappedIP, found := c.mappings[in.GetIp()]
+		synced := c.synced
+		c.mutex.RUnlock()
+		if synced && found {
+			return &MapResponse{Ip: mappedIP}, nil
+		}
+	}
+	response, err := c.IpamClient.MapEndpointIP(ctx, in, opts...)
+	if err != nil || in.GetClusterID() != c.clusterID {
+		return response, err
+	}
+	// The mappings of the local pods are not notified, since they do not reserve any IP:
+	// being deterministic, they are cached as soon as they are known.
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	if c.synced && c.isLocalPodMapping(in.GetIp(), response.GetIp()) {
+		c.mappings[in.GetIp()] = response.GetIp()
+		delete(c.unmapped, in.GetIp())
+	}
+	return response, nil
+}
+
+// UnmapEndpointIP forwards the request, and removes the mapping of the endpoint from the cache.
+func (c *CachedClient) UnmapEndpointIP(ctx context.Context, in *UnmapRequest, opts ...grpc.CallOption) (*UnmapResponse, error) {
+	response, err := c.IpamClient.UnmapEndpointIP(ctx, in, opts...)
+	if err != nil || in.GetClusterID() != c.clusterID {
+		return response, err
+	}
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	c.forget(in.GetIp())
+	return response, nil
+}
+
+// forget removes the mapping of an unmapped endpoint from the cache, unless it concerns a local pod.
+// It must be called while holding the mutex.
+func (c *CachedClient) forget(ip string) {
+	mappedIP, found := c.mappings[ip]
+	if found && c.isLocalPodMapping(ip, mappedIP) {
+		return
+	}
+	delete(c.mappings, ip)
+	if c.synced {
+		c.unmapped[ip] = struct{}{}
+	}
+}
+
+// MapEndpointIPs returns the cached mappings of the endpoints, forwarding a single request for the ones not cached.
+func (c *CachedClient) MapEndpointIPs(ctx context.Context, in *MapEndpointIPsRequest,
+	opts ...grpc.CallOption) (*MapEndpointIPsResponse, error) {
+	if in.GetClusterID() != c.clusterID {
+		return c.IpamClient.MapEndpointIPs(ctx, in, opts...)
+	}
+	mappedIPs := make([]string, len(in.GetIps()))
+	var missing []string
+	var missingIdx []int
+	c.mutex.RLock()
+	for i, ip := range in.GetIps() {
+		mappedIP, found := c.mappings[ip]
+		if !c.synced || !found {
+			missing = append(missing, ip)
+			missingIdx = append(missingIdx, i)
+			continue
+		}
+		mappedIPs[i] = mappedIP
+	}
+	c.mutex.RUnlock()
+	if len(missing) == 0 {
+		return &MapEndpointIPsResponse{Ips: mappedIPs}, nil
+	}
+
+	response, err := c.IpamClient.MapEndpointIPs(ctx, &MapEndpointIPsRequest{ClusterID: c.clusterID, Ips: missing}, opts...)
+	if err != nil {
+		return response, err
+	}
+	if len(response.GetIps()) != len(missing) {
+		return &MapEndpointIPsResponse{}, fmt.Errorf("expected %d mapped IPs, got %d", len(missing), len(response.GetIps()))
+	}
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	for i, ip := range missing {
+		mappedIP := response.GetIps()[i]
+		mappedIPs[missingIdx[i]] = mappedIP
+		if c.synced && c.isLocalPodMapping(ip, mappedIP) {
+			c.mappings[ip] = mappedIP
+			delete(c.unmapped, ip)
+		}
+	}
+	return &MapEndpointIPsResponse{Ips: mappedIPs}, nil
+}
+
+// UnmapEndpointIPs forwards the request, and removes the mappings of the endpoints from the cache.
+func (c *CachedClient) UnmapEndpointIPs(ctx context.Context, in *UnmapEndpointIPsRequest,
+	opts ...grpc.CallOption) (*UnmapEndpointIPsResponse, error) {
+	response, err := c.IpamClient.UnmapEndpointIPs(ctx, in, opts...)
+	if err != nil || in.GetClusterID() != c.clusterID {
+		return response, err
+	}
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	for _, ip := range in.GetIps() {
+		c.forget(ip)
+	}
+	return response, nil
+}
+
+// GetHomePodIP maps the IP using the cached RemotePodCIDR, if known, otherwise it forwards the request.
+func (c *CachedClient) GetHomePodIP(ctx context.Context, in *GetHomePodIPRequest,
+	opts ...grpc.CallOption) (*GetHomePodIPResponse, error) {
+	if in.GetClusterID() == c.clusterID {
+		c.mutex.RLock()
+		subnets := c.subnets
+		c.mutex.RUnlock()
+		if subnets.GetRemotePodCIDR() != "" {
+			if homeIP, err := utils.MapIPToNetwork(subnets.GetRemotePodCIDR(), in.GetIp()); err == nil {
+				return &GetHomePodIPResponse{HomeIP: homeIP}, nil
+			}
+		}
+	}
+	return c.IpamClient.GetHomePodIP(ctx, in, opts...)
+}
+
+// isLocalPodMapping returns whether the given mapping concerns a local pod, i.e. the endpoint is either
+// not remapped or remapped to the network used by the remote cluster for the local PodCIDR.
+func (c *CachedClient) isLocalPodMapping(ip, mappedIP string) bool {
+	if ip == mappedIP {
+		return true
+	}
+	localNATPodCIDR := c.subnets.GetLocalNATPodCIDR()
+	if localNATPodCIDR == "" || localNATPodCIDR == consts.DefaultCIDRValue {
+		return false
+	}
+	_, network, err := net.ParseCIDR(localNATPodCIDR)
+	parsedIP := net.ParseIP(mappedIP)
+	return err == nil && parsedIP != nil && network.Contains(parsedIP)
+}
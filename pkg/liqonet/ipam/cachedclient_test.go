@@ -0,0 +1,156 @@
+package ipam
+
+import (
+	"context"
+	"io"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	grpc "google.golang.org/grpc"
+)
+
+// fakeIpamClient serves the WatchMappings stream from a channel and counts the forwarded unary requests.
+type fakeIpamClient struct {
+	IpamClient
+	events       chan *MappingEvent
+	mapRequests  int
+	homeRequests int
+	mappedIP     string
+}
+
+type fakeWatchMappingsClient struct {
+	grpc.ClientStream
+	events chan *MappingEvent
+}
+
+func (f *fakeWatchMappingsClient) Recv() (*MappingEvent, error) {
+	event, ok := <-f.events
+	if !ok {
+		return nil, io.EOF
+	}
+	return event, nil
+}
+
+func (f *fakeIpamClient) WatchMappings(ctx context.Context, in *WatchMappingsRequest,
+	opts ...grpc.CallOption) (Ipam_WatchMappingsClient, error) {
+	return &fakeWatchMappingsClient{events: f.events}, nil
+}
+
+func (f *fakeIpamClient) MapEndpointIP(ctx context.Context, in *MapRequest, opts ...grpc.CallOption) (*MapResponse, error) {
+	f.mapRequests++
+	return &MapResponse{Ip: f.mappedIP}, nil
+}
+
+func (f *fakeIpamClient) MapEndpointIPs(ctx context.Context, in *MapEndpointIPsRequest,
+	opts ...grpc.CallOption) (*MapEndpointIPsResponse, error) {
+	f.mapRequests++
+	response := &MapEndpointIPsResponse{}
+	for range in.GetIps() {
+		response.Ips = append(response.Ips, f.mappedIP)
+	}
+	return response, nil
+}
+
+func (f *fakeIpamClient) UnmapEndpointIP(ctx context.Context, in *UnmapRequest, opts ...grpc.CallOption) (*UnmapResponse, error) {
+	return &UnmapResponse{}, nil
+}
+
+func (f *fakeIpamClient) GetHomePodIP(ctx context.Context, in *GetHomePodIPRequest,
+	opts ...grpc.CallOption) (*GetHomePodIPResponse, error) {
+	f.homeRequests++
+	return &GetHomePodIPResponse{}, nil
+}
+
+var _ = Describe("CachedClient", func() {
+	const (
+		clusterID   = "cluster1"
+		localPodIP  = "10.0.0.6"
+		remotePodIP = "10.50.0.6"
+	)
+
+	var (
+		fake   *fakeIpamClient
+		client *CachedClient
+		cancel context.CancelFunc
+		done   chan struct{}
+	)
+
+	mapIP := func(ip string) string {
+		response, err := client.MapEndpointIP(context.Background(), &MapRequest{ClusterID: clusterID, Ip: ip})
+		Expect(err).ToNot(HaveOccurred())
+		return response.GetIp()
+	}
+	added := func(ip, mappedIP string) *MappingEvent {
+		return &MappingEvent{Type: MappingEvent_ADDED, Mapping: &EndpointMapping{ClusterID: clusterID, Ip: ip, MappedIP: mappedIP}}
+	}
+	// waitFor sends the given events, and waits until the cache has processed them: since the channel is
+	// unbuffered and the events are handled before receiving the next one, it is enough to send a further no-op.
+	waitFor := func(events ...*MappingEvent) {
+		for _, event := range events {
+			fake.events <- event
+		}
+		fake.events <- &MappingEvent{Type: MappingEvent_DELETED, Mapping: &EndpointMapping{Ip: "none"}}
+	}
+
+	BeforeEach(func() {
+		fake = &fakeIpamClient{events: make(chan *MappingEvent), mappedIP: "10.0.1.6"}
+		client = NewCachedClient(fake, clusterID)
+		done = make(chan struct{})
+		var ctx context.Context
+		ctx, cancel = context.WithCancel(context.Background())
+		go func() {
+			client.watch(ctx)
+			close(done)
+		}()
+		waitFor(&MappingEvent{Type: MappingEvent_SUBNETS, Subnets: &ClusterSubnets{
+			ClusterID:       clusterID,
+			RemotePodCIDR:   "10.70.0.0/16",
+			LocalNATPodCIDR: "10.0.1.0/24",
+		}})
+	})
+
+	AfterEach(func() {
+		cancel()
+		close(fake.events)
+		<-done
+	})
+
+	It("should serve the watched mappings from the cache", func() {
+		waitFor(added(remotePodIP, "192.168.30.1"))
+		Expect(mapIP(remotePodIP)).To(Equal("192.168.30.1"))
+		Expect(fake.mapRequests).To(BeZero())
+	})
+
+	It("should cache the mappings of the local pods once forwarded", func() {
+		Expect(mapIP(localPodIP)).To(Equal("10.0.1.6"))
+		Expect(mapIP(localPodIP)).To(Equal("10.0.1.6"))
+		Expect(fake.mapRequests).To(Equal(1))
+	})
+
+	It("should ignore the stale mappings of the endpoints unmapped", func() {
+		_, err := client.UnmapEndpointIP(context.Background(), &UnmapRequest{ClusterID: clusterID, Ip: remotePodIP})
+		Expect(err).ToNot(HaveOccurred())
+		waitFor(added(remotePodIP, "192.168.30.1"))
+		fake.mappedIP = "192.168.30.2"
+		Expect(mapIP(remotePodIP)).To(Equal("192.168.30.2"))
+		Expect(fake.mapRequests).To(Equal(1))
+	})
+
+	It("should forward a single request for the endpoints not cached", func() {
+		waitFor(added(remotePodIP, "192.168.30.1"))
+		response, err := client.MapEndpointIPs(context.Background(), &MapEndpointIPsRequest{
+			ClusterID: clusterID, Ips: []string{localPodIP, remotePodIP, "10.0.0.7"}})
+		Expect(err).ToNot(HaveOccurred())
+		Expect(response.GetIps()).To(Equal([]string{"10.0.1.6", "192.168.30.1", "10.0.1.6"}))
+		Expect(fake.mapRequests).To(Equal(1))
+		Expect(mapIP(localPodIP)).To(Equal("10.0.1.6"))
+		Expect(fake.mapRequests).To(Equal(1))
+	})
+
+	It("should compute the home pod IPs from the remote PodCIDR", func() {
+		response, err := client.GetHomePodIP(context.Background(), &GetHomePodIPRequest{ClusterID: clusterID, Ip: "10.50.1.2"})
+		Expect(err).ToNot(HaveOccurred())
+		Expect(response.GetHomeIP()).To(Equal("10.70.1.2"))
+		Expect(fake.homeRequests).To(BeZero())
+	})
+})
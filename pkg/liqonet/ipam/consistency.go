@@ -0,0 +1,467 @@
+package ipam
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+	"time"
+
+	coordinationv1 "k8s.io/api/coordination/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/klog"
+
+	netv1alpha1 "github.com/liqotech/liqo/apis/net/v1alpha1"
+	"github.com/liqotech/liqo/pkg/consts"
+	"github.com/liqotech/liqo/pkg/liqonet/utils"
+)
+
+// Inconsistency describes a mismatch between the IPAM state and the resources describing the remote clusters,
+// i.e. the TunnelEndpoints and the NatMappings.
+type Inconsistency struct {
+	// ClusterID is the identifier of the remote cluster the inconsistency refers to, if any.
+	ClusterID string
+	// Resource is the kind of the resource which does not match the IPAM state.
+	Resource string
+	// Message describes the inconsistency.
+	Message string
+}
+
+func (i Inconsistency) String() string {
+	if i.ClusterID == "" {
+		return fmt.Sprintf("%s: %s", i.Resource, i.Message)
+	}
+	return fmt.Sprintf("%s -> %s: %s", i.ClusterID, i.Resource, i.Message)
+}
+
+// ExportState returns the IPAM state stored in the IpamStorage resource, encoded in JSON.
+func ExportState(dynClient dynamic.Interface) ([]byte, error) {
+	ipamStorage := &IPAMStorage{dynClient: dynClient}
+	ipam, err := ipamStorage.getConfig()
+	if err != nil {
+		return nil, fmt.Errorf("cannot get the IPAM state: %w", err)
+	}
+	return json.MarshalIndent(ipam.Spec, "", "  ")
+}
+
+// ImportState replaces the IPAM state stored in the IpamStorage resource with the given one,
+// previously retrieved through ExportState. The IpamStorage resource is created if it does not exist.
+// The network manager, running in the given namespace, has to be stopped while the state is imported.
+func ImportState(dynClient dynamic.Interface, namespace string, state []byte) error {
+	if err := checkNetworkManagerStopped(dynClient, namespace); err != nil {
+		return err
+	}
+	spec := netv1alpha1.IpamSpec{}
+	if err := json.Unmarshal(state, &spec); err != nil {
+		return fmt.Errorf("cannot decode the IPAM state: %w", err)
+	}
+	ipamStorage, err := NewIPAMStorage(dynClient)
+	if err != nil {
+		return err
+	}
+	return ipamStorage.replaceSpec(&spec)
+}
+
+// CheckConsistency cross-validates the IPAM state stored in the IpamStorage resource against
+// the TunnelEndpoints and the NatMappings, and returns the inconsistencies found.
+func CheckConsistency(dynClient dynamic.Interface) ([]Inconsistency, error) {
+	ipamStorage := &IPAMStorage{dynClient: dynClient}
+	ipam, err := ipamStorage.getConfig()
+	if errors.IsNotFound(err) {
+		return []Inconsistency{{Resource: netv1alpha1.IpamGroupResource.Resource, Message: "resource not found"}}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("cannot get the IPAM state: %w", err)
+	}
+	teps, err := listTunnelEndpoints(dynClient)
+	if err != nil {
+		return nil, err
+	}
+	natMappings, err := listNatMappings(dynClient)
+	if err != nil {
+		return nil, err
+	}
+	return checkConsistency(&ipam.Spec, teps, natMappings), nil
+}
+
+// checkConsistency returns the inconsistencies between the given IPAM state and the given resources.
+func checkConsistency(spec *netv1alpha1.IpamSpec, teps []netv1alpha1.TunnelEndpoint,
+	natMappings []netv1alpha1.NatMapping) []Inconsistency {
+	const tepKind, natMappingKind, ipamKind = "TunnelEndpoint", "NatMapping", "IpamStorage"
+	var inconsistencies []Inconsistency
+	report := func(clusterID, resource, format string, args ...interface{}) {
+		inconsistencies = append(inconsistencies, Inconsistency{
+			ClusterID: clusterID,
+			Resource:  resource,
+			Message:   fmt.Sprintf(format, args...),
+		})
+	}
+
+	peered := make(map[string]struct{})
+	for i := range teps {
+		tep := &teps[i]
+		clusterID := tep.Spec.ClusterID
+		if tep.Status.Phase != consts.TepReady {
+			continue
+		}
+		peered[clusterID] = struct{}{}
+		if tep.Status.LocalPodCIDR != "" && tep.Status.LocalPodCIDR != spec.PodCIDR {
+			report(clusterID, tepKind, "local PodCIDR %s does not match the IPAM PodCIDR %s", tep.Status.LocalPodCIDR, spec.PodCIDR)
+		}
+		if tep.Status.LocalExternalCIDR != "" && tep.Status.LocalExternalCIDR != spec.ExternalCIDR {
+			report(clusterID, tepKind, "local ExternalCIDR %s does not match the IPAM ExternalCIDR %s",
+				tep.Status.LocalExternalCIDR, spec.ExternalCIDR)
+		}
+		subnets, found := spec.ClusterSubnets[clusterID]
+		if !found {
+			report(clusterID, tepKind, "the IPAM has no subnets for the peered cluster")
+			continue
+		}
+		_, remotePodCIDR := utils.GetPodCIDRS(tep)
+		_, remoteExternalCIDR := utils.GetExternalCIDRS(tep)
+		compare := func(field, tepValue, ipamValue string) {
+			if tepValue != ipamValue {
+				report(clusterID, tepKind, "%s is %s, while the IPAM reserved %s", field, tepValue, ipamValue)
+			}
+		}
+		compare("remote PodCIDR", remotePodCIDR, subnets.RemotePodCIDR)
+		compare("remote ExternalCIDR", remoteExternalCIDR, subnets.RemoteExternalCIDR)
+		compare("local NAT PodCIDR", tep.Status.LocalNATPodCIDR, subnets.LocalNATPodCIDR)
+		compare("local NAT ExternalCIDR", tep.Status.LocalNATExternalCIDR, subnets.LocalNATExternalCIDR)
+	}
+
+	for _, clusterID := range sortedKeys(spec.ClusterSubnets) {
+		if _, found := peered[clusterID]; !found {
+			report(clusterID, ipamKind, "the IPAM holds subnets for a cluster without a ready TunnelEndpoint")
+		}
+	}
+
+	withNatMapping := make(map[string]struct{})
+	for i := range natMappings {
+		nm := &natMappings[i]
+		clusterID := nm.Spec.ClusterID
+		withNatMapping[clusterID] = struct{}{}
+		if _, found := spec.NatMappingsConfigured[clusterID]; !found {
+			report(clusterID, natMappingKind, "the IPAM does not record the NAT mappings as configured")
+		}
+		subnets, found := spec.ClusterSubnets[clusterID]
+		if !found {
+			report(clusterID, natMappingKind, "the IPAM has no subnets for the cluster")
+			continue
+		}
+		if nm.Spec.PodCIDR != subnets.RemotePodCIDR {
+			report(clusterID, natMappingKind, "PodCIDR %s does not match the remote PodCIDR %s", nm.Spec.PodCIDR, subnets.RemotePodCIDR)
+		}
+		externalCIDR := subnets.LocalNATExternalCIDR
+		if externalCIDR == consts.DefaultCIDRValue {
+			externalCIDR = spec.ExternalCIDR
+		}
+		if nm.Spec.ExternalCIDR != externalCIDR {
+			report(clusterID, natMappingKind, "ExternalCIDR %s does not match the one used by the remote cluster %s",
+				nm.Spec.ExternalCIDR, externalCIDR)
+		}
+		for _, oldIP := range sortedKeys(nm.Spec.ClusterMappings) {
+			newIP := nm.Spec.ClusterMappings[oldIP]
+			endpointMapping, found := spec.EndpointMappings[oldIP]
+			if !found {
+				report(clusterID, natMappingKind, "endpoint %s is mapped to %s, but the IPAM does not hold its mapping", oldIP, newIP)
+				continue
+			}
+			if _, found := endpointMapping.ClusterMappings[clusterID]; !found {
+				report(clusterID, natMappingKind, "endpoint %s is mapped, but the IPAM does not reflect it in the cluster", oldIP)
+			}
+			expectedIP, err := utils.MapIPToNetwork(nm.Spec.ExternalCIDR, endpointMapping.IP)
+			if err != nil {
+				report(clusterID, natMappingKind, "cannot map IP %s of endpoint %s: %v", endpointMapping.IP, oldIP, err)
+				continue
+			}
+			if expectedIP != newIP {
+				report(clusterID, natMappingKind, "endpoint %s is mapped to %s, while the IPAM expects %s", oldIP, newIP, expectedIP)
+			}
+		}
+	}
+
+	for _, clusterID := range sortedKeys(spec.NatMappingsConfigured) {
+		if _, found := withNatMapping[clusterID]; !found {
+			report(clusterID, ipamKind, "NAT mappings recorded as configured, but no NatMapping exists")
+		}
+	}
+	for _, oldIP := range sortedKeys(spec.EndpointMappings) {
+		for _, clusterID := range sortedKeys(spec.EndpointMappings[oldIP].ClusterMappings) {
+			if !hasNatMapping(natMappings, clusterID, oldIP) {
+				report(clusterID, ipamKind, "endpoint %s is reflected in the cluster, but the NatMapping does not contain it", oldIP)
+			}
+		}
+	}
+	return inconsistencies
+}
+
+// RebuildState discards the IPAM state stored in the IpamStorage resource and rebuilds it starting from
+// the ready TunnelEndpoints and the NatMappings. The pools, the PodCIDR, the ServiceCIDR, the ExternalCIDR and the mesh
+// subnets are preserved if available, otherwise the given pools and the networks reported by the TunnelEndpoints are used.
+// The network manager, running in the given namespace, has to be stopped while the state is rebuilt, and the previous state
+// is restored if the rebuild fails.
+func RebuildState(dynClient dynamic.Interface, namespace string, pools []string) error {
+	if err := checkNetworkManagerStopped(dynClient, namespace); err != nil {
+		return err
+	}
+	ipamStorage, err := NewIPAMStorage(dynClient)
+	if err != nil {
+		return err
+	}
+	ipam, err := ipamStorage.getConfig()
+	if err != nil {
+		return fmt.Errorf("cannot get the IPAM state: %w", err)
+	}
+	if len(ipam.Spec.Pools) > 0 {
+		pools = ipam.Spec.Pools
+	}
+	previousSpec := ipam.Spec.DeepCopy()
+	teps, err := listTunnelEndpoints(dynClient)
+	if err != nil {
+		return err
+	}
+	natMappings, err := listNatMappings(dynClient)
+	if err != nil {
+		return err
+	}
+
+	if err := ipamStorage.replaceSpec(&netv1alpha1.IpamSpec{
+		Prefixes:              make(map[string][]byte),
+		Pools:                 make([]string, 0),
+		ClusterSubnets:        make(map[string]netv1alpha1.Subnets),
+		EndpointMappings:      make(map[string]netv1alpha1.EndpointMapping),
+		NatMappingsConfigured: make(map[string]netv1alpha1.ConfiguredCluster),
+		MeshSubnets:           ipam.Spec.MeshSubnets,
+	}); err != nil {
+		return err
+	}
+	liqoIPAM := NewIPAM()
+	err = liqoIPAM.Init(pools, dynClient, 0)
+	if err == nil {
+		err = liqoIPAM.rebuild(previousSpec.PodCIDR, previousSpec.ServiceCIDR, previousSpec.ExternalCIDR, teps, natMappings)
+	}
+	if err != nil {
+		if restoreErr := ipamStorage.replaceSpec(previousSpec); restoreErr != nil {
+			return fmt.Errorf("%w, and the previous state cannot be restored: %v", err, restoreErr)
+		}
+		return fmt.Errorf("%w, the previous state has been restored", err)
+	}
+	return nil
+}
+
+// rebuild reserves the networks and the endpoint IPs described by the given resources. The given PodCIDR and
+// ExternalCIDR, if empty, are replaced by the ones reported by the ready TunnelEndpoints.
+func (liqoIPAM *IPAM) rebuild(podCIDR, serviceCIDR, externalCIDR string,
+	teps []netv1alpha1.TunnelEndpoint, natMappings []netv1alpha1.NatMapping) error {
+	for i := range teps {
+		status := &teps[i].Status
+		if status.Phase != consts.TepReady {
+			continue
+		}
+		if podCIDR == "" {
+			podCIDR = status.LocalPodCIDR
+		} else if status.LocalPodCIDR != "" && status.LocalPodCIDR != podCIDR {
+			klog.Warningf("%s -> local PodCIDR %s does not match the IPAM PodCIDR %s", teps[i].Spec.ClusterID, status.LocalPodCIDR, podCIDR)
+		}
+		if externalCIDR == "" {
+			externalCIDR = status.LocalExternalCIDR
+		} else if status.LocalExternalCIDR != "" && status.LocalExternalCIDR != externalCIDR {
+			klog.Warningf("%s -> local ExternalCIDR %s does not match the IPAM ExternalCIDR %s",
+				teps[i].Spec.ClusterID, status.LocalExternalCIDR, externalCIDR)
+		}
+	}
+	if podCIDR != "" {
+		if err := liqoIPAM.SetPodCIDR(podCIDR); err != nil {
+			return err
+		}
+	}
+	if serviceCIDR != "" {
+		if err := liqoIPAM.SetServiceCIDR(serviceCIDR); err != nil {
+			return err
+		}
+	}
+	if externalCIDR != "" {
+		if err := liqoIPAM.AcquireReservedSubnet(externalCIDR); err != nil {
+			return fmt.Errorf("cannot acquire ExternalCIDR: %w", err)
+		}
+		if err := liqoIPAM.ipamStorage.updateExternalCIDR(externalCIDR); err != nil {
+			return fmt.Errorf("cannot set ExternalCIDR: %w", err)
+		}
+	}
+
+	clusterSubnets := make(map[string]netv1alpha1.Subnets)
+	for i := range teps {
+		tep := &teps[i]
+		if tep.Status.Phase != consts.TepReady {
+			continue
+		}
+		_, remotePodCIDR := utils.GetPodCIDRS(tep)
+		_, remoteExternalCIDR := utils.GetExternalCIDRS(tep)
+		for _, network := range []string{remotePodCIDR, remoteExternalCIDR} {
+			if err := liqoIPAM.AcquireReservedSubnet(network); err != nil {
+				return fmt.Errorf("cannot acquire network %s of cluster %s: %w", network, tep.Spec.ClusterID, err)
+			}
+		}
+		clusterSubnets[tep.Spec.ClusterID] = netv1alpha1.Subnets{
+			LocalNATPodCIDR:      tep.Status.LocalNATPodCIDR,
+			RemotePodCIDR:        remotePodCIDR,
+			LocalNATExternalCIDR: tep.Status.LocalNATExternalCIDR,
+			RemoteExternalCIDR:   remoteExternalCIDR,
+		}
+		klog.Infof("%s -> networks restored in the IPAM", tep.Spec.ClusterID)
+	}
+	if err := liqoIPAM.ipamStorage.updateClusterSubnets(clusterSubnets); err != nil {
+		return fmt.Errorf("cannot update cluster subnets: %w", err)
+	}
+
+	natMappingsConfigured := make(map[string]netv1alpha1.ConfiguredCluster)
+	endpointMappings := make(map[string]netv1alpha1.EndpointMapping)
+	for i := range natMappings {
+		nm := &natMappings[i]
+		clusterID := nm.Spec.ClusterID
+		if _, found := clusterSubnets[clusterID]; !found {
+			klog.Warningf("%s -> NatMapping %s skipped, since the cluster is not peered", clusterID, nm.Name)
+			continue
+		}
+		natMappingsConfigured[clusterID] = netv1alpha1.ConfiguredCluster{}
+		for _, oldIP := range sortedKeys(nm.Spec.ClusterMappings) {
+			// The NatMapping holds the IP in the ExternalCIDR used by the remote cluster, which has to be
+			// brought back to the local ExternalCIDR, where the IPs are reserved.
+			localIP, err := utils.MapIPToNetwork(externalCIDR, nm.Spec.ClusterMappings[oldIP])
+			if err != nil {
+				return fmt.Errorf("cannot map IP %s of endpoint %s: %w", nm.Spec.ClusterMappings[oldIP], oldIP, err)
+			}
+			endpointMapping, found := endpointMappings[oldIP]
+			if !found {
+				if _, err := liqoIPAM.ipam.AcquireSpecificIP(externalCIDR, localIP); err != nil {
+					return fmt.Errorf("cannot acquire IP %s for endpoint %s: %w", localIP, oldIP, err)
+				}
+				endpointMapping = netv1alpha1.EndpointMapping{
+					IP:              localIP,
+					ClusterMappings: make(map[string]netv1alpha1.ClusterMapping),
+				}
+			} else if endpointMapping.IP != localIP {
+				klog.Warningf("%s -> endpoint %s is mapped to %s, while other clusters use %s",
+					clusterID, oldIP, localIP, endpointMapping.IP)
+			}
+			endpointMapping.ClusterMappings[clusterID] = netv1alpha1.ClusterMapping{}
+			endpointMappings[oldIP] = endpointMapping
+		}
+	}
+	if err := liqoIPAM.ipamStorage.updateEndpointMappings(endpointMappings); err != nil {
+		return fmt.Errorf("cannot update endpointMappings: %w", err)
+	}
+	if err := liqoIPAM.ipamStorage.updateNatMappingsConfigured(natMappingsConfigured); err != nil {
+		return fmt.Errorf("cannot update natMappingsConfigured: %w", err)
+	}
+	return nil
+}
+
+// checkNetworkManagerStopped returns an error if the network manager running in the given namespace holds its lease,
+// i.e. it may be modifying the IPAM state.
+func checkNetworkManagerStopped(dynClient dynamic.Interface, namespace string) error {
+	unstructuredLease, err := dynClient.Resource(coordinationv1.SchemeGroupVersion.WithResource("leases")).Namespace(namespace).
+		Get(context.Background(), consts.NetworkManagerLeaderElectionID, metav1.GetOptions{})
+	if errors.IsNotFound(err) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("cannot get the lease of the network manager: %w", err)
+	}
+	lease := &coordinationv1.Lease{}
+	if err := runtime.DefaultUnstructuredConverter.FromUnstructured(unstructuredLease.Object, lease); err != nil {
+		return fmt.Errorf("cannot map unstructured resource to Lease resource: %w", err)
+	}
+	if isLeaseHeld(lease, time.Now()) {
+		return fmt.Errorf("the network manager %s holds its lease: it has to be stopped first", *lease.Spec.HolderIdentity)
+	}
+	return nil
+}
+
+// isLeaseHeld returns whether the given lease has a holder and has not expired at the given time.
+func isLeaseHeld(lease *coordinationv1.Lease, now time.Time) bool {
+	if lease.Spec.HolderIdentity == nil || *lease.Spec.HolderIdentity == "" ||
+		lease.Spec.RenewTime == nil || lease.Spec.LeaseDurationSeconds == nil {
+		return false
+	}
+	expiration := lease.Spec.RenewTime.Add(time.Duration(*lease.Spec.LeaseDurationSeconds) * time.Second)
+	return now.Before(expiration)
+}
+
+// replaceSpec overwrites the whole spec of the IpamStorage resource.
+func (ipamStorage *IPAMStorage) replaceSpec(spec *netv1alpha1.IpamSpec) error {
+	ipam, err := ipamStorage.getConfig()
+	if err != nil {
+		return err
+	}
+	ipam.Spec = *spec
+	unstructuredIpam, err := runtime.DefaultUnstructuredConverter.ToUnstructured(ipam)
+	if err != nil {
+		return fmt.Errorf("cannot map ipam resource to unstructured resource: %w", err)
+	}
+	_, err = ipamStorage.dynClient.Resource(netv1alpha1.IpamGroupResource).
+		Update(context.Background(), &unstructured.Unstructured{Object: unstructuredIpam}, metav1.UpdateOptions{})
+	if err != nil {
+		return fmt.Errorf("cannot update resource %s: %w", ipam.Name, err)
+	}
+	return nil
+}
+
+func listTunnelEndpoints(dynClient dynamic.Interface) ([]netv1alpha1.TunnelEndpoint, error) {
+	list, err := dynClient.Resource(netv1alpha1.TunnelEndpointGroupVersionResource).
+		List(context.Background(), metav1.ListOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("unable to list resources of type %s: %w", netv1alpha1.TunnelEndpointGroupVersionResource, err)
+	}
+	teps := make([]netv1alpha1.TunnelEndpoint, len(list.Items))
+	for i := range list.Items {
+		if err := runtime.DefaultUnstructuredConverter.FromUnstructured(list.Items[i].Object, &teps[i]); err != nil {
+			return nil, fmt.Errorf("cannot map unstructured resource to TunnelEndpoint resource: %w", err)
+		}
+	}
+	return teps, nil
+}
+
+func listNatMappings(dynClient dynamic.Interface) ([]netv1alpha1.NatMapping, error) {
+	list, err := dynClient.Resource(netv1alpha1.NatMappingGroupResource).
+		List(context.Background(), metav1.ListOptions{
+			LabelSelector: fmt.Sprintf("%s=%s", consts.NatMappingResourceLabelKey, consts.NatMappingResourceLabelValue),
+		})
+	if err != nil {
+		return nil, fmt.Errorf("unable to list resources of type %s: %w", netv1alpha1.NatMappingGroupResource, err)
+	}
+	natMappings := make([]netv1alpha1.NatMapping, len(list.Items))
+	for i := range list.Items {
+		if err := runtime.DefaultUnstructuredConverter.FromUnstructured(list.Items[i].Object, &natMappings[i]); err != nil {
+			return nil, fmt.Errorf("cannot map unstructured resource to NatMapping resource: %w", err)
+		}
+	}
+	return natMappings, nil
+}
+
+func hasNatMapping(natMappings []netv1alpha1.NatMapping, clusterID, oldIP string) bool {
+	for i := range natMappings {
+		if natMappings[i].Spec.ClusterID == clusterID {
+			if _, found := natMappings[i].Spec.ClusterMappings[oldIP]; found {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// sortedKeys returns the keys of the given map, which must be keyed by strings, in lexicographic order.
+func sortedKeys(m interface{}) []string {
+	var keys []string
+	for _, key := range reflect.ValueOf(m).MapKeys() {
+		keys = append(keys, key.String())
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,142 @@
+package ipam
+
+import (
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/ginkgo/extensions/table"
+	. "github.com/onsi/gomega"
+	coordinationv1 "k8s.io/api/coordination/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/pointer"
+
+	netv1alpha1 "github.com/liqotech/liqo/apis/net/v1alpha1"
+	"github.com/liqotech/liqo/pkg/consts"
+)
+
+var _ = Describe("CheckConsistency", func() {
+	const (
+		clusterID          = "cluster1"
+		podCIDR            = "10.0.0.0/24"
+		externalCIDR       = "10.0.1.0/24"
+		remotePodCIDR      = "10.50.0.0/16"
+		remoteExternalCIDR = "10.60.0.0/16"
+		natExternalCIDR    = "192.168.30.0/24"
+		endpointIP         = "10.50.0.6"
+	)
+
+	var (
+		spec        *netv1alpha1.IpamSpec
+		teps        []netv1alpha1.TunnelEndpoint
+		natMappings []netv1alpha1.NatMapping
+	)
+
+	BeforeEach(func() {
+		spec = &netv1alpha1.IpamSpec{
+			PodCIDR:      podCIDR,
+			ExternalCIDR: externalCIDR,
+			ClusterSubnets: map[string]netv1alpha1.Subnets{
+				clusterID: {
+					LocalNATPodCIDR:      consts.DefaultCIDRValue,
+					RemotePodCIDR:        remotePodCIDR,
+					LocalNATExternalCIDR: natExternalCIDR,
+					RemoteExternalCIDR:   remoteExternalCIDR,
+				},
+			},
+			EndpointMappings: map[string]netv1alpha1.EndpointMapping{
+				endpointIP: {IP: "10.0.1.1", ClusterMappings: map[string]netv1alpha1.ClusterMapping{clusterID: {}}},
+			},
+			NatMappingsConfigured: map[string]netv1alpha1.ConfiguredCluster{clusterID: {}},
+		}
+		teps = []netv1alpha1.TunnelEndpoint{{
+			Spec: netv1alpha1.TunnelEndpointSpec{
+				ClusterID:    clusterID,
+				PodCIDR:      remotePodCIDR,
+				ExternalCIDR: remoteExternalCIDR,
+			},
+			Status: netv1alpha1.TunnelEndpointStatus{
+				Phase:                 consts.TepReady,
+				LocalPodCIDR:          podCIDR,
+				LocalNATPodCIDR:       consts.DefaultCIDRValue,
+				LocalExternalCIDR:     externalCIDR,
+				LocalNATExternalCIDR:  natExternalCIDR,
+				RemoteNATPodCIDR:      consts.DefaultCIDRValue,
+				RemoteNATExternalCIDR: consts.DefaultCIDRValue,
+			},
+		}}
+		natMappings = []netv1alpha1.NatMapping{{
+			Spec: netv1alpha1.NatMappingSpec{
+				ClusterID:       clusterID,
+				PodCIDR:         remotePodCIDR,
+				ExternalCIDR:    natExternalCIDR,
+				ClusterMappings: netv1alpha1.Mappings{endpointIP: "192.168.30.1"},
+			},
+		}}
+	})
+
+	Context("When the IPAM state matches the resources", func() {
+		It("should not report any inconsistency", func() {
+			Expect(checkConsistency(spec, teps, natMappings)).To(BeEmpty())
+		})
+	})
+
+	Context("When the TunnelEndpoint reports a different remapped network", func() {
+		It("should report the mismatch", func() {
+			teps[0].Status.RemoteNATPodCIDR = "10.70.0.0/16"
+			Expect(checkConsistency(spec, teps, natMappings)).To(ConsistOf(Inconsistency{
+				ClusterID: clusterID,
+				Resource:  "TunnelEndpoint",
+				Message:   "remote PodCIDR is 10.70.0.0/16, while the IPAM reserved 10.50.0.0/16",
+			}))
+		})
+	})
+
+	Context("When the IPAM holds the subnets of a cluster which is not peered", func() {
+		It("should report the orphan subnets and NAT mappings", func() {
+			inconsistencies := checkConsistency(spec, nil, nil)
+			Expect(inconsistencies).To(HaveLen(3))
+			Expect(inconsistencies[0].Resource).To(Equal("IpamStorage"))
+			Expect(inconsistencies[0].ClusterID).To(Equal(clusterID))
+		})
+	})
+
+	Context("When an endpoint is mapped to an IP not matching the IPAM", func() {
+		It("should report the wrong mapping", func() {
+			natMappings[0].Spec.ClusterMappings[endpointIP] = "192.168.30.2"
+			Expect(checkConsistency(spec, teps, natMappings)).To(ConsistOf(Inconsistency{
+				ClusterID: clusterID,
+				Resource:  "NatMapping",
+				Message:   "endpoint 10.50.0.6 is mapped to 192.168.30.2, while the IPAM expects 192.168.30.1",
+			}))
+		})
+	})
+
+	Context("When the IPAM holds an endpoint mapping missing from the NatMapping", func() {
+		It("should report the stale mapping", func() {
+			natMappings[0].Spec.ClusterMappings = netv1alpha1.Mappings{}
+			Expect(checkConsistency(spec, teps, natMappings)).To(ConsistOf(Inconsistency{
+				ClusterID: clusterID,
+				Resource:  "IpamStorage",
+				Message:   "endpoint 10.50.0.6 is reflected in the cluster, but the NatMapping does not contain it",
+			}))
+		})
+	})
+})
+
+var _ = Describe("isLeaseHeld", func() {
+	now := time.Now()
+
+	DescribeTable("checking whether the network manager holds its lease",
+		func(holder string, renewTime time.Time, expected bool) {
+			lease := &coordinationv1.Lease{Spec: coordinationv1.LeaseSpec{
+				HolderIdentity:       pointer.StringPtr(holder),
+				LeaseDurationSeconds: pointer.Int32Ptr(7),
+				RenewTime:            &metav1.MicroTime{Time: renewTime},
+			}}
+			Expect(isLeaseHeld(lease, now)).To(Equal(expected))
+		},
+		Entry("lease recently renewed", "network-manager", now.Add(-time.Second), true),
+		Entry("lease expired", "network-manager", now.Add(-time.Minute), false),
+		Entry("lease released", "", now.Add(-time.Second), false),
+	)
+})
@@ -6,6 +6,7 @@ import (
 	"fmt"
 	"net"
 	"strings"
+	"sync"
 
 	goipam "github.com/metal-stack/go-ipam"
 	grpc "google.golang.org/grpc"
@@ -56,6 +57,8 @@ type Ipam interface {
 	SetPodCIDR(podCIDR string) error
 	// SetServiceCIDR sets the cluster ServiceCIDR.
 	SetServiceCIDR(serviceCIDR string) error
+	// GetMeshSubnets returns the networks recorded for the given cluster of a multi-peer mesh, or nil if none.
+	GetMeshSubnets(clusterID string) (*netv1alpha1.MeshSubnets, error)
 	// Terminate function enforces a graceful termination of the IPAM module.
 	Terminate()
 	IpamServer
@@ -66,7 +69,10 @@ type IPAM struct {
 	ipam               goipam.Ipamer
 	ipamStorage        IpamStorage
 	natMappingInflater natmappinginflater.Interface
-	grpcServer         *grpc.Server
+	mappingNotifier    mappingNotifier
+	// mappingMutex serializes the changes of the endpoint mappings, since the gRPC requests are served concurrently.
+	mappingMutex sync.Mutex
+	grpcServer   *grpc.Server
 	UnimplementedIpamServer
 }
 
@@ -82,6 +88,12 @@ var Pools = []string{
 	"172.16.0.0/12",
 }
 
+// IPv6Pools is a constant slice containing private IPv6 networks (unique local addresses).
+// The networks of IPv6 clusters are remapped only using the IPv6 pools, and vice versa.
+var IPv6Pools = []string{
+	"fd00::/8",
+}
+
 // Init uses the Ipam resource to retrieve and allocate reserved networks.
 func (liqoIPAM *IPAM) Init(pools []string, dynClient dynamic.Interface, listeningPort int) error {
 	var err error
@@ -98,15 +110,25 @@ func (liqoIPAM *IPAM) Init(pools []string, dynClient dynamic.Interface, listenin
 		return fmt.Errorf("cannot get Ipam config: %w", err)
 	}
 
-	// Have network pools been already set? If not, take them from caller
-	if len(ipamPools) == 0 {
-		for _, network := range pools {
-			if _, err := liqoIPAM.ipam.NewPrefix(network); err != nil {
-				return fmt.Errorf("failed to create a new prefix for network %s", network)
-			}
-			ipamPools = append(ipamPools, network)
-			klog.Infof("Pool %s has been successfully added to the pool list", network)
+	// Add the network pools taken from the caller which have not been set yet. This covers both the first start,
+	// and the upgrades introducing new default pools.
+	poolsAdded := false
+	for _, network := range pools {
+		if slice.ContainsString(ipamPools, network, nil) {
+			continue
+		}
+		if pool, overlaps := liqoIPAM.overlapsWithPools(network, ipamPools); overlaps {
+			klog.Warningf("Pool %s has not been added to the pool list, since it overlaps with the existing pool %s", network, pool)
+			continue
+		}
+		if _, err := liqoIPAM.ipam.NewPrefix(network); err != nil {
+			return fmt.Errorf("failed to create a new prefix for network %s", network)
 		}
+		ipamPools = append(ipamPools, network)
+		poolsAdded = true
+		klog.Infof("Pool %s has been successfully added to the pool list", network)
+	}
+	if poolsAdded {
 		err = liqoIPAM.ipamStorage.updatePools(ipamPools)
 		if err != nil {
 			return fmt.Errorf("cannot set pools: %w", err)
@@ -244,17 +266,18 @@ func (liqoIPAM *IPAM) overlapsWithPool(network string) (overlappingPool string,
 		err = fmt.Errorf("cannot get Ipam config: %w", err)
 		return
 	}
+	overlappingPool, overlaps = liqoIPAM.overlapsWithPools(network, pools)
+	return
+}
+
+func (liqoIPAM *IPAM) overlapsWithPools(network string, pools []string) (overlappingPool string, overlaps bool) {
 	for _, pool := range pools {
-		overlaps, err = liqoIPAM.overlapsWithNetwork(network, pool)
-		if err != nil {
-			return
-		}
-		if overlaps {
-			overlappingPool = pool
-			return
+		// overlapsWithNetwork never returns an error.
+		if overlaps, _ = liqoIPAM.overlapsWithNetwork(network, pool); overlaps {
+			return pool, true
 		}
 	}
-	return
+	return "", false
 }
 
 // Function that receives a network as parameter and returns the pool to which this network belongs to.
@@ -291,7 +314,7 @@ func (liqoIPAM *IPAM) getPoolFromNetwork(network string) (networkPool string, su
 
 func (liqoIPAM *IPAM) clusterSubnetEqualToPool(pool string) (string, error) {
 	klog.Infof("Network %s is equal to a pool, looking for a mapping..", pool)
-	mappedNetwork, err := liqoIPAM.getNetworkFromPool(utils.GetMask(pool))
+	mappedNetwork, err := liqoIPAM.getNetworkFromPool(utils.GetMask(pool), utils.IsIPv6CIDR(pool))
 	if err != nil {
 		klog.Infof("Mapping not found, acquiring the entire network pool..")
 		err = liqoIPAM.reservePoolInHalves(pool)
@@ -347,7 +370,7 @@ func (liqoIPAM *IPAM) getOrRemapNetwork(network string) (string, error) {
 		}
 	}
 	/* Network is already reserved, need a mapping */
-	mappedNetwork, err = liqoIPAM.getNetworkFromPool(utils.GetMask(network))
+	mappedNetwork, err = liqoIPAM.getNetworkFromPool(utils.GetMask(network), utils.IsIPv6CIDR(network))
 	if err != nil {
 		return "", err
 	}
@@ -385,8 +408,15 @@ func (liqoIPAM *IPAM) GetSubnetsPerCluster(
 
 	klog.Infof("Cluster networks allocation request received: %s", clusterID)
 
+	// Get the networks negotiated for the cluster, if it belongs to a multi-peer mesh
+	meshSubnets, err := liqoIPAM.ipamStorage.getMeshSubnets()
+	if err != nil {
+		return "", "", fmt.Errorf("cannot get mesh subnets: %w", err)
+	}
+	mesh := meshSubnets[clusterID]
+
 	// Get PodCidr
-	mappedPodCIDR, err = liqoIPAM.getOrRemapNetwork(podCidr)
+	mappedPodCIDR, err = liqoIPAM.getOrRemapClusterNetwork(podCidr, mesh.PodCIDR)
 	if err != nil {
 		return "", "", fmt.Errorf("cannot get a PodCIDR for cluster %s:%w", clusterID, err)
 	}
@@ -400,7 +430,7 @@ func (liqoIPAM *IPAM) GetSubnetsPerCluster(
 	}
 
 	// Get ExternalCIDR
-	mappedExternalCIDR, err = liqoIPAM.getOrRemapNetwork(externalCIDR)
+	mappedExternalCIDR, err = liqoIPAM.getOrRemapClusterNetwork(externalCIDR, mesh.ExternalCIDR)
 	if err != nil {
 		_ = liqoIPAM.FreeReservedSubnet(mappedPodCIDR)
 		return "", "", fmt.Errorf("cannot get an ExternalCIDR for cluster %s:%w", clusterID, err)
@@ -432,15 +462,19 @@ func (liqoIPAM *IPAM) GetSubnetsPerCluster(
 	return mappedPodCIDR, mappedExternalCIDR, nil
 }
 
-// getNetworkFromPool returns a network with mask length equal to mask taken by a network pool.
-func (liqoIPAM *IPAM) getNetworkFromPool(mask uint8) (string, error) {
+// getNetworkFromPool returns a network with mask length equal to mask taken by a network pool
+// of the IPv6 or of the IPv4 family.
+func (liqoIPAM *IPAM) getNetworkFromPool(mask uint8, ipv6 bool) (string, error) {
 	// Get network pools
 	pools, err := liqoIPAM.ipamStorage.getPools()
 	if err != nil {
 		return "", fmt.Errorf("cannot get network pools: %w", err)
 	}
-	// For each pool, try to get a network with mask length mask
+	// For each pool of the requested family, try to get a network with mask length mask
 	for _, pool := range pools {
+		if utils.IsIPv6CIDR(pool) != ipv6 {
+			continue
+		}
 		if mappedNetwork, err := liqoIPAM.ipam.AcquireChildPrefix(pool, mask); err == nil {
 			klog.Infof("Acquired network %s", mappedNetwork)
 			return mappedNetwork.String(), nil
@@ -618,6 +652,8 @@ func (liqoIPAM *IPAM) initNatMappingsPerCluster(clusterID string, subnets netv1a
 
 // terminateNatMappingsPerCluster is used to update endpointMappings after a cluster peering is terminated.
 func (liqoIPAM *IPAM) terminateNatMappingsPerCluster(clusterID string) error {
+	liqoIPAM.mappingMutex.Lock()
+	defer liqoIPAM.mappingMutex.Unlock()
 	// Get NAT mappings
 	// natMappings keys are the set of endpoint reflected on remote cluster.
 	natMappings, err := liqoIPAM.natMappingInflater.GetNatMappings(clusterID)
@@ -649,6 +685,8 @@ func (liqoIPAM *IPAM) terminateNatMappingsPerCluster(clusterID string) error {
 	for ip := range natMappings {
 		m := endpointMappings[ip]
 		delete(m.ClusterMappings, clusterID)
+		liqoIPAM.mappingNotifier.notify(MappingEvent_DELETED,
+			&EndpointMapping{ClusterID: clusterID, Ip: ip, ExternalIP: m.IP, MappedIP: natMappings[ip]})
 		if len(m.ClusterMappings) == 0 {
 			// There are no more clusters using this endpoint IP
 
@@ -738,7 +776,7 @@ func (liqoIPAM *IPAM) RemoveNetworkPool(network string) error {
 		return fmt.Errorf("network %s is not a network pool", network)
 	}
 	// Cannot remove a default one
-	if contains := slice.ContainsString(Pools, network, nil); contains {
+	if slice.ContainsString(Pools, network, nil) || slice.ContainsString(IPv6Pools, network, nil) {
 		return fmt.Errorf("cannot remove a default network pool")
 	}
 	// Check overlapping with cluster networks
@@ -874,6 +912,7 @@ func (liqoIPAM *IPAM) RemoveLocalSubnetsPerCluster(clusterID string) error {
 }
 
 // GetExternalCIDR chooses and returns the local cluster's ExternalCIDR.
+// It belongs to the same IP family of the cluster PodCIDR, if already set, and to the IPv4 one otherwise.
 func (liqoIPAM *IPAM) GetExternalCIDR(mask uint8) (string, error) {
 	var externalCIDR string
 	var err error
@@ -885,7 +924,11 @@ func (liqoIPAM *IPAM) GetExternalCIDR(mask uint8) (string, error) {
 	if externalCIDR != "" {
 		return externalCIDR, nil
 	}
-	if externalCIDR, err = liqoIPAM.getNetworkFromPool(mask); err != nil {
+	podCIDR, err := liqoIPAM.ipamStorage.getPodCIDR()
+	if err != nil {
+		return "", fmt.Errorf("cannot get PodCIDR: %w", err)
+	}
+	if externalCIDR, err = liqoIPAM.getNetworkFromPool(mask, utils.IsIPv6CIDR(podCIDR)); err != nil {
 		return "", fmt.Errorf("cannot allocate an ExternalCIDR:%w", err)
 	}
 	if err := liqoIPAM.ipamStorage.updateExternalCIDR(externalCIDR); err != nil {
@@ -966,6 +1009,8 @@ func (liqoIPAM *IPAM) mapIPToExternalCIDR(clusterID, remoteExternalCIDR, ip stri
 	if err := liqoIPAM.natMappingInflater.AddMapping(ip, newIP, clusterID); err != nil {
 		return "", fmt.Errorf("cannot add NAT mapping: %w", err)
 	}
+	liqoIPAM.mappingNotifier.notify(MappingEvent_ADDED,
+		&EndpointMapping{ClusterID: clusterID, Ip: ip, ExternalIP: endpointMapping.IP, MappedIP: newIP})
 
 	return newIP, nil
 }
@@ -1029,6 +1074,8 @@ func (liqoIPAM *IPAM) mapEndpointIPInternal(clusterID, ip string) (string, error
 // if the endpoint IP does not belong to cluster PodCIDR, maps
 // the endpoint IP to a new IP taken from the remote ExternalCIDR of the remote cluster.
 func (liqoIPAM *IPAM) MapEndpointIP(ctx context.Context, mapRequest *MapRequest) (*MapResponse, error) {
+	liqoIPAM.mappingMutex.Lock()
+	defer liqoIPAM.mappingMutex.Unlock()
 	mappedIP, err := liqoIPAM.mapEndpointIPInternal(mapRequest.GetClusterID(), mapRequest.GetIp())
 	if err != nil {
 		return &MapResponse{}, fmt.Errorf("cannot map endpoint IP to ExternalCIDR of cluster %s, %w",
@@ -1134,9 +1181,16 @@ func (liqoIPAM *IPAM) unmapEndpointIPInternal(clusterID, endpointIP string) erro
 	}
 
 	// Remove NAT mapping
+	natMappings, err := liqoIPAM.natMappingInflater.GetNatMappings(clusterID)
+	if err != nil {
+		return err
+	}
+	mappedIP := natMappings[endpointIP]
 	if err := liqoIPAM.natMappingInflater.RemoveMapping(endpointIP, clusterID); err != nil {
 		return err
 	}
+	liqoIPAM.mappingNotifier.notify(MappingEvent_DELETED,
+		&EndpointMapping{ClusterID: clusterID, Ip: endpointIP, ExternalIP: endpointMapping.IP, MappedIP: mappedIP})
 
 	// Set endpoint IP as unused by deleting entry of cluster
 	delete(endpointMapping.ClusterMappings, clusterID)
@@ -1161,6 +1215,8 @@ func (liqoIPAM *IPAM) unmapEndpointIPInternal(clusterID, endpointIP string) erro
 
 // UnmapEndpointIP set the endpoint as unused for a specific cluster.
 func (liqoIPAM *IPAM) UnmapEndpointIP(ctx context.Context, unmapRequest *UnmapRequest) (*UnmapResponse, error) {
+	liqoIPAM.mappingMutex.Lock()
+	defer liqoIPAM.mappingMutex.Unlock()
 	err := liqoIPAM.unmapEndpointIPInternal(unmapRequest.GetClusterID(), unmapRequest.GetIp())
 	if err != nil {
 		return &UnmapResponse{}, fmt.Errorf("cannot unmap the IP of endpoint %s:%w", unmapRequest.GetIp(), err)
@@ -1168,6 +1224,250 @@ func (liqoIPAM *IPAM) UnmapEndpointIP(ctx context.Context, unmapRequest *UnmapRe
 	return &UnmapResponse{}, nil
 }
 
+/* mapEndpointIPsInternal is the internal implementation of MapEndpointIPs. Each IP is mapped as in
+mapEndpointIPInternal, but either all the IPs are mapped or none, and the new mappings are persisted
+with a single update of both the IpamStorage and the NatMapping resources. The IPs of the ExternalCIDR
+are acquired from an in-memory copy of its prefix, which is persisted together with the endpoint mappings. */
+func (liqoIPAM *IPAM) mapEndpointIPsInternal(clusterID string, ips []string) ([]string, error) {
+	for _, ip := range ips {
+		if err := validateEndpointMappingInputs(clusterID, ip); err != nil {
+			return nil, err
+		}
+	}
+
+	// Get cluster subnets
+	clusterSubnets, err := liqoIPAM.ipamStorage.getClusterSubnets()
+	if err != nil {
+		return nil, fmt.Errorf("cannot get cluster subnets:%w", err)
+	}
+	subnets, exists := clusterSubnets[clusterID]
+	if !exists {
+		return nil, fmt.Errorf("cluster %s has not a network configuration", clusterID)
+	}
+	podCIDR, err := liqoIPAM.ipamStorage.getPodCIDR()
+	if err != nil || podCIDR == "" {
+		return nil, fmt.Errorf("cannot get cluster PodCIDR: %w", err)
+	}
+	endpointMappings, err := liqoIPAM.ipamStorage.getEndpointMappings()
+	if err != nil {
+		return nil, fmt.Errorf("cannot get Endpoint IPs: %w", err)
+	}
+	localExternalCIDR, err := liqoIPAM.ipamStorage.getExternalCIDR()
+	if err != nil {
+		return nil, fmt.Errorf("cannot get ExternalCIDR: %w", err)
+	}
+	externalCIDR := subnets.LocalNATExternalCIDR
+	if externalCIDR == consts.DefaultCIDRValue {
+		externalCIDR = localExternalCIDR
+	}
+	natMappings, err := liqoIPAM.natMappingInflater.GetNatMappings(clusterID)
+	if err != nil {
+		return nil, fmt.Errorf("cannot get NAT mappings: %w", err)
+	}
+
+	// The in-memory copy of the ExternalCIDR prefix is created only if new IPs have to be acquired.
+	var externalCIDRStorage goipam.Storage
+	var externalCIDRIPAM goipam.Ipamer
+
+	mappedIPs := make([]string, len(ips))
+	newNatMappings := make(map[string]string)
+	var added []*EndpointMapping
+	for i, ip := range ips {
+		belongs, err := ipBelongsToNetwork(ip, podCIDR)
+		if err != nil {
+			return nil, fmt.Errorf("cannot establish if IP %s belongs to PodCIDR:%w", ip, err)
+		}
+		if belongs {
+			// Local Pod: the IP is mapped to the network used in the remote cluster for the local PodCIDR.
+			if mappedIPs[i], err = utils.MapIPToNetwork(subnets.LocalNATPodCIDR, ip); err != nil {
+				return nil, fmt.Errorf("cannot map endpoint IP %s to PodCIDR of remote cluster %s:%w", ip, clusterID, err)
+			}
+			continue
+		}
+		// Reflected Pod: the IP is mapped to an IP of the ExternalCIDR, acquired if not already done.
+		endpointMapping, exists := endpointMappings[ip]
+		if !exists {
+			if externalCIDRIPAM == nil {
+				if externalCIDRStorage, err = newMemoryStorage(liqoIPAM.ipamStorage, localExternalCIDR); err != nil {
+					return nil, err
+				}
+				externalCIDRIPAM = goipam.NewWithStorage(externalCIDRStorage)
+			}
+			ipamIP, err := externalCIDRIPAM.AcquireIP(localExternalCIDR)
+			if err != nil {
+				return nil, fmt.Errorf("cannot allocate a new IP for endpoint %s:%w", ip, err)
+			}
+			endpointMapping = netv1alpha1.EndpointMapping{
+				IP:              ipamIP.IP.String(),
+				ClusterMappings: make(map[string]netv1alpha1.ClusterMapping),
+			}
+		}
+		endpointMapping.ClusterMappings[clusterID] = netv1alpha1.ClusterMapping{}
+		endpointMappings[ip] = endpointMapping
+		if mappedIPs[i], err = utils.MapIPToNetwork(externalCIDR, endpointMapping.IP); err != nil {
+			return nil, fmt.Errorf("cannot map endpoint IP %s to ExternalCIDR:%w", endpointMapping.IP, err)
+		}
+		if natMappings[ip] != mappedIPs[i] {
+			newNatMappings[ip] = mappedIPs[i]
+			added = append(added, &EndpointMapping{ClusterID: clusterID, Ip: ip, ExternalIP: endpointMapping.IP, MappedIP: mappedIPs[i]})
+		}
+	}
+	if len(newNatMappings) == 0 {
+		return mappedIPs, nil
+	}
+
+	if err := liqoIPAM.natMappingInflater.AddMappings(newNatMappings, clusterID); err != nil {
+		return nil, fmt.Errorf("cannot add NAT mappings: %w", err)
+	}
+	if err := liqoIPAM.persistEndpointMappings(endpointMappings, externalCIDRStorage, localExternalCIDR); err != nil {
+		var newIPs []string
+		for ip := range newNatMappings {
+			newIPs = append(newIPs, ip)
+		}
+		if err := liqoIPAM.natMappingInflater.RemoveMappings(newIPs, clusterID); err != nil {
+			klog.Errorf("cannot remove NAT mappings of cluster %s: %s", clusterID, err)
+		}
+		return nil, fmt.Errorf("cannot update endpointMappings:%w", err)
+	}
+	for _, mapping := range added {
+		liqoIPAM.mappingNotifier.notify(MappingEvent_ADDED, mapping)
+	}
+	return mappedIPs, nil
+}
+
+// newMemoryStorage returns an in-memory storage containing a copy of the given prefix, read from the IpamStorage.
+func newMemoryStorage(ipamStorage IpamStorage, cidr string) (goipam.Storage, error) {
+	prefix, err := ipamStorage.ReadPrefix(cidr)
+	if err != nil {
+		return nil, fmt.Errorf("cannot get prefix %s: %w", cidr, err)
+	}
+	storage := goipam.NewMemory()
+	if _, err := storage.CreatePrefix(prefix); err != nil {
+		return nil, fmt.Errorf("cannot copy prefix %s: %w", cidr, err)
+	}
+	return storage, nil
+}
+
+// persistEndpointMappings updates the endpoint mappings in the IpamStorage, together with the given prefix
+// if an in-memory storage containing it is provided.
+func (liqoIPAM *IPAM) persistEndpointMappings(endpointMappings map[string]netv1alpha1.EndpointMapping,
+	memoryStorage goipam.Storage, cidr string) error {
+	if memoryStorage == nil {
+		return liqoIPAM.ipamStorage.updateEndpointMappings(endpointMappings)
+	}
+	prefix, err := memoryStorage.ReadPrefix(cidr)
+	if err != nil {
+		return fmt.Errorf("cannot get prefix %s: %w", cidr, err)
+	}
+	return liqoIPAM.ipamStorage.updateEndpointMappingsAndPrefix(endpointMappings, prefix)
+}
+
+// MapEndpointIPs maps a set of endpoint IPs, e.g. the ones of an EndpointSlice, as MapEndpointIP does
+// for a single IP. Either all the IPs are mapped or none, and the mapped IPs are returned in the same order.
+func (liqoIPAM *IPAM) MapEndpointIPs(ctx context.Context, request *MapEndpointIPsRequest) (*MapEndpointIPsResponse, error) {
+	liqoIPAM.mappingMutex.Lock()
+	defer liqoIPAM.mappingMutex.Unlock()
+	mappedIPs, err := liqoIPAM.mapEndpointIPsInternal(request.GetClusterID(), request.GetIps())
+	if err != nil {
+		return &MapEndpointIPsResponse{}, fmt.Errorf("cannot map endpoint IPs to ExternalCIDR of cluster %s, %w",
+			request.GetClusterID(), err)
+	}
+	return &MapEndpointIPsResponse{Ips: mappedIPs}, nil
+}
+
+/* unmapEndpointIPsInternal is the internal implementation of UnmapEndpointIPs. Each IP is unmapped as in
+unmapEndpointIPInternal, but the changes are persisted with a single update of both the IpamStorage and the
+NatMapping resources, and the IPs of the ExternalCIDR are freed only once the update succeeded. */
+func (liqoIPAM *IPAM) unmapEndpointIPsInternal(clusterID string, ips []string) error {
+	for _, ip := range ips {
+		if err := validateEndpointMappingInputs(clusterID, ip); err != nil {
+			return err
+		}
+	}
+
+	endpointMappings, err := liqoIPAM.ipamStorage.getEndpointMappings()
+	if err != nil {
+		return fmt.Errorf("cannot get Endpoint IPs: %w", err)
+	}
+	localExternalCIDR, err := liqoIPAM.ipamStorage.getExternalCIDR()
+	if err != nil {
+		return fmt.Errorf("cannot get ExternalCIDR: %w", err)
+	}
+
+	var unmapped, freeIPs []string
+	var deleted []*EndpointMapping
+	for _, ip := range ips {
+		endpointMapping, exists := endpointMappings[ip]
+		if !exists {
+			// The IP belongs to the local PodCIDR, or it has already been unmapped.
+			continue
+		}
+		if _, reflected := endpointMapping.ClusterMappings[clusterID]; !reflected {
+			continue
+		}
+		delete(endpointMapping.ClusterMappings, clusterID)
+		if len(endpointMapping.ClusterMappings) == 0 {
+			// There are no more clusters using this endpoint IP
+			freeIPs = append(freeIPs, endpointMapping.IP)
+			delete(endpointMappings, ip)
+		}
+		unmapped = append(unmapped, ip)
+		deleted = append(deleted, &EndpointMapping{ClusterID: clusterID, Ip: ip, ExternalIP: endpointMapping.IP})
+	}
+	if len(unmapped) == 0 {
+		return nil
+	}
+
+	natMappings, err := liqoIPAM.natMappingInflater.GetNatMappings(clusterID)
+	if err != nil {
+		return err
+	}
+	// The removed NAT mappings are restored if the IpamStorage cannot be updated.
+	removed := make(map[string]string)
+	for _, mapping := range deleted {
+		if mappedIP, exists := natMappings[mapping.Ip]; exists {
+			mapping.MappedIP = mappedIP
+			removed[mapping.Ip] = mappedIP
+		}
+	}
+	if err := liqoIPAM.natMappingInflater.RemoveMappings(unmapped, clusterID); err != nil {
+		return err
+	}
+	if err := liqoIPAM.ipamStorage.updateEndpointMappings(endpointMappings); err != nil {
+		if err := liqoIPAM.natMappingInflater.AddMappings(removed, clusterID); err != nil {
+			klog.Errorf("cannot restore NAT mappings of cluster %s: %s", clusterID, err)
+		}
+		return fmt.Errorf("cannot update endpointIPs:%w", err)
+	}
+	for _, mapping := range deleted {
+		liqoIPAM.mappingNotifier.notify(MappingEvent_DELETED, mapping)
+	}
+
+	var releaseErrors []string
+	for _, freeIP := range freeIPs {
+		if err := liqoIPAM.ipam.ReleaseIPFromPrefix(localExternalCIDR, freeIP); err != nil {
+			releaseErrors = append(releaseErrors, fmt.Sprintf("%s: %s", freeIP, err))
+			continue
+		}
+		klog.Infof("IP %s has been freed", freeIP)
+	}
+	if len(releaseErrors) > 0 {
+		return fmt.Errorf("cannot free IPs: %s", strings.Join(releaseErrors, ", "))
+	}
+	return nil
+}
+
+// UnmapEndpointIPs sets a set of endpoints, e.g. the ones of an EndpointSlice, as unused for a specific cluster.
+func (liqoIPAM *IPAM) UnmapEndpointIPs(ctx context.Context, request *UnmapEndpointIPsRequest) (*UnmapEndpointIPsResponse, error) {
+	liqoIPAM.mappingMutex.Lock()
+	defer liqoIPAM.mappingMutex.Unlock()
+	if err := liqoIPAM.unmapEndpointIPsInternal(request.GetClusterID(), request.GetIps()); err != nil {
+		return &UnmapEndpointIPsResponse{}, fmt.Errorf("cannot unmap the IPs of the endpoints of cluster %s:%w",
+			request.GetClusterID(), err)
+	}
+	return &UnmapEndpointIPsResponse{}, nil
+}
+
 // SetPodCIDR sets the PodCIDR.
 func (liqoIPAM *IPAM) SetPodCIDR(podCIDR string) error {
 	var oldPodCIDR string
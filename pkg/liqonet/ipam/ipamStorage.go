@@ -37,6 +37,7 @@ type IpamStorage interface {
 	updatePools(pools []string) error
 	updateExternalCIDR(externalCIDR string) error
 	updateEndpointMappings(endpoints map[string]netv1alpha1.EndpointMapping) error
+	updateEndpointMappingsAndPrefix(endpoints map[string]netv1alpha1.EndpointMapping, prefix goipam.Prefix) error
 	updatePodCIDR(podCIDR string) error
 	updateServiceCIDR(serviceCIDR string) error
 	updateNatMappingsConfigured(natMappingsConfigured map[string]netv1alpha1.ConfiguredCluster) error
@@ -47,6 +48,7 @@ type IpamStorage interface {
 	getPodCIDR() (string, error)
 	getServiceCIDR() (string, error)
 	getNatMappingsConfigured() (map[string]netv1alpha1.ConfiguredCluster, error)
+	getMeshSubnets() (map[string]netv1alpha1.MeshSubnets, error)
 	goipam.Storage
 }
 
@@ -241,6 +243,28 @@ func (ipamStorage *IPAMStorage) updateExternalCIDR(externalCIDR string) error {
 func (ipamStorage *IPAMStorage) updateEndpointMappings(endpoints map[string]netv1alpha1.EndpointMapping) error {
 	return ipamStorage.updateConfig(endpointMappingsUpdate, endpoints)
 }
+
+// updateEndpointMappingsAndPrefix updates the endpoint mappings and the given prefix through a single patch,
+// hence either both or none of them are persisted.
+func (ipamStorage *IPAMStorage) updateEndpointMappingsAndPrefix(endpoints map[string]netv1alpha1.EndpointMapping,
+	prefix goipam.Prefix) error {
+	ipam, err := ipamStorage.getConfig()
+	if err != nil {
+		return err
+	}
+	if _, ok := ipam.Spec.Prefixes[prefix.Cidr]; !ok {
+		return fmt.Errorf("prefix %s not found", prefix.Cidr)
+	}
+	gob, err := prefix.GobEncode()
+	if err != nil {
+		return fmt.Errorf("failed to encode prefix %s: %w", prefix.Cidr, err)
+	}
+	ipam.Spec.Prefixes[prefix.Cidr] = gob
+	return ipamStorage.patchConfig(map[string]interface{}{
+		endpointMappingsUpdate: endpoints,
+		prefixesUpdate:         ipam.Spec.Prefixes,
+	})
+}
 func (ipamStorage *IPAMStorage) updatePodCIDR(podCIDR string) error {
 	return ipamStorage.updateConfig(podCIDRUpdate, podCIDR)
 }
@@ -253,30 +277,36 @@ func (ipamStorage *IPAMStorage) updateNatMappingsConfigured(natMappingsConfigure
 }
 
 func (ipamStorage *IPAMStorage) updateConfig(updateType string, data interface{}) error {
-	jsonData, err := json.Marshal(data)
-
-	if err != nil {
-		klog.Errorf("cannot marshal object:%s", err.Error())
+	if err := ipamStorage.patchConfig(map[string]interface{}{updateType: data}); err != nil {
+		klog.Error(err)
 	}
+	return nil
+}
 
+// patchConfig replaces the given fields of the spec of the IpamStorage resource through a single patch.
+func (ipamStorage *IPAMStorage) patchConfig(updates map[string]interface{}) error {
 	var b bytes.Buffer
-	patch := fmt.Sprintf(
-		`[{"op": "replace", "path": "/spec/%s", "value": `,
-		updateType)
-	b.WriteString(patch)
-	b.Write(jsonData)
-	b.WriteString("}]")
+	b.WriteString("[")
+	for updateType, data := range updates {
+		jsonData, err := json.Marshal(data)
+		if err != nil {
+			return fmt.Errorf("cannot marshal object: %w", err)
+		}
+		if b.Len() > 1 {
+			b.WriteString(",")
+		}
+		fmt.Fprintf(&b, `{"op": "replace", "path": "/spec/%s", "value": `, updateType)
+		b.Write(jsonData)
+		b.WriteString("}")
+	}
+	b.WriteString("]")
 
-	_, err = ipamStorage.dynClient.Resource(netv1alpha1.IpamGroupResource).Patch(context.Background(),
+	_, err := ipamStorage.dynClient.Resource(netv1alpha1.IpamGroupResource).Patch(context.Background(),
 		ipamStorage.resourceName,
 		types.JSONPatchType,
 		b.Bytes(),
 		metav1.PatchOptions{})
-	if err != nil {
-		klog.Error(err)
-		return nil
-	}
-	return nil
+	return err
 }
 
 func (ipamStorage *IPAMStorage) getPools() ([]string, error) {
@@ -332,6 +362,14 @@ func (ipamStorage *IPAMStorage) getNatMappingsConfigured() (map[string]netv1alph
 	return ipam.Spec.NatMappingsConfigured, nil
 }
 
+func (ipamStorage *IPAMStorage) getMeshSubnets() (map[string]netv1alpha1.MeshSubnets, error) {
+	ipam, err := ipamStorage.getConfig()
+	if err != nil {
+		return nil, err
+	}
+	return ipam.Spec.MeshSubnets, nil
+}
+
 func (ipamStorage *IPAMStorage) getConfig() (*netv1alpha1.IpamStorage, error) {
 	res := &netv1alpha1.IpamStorage{}
 	list, err := ipamStorage.dynClient.
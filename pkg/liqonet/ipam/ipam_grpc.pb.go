@@ -22,6 +22,12 @@ type IpamClient interface {
 	MapEndpointIP(ctx context.Context, in *MapRequest, opts ...grpc.CallOption) (*MapResponse, error)
 	UnmapEndpointIP(ctx context.Context, in *UnmapRequest, opts ...grpc.CallOption) (*UnmapResponse, error)
 	GetHomePodIP(ctx context.Context, in *GetHomePodIPRequest, opts ...grpc.CallOption) (*GetHomePodIPResponse, error)
+	ListClusters(ctx context.Context, in *ListClustersRequest, opts ...grpc.CallOption) (*ListClustersResponse, error)
+	ListEndpointMappings(ctx context.Context, in *ListEndpointMappingsRequest, opts ...grpc.CallOption) (*ListEndpointMappingsResponse, error)
+	LookupIP(ctx context.Context, in *LookupIPRequest, opts ...grpc.CallOption) (*LookupIPResponse, error)
+	WatchMappings(ctx context.Context, in *WatchMappingsRequest, opts ...grpc.CallOption) (Ipam_WatchMappingsClient, error)
+	MapEndpointIPs(ctx context.Context, in *MapEndpointIPsRequest, opts ...grpc.CallOption) (*MapEndpointIPsResponse, error)
+	UnmapEndpointIPs(ctx context.Context, in *UnmapEndpointIPsRequest, opts ...grpc.CallOption) (*UnmapEndpointIPsResponse, error)
 }
 
 type ipamClient struct {
@@ -59,6 +65,83 @@ func (c *ipamClient) GetHomePodIP(ctx context.Context, in *GetHomePodIPRequest,
 	return out, nil
 }
 
+func (c *ipamClient) ListClusters(ctx context.Context, in *ListClustersRequest, opts ...grpc.CallOption) (*ListClustersResponse, error) {
+	out := new(ListClustersResponse)
+	err := c.cc.Invoke(ctx, "/ipam/ListClusters", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *ipamClient) ListEndpointMappings(ctx context.Context, in *ListEndpointMappingsRequest, opts ...grpc.CallOption) (*ListEndpointMappingsResponse, error) {
+	out := new(ListEndpointMappingsResponse)
+	err := c.cc.Invoke(ctx, "/ipam/ListEndpointMappings", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *ipamClient) LookupIP(ctx context.Context, in *LookupIPRequest, opts ...grpc.CallOption) (*LookupIPResponse, error) {
+	out := new(LookupIPResponse)
+	err := c.cc.Invoke(ctx, "/ipam/LookupIP", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *ipamClient) WatchMappings(ctx context.Context, in *WatchMappingsRequest, opts ...grpc.CallOption) (Ipam_WatchMappingsClient, error) {
+	stream, err := c.cc.NewStream(ctx, &Ipam_ServiceDesc.Streams[0], "/ipam/WatchMappings", opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &ipamWatchMappingsClient{stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type Ipam_WatchMappingsClient interface {
+	Recv() (*MappingEvent, error)
+	grpc.ClientStream
+}
+
+type ipamWatchMappingsClient struct {
+	grpc.ClientStream
+}
+
+func (x *ipamWatchMappingsClient) Recv() (*MappingEvent, error) {
+	m := new(MappingEvent)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func (c *ipamClient) MapEndpointIPs(ctx context.Context, in *MapEndpointIPsRequest, opts ...grpc.CallOption) (*MapEndpointIPsResponse, error) {
+	out := new(MapEndpointIPsResponse)
+	err := c.cc.Invoke(ctx, "/ipam/MapEndpointIPs", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *ipamClient) UnmapEndpointIPs(ctx context.Context, in *UnmapEndpointIPsRequest, opts ...grpc.CallOption) (*UnmapEndpointIPsResponse, error) {
+	out := new(UnmapEndpointIPsResponse)
+	err := c.cc.Invoke(ctx, "/ipam/UnmapEndpointIPs", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 // IpamServer is the server API for Ipam service.
 // All implementations must embed UnimplementedIpamServer
 // for forward compatibility
@@ -66,6 +149,12 @@ type IpamServer interface {
 	MapEndpointIP(context.Context, *MapRequest) (*MapResponse, error)
 	UnmapEndpointIP(context.Context, *UnmapRequest) (*UnmapResponse, error)
 	GetHomePodIP(context.Context, *GetHomePodIPRequest) (*GetHomePodIPResponse, error)
+	ListClusters(context.Context, *ListClustersRequest) (*ListClustersResponse, error)
+	ListEndpointMappings(context.Context, *ListEndpointMappingsRequest) (*ListEndpointMappingsResponse, error)
+	LookupIP(context.Context, *LookupIPRequest) (*LookupIPResponse, error)
+	WatchMappings(*WatchMappingsRequest, Ipam_WatchMappingsServer) error
+	MapEndpointIPs(context.Context, *MapEndpointIPsRequest) (*MapEndpointIPsResponse, error)
+	UnmapEndpointIPs(context.Context, *UnmapEndpointIPsRequest) (*UnmapEndpointIPsResponse, error)
 	mustEmbedUnimplementedIpamServer()
 }
 
@@ -82,6 +171,24 @@ func (UnimplementedIpamServer) UnmapEndpointIP(context.Context, *UnmapRequest) (
 func (UnimplementedIpamServer) GetHomePodIP(context.Context, *GetHomePodIPRequest) (*GetHomePodIPResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method GetHomePodIP not implemented")
 }
+func (UnimplementedIpamServer) ListClusters(context.Context, *ListClustersRequest) (*ListClustersResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListClusters not implemented")
+}
+func (UnimplementedIpamServer) ListEndpointMappings(context.Context, *ListEndpointMappingsRequest) (*ListEndpointMappingsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListEndpointMappings not implemented")
+}
+func (UnimplementedIpamServer) LookupIP(context.Context, *LookupIPRequest) (*LookupIPResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method LookupIP not implemented")
+}
+func (UnimplementedIpamServer) WatchMappings(*WatchMappingsRequest, Ipam_WatchMappingsServer) error {
+	return status.Errorf(codes.Unimplemented, "method WatchMappings not implemented")
+}
+func (UnimplementedIpamServer) MapEndpointIPs(context.Context, *MapEndpointIPsRequest) (*MapEndpointIPsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method MapEndpointIPs not implemented")
+}
+func (UnimplementedIpamServer) UnmapEndpointIPs(context.Context, *UnmapEndpointIPsRequest) (*UnmapEndpointIPsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method UnmapEndpointIPs not implemented")
+}
 func (UnimplementedIpamServer) mustEmbedUnimplementedIpamServer() {}
 
 // UnsafeIpamServer may be embedded to opt out of forward compatibility for this service.
@@ -149,6 +256,117 @@ func _Ipam_GetHomePodIP_Handler(srv interface{}, ctx context.Context, dec func(i
 	return interceptor(ctx, in, info, handler)
 }
 
+func _Ipam_ListClusters_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListClustersRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(IpamServer).ListClusters(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/ipam/ListClusters",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(IpamServer).ListClusters(ctx, req.(*ListClustersRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Ipam_ListEndpointMappings_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListEndpointMappingsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(IpamServer).ListEndpointMappings(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/ipam/ListEndpointMappings",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(IpamServer).ListEndpointMappings(ctx, req.(*ListEndpointMappingsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Ipam_LookupIP_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(LookupIPRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(IpamServer).LookupIP(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/ipam/LookupIP",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(IpamServer).LookupIP(ctx, req.(*LookupIPRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Ipam_WatchMappings_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(WatchMappingsRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(IpamServer).WatchMappings(m, &ipamWatchMappingsServer{stream})
+}
+
+type Ipam_WatchMappingsServer interface {
+	Send(*MappingEvent) error
+	grpc.ServerStream
+}
+
+type ipamWatchMappingsServer struct {
+	grpc.ServerStream
+}
+
+func (x *ipamWatchMappingsServer) Send(m *MappingEvent) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+func _Ipam_MapEndpointIPs_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(MapEndpointIPsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(IpamServer).MapEndpointIPs(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/ipam/MapEndpointIPs",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(IpamServer).MapEndpointIPs(ctx, req.(*MapEndpointIPsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Ipam_UnmapEndpointIPs_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(UnmapEndpointIPsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(IpamServer).UnmapEndpointIPs(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/ipam/UnmapEndpointIPs",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(IpamServer).UnmapEndpointIPs(ctx, req.(*UnmapEndpointIPsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 // Ipam_ServiceDesc is the grpc.ServiceDesc for Ipam service.
 // It's only intended for direct use with grpc.RegisterService,
 // and not to be introspected or modified (even as a copy)
@@ -168,7 +386,33 @@ var Ipam_ServiceDesc = grpc.ServiceDesc{
 			MethodName: "GetHomePodIP",
 			Handler:    _Ipam_GetHomePodIP_Handler,
 		},
+		{
+			MethodName: "ListClusters",
+			Handler:    _Ipam_ListClusters_Handler,
+		},
+		{
+			MethodName: "ListEndpointMappings",
+			Handler:    _Ipam_ListEndpointMappings_Handler,
+		},
+		{
+			MethodName: "LookupIP",
+			Handler:    _Ipam_LookupIP_Handler,
+		},
+		{
+			MethodName: "MapEndpointIPs",
+			Handler:    _Ipam_MapEndpointIPs_Handler,
+		},
+		{
+			MethodName: "UnmapEndpointIPs",
+			Handler:    _Ipam_UnmapEndpointIPs_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "WatchMappings",
+			Handler:       _Ipam_WatchMappings_Handler,
+			ServerStreams: true,
+		},
 	},
-	Streams:  []grpc.StreamDesc{},
 	Metadata: "pkg/liqonet/ipam.proto",
 }
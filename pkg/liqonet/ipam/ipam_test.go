@@ -128,13 +128,28 @@ var _ = Describe("Ipam", func() {
 		Expect(err).To(BeNil())
 		n, err := rand.Int(rand.Reader, big.NewInt(10000))
 		Expect(err).To(BeNil())
-		err = ipam.Init(liqonetIpam.Pools, dynClient, 2000+int(n.Int64()))
+		pools := append(append([]string{}, liqonetIpam.Pools...), liqonetIpam.IPv6Pools...)
+		err = ipam.Init(pools, dynClient, 2000+int(n.Int64()))
 		Expect(err).To(BeNil())
 	})
 	AfterEach(func() {
 		ipam.Terminate()
 	})
 
+	Describe("CheckMeshSubnets", func() {
+		mesh := &liqonetapi.MeshSubnets{PodCIDR: "10.4.0.0/16", ExternalCIDR: "10.5.0.0/16"}
+		It("should accept the peering if the clusters agree on the mesh subnets", func() {
+			Expect(liqonetIpam.CheckMeshSubnets(nil, nil)).To(Succeed())
+			Expect(liqonetIpam.CheckMeshSubnets(mesh, mesh.DeepCopy())).To(Succeed())
+		})
+		It("should refuse the peering if the clusters disagree on the mesh subnets", func() {
+			Expect(liqonetIpam.CheckMeshSubnets(mesh, nil)).ToNot(Succeed())
+			Expect(liqonetIpam.CheckMeshSubnets(nil, mesh)).ToNot(Succeed())
+			Expect(liqonetIpam.CheckMeshSubnets(mesh, &liqonetapi.MeshSubnets{PodCIDR: "10.4.0.0/16", ExternalCIDR: "10.6.0.0/16"})).
+				ToNot(Succeed())
+		})
+	})
+
 	Describe("AcquireReservedSubnet", func() {
 		Context("When the reserved network equals a network pool", func() {
 			It("Should successfully reserve the subnet", func() {
@@ -177,6 +192,64 @@ var _ = Describe("Ipam", func() {
 	})
 
 	Describe("GetSubnetsPerCluster", func() {
+		Context("When the remote cluster asks for IPv6 subnets", func() {
+			It("should allocate them, and map them using the IPv6 pools in case of conflicts", func() {
+				p, e, err := ipam.GetSubnetsPerCluster("fd00:1::/64", "fd00:2::/64", clusterID1)
+				Expect(err).To(BeNil())
+				Expect(p).To(Equal("fd00:1::/64"))
+				Expect(e).To(Equal("fd00:2::/64"))
+				p, e, err = ipam.GetSubnetsPerCluster("fd00:1::/64", "fd00:2::/64", clusterID2)
+				Expect(err).To(BeNil())
+				Expect(p).ToNot(Equal("fd00:1::/64"))
+				Expect(p).To(HavePrefix("fd"))
+				Expect(p).To(HaveSuffix("/64"))
+				Expect(e).ToNot(Equal("fd00:2::/64"))
+				Expect(e).To(HavePrefix("fd"))
+				Expect(e).To(HaveSuffix("/64"))
+			})
+		})
+		Context("When mesh subnets have been recorded for the remote cluster", func() {
+			BeforeEach(func() {
+				err := liqonetIpam.RecordMeshSubnets(dynClient, map[string]liqonetapi.MeshSubnets{
+					clusterID2: {PodCIDR: "10.4.0.0/16", ExternalCIDR: "10.5.0.0/16"},
+				})
+				Expect(err).To(BeNil())
+			})
+			It("should use the mesh subnets instead of remapping the networks independently", func() {
+				p, e, err := ipam.GetSubnetsPerCluster("11.0.0.0/16", "11.1.0.0/16", clusterID1)
+				Expect(err).To(BeNil())
+				Expect(p).To(Equal("11.0.0.0/16"))
+				Expect(e).To(Equal("11.1.0.0/16"))
+				p, e, err = ipam.GetSubnetsPerCluster("11.0.0.0/16", "11.1.0.0/16", clusterID2)
+				Expect(err).To(BeNil())
+				Expect(p).To(Equal("10.4.0.0/16"))
+				Expect(e).To(Equal("10.5.0.0/16"))
+			})
+			It("should fail if the mesh subnets have been assigned to another cluster", func() {
+				_, _, err := ipam.GetSubnetsPerCluster("10.4.0.0/16", "11.1.0.0/16", clusterID1)
+				Expect(err).To(BeNil())
+				_, _, err = ipam.GetSubnetsPerCluster("11.0.0.0/16", "11.2.0.0/16", clusterID2)
+				Expect(err).ToNot(BeNil())
+			})
+			It("should return the mesh subnets recorded for the cluster", func() {
+				subnets, err := ipam.GetMeshSubnets(clusterID2)
+				Expect(err).To(BeNil())
+				Expect(subnets).To(Equal(&liqonetapi.MeshSubnets{PodCIDR: "10.4.0.0/16", ExternalCIDR: "10.5.0.0/16"}))
+				subnets, err = ipam.GetMeshSubnets(clusterID1)
+				Expect(err).To(BeNil())
+				Expect(subnets).To(BeNil())
+			})
+		})
+		Context("When the IPv4 pools are full", func() {
+			It("should not map the IPv4 subnets using the IPv6 pools", func() {
+				for _, network := range liqonetIpam.Pools {
+					err := fillNetworkPool(network, ipam)
+					Expect(err).To(BeNil())
+				}
+				_, _, err := ipam.GetSubnetsPerCluster("10.1.0.0/16", "10.2.0.0/16", clusterID1)
+				Expect(err).ToNot(BeNil())
+			})
+		})
 		Context("When the remote cluster asks for subnets not belonging to any pool", func() {
 			Context("and the subnets have not already been assigned to any other cluster", func() {
 				It("Should allocate the subnets without mapping", func() {
@@ -569,6 +642,27 @@ var _ = Describe("Ipam", func() {
 			Expect(p).ToNot(Equal("10.0.1.0/24"))
 			Expect(e).ToNot(Equal("10.0.2.0/24"))
 		})
+		It("ipam should add the default pools introduced by an upgrade", func() {
+			// Simulate a cluster initialized before the introduction of the IPv6 pools
+			ipam.Terminate()
+			Expect(setDynClient()).To(Succeed())
+			ipam = liqonetIpam.NewIPAM()
+			n, err := rand.Int(rand.Reader, big.NewInt(2000))
+			Expect(err).To(BeNil())
+			Expect(ipam.Init(liqonetIpam.Pools, dynClient, 2000+int(n.Int64()))).To(Succeed())
+			ipamStorage, err := getIpamStorageResource()
+			Expect(err).To(BeNil())
+			Expect(ipamStorage.Spec.Pools).To(ConsistOf(liqonetIpam.Pools))
+
+			// Simulate the upgrade
+			ipam.Terminate()
+			ipam = liqonetIpam.NewIPAM()
+			pools := append(append([]string{}, liqonetIpam.Pools...), liqonetIpam.IPv6Pools...)
+			Expect(ipam.Init(pools, dynClient, 4000+int(n.Int64()))).To(Succeed())
+			ipamStorage, err = getIpamStorageResource()
+			Expect(err).To(BeNil())
+			Expect(ipamStorage.Spec.Pools).To(ConsistOf(pools))
+		})
 	})
 	Describe("AddNetworkPool", func() {
 		Context("Trying to add a default network pool", func() {
@@ -663,6 +757,8 @@ var _ = Describe("Ipam", func() {
 			It("Should generate an error", func() {
 				err := ipam.RemoveNetworkPool(liqonetIpam.Pools[0])
 				Expect(err).ToNot(BeNil())
+				err = ipam.RemoveNetworkPool(liqonetIpam.IPv6Pools[0])
+				Expect(err).ToNot(BeNil())
 			})
 		})
 		Context("Remove a network pool that is used for a cluster", func() {
@@ -810,6 +906,16 @@ var _ = Describe("Ipam", func() {
 				Expect(externalCIDR).To(Equal("10.0.1.0/24"))
 			})
 		})
+		Context("Call after SetPodCIDR with an IPv6 PodCIDR", func() {
+			It("should return an IPv6 network", func() {
+				err := ipam.SetPodCIDR("fd00:10::/64")
+				Expect(err).To(BeNil())
+				externalCIDR, err := ipam.GetExternalCIDR(64)
+				Expect(err).To(BeNil())
+				Expect(externalCIDR).To(HavePrefix("fd"))
+				Expect(externalCIDR).To(HaveSuffix("/64"))
+			})
+		})
 		Context("Call before SetPodCIDR", func() {
 			It("should produce an error in SetPodCIDR", func() {
 				externalCIDR, err := ipam.GetExternalCIDR(24)
@@ -1417,6 +1523,104 @@ var _ = Describe("Ipam", func() {
 			})
 		})
 	})
+	Describe("MapEndpointIPs and UnmapEndpointIPs", func() {
+		BeforeEach(func() {
+			// Set PodCIDR
+			err := ipam.SetPodCIDR(homePodCIDR)
+			Expect(err).To(BeNil())
+
+			// Get ExternalCIDR
+			_, err = ipam.GetExternalCIDR(24)
+			Expect(err).To(BeNil())
+
+			// Assign networks to cluster
+			_, _, err = ipam.GetSubnetsPerCluster(remotePodCIDR, remoteExternalCIDR, clusterID1)
+			Expect(err).To(BeNil())
+			err = ipam.AddLocalSubnetsPerCluster(consts.DefaultCIDRValue, consts.DefaultCIDRValue, clusterID1)
+			Expect(err).To(BeNil())
+		})
+		Context("Passing an invalid IP", func() {
+			It("should not map any of the endpoint IPs", func() {
+				_, err := ipam.MapEndpointIPs(context.Background(), &liqonetIpam.MapEndpointIPsRequest{
+					ClusterID: clusterID1,
+					Ips:       []string{"20.0.0.1", "10.9.9"},
+				})
+				Expect(err.Error()).To(ContainSubstring("Endpoint IP must be a valid IP"))
+
+				ipamConfig, err := getIpamStorageResource()
+				Expect(err).To(BeNil())
+				Expect(ipamConfig.Spec.EndpointMappings).To(HaveLen(0))
+			})
+		})
+		Context("Mapping and unmapping the endpoints of a slice", func() {
+			It("should behave as the corresponding single IP requests", func() {
+				ips := []string{localEndpointIP, "20.0.0.1", "20.0.0.2"}
+				response, err := ipam.MapEndpointIPs(context.Background(), &liqonetIpam.MapEndpointIPsRequest{
+					ClusterID: clusterID1,
+					Ips:       ips,
+				})
+				Expect(err).To(BeNil())
+				Expect(response.GetIps()).To(HaveLen(3))
+				Expect(response.GetIps()[0]).To(Equal(localEndpointIP))
+
+				// The mappings should match the ones returned by MapEndpointIP
+				for i, ip := range ips {
+					single, err := ipam.MapEndpointIP(context.Background(), &liqonetIpam.MapRequest{
+						ClusterID: clusterID1,
+						Ip:        ip,
+					})
+					Expect(err).To(BeNil())
+					Expect(single.GetIp()).To(Equal(response.GetIps()[i]))
+				}
+
+				nm, err := getNatMappingResourcePerCluster(clusterID1)
+				Expect(err).To(BeNil())
+				Expect(nm.Spec.ClusterMappings).To(HaveKeyWithValue("20.0.0.1", response.GetIps()[1]))
+				Expect(nm.Spec.ClusterMappings).To(HaveKeyWithValue("20.0.0.2", response.GetIps()[2]))
+
+				_, err = ipam.UnmapEndpointIPs(context.Background(), &liqonetIpam.UnmapEndpointIPsRequest{
+					ClusterID: clusterID1,
+					Ips:       ips,
+				})
+				Expect(err).To(BeNil())
+
+				ipamConfig, err := getIpamStorageResource()
+				Expect(err).To(BeNil())
+				Expect(ipamConfig.Spec.EndpointMappings).To(HaveLen(0))
+				nm, err = getNatMappingResourcePerCluster(clusterID1)
+				Expect(err).To(BeNil())
+				Expect(nm.Spec.ClusterMappings).ToNot(HaveKey("20.0.0.1"))
+				Expect(nm.Spec.ClusterMappings).ToNot(HaveKey("20.0.0.2"))
+			})
+		})
+		Context("Mapping the new endpoints of a slice", func() {
+			It("should update the IpamStorage once and persist the acquired IPs", func() {
+				dynClient.ClearActions()
+				response, err := ipam.MapEndpointIPs(context.Background(), &liqonetIpam.MapEndpointIPsRequest{
+					ClusterID: clusterID1,
+					Ips:       []string{"20.0.0.1", "20.0.0.2", "20.0.0.3"},
+				})
+				Expect(err).To(BeNil())
+				Expect(response.GetIps()).To(HaveLen(3))
+
+				patches := 0
+				for _, action := range dynClient.Actions() {
+					if action.GetVerb() == "patch" && action.GetResource() == liqonetapi.IpamGroupResource {
+						patches++
+					}
+				}
+				Expect(patches).To(Equal(1))
+
+				// A further endpoint should get an IP different from the ones already acquired
+				single, err := ipam.MapEndpointIP(context.Background(), &liqonetIpam.MapRequest{
+					ClusterID: clusterID1,
+					Ip:        "20.0.0.4",
+				})
+				Expect(err).To(BeNil())
+				Expect(response.GetIps()).ToNot(ContainElement(single.GetIp()))
+			})
+		})
+	})
 })
 
 func getNatMappingResourcePerCluster(clusterID string) (*liqonetapi.NatMapping, error) {
@@ -0,0 +1,239 @@
+package ipam
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"sync"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"k8s.io/klog"
+
+	netv1alpha1 "github.com/liqotech/liqo/apis/net/v1alpha1"
+	"github.com/liqotech/liqo/pkg/consts"
+	"github.com/liqotech/liqo/pkg/liqonet/utils"
+)
+
+// watcherBufferSize is the number of events buffered for each WatchMappings stream. A stream which
+// falls behind is closed, and the client is expected to open a new one to get a fresh snapshot.
+const watcherBufferSize = 256
+
+// mappingNotifier dispatches the changes of the endpoint mappings to the WatchMappings streams.
+type mappingNotifier struct {
+	mutex    sync.Mutex
+	watchers map[chan *MappingEvent]string
+}
+
+// subscribe registers a new watcher for the mappings of the given cluster. The returned channel is closed
+// if the watcher does not keep up with the events, while the returned function unregisters the watcher.
+func (n *mappingNotifier) subscribe(clusterID string) (events <-chan *MappingEvent, cancel func()) {
+	n.mutex.Lock()
+	defer n.mutex.Unlock()
+	if n.watchers == nil {
+		n.watchers = make(map[chan *MappingEvent]string)
+	}
+	ch := make(chan *MappingEvent, watcherBufferSize)
+	n.watchers[ch] = clusterID
+	return ch, func() {
+		n.mutex.Lock()
+		defer n.mutex.Unlock()
+		if _, found := n.watchers[ch]; found {
+			delete(n.watchers, ch)
+			close(ch)
+		}
+	}
+}
+
+// notify sends the given event to the watchers of the cluster it refers to, without blocking.
+func (n *mappingNotifier) notify(eventType MappingEvent_Type, mapping *EndpointMapping) {
+	n.mutex.Lock()
+	defer n.mutex.Unlock()
+	for ch, clusterID := range n.watchers {
+		if clusterID != mapping.GetClusterID() {
+			continue
+		}
+		select {
+		case ch <- &MappingEvent{Type: eventType, Mapping: mapping}:
+		default:
+			klog.Warningf("%s -> closing a mapping watcher, since it is not keeping up with the events", clusterID)
+			delete(n.watchers, ch)
+			close(ch)
+		}
+	}
+}
+
+// ListClusters returns the subnets reserved for each remote cluster.
+func (liqoIPAM *IPAM) ListClusters(ctx context.Context, request *ListClustersRequest) (*ListClustersResponse, error) {
+	clusterSubnets, err := liqoIPAM.ipamStorage.getClusterSubnets()
+	if err != nil {
+		return &ListClustersResponse{}, fmt.Errorf("cannot get cluster subnets: %w", err)
+	}
+	response := &ListClustersResponse{}
+	for _, clusterID := range sortedKeys(clusterSubnets) {
+		response.Clusters = append(response.Clusters, toClusterSubnets(clusterID, clusterSubnets[clusterID]))
+	}
+	return response, nil
+}
+
+// ListEndpointMappings returns the mappings of the endpoints reflected in the given remote cluster,
+// or in all the remote clusters if the cluster is not specified.
+func (liqoIPAM *IPAM) ListEndpointMappings(ctx context.Context,
+	request *ListEndpointMappingsRequest) (*ListEndpointMappingsResponse, error) {
+	mappings, err := liqoIPAM.getEndpointMappings(request.GetClusterID())
+	if err != nil {
+		return &ListEndpointMappingsResponse{}, fmt.Errorf("cannot get endpoint mappings: %w", err)
+	}
+	return &ListEndpointMappingsResponse{Mappings: mappings}, nil
+}
+
+// LookupIP returns the endpoint mappings involving the given IP, either as endpoint IP, as IP reserved in
+// the local ExternalCIDR or as IP used by a remote cluster, and the networks the IP belongs to.
+func (liqoIPAM *IPAM) LookupIP(ctx context.Context, request *LookupIPRequest) (*LookupIPResponse, error) {
+	ip := request.GetIp()
+	if net.ParseIP(ip) == nil {
+		return &LookupIPResponse{}, status.Errorf(codes.InvalidArgument, "invalid IP %q", ip)
+	}
+	mappings, err := liqoIPAM.getEndpointMappings("")
+	if err != nil {
+		return &LookupIPResponse{}, fmt.Errorf("cannot get endpoint mappings: %w", err)
+	}
+	response := &LookupIPResponse{}
+	for _, mapping := range mappings {
+		if mapping.GetIp() == ip || mapping.GetExternalIP() == ip || mapping.GetMappedIP() == ip {
+			response.Mappings = append(response.Mappings, mapping)
+		}
+	}
+
+	networks := make(map[string]string)
+	if networks["PodCIDR"], err = liqoIPAM.ipamStorage.getPodCIDR(); err != nil {
+		return &LookupIPResponse{}, fmt.Errorf("cannot get PodCIDR: %w", err)
+	}
+	if networks["ServiceCIDR"], err = liqoIPAM.ipamStorage.getServiceCIDR(); err != nil {
+		return &LookupIPResponse{}, fmt.Errorf("cannot get ServiceCIDR: %w", err)
+	}
+	if networks["ExternalCIDR"], err = liqoIPAM.ipamStorage.getExternalCIDR(); err != nil {
+		return &LookupIPResponse{}, fmt.Errorf("cannot get ExternalCIDR: %w", err)
+	}
+	clusterSubnets, err := liqoIPAM.ipamStorage.getClusterSubnets()
+	if err != nil {
+		return &LookupIPResponse{}, fmt.Errorf("cannot get cluster subnets: %w", err)
+	}
+	for clusterID, subnets := range clusterSubnets {
+		networks[clusterID+"/RemotePodCIDR"] = subnets.RemotePodCIDR
+		networks[clusterID+"/RemoteExternalCIDR"] = subnets.RemoteExternalCIDR
+	}
+	for _, name := range sortedKeys(networks) {
+		if networks[name] == "" || networks[name] == consts.DefaultCIDRValue {
+			continue
+		}
+		// Networks of a different IP family simply do not contain the IP.
+		if belongs, err := ipBelongsToNetwork(ip, networks[name]); err == nil && belongs {
+			response.Networks = append(response.Networks, name)
+		}
+	}
+	return response, nil
+}
+
+// WatchMappings streams the subnets of the given remote cluster, the mappings of the endpoints currently
+// reflected in it, and then the mappings added and deleted, until the client closes the stream.
+func (liqoIPAM *IPAM) WatchMappings(request *WatchMappingsRequest, stream Ipam_WatchMappingsServer) error {
+	clusterID := request.GetClusterID()
+	if clusterID == "" {
+		return status.Error(codes.InvalidArgument, "cluster ID must not be empty")
+	}
+	// The watcher is registered before taking the snapshot, so that no change is lost in between.
+	events, cancel := liqoIPAM.mappingNotifier.subscribe(clusterID)
+	defer cancel()
+
+	clusterSubnets, err := liqoIPAM.ipamStorage.getClusterSubnets()
+	if err != nil {
+		return fmt.Errorf("cannot get cluster subnets: %w", err)
+	}
+	subnets, found := clusterSubnets[clusterID]
+	if !found {
+		return status.Errorf(codes.NotFound, "cluster %s has not a network configuration", clusterID)
+	}
+	if err := stream.Send(&MappingEvent{Type: MappingEvent_SUBNETS, Subnets: toClusterSubnets(clusterID, subnets)}); err != nil {
+		return err
+	}
+	mappings, err := liqoIPAM.getEndpointMappings(clusterID)
+	if err != nil {
+		return fmt.Errorf("cannot get endpoint mappings: %w", err)
+	}
+	for _, mapping := range mappings {
+		if err := stream.Send(&MappingEvent{Type: MappingEvent_ADDED, Mapping: mapping}); err != nil {
+			return err
+		}
+	}
+
+	for {
+		select {
+		case <-stream.Context().Done():
+			return nil
+		case event, ok := <-events:
+			if !ok {
+				return status.Error(codes.ResourceExhausted, "too many pending events, the watch has to be restarted")
+			}
+			if err := stream.Send(event); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+// getEndpointMappings returns the mappings of the endpoints reflected in the given remote cluster, or in all
+// the remote clusters if clusterID is empty. The IPs used by the remote clusters are derived from the IPs
+// reserved in the local ExternalCIDR, since the ExternalCIDR may have been remapped by the remote clusters.
+func (liqoIPAM *IPAM) getEndpointMappings(clusterID string) ([]*EndpointMapping, error) {
+	endpointMappings, err := liqoIPAM.ipamStorage.getEndpointMappings()
+	if err != nil {
+		return nil, err
+	}
+	clusterSubnets, err := liqoIPAM.ipamStorage.getClusterSubnets()
+	if err != nil {
+		return nil, err
+	}
+	localExternalCIDR, err := liqoIPAM.ipamStorage.getExternalCIDR()
+	if err != nil {
+		return nil, err
+	}
+	var mappings []*EndpointMapping
+	for _, ip := range sortedKeys(endpointMappings) {
+		endpointMapping := endpointMappings[ip]
+		for _, cluster := range sortedKeys(endpointMapping.ClusterMappings) {
+			if clusterID != "" && cluster != clusterID {
+				continue
+			}
+			mapping, err := toEndpointMapping(cluster, ip, endpointMapping.IP, localExternalCIDR, clusterSubnets[cluster])
+			if err != nil {
+				return nil, err
+			}
+			mappings = append(mappings, mapping)
+		}
+	}
+	return mappings, nil
+}
+
+// toEndpointMapping returns the mapping of the given endpoint, reflected in the given remote cluster.
+func toEndpointMapping(clusterID, ip, externalIP, localExternalCIDR string, subnets netv1alpha1.Subnets) (*EndpointMapping, error) {
+	externalCIDR := subnets.LocalNATExternalCIDR
+	if externalCIDR == "" || externalCIDR == consts.DefaultCIDRValue {
+		externalCIDR = localExternalCIDR
+	}
+	mappedIP, err := utils.MapIPToNetwork(externalCIDR, externalIP)
+	if err != nil {
+		return nil, fmt.Errorf("cannot map IP %s of endpoint %s for cluster %s: %w", externalIP, ip, clusterID, err)
+	}
+	return &EndpointMapping{ClusterID: clusterID, Ip: ip, ExternalIP: externalIP, MappedIP: mappedIP}, nil
+}
+
+func toClusterSubnets(clusterID string, subnets netv1alpha1.Subnets) *ClusterSubnets {
+	return &ClusterSubnets{
+		ClusterID:            clusterID,
+		RemotePodCIDR:        subnets.RemotePodCIDR,
+		RemoteExternalCIDR:   subnets.RemoteExternalCIDR,
+		LocalNATPodCIDR:      subnets.LocalNATPodCIDR,
+		LocalNATExternalCIDR: subnets.LocalNATExternalCIDR,
+	}
+}
@@ -0,0 +1,90 @@
+package ipam
+
+import (
+	"fmt"
+
+	"k8s.io/client-go/dynamic"
+	"k8s.io/klog"
+
+	netv1alpha1 "github.com/liqotech/liqo/apis/net/v1alpha1"
+	"github.com/liqotech/liqo/pkg/liqonet/utils"
+)
+
+// RecordMeshSubnets records in the IpamStorage resource the networks planned for the clusters of a multi-peer
+// mesh, replacing the ones previously recorded. The same plan has to be recorded in every cluster of the mesh,
+// including the networks of the cluster itself: they are compared with the ones advertised by the remote clusters
+// in the NetworkConfigs, and the peering is refused if they differ. The networks of the clusters already peered
+// are not changed, and the recorded ones are used starting from the next peering.
+func RecordMeshSubnets(dynClient dynamic.Interface, meshSubnets map[string]netv1alpha1.MeshSubnets) error {
+	for clusterID, subnets := range meshSubnets {
+		for _, network := range []string{subnets.PodCIDR, subnets.ExternalCIDR} {
+			if err := utils.IsValidCIDR(network); err != nil {
+				return fmt.Errorf("invalid network %q of cluster %s: %w", network, clusterID, err)
+			}
+		}
+	}
+	ipamStorage := &IPAMStorage{dynClient: dynClient}
+	ipam, err := ipamStorage.getConfig()
+	if err != nil {
+		return fmt.Errorf("cannot get the IPAM state: %w", err)
+	}
+	for _, clusterID := range sortedKeys(meshSubnets) {
+		current, peered := ipam.Spec.ClusterSubnets[clusterID]
+		if !peered {
+			continue
+		}
+		if current.RemotePodCIDR != meshSubnets[clusterID].PodCIDR || current.RemoteExternalCIDR != meshSubnets[clusterID].ExternalCIDR {
+			klog.Warningf("%s -> the cluster is reached with networks %s and %s, which differ from the mesh ones until it is peered again",
+				clusterID, current.RemotePodCIDR, current.RemoteExternalCIDR)
+		}
+	}
+	spec := ipam.Spec.DeepCopy()
+	spec.MeshSubnets = meshSubnets
+	return ipamStorage.replaceSpec(spec)
+}
+
+// GetMeshSubnets returns the networks recorded for the given cluster of a multi-peer mesh, or nil if none.
+func (liqoIPAM *IPAM) GetMeshSubnets(clusterID string) (*netv1alpha1.MeshSubnets, error) {
+	meshSubnets, err := liqoIPAM.ipamStorage.getMeshSubnets()
+	if err != nil {
+		return nil, fmt.Errorf("cannot get mesh subnets: %w", err)
+	}
+	subnets, found := meshSubnets[clusterID]
+	if !found {
+		return nil, nil
+	}
+	return &subnets, nil
+}
+
+// CheckMeshSubnets returns an error if the networks the local cluster is reached with, as recorded in the local
+// mesh plan, differ from the ones advertised by a remote cluster. Both are nil if the clusters do not belong to a mesh.
+func CheckMeshSubnets(local, advertised *netv1alpha1.MeshSubnets) error {
+	describe := func(subnets *netv1alpha1.MeshSubnets) string {
+		if subnets == nil {
+			return "no mesh networks"
+		}
+		return fmt.Sprintf("PodCIDR %s and ExternalCIDR %s", subnets.PodCIDR, subnets.ExternalCIDR)
+	}
+	if (local == nil) != (advertised == nil) || (local != nil && *local != *advertised) {
+		return fmt.Errorf("the remote cluster reaches the local cluster with %s, while the local mesh plan records %s",
+			describe(advertised), describe(local))
+	}
+	return nil
+}
+
+// getOrRemapClusterNetwork returns the network a remote cluster is reached with. If a network has been negotiated
+// for the cluster in the mesh, it has to be used as is, since the other clusters of the mesh use the same one.
+// Otherwise, the received network is acquired or remapped as needed.
+func (liqoIPAM *IPAM) getOrRemapClusterNetwork(network, meshNetwork string) (string, error) {
+	if meshNetwork == "" {
+		return liqoIPAM.getOrRemapNetwork(network)
+	}
+	if utils.GetMask(network) != utils.GetMask(meshNetwork) || utils.IsIPv6CIDR(network) != utils.IsIPv6CIDR(meshNetwork) {
+		return "", fmt.Errorf("mesh network %s does not have the same size of network %s", meshNetwork, network)
+	}
+	if err := liqoIPAM.AcquireReservedSubnet(meshNetwork); err != nil {
+		return "", fmt.Errorf("cannot acquire mesh network %s: %w", meshNetwork, err)
+	}
+	klog.Infof("Network %s successfully mapped to mesh network %s", network, meshNetwork)
+	return meshNetwork, nil
+}
@@ -0,0 +1,100 @@
+package iptables
+
+import (
+	"fmt"
+	"os/exec"
+
+	"github.com/coreos/go-iptables/iptables"
+	"k8s.io/klog/v2"
+
+	netv1alpha1 "github.com/liqotech/liqo/apis/net/v1alpha1"
+)
+
+// Backend is the technology used to configure the NAT and filtering rules of the gateway.
+type Backend string
+
+const (
+	// IPTablesBackend configures the rules through iptables.
+	IPTablesBackend Backend = "iptables"
+	// NFTablesBackend configures the rules through nftables.
+	NFTablesBackend Backend = "nftables"
+	// AutoBackend selects iptables if it is usable in the current network namespace, and nftables otherwise.
+	AutoBackend Backend = "auto"
+)
+
+// NATHandler exposes all the functions needed to configure the NAT and filtering rules of the gateway,
+// independently of the underlying technology.
+type NATHandler interface {
+	// Init creates the configuration shared by all the remote clusters. It is called at startup of the operator.
+	Init() error
+	// Terminate removes the whole configuration inserted by Liqo.
+	Terminate() error
+	// EnsureChainsPerCluster makes sure that the chains for the given remote cluster exist.
+	EnsureChainsPerCluster(clusterID string) error
+	// EnsureChainRulesPerCluster makes sure that the traffic related to the remote cluster is steered
+	// towards the chains of the cluster.
+	EnsureChainRulesPerCluster(tep *netv1alpha1.TunnelEndpoint) error
+	// EnsurePostroutingRules makes sure that the postrouting rules for the remote cluster are in place and updated.
+	EnsurePostroutingRules(tep *netv1alpha1.TunnelEndpoint) error
+	// EnsurePreroutingRulesPerTunnelEndpoint makes sure that the prerouting rules extracted from a
+	// TunnelEndpoint resource are in place and updated.
+	EnsurePreroutingRulesPerTunnelEndpoint(tep *netv1alpha1.TunnelEndpoint) error
+	// EnsurePreroutingRulesPerNatMapping makes sure that the prerouting rules extracted from a
+	// NatMapping resource are in place and updated.
+	EnsurePreroutingRulesPerNatMapping(nm *netv1alpha1.NatMapping) error
+	// RemoveIPTablesConfigurationPerCluster removes the whole configuration related to the remote cluster.
+	RemoveIPTablesConfigurationPerCluster(tep *netv1alpha1.TunnelEndpoint) error
+}
+
+var (
+	_ NATHandler = IPTHandler{}
+	_ NATHandler = &NFTHandler{}
+)
+
+// ParseBackend validates the given backend name.
+func ParseBackend(backend string) (Backend, error) {
+	switch Backend(backend) {
+	case IPTablesBackend, NFTablesBackend, AutoBackend:
+		return Backend(backend), nil
+	default:
+		return "", fmt.Errorf("unknown NAT backend %q, accepted values are: %s, %s, %s",
+			backend, IPTablesBackend, NFTablesBackend, AutoBackend)
+	}
+}
+
+// NewNATHandler returns the handler used to configure the NAT and filtering rules through the given backend.
+// If the backend is AutoBackend, it is detected in the current network namespace.
+func NewNATHandler(backend Backend) (NATHandler, error) {
+	if backend == AutoBackend {
+		backend = DetectBackend()
+		klog.Infof("Detected NAT backend: %s", backend)
+	}
+
+	switch backend {
+	case IPTablesBackend:
+		return NewIPTHandler()
+	case NFTablesBackend:
+		return NewNFTHandler()
+	default:
+		return nil, fmt.Errorf("unknown NAT backend %q", backend)
+	}
+}
+
+// DetectBackend returns IPTablesBackend if iptables is available and its nat table can be accessed in the current
+// network namespace, which is not the case for the distributions shipping nftables-only kernels. Otherwise, it
+// returns NFTablesBackend if the nft binary is available.
+func DetectBackend() Backend {
+	ipt, err := iptables.New()
+	if err == nil {
+		if _, err = ipt.ListChains(natTable); err == nil {
+			return IPTablesBackend
+		}
+	}
+	klog.V(3).Infof("iptables is not usable: %v", err)
+
+	if _, err := exec.LookPath(nftBinary); err == nil {
+		return NFTablesBackend
+	}
+	// Neither is available: iptables is kept as default, to report a meaningful error at initialization.
+	return IPTablesBackend
+}
@@ -0,0 +1,388 @@
+package iptables
+
+import (
+	"bytes"
+	"fmt"
+	"net"
+	"os/exec"
+	"sort"
+	"strings"
+	"sync"
+
+	"k8s.io/klog/v2"
+
+	netv1alpha1 "github.com/liqotech/liqo/apis/net/v1alpha1"
+	"github.com/liqotech/liqo/pkg/consts"
+	"github.com/liqotech/liqo/pkg/liqonet/errors"
+	"github.com/liqotech/liqo/pkg/liqonet/utils"
+)
+
+const (
+	// nftBinary is the name of the binary used to configure nftables.
+	nftBinary = "nft"
+	// nftFamily is the family of the tables inserted by liqo.
+	nftFamily = "ip"
+	// nftTablePrefix is the prefix shared by all the tables inserted by liqo.
+	nftTablePrefix = "liqo-"
+	// nftClusterTablePrefix is the prefix used to name the table containing the rules derived from the
+	// TunnelEndpoint of a specific cluster.
+	nftClusterTablePrefix = nftTablePrefix + "cls-"
+	// nftMappingTablePrefix is the prefix used to name the table containing the rules derived from the
+	// NatMapping of a specific cluster.
+	nftMappingTablePrefix = nftTablePrefix + "map-"
+	// nftSrcNATPriority is the priority of the postrouting chains, equivalent to the iptables nat table.
+	nftSrcNATPriority = 100
+	// nftDstNATPriority is the priority of the prerouting chains, equivalent to the iptables nat table.
+	nftDstNATPriority = -100
+	// nftFilterPriority is the priority of the forward and input chains, equivalent to the iptables filter table.
+	nftFilterPriority = 0
+	// nftNATType is the type of the base chains performing NAT.
+	nftNATType = "nat"
+	// nftFilterType is the type of the base chains filtering the traffic.
+	nftFilterType = "filter"
+)
+
+// nftChain is a chain of a table inserted by liqo. It is a base chain if attached to a hook, and a regular one
+// (i.e. reachable only through jumps) otherwise.
+type nftChain struct {
+	name     string
+	kind     string
+	hook     string
+	priority int
+	rules    []string
+}
+
+// nftTableState is the state of a table, as last applied by the handler.
+type nftTableState struct {
+	// ruleset is the definition of the table, as forged by the handler.
+	ruleset string
+	// listed is the content of the table, as listed by nft right after the replacement.
+	listed string
+}
+
+// NFTHandler a handler that exposes all the functions needed to configure the NAT rules through nftables.
+// The rules of each remote cluster are inserted in dedicated tables, which are atomically replaced as a whole
+// when the configuration changes, instead of being compared rule by rule.
+type NFTHandler struct {
+	nft string
+
+	mutex sync.Mutex
+	// applied contains the last state applied for each table, to skip the replacements not changing anything.
+	// The content currently listed by nft is compared as well, to repair the tables modified out-of-band.
+	applied map[string]nftTableState
+}
+
+// NewNFTHandler return the nftables handler used to configure the NAT rules.
+func NewNFTHandler() (*NFTHandler, error) {
+	nft, err := exec.LookPath(nftBinary)
+	if err != nil {
+		return nil, fmt.Errorf("unable to find the %s binary: %w", nftBinary, err)
+	}
+	return &NFTHandler{
+		nft:     nft,
+		applied: map[string]nftTableState{},
+	}, nil
+}
+
+// Init function is called at startup of the operator. Since the tables of the remote clusters are
+// independent of each other, it only checks that nftables can be configured.
+func (h *NFTHandler) Init() error {
+	if _, err := h.run(nil, "list", "tables"); err != nil {
+		return fmt.Errorf("cannot initialize nftables: %w", err)
+	}
+	return nil
+}
+
+// Terminate func is the counterpart of Init. It removes all the tables inserted by liqo.
+func (h *NFTHandler) Terminate() error {
+	tables, err := h.listLiqoTables()
+	if err != nil {
+		return err
+	}
+	if err := h.deleteTables(tables...); err != nil {
+		return err
+	}
+	klog.Infof("NFTables Liqo configuration has been successfully removed.")
+	return nil
+}
+
+// EnsureChainsPerCluster makes sure that the table containing the rules of the given cluster exists.
+func (h *NFTHandler) EnsureChainsPerCluster(clusterID string) error {
+	if clusterID == "" {
+		return &errors.WrongParameter{
+			Parameter: consts.ClusterIDLabelName,
+			Reason:    errors.StringNotEmpty,
+		}
+	}
+	table := getClusterTable(clusterID)
+	if _, err := h.run(nil, "add", "table", nftFamily, table); err != nil {
+		return fmt.Errorf("unable to create table %s: %w", table, err)
+	}
+	return nil
+}
+
+// EnsureChainRulesPerCluster makes sure that the rules for the given cluster are in place and updated.
+// Differently from iptables, the traffic is matched directly by the base chains of the cluster table.
+func (h *NFTHandler) EnsureChainRulesPerCluster(tep *netv1alpha1.TunnelEndpoint) error {
+	return h.ensureClusterTable(tep)
+}
+
+// EnsurePostroutingRules makes sure that the postrouting rules for a given cluster are in place and updated.
+func (h *NFTHandler) EnsurePostroutingRules(tep *netv1alpha1.TunnelEndpoint) error {
+	return h.ensureClusterTable(tep)
+}
+
+// EnsurePreroutingRulesPerTunnelEndpoint makes sure that the prerouting rules extracted from a
+// TunnelEndpoint resource are place and updated.
+func (h *NFTHandler) EnsurePreroutingRulesPerTunnelEndpoint(tep *netv1alpha1.TunnelEndpoint) error {
+	return h.ensureClusterTable(tep)
+}
+
+// EnsurePreroutingRulesPerNatMapping makes sure that the prerouting rules extracted from a
+// NatMapping resource are place and updated.
+func (h *NFTHandler) EnsurePreroutingRulesPerNatMapping(nm *netv1alpha1.NatMapping) error {
+	chains, err := getNFTChainsPerNatMapping(nm)
+	if err != nil {
+		return err
+	}
+	return h.replaceTable(getMappingTable(nm.Spec.ClusterID), chains)
+}
+
+// RemoveIPTablesConfigurationPerCluster removes the tables containing the rules of a remote cluster.
+func (h *NFTHandler) RemoveIPTablesConfigurationPerCluster(tep *netv1alpha1.TunnelEndpoint) error {
+	clusterID := tep.Spec.ClusterID
+	if err := h.deleteTables(getClusterTable(clusterID), getMappingTable(clusterID)); err != nil {
+		return fmt.Errorf("cannot remove tables per cluster %s: %w", clusterID, err)
+	}
+	klog.Infof("NFTables config per cluster %s has been deleted", clusterID)
+	return nil
+}
+
+func (h *NFTHandler) ensureClusterTable(tep *netv1alpha1.TunnelEndpoint) error {
+	chains, err := getNFTChainsPerCluster(tep)
+	if err != nil {
+		return err
+	}
+	return h.replaceTable(getClusterTable(tep.Spec.ClusterID), chains)
+}
+
+// replaceTable atomically replaces the content of a table with the given chains, or deletes it if there are none.
+func (h *NFTHandler) replaceTable(table string, chains []nftChain) error {
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+
+	ruleset := forgeNFTTable(table, chains)
+	current, err := h.listTable(table)
+	if err != nil {
+		return err
+	}
+	if state, found := h.applied[table]; found && state.ruleset == ruleset && state.listed == current {
+		return nil
+	}
+
+	// Adding the table before deleting it makes the deletion succeed even if the table does not exist.
+	script := fmt.Sprintf("add table %s %s\ndelete table %s %s\n%s", nftFamily, table, nftFamily, table, ruleset)
+	if _, err := h.run(strings.NewReader(script), "-f", "-"); err != nil {
+		delete(h.applied, table)
+		return fmt.Errorf("unable to replace table %s: %w", table, err)
+	}
+	listed, err := h.listTable(table)
+	if err != nil {
+		delete(h.applied, table)
+		return err
+	}
+	h.applied[table] = nftTableState{ruleset: ruleset, listed: listed}
+	klog.Infof("Replaced table %s (%d chains)", table, len(chains))
+	return nil
+}
+
+// deleteTables atomically deletes the given tables, if existing.
+func (h *NFTHandler) deleteTables(tables ...string) error {
+	if len(tables) == 0 {
+		return nil
+	}
+
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+
+	var script strings.Builder
+	for _, table := range tables {
+		fmt.Fprintf(&script, "add table %s %s\ndelete table %s %s\n", nftFamily, table, nftFamily, table)
+	}
+	if _, err := h.run(strings.NewReader(script.String()), "-f", "-"); err != nil {
+		return fmt.Errorf("unable to delete tables %s: %w", tables, err)
+	}
+	for _, table := range tables {
+		delete(h.applied, table)
+		klog.Infof("Deleted table %s", table)
+	}
+	return nil
+}
+
+// listTable returns the content of the given table as listed by nft, or an empty string if it does not exist.
+func (h *NFTHandler) listTable(table string) (string, error) {
+	output, err := h.run(nil, "list", "table", nftFamily, table)
+	if err != nil {
+		if strings.Contains(err.Error(), "No such file or directory") {
+			return "", nil
+		}
+		return "", fmt.Errorf("unable to list table %s: %w", table, err)
+	}
+	return output, nil
+}
+
+// listLiqoTables returns the names of the tables inserted by liqo.
+func (h *NFTHandler) listLiqoTables() ([]string, error) {
+	output, err := h.run(nil, "list", "tables", nftFamily)
+	if err != nil {
+		return nil, fmt.Errorf("unable to list tables: %w", err)
+	}
+	// Each line has the format "table <family> <name>".
+	var tables []string
+	for _, line := range strings.Split(output, "\n") {
+		fields := strings.Fields(line)
+		if len(fields) == 3 && strings.HasPrefix(fields[2], nftTablePrefix) {
+			tables = append(tables, fields[2])
+		}
+	}
+	return tables, nil
+}
+
+func (h *NFTHandler) run(stdin *strings.Reader, args ...string) (string, error) {
+	var stdout, stderr bytes.Buffer
+	cmd := exec.Command(h.nft, args...)
+	if stdin != nil {
+		cmd.Stdin = stdin
+	}
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		return "", fmt.Errorf("%s %s: %w (%s)", nftBinary, strings.Join(args, " "), err, strings.TrimSpace(stderr.String()))
+	}
+	return stdout.String(), nil
+}
+
+// forgeNFTTable returns the definition of the table with the given chains, in the nft scripting format.
+func forgeNFTTable(table string, chains []nftChain) string {
+	if len(chains) == 0 {
+		return ""
+	}
+	var ruleset strings.Builder
+	fmt.Fprintf(&ruleset, "table %s %s {\n", nftFamily, table)
+	// The regular chains are defined first, since they are the target of the jumps of the base chains.
+	for _, base := range []bool{false, true} {
+		for _, chain := range chains {
+			if (chain.hook != "") != base {
+				continue
+			}
+			fmt.Fprintf(&ruleset, "\tchain %s {\n", chain.name)
+			if base {
+				fmt.Fprintf(&ruleset, "\t\ttype %s hook %s priority %d; policy accept;\n", chain.kind, chain.hook, chain.priority)
+			}
+			for _, rule := range chain.rules {
+				fmt.Fprintf(&ruleset, "\t\t%s\n", rule)
+			}
+			ruleset.WriteString("\t}\n")
+		}
+	}
+	ruleset.WriteString("}\n")
+	return ruleset.String()
+}
+
+// getNFTChainsPerCluster returns the chains containing the NAT rules derived from the TunnelEndpoint,
+// which are the equivalent of the postrouting and prerouting rules inserted through iptables. The forward and
+// input chains jump to the per-cluster chains for the traffic towards the remote pods, as the iptables ones.
+func getNFTChainsPerCluster(tep *netv1alpha1.TunnelEndpoint) ([]nftChain, error) {
+	if err := utils.CheckTep(tep); err != nil {
+		return nil, fmt.Errorf("invalid TunnelEndpoint resource: %w", err)
+	}
+	clusterID := tep.Spec.ClusterID
+	localPodCIDR := tep.Status.LocalPodCIDR
+	localRemappedPodCIDR, remotePodCIDR := utils.GetPodCIDRS(tep)
+	_, remoteExternalCIDR := utils.GetExternalCIDRS(tep)
+
+	postrouting := nftChain{name: "postrouting", kind: nftNATType, hook: "postrouting", priority: nftSrcNATPriority}
+	prerouting := nftChain{name: "prerouting", kind: nftNATType, hook: "prerouting", priority: nftDstNATPriority}
+
+	natCIDR := localPodCIDR
+	if localRemappedPodCIDR != consts.DefaultCIDRValue {
+		// The remote cluster has remapped the home PodCIDR, hence the local pods are translated to the remapped
+		// network and vice versa.
+		natCIDR = localRemappedPodCIDR
+		postrouting.rules = append(postrouting.rules, fmt.Sprintf("ip saddr %s ip daddr %s snat ip prefix to ip saddr map { %s : %s }",
+			localPodCIDR, remotePodCIDR, localPodCIDR, localRemappedPodCIDR))
+		prerouting.rules = append(prerouting.rules, fmt.Sprintf("ip saddr %s ip daddr %s dnat ip prefix to ip daddr map { %s : %s }",
+			remotePodCIDR, localRemappedPodCIDR, localRemappedPodCIDR, localPodCIDR))
+	}
+
+	// Get the first IP address from the podCIDR used by the local cluster in the remote one.
+	natIP, err := utils.GetFirstIP(natCIDR)
+	if err != nil {
+		klog.Errorf("Unable to get the IP from %s for remote cluster %s used to NAT the traffic from localhosts to remote hosts",
+			natCIDR, clusterID)
+		return nil, err
+	}
+	postrouting.rules = append(postrouting.rules,
+		fmt.Sprintf("ip saddr != %s ip daddr %s snat to %s", localPodCIDR, remotePodCIDR, natIP),
+		fmt.Sprintf("ip saddr != %s ip daddr %s snat to %s", localPodCIDR, remoteExternalCIDR, natIP))
+
+	chains := []nftChain{postrouting}
+	if len(prerouting.rules) > 0 {
+		chains = append(chains, prerouting)
+	}
+	forwardCluster, inputCluster := getClusterForwardChain(clusterID), getClusterInputChain(clusterID)
+	chains = append(chains,
+		nftChain{name: "forward", kind: nftFilterType, hook: "forward", priority: nftFilterPriority,
+			rules: []string{fmt.Sprintf("ip daddr %s jump %s", remotePodCIDR, forwardCluster)}},
+		nftChain{name: "input", kind: nftFilterType, hook: "input", priority: nftFilterPriority,
+			rules: []string{fmt.Sprintf("ip daddr %s jump %s", remotePodCIDR, inputCluster)}},
+		nftChain{name: forwardCluster},
+		nftChain{name: inputCluster})
+	return chains, nil
+}
+
+// getNFTChainsPerNatMapping returns the chain containing the DNAT rules derived from the NatMapping, which are
+// expressed through a single map lookup instead of a rule per mapping.
+func getNFTChainsPerNatMapping(nm *netv1alpha1.NatMapping) ([]nftChain, error) {
+	if nm.Spec.ClusterID == "" {
+		return nil, &errors.WrongParameter{
+			Parameter: consts.ClusterIDLabelName,
+			Reason:    errors.StringNotEmpty,
+		}
+	}
+	if len(nm.Spec.ClusterMappings) == 0 {
+		return nil, nil
+	}
+	for _, cidr := range []string{nm.Spec.PodCIDR, nm.Spec.ExternalCIDR} {
+		if _, _, err := net.ParseCIDR(cidr); err != nil {
+			return nil, fmt.Errorf("invalid NatMapping resource: %w", err)
+		}
+	}
+
+	elements := make([]string, 0, len(nm.Spec.ClusterMappings))
+	for oldIP, newIP := range nm.Spec.ClusterMappings {
+		if net.ParseIP(oldIP) == nil || net.ParseIP(newIP) == nil {
+			return nil, fmt.Errorf("invalid NatMapping resource: invalid mapping %s -> %s", oldIP, newIP)
+		}
+		elements = append(elements, fmt.Sprintf("%s : %s", newIP, oldIP))
+	}
+	// The elements are sorted to generate always the same ruleset for the same mappings.
+	sort.Strings(elements)
+
+	return []nftChain{{
+		name:     "prerouting",
+		kind:     nftNATType,
+		hook:     "prerouting",
+		priority: nftDstNATPriority,
+		rules: []string{fmt.Sprintf("ip saddr %s ip daddr %s dnat to ip daddr map { %s }",
+			nm.Spec.PodCIDR, nm.Spec.ExternalCIDR, strings.Join(elements, ", "))},
+	}}, nil
+}
+
+func getClusterTable(clusterID string) string {
+	return fmt.Sprintf("%s%s", nftClusterTablePrefix, strings.Split(clusterID, "-")[0])
+}
+
+func getMappingTable(clusterID string) string {
+	return fmt.Sprintf("%s%s", nftMappingTablePrefix, strings.Split(clusterID, "-")[0])
+}
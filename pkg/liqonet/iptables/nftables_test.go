@@ -0,0 +1,241 @@
+package iptables
+
+import (
+	"os/exec"
+	"strings"
+
+	"github.com/containernetworking/plugins/pkg/ns"
+	"github.com/containernetworking/plugins/pkg/testutils"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"github.com/liqotech/liqo/apis/net/v1alpha1"
+	"github.com/liqotech/liqo/pkg/consts"
+	"github.com/liqotech/liqo/pkg/liqonet/errors"
+)
+
+var _ = Describe("nftables", func() {
+	var (
+		clusterTable = getClusterTable(clusterID1)
+		mappingTable = getMappingTable(clusterID1)
+		natMapping   *v1alpha1.NatMapping
+	)
+
+	BeforeEach(func() {
+		tep = validTep.DeepCopy()
+		natMapping = &v1alpha1.NatMapping{
+			Spec: v1alpha1.NatMappingSpec{
+				ClusterID:       clusterID1,
+				PodCIDR:         "10.0.0.0/24",
+				ExternalCIDR:    "192.168.4.0/24",
+				ClusterMappings: natMappings,
+			},
+		}
+	})
+
+	Describe("forging the chains per cluster", func() {
+		Context("if the remote cluster has remapped the local PodCIDR", func() {
+			It("should translate the local pods to the remapped network and vice versa", func() {
+				chains, err := getNFTChainsPerCluster(tep)
+				Expect(err).To(BeNil())
+				Expect(chains).To(HaveLen(6))
+				Expect(chains[0].hook).To(Equal("postrouting"))
+				Expect(chains[0].rules).To(Equal([]string{
+					"ip saddr 192.168.0.0/24 ip daddr 10.60.0.0/24 snat ip prefix to ip saddr map { 192.168.0.0/24 : 192.168.1.0/24 }",
+					"ip saddr != 192.168.0.0/24 ip daddr 10.60.0.0/24 snat to 192.168.1.0",
+					"ip saddr != 192.168.0.0/24 ip daddr 192.168.5.0/24 snat to 192.168.1.0",
+				}))
+				Expect(chains[1].hook).To(Equal("prerouting"))
+				Expect(chains[1].rules).To(Equal([]string{
+					"ip saddr 10.60.0.0/24 ip daddr 192.168.1.0/24 dnat ip prefix to ip daddr map { 192.168.1.0/24 : 192.168.0.0/24 }",
+				}))
+			})
+		})
+
+		Context("if the remote cluster has not remapped the local PodCIDR", func() {
+			It("should only masquerade the traffic not originated by the local pods", func() {
+				tep.Status.LocalNATPodCIDR = consts.DefaultCIDRValue
+				chains, err := getNFTChainsPerCluster(tep)
+				Expect(err).To(BeNil())
+				Expect(chains).To(HaveLen(5))
+				Expect(chains[0].rules).To(Equal([]string{
+					"ip saddr != 192.168.0.0/24 ip daddr 10.60.0.0/24 snat to 192.168.0.0",
+					"ip saddr != 192.168.0.0/24 ip daddr 192.168.5.0/24 snat to 192.168.0.0",
+				}))
+			})
+		})
+
+		It("should jump to the per-cluster filter chains for the traffic towards the remote pods", func() {
+			chains, err := getNFTChainsPerCluster(tep)
+			Expect(err).To(BeNil())
+			filter := chains[len(chains)-4:]
+			Expect(filter[0]).To(Equal(nftChain{name: "forward", kind: nftFilterType, hook: "forward", priority: nftFilterPriority,
+				rules: []string{"ip daddr 10.60.0.0/24 jump " + getClusterForwardChain(clusterID1)}}))
+			Expect(filter[1]).To(Equal(nftChain{name: "input", kind: nftFilterType, hook: "input", priority: nftFilterPriority,
+				rules: []string{"ip daddr 10.60.0.0/24 jump " + getClusterInputChain(clusterID1)}}))
+			Expect(filter[2]).To(Equal(nftChain{name: getClusterForwardChain(clusterID1)}))
+			Expect(filter[3]).To(Equal(nftChain{name: getClusterInputChain(clusterID1)}))
+		})
+
+		Context("if the TunnelEndpoint is not valid", func() {
+			It("should return an error", func() {
+				tep.Spec.ClusterID = ""
+				_, err := getNFTChainsPerCluster(tep)
+				Expect(err).To(HaveOccurred())
+			})
+		})
+
+		It("should forge a table with base nat and filter chains", func() {
+			chains, err := getNFTChainsPerCluster(tep)
+			Expect(err).To(BeNil())
+			ruleset := forgeNFTTable(clusterTable, chains)
+			Expect(ruleset).To(HavePrefix("table ip liqo-cls-cluster1 {\n"))
+			Expect(ruleset).To(ContainSubstring("type nat hook postrouting priority 100; policy accept;"))
+			Expect(ruleset).To(ContainSubstring("type nat hook prerouting priority -100; policy accept;"))
+			Expect(ruleset).To(ContainSubstring("type filter hook forward priority 0; policy accept;"))
+			Expect(ruleset).To(ContainSubstring("type filter hook input priority 0; policy accept;"))
+			// The regular chains are defined before the base chains jumping to them.
+			Expect(strings.Index(ruleset, "chain "+getClusterForwardChain(clusterID1))).To(
+				BeNumerically("<", strings.Index(ruleset, "chain forward")))
+			Expect(forgeNFTTable(clusterTable, nil)).To(BeEmpty())
+		})
+	})
+
+	Describe("forging the chains per NatMapping", func() {
+		It("should map all the new IPs to the old ones through a single rule", func() {
+			chains, err := getNFTChainsPerNatMapping(natMapping)
+			Expect(err).To(BeNil())
+			Expect(chains).To(HaveLen(1))
+			Expect(chains[0].rules).To(Equal([]string{
+				"ip saddr 10.0.0.0/24 ip daddr 192.168.4.0/24 dnat to ip daddr map { 10.0.3.2 : 10.0.0.2, 10.0.5.2 : 12.0.0.4 }",
+			}))
+		})
+
+		It("should return no chains if there are no mappings", func() {
+			natMapping.Spec.ClusterMappings = v1alpha1.Mappings{}
+			Expect(getNFTChainsPerNatMapping(natMapping)).To(BeEmpty())
+		})
+
+		It("should return an error if the cluster ID is empty", func() {
+			natMapping.Spec.ClusterID = ""
+			_, err := getNFTChainsPerNatMapping(natMapping)
+			Expect(err).To(MatchError(&errors.WrongParameter{
+				Parameter: consts.ClusterIDLabelName,
+				Reason:    errors.StringNotEmpty,
+			}))
+		})
+
+		It("should return an error if a mapping is not valid", func() {
+			natMapping.Spec.ClusterMappings = v1alpha1.Mappings{oldIP1: invalidValue}
+			_, err := getNFTChainsPerNatMapping(natMapping)
+			Expect(err).To(HaveOccurred())
+		})
+	})
+
+	Describe("configuring the rules in a network namespace", func() {
+		var (
+			netns ns.NetNS
+			nft   *NFTHandler
+		)
+
+		listTables := func() []string {
+			var tables []string
+			Expect(netns.Do(func(ns.NetNS) error {
+				var err error
+				tables, err = nft.listLiqoTables()
+				return err
+			})).To(Succeed())
+			return tables
+		}
+
+		BeforeEach(func() {
+			netns = nil
+			if _, err := exec.LookPath(nftBinary); err != nil {
+				Skip("the nft binary is not available")
+			}
+			var err error
+			nft, err = NewNFTHandler()
+			Expect(err).To(BeNil())
+			netns, err = testutils.NewNS()
+			Expect(err).To(BeNil())
+			Expect(netns.Do(func(ns.NetNS) error { return nft.Init() })).To(Succeed())
+		})
+
+		AfterEach(func() {
+			if netns == nil {
+				return
+			}
+			Expect(netns.Do(func(ns.NetNS) error { return nft.Terminate() })).To(Succeed())
+			Expect(netns.Close()).To(Succeed())
+			Expect(testutils.UnmountNS(netns)).To(Succeed())
+		})
+
+		It("should create, replace and remove the tables per cluster", func() {
+			Expect(netns.Do(func(ns.NetNS) error {
+				if err := nft.EnsureChainsPerCluster(clusterID1); err != nil {
+					return err
+				}
+				if err := nft.EnsurePostroutingRules(tep); err != nil {
+					return err
+				}
+				return nft.EnsurePreroutingRulesPerNatMapping(natMapping)
+			})).To(Succeed())
+			Expect(listTables()).To(ConsistOf(clusterTable, mappingTable))
+
+			// The tables are replaced as a whole when the configuration changes.
+			tep.Status.LocalNATPodCIDR = consts.DefaultCIDRValue
+			natMapping.Spec.ClusterMappings = v1alpha1.Mappings{}
+			Expect(netns.Do(func(ns.NetNS) error {
+				if err := nft.EnsurePreroutingRulesPerTunnelEndpoint(tep); err != nil {
+					return err
+				}
+				return nft.EnsurePreroutingRulesPerNatMapping(natMapping)
+			})).To(Succeed())
+			Expect(listTables()).To(ConsistOf(clusterTable))
+			Expect(nft.applied[clusterTable].ruleset).NotTo(ContainSubstring("prerouting"))
+
+			Expect(netns.Do(func(ns.NetNS) error {
+				return nft.RemoveIPTablesConfigurationPerCluster(tep)
+			})).To(Succeed())
+			Expect(listTables()).To(BeEmpty())
+		})
+
+		It("should repair the tables modified out-of-band", func() {
+			var listed string
+			Expect(netns.Do(func(ns.NetNS) error {
+				if err := nft.EnsurePostroutingRules(tep); err != nil {
+					return err
+				}
+				var err error
+				listed, err = nft.listTable(clusterTable)
+				return err
+			})).To(Succeed())
+			Expect(listed).To(ContainSubstring("snat"))
+
+			// The rules removed out-of-band are restored, even though the configuration did not change.
+			var repaired string
+			Expect(netns.Do(func(ns.NetNS) error {
+				if _, err := nft.run(nil, "flush", "chain", nftFamily, clusterTable, "postrouting"); err != nil {
+					return err
+				}
+				if err := nft.EnsurePostroutingRules(tep); err != nil {
+					return err
+				}
+				var err error
+				repaired, err = nft.listTable(clusterTable)
+				return err
+			})).To(Succeed())
+			Expect(repaired).To(Equal(listed))
+		})
+
+		It("should remove all the tables on termination", func() {
+			Expect(netns.Do(func(ns.NetNS) error {
+				if err := nft.EnsureChainRulesPerCluster(tep); err != nil {
+					return err
+				}
+				return nft.Terminate()
+			})).To(Succeed())
+			Expect(listTables()).To(BeEmpty())
+		})
+	})
+})
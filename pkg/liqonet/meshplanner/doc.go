@@ -0,0 +1,4 @@
+// Package meshplanner computes and validates the networks the clusters of a multi-peer mesh are reached with.
+// In the mesh mode each cluster is assigned a single set of networks, used by all the other clusters of the mesh,
+// so that an endpoint is reached with the same address regardless of the cluster the traffic comes through.
+package meshplanner
@@ -0,0 +1,13 @@
+package meshplanner
+
+import (
+	"testing"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+func TestMeshPlanner(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "MeshPlanner Suite")
+}
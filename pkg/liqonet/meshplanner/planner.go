@@ -0,0 +1,409 @@
+package meshplanner
+
+import (
+	"fmt"
+	"math/big"
+	"net"
+	"sort"
+)
+
+// Mode selects how the networks of the remote clusters are remapped.
+type Mode string
+
+const (
+	// IndependentMode mimics the default behavior of the IPAM: each cluster remaps the conflicting networks
+	// of its peers on its own, picking the first free network from the pools.
+	IndependentMode Mode = "independent"
+	// MeshMode assigns each cluster a single set of networks, used by all the other clusters of the mesh.
+	MeshMode Mode = "mesh"
+)
+
+// Cluster describes the networks of a cluster taking part in the mesh.
+type Cluster struct {
+	ID           string `json:"id"`
+	PodCIDR      string `json:"podCIDR"`
+	ExternalCIDR string `json:"externalCIDR"`
+	// ServiceCIDR is optional, and it is only used to avoid remapping the networks of the peers on top of it.
+	ServiceCIDR string `json:"serviceCIDR,omitempty"`
+	// Peers are the clusters this cluster is peered with. Peerings are symmetric, hence it is enough to
+	// list them on one side. If no cluster lists any peer, the clusters are assumed to be fully meshed.
+	Peers []string `json:"peers,omitempty"`
+}
+
+// Mesh is the set of clusters the plan is computed for.
+type Mesh struct {
+	Clusters []Cluster `json:"clusters"`
+	// Pools are the networks the remapped networks are taken from. The pools of the IPAM are used if not set.
+	Pools []string `json:"pools,omitempty"`
+}
+
+// Networks are the networks a cluster is reached with.
+type Networks struct {
+	PodCIDR      string `json:"podCIDR"`
+	ExternalCIDR string `json:"externalCIDR"`
+}
+
+// Plan contains the networks each cluster uses to reach its peers.
+type Plan struct {
+	Mode Mode `json:"mode"`
+	// Networks contains the networks each cluster is reached with by all the other clusters (mesh mode only).
+	Networks map[string]Networks `json:"networks,omitempty"`
+	// Tables contains, for each cluster, the networks its peers are reached with. Key is the cluster ID.
+	Tables map[string]map[string]Networks `json:"tables"`
+}
+
+// Violation is a problem detected while validating a plan.
+type Violation struct {
+	ClusterID string `json:"clusterID"`
+	Message   string `json:"message"`
+}
+
+func (v Violation) String() string {
+	return fmt.Sprintf("%s -> %s", v.ClusterID, v.Message)
+}
+
+// topology is the parsed representation of a Mesh.
+type topology struct {
+	ids      []string
+	clusters map[string]*Cluster
+	natives  map[string][]*net.IPNet
+	peers    map[string]map[string]bool
+	pools    []*net.IPNet
+}
+
+// ComputePlan computes the networks each cluster of the mesh uses to reach its peers, using the given mode.
+// The default pools are used if the mesh does not specify any.
+func ComputePlan(mesh *Mesh, mode Mode, defaultPools []string) (*Plan, error) {
+	topo, err := parseMesh(mesh, defaultPools)
+	if err != nil {
+		return nil, err
+	}
+	plan := &Plan{Mode: mode, Tables: make(map[string]map[string]Networks)}
+	switch mode {
+	case MeshMode:
+		plan.Networks, err = topo.meshNetworks()
+		if err != nil {
+			return nil, err
+		}
+		for _, id := range topo.ids {
+			plan.Tables[id] = make(map[string]Networks)
+			for _, peer := range topo.peersOf(id) {
+				plan.Tables[id][peer] = plan.Networks[peer]
+			}
+		}
+	case IndependentMode:
+		for _, id := range topo.ids {
+			if plan.Tables[id], err = topo.independentTable(id); err != nil {
+				return nil, err
+			}
+		}
+	default:
+		return nil, fmt.Errorf("unsupported mode %q", mode)
+	}
+	return plan, nil
+}
+
+// meshNetworks assigns each cluster its networks. A network is kept if it does not overlap the networks of
+// the other clusters, otherwise it is remapped to a network not overlapping any of the networks of the mesh.
+func (topo *topology) meshNetworks() (map[string]Networks, error) {
+	var allNatives []*net.IPNet
+	for _, id := range topo.ids {
+		allNatives = append(allNatives, topo.natives[id]...)
+	}
+	var used []*net.IPNet
+	assign := func(id, cidr string) (string, error) {
+		_, network, _ := net.ParseCIDR(cidr)
+		conflicts := overlapsAny(network, used)
+		for _, other := range topo.ids {
+			conflicts = conflicts || (other != id && overlapsAny(network, topo.natives[other]))
+		}
+		if !conflicts {
+			used = append(used, network)
+			return network.String(), nil
+		}
+		remapped, err := allocate(network, topo.pools, append(append([]*net.IPNet{}, allNatives...), used...))
+		if err != nil {
+			return "", fmt.Errorf("cannot remap network %s of cluster %s: %w", cidr, id, err)
+		}
+		used = append(used, remapped)
+		return remapped.String(), nil
+	}
+
+	networks := make(map[string]Networks)
+	for _, id := range topo.ids {
+		podCIDR, err := assign(id, topo.clusters[id].PodCIDR)
+		if err != nil {
+			return nil, err
+		}
+		externalCIDR, err := assign(id, topo.clusters[id].ExternalCIDR)
+		if err != nil {
+			return nil, err
+		}
+		networks[id] = Networks{PodCIDR: podCIDR, ExternalCIDR: externalCIDR}
+	}
+	return networks, nil
+}
+
+// independentTable remaps the networks of the peers of the given cluster, as its IPAM would do.
+func (topo *topology) independentTable(id string) (map[string]Networks, error) {
+	used := append([]*net.IPNet{}, topo.natives[id]...)
+	assign := func(peer, cidr string) (string, error) {
+		_, network, _ := net.ParseCIDR(cidr)
+		if !overlapsAny(network, used) {
+			used = append(used, network)
+			return network.String(), nil
+		}
+		remapped, err := allocate(network, topo.pools, used)
+		if err != nil {
+			return "", fmt.Errorf("cannot remap network %s of cluster %s in cluster %s: %w", cidr, peer, id, err)
+		}
+		used = append(used, remapped)
+		return remapped.String(), nil
+	}
+
+	table := make(map[string]Networks)
+	for _, peer := range topo.peersOf(id) {
+		podCIDR, err := assign(peer, topo.clusters[peer].PodCIDR)
+		if err != nil {
+			return nil, err
+		}
+		externalCIDR, err := assign(peer, topo.clusters[peer].ExternalCIDR)
+		if err != nil {
+			return nil, err
+		}
+		table[peer] = Networks{PodCIDR: podCIDR, ExternalCIDR: externalCIDR}
+	}
+	return table, nil
+}
+
+// Validate checks that the given plan can be applied to the mesh. In particular, each cluster must reach
+// its peers with networks not overlapping each other nor the local ones, and two peered clusters must reach
+// a common peer with the same networks, otherwise the addresses seen through one of them would be different.
+func Validate(mesh *Mesh, plan *Plan) ([]Violation, error) {
+	topo, err := parseMesh(mesh, nil)
+	if err != nil {
+		return nil, err
+	}
+	var violations []Violation
+	report := func(clusterID, format string, args ...interface{}) {
+		violations = append(violations, Violation{ClusterID: clusterID, Message: fmt.Sprintf(format, args...)})
+	}
+
+	for _, id := range topo.ids {
+		type visible struct {
+			name    string
+			network *net.IPNet
+		}
+		cluster := topo.clusters[id]
+		var networks []visible
+		add := func(name, cidr, native string) {
+			_, network, err := net.ParseCIDR(cidr)
+			if err != nil {
+				report(id, "%s is not a valid network: %q", name, cidr)
+				return
+			}
+			if native != "" {
+				_, nativeNetwork, _ := net.ParseCIDR(native)
+				nativeOnes, nativeBits := nativeNetwork.Mask.Size()
+				ones, bits := network.Mask.Size()
+				if ones != nativeOnes || bits != nativeBits {
+					report(id, "%s is %s, which does not have the same size of %s", name, network, nativeNetwork)
+				}
+			}
+			networks = append(networks, visible{name: name, network: network})
+		}
+		add("local PodCIDR", cluster.PodCIDR, "")
+		add("local ExternalCIDR", cluster.ExternalCIDR, "")
+		if cluster.ServiceCIDR != "" {
+			add("local ServiceCIDR", cluster.ServiceCIDR, "")
+		}
+		for _, peer := range topo.peersOf(id) {
+			entry, found := plan.Tables[id][peer]
+			if !found {
+				report(id, "the networks of peer %s are missing", peer)
+				continue
+			}
+			add("PodCIDR of "+peer, entry.PodCIDR, topo.clusters[peer].PodCIDR)
+			add("ExternalCIDR of "+peer, entry.ExternalCIDR, topo.clusters[peer].ExternalCIDR)
+		}
+		for i := range networks {
+			for j := i + 1; j < len(networks); j++ {
+				if overlaps(networks[i].network, networks[j].network) {
+					report(id, "%s (%s) overlaps with %s (%s)", networks[i].name, networks[i].network,
+						networks[j].name, networks[j].network)
+				}
+			}
+		}
+	}
+
+	for _, id := range topo.ids {
+		for _, peer := range topo.peersOf(id) {
+			// Each pair of peered clusters is checked once.
+			if peer < id {
+				continue
+			}
+			for _, common := range topo.peersOf(id) {
+				if common == peer || !topo.peers[peer][common] {
+					continue
+				}
+				mine, foundMine := plan.Tables[id][common]
+				theirs, foundTheirs := plan.Tables[peer][common]
+				if !foundMine || !foundTheirs {
+					// Already reported as missing.
+					continue
+				}
+				if mine.PodCIDR != theirs.PodCIDR || mine.ExternalCIDR != theirs.ExternalCIDR {
+					report(common, "it is reached by %s with %s and %s, and by %s with %s and %s",
+						id, mine.PodCIDR, mine.ExternalCIDR, peer, theirs.PodCIDR, theirs.ExternalCIDR)
+				}
+			}
+		}
+	}
+	return violations, nil
+}
+
+// parseMesh checks the given mesh, and returns its parsed representation.
+func parseMesh(mesh *Mesh, defaultPools []string) (*topology, error) {
+	topo := &topology{
+		clusters: make(map[string]*Cluster),
+		natives:  make(map[string][]*net.IPNet),
+		peers:    make(map[string]map[string]bool),
+	}
+	for i := range mesh.Clusters {
+		cluster := &mesh.Clusters[i]
+		if cluster.ID == "" {
+			return nil, fmt.Errorf("cluster %d has an empty ID", i)
+		}
+		if _, found := topo.clusters[cluster.ID]; found {
+			return nil, fmt.Errorf("cluster %s is specified more than once", cluster.ID)
+		}
+		topo.clusters[cluster.ID] = cluster
+		topo.ids = append(topo.ids, cluster.ID)
+		topo.peers[cluster.ID] = make(map[string]bool)
+		cidrs := []string{cluster.PodCIDR, cluster.ExternalCIDR}
+		if cluster.ServiceCIDR != "" {
+			cidrs = append(cidrs, cluster.ServiceCIDR)
+		}
+		for _, cidr := range cidrs {
+			_, network, err := net.ParseCIDR(cidr)
+			if err != nil {
+				return nil, fmt.Errorf("invalid network %q of cluster %s: %w", cidr, cluster.ID, err)
+			}
+			topo.natives[cluster.ID] = append(topo.natives[cluster.ID], network)
+		}
+	}
+	sort.Strings(topo.ids)
+
+	fullMesh := true
+	for _, cluster := range mesh.Clusters {
+		for _, peer := range cluster.Peers {
+			if _, found := topo.clusters[peer]; !found || peer == cluster.ID {
+				return nil, fmt.Errorf("invalid peer %q of cluster %s", peer, cluster.ID)
+			}
+			topo.peers[cluster.ID][peer] = true
+			topo.peers[peer][cluster.ID] = true
+			fullMesh = false
+		}
+	}
+	if fullMesh {
+		for _, id := range topo.ids {
+			for _, peer := range topo.ids {
+				topo.peers[id][peer] = id != peer
+			}
+		}
+	}
+
+	pools := mesh.Pools
+	if len(pools) == 0 {
+		pools = defaultPools
+	}
+	for _, pool := range pools {
+		_, network, err := net.ParseCIDR(pool)
+		if err != nil {
+			return nil, fmt.Errorf("invalid pool %q: %w", pool, err)
+		}
+		topo.pools = append(topo.pools, network)
+	}
+	return topo, nil
+}
+
+// peersOf returns the sorted list of the peers of the given cluster.
+func (topo *topology) peersOf(id string) []string {
+	var peers []string
+	for _, peer := range topo.ids {
+		if topo.peers[id][peer] {
+			peers = append(peers, peer)
+		}
+	}
+	return peers
+}
+
+// allocate returns the first network with the same size of the given one, taken from a pool of the same
+// IP family, which does not overlap any of the used networks.
+func allocate(network *net.IPNet, pools, used []*net.IPNet) (*net.IPNet, error) {
+	ones, bits := network.Mask.Size()
+	blockSize := new(big.Int).Lsh(big.NewInt(1), uint(bits-ones))
+	for _, pool := range pools {
+		poolOnes, poolBits := pool.Mask.Size()
+		if poolBits != bits || poolOnes > ones {
+			continue
+		}
+		candidate := ipToInt(pool.IP)
+		poolEnd := lastIP(pool)
+		for candidate.Cmp(poolEnd) <= 0 {
+			subnet := &net.IPNet{IP: intToIP(candidate, bits), Mask: net.CIDRMask(ones, bits)}
+			conflict := firstOverlapping(subnet, used)
+			if conflict == nil {
+				return subnet, nil
+			}
+			// Skip to the first aligned network following both the candidate and the conflicting network.
+			next := new(big.Int).Add(candidate, blockSize)
+			if afterConflict := new(big.Int).Add(lastIP(conflict), big.NewInt(1)); afterConflict.Cmp(next) > 0 {
+				next = afterConflict
+			}
+			remainder := new(big.Int).Mod(next, blockSize)
+			if remainder.Sign() != 0 {
+				next.Add(next, new(big.Int).Sub(blockSize, remainder))
+			}
+			candidate = next
+		}
+	}
+	return nil, fmt.Errorf("no networks available")
+}
+
+func overlaps(a, b *net.IPNet) bool {
+	return a.Contains(b.IP) || b.Contains(a.IP)
+}
+
+func overlapsAny(network *net.IPNet, networks []*net.IPNet) bool {
+	return firstOverlapping(network, networks) != nil
+}
+
+func firstOverlapping(network *net.IPNet, networks []*net.IPNet) *net.IPNet {
+	for _, other := range networks {
+		if overlaps(network, other) {
+			return other
+		}
+	}
+	return nil
+}
+
+func ipToInt(ip net.IP) *big.Int {
+	if ipv4 := ip.To4(); ipv4 != nil {
+		return new(big.Int).SetBytes(ipv4)
+	}
+	return new(big.Int).SetBytes(ip.To16())
+}
+
+func intToIP(value *big.Int, bits int) net.IP {
+	ip := make(net.IP, bits/8)
+	value.FillBytes(ip)
+	return ip
+}
+
+// lastIP returns the last address of the given network.
+func lastIP(network *net.IPNet) *big.Int {
+	ones, bits := network.Mask.Size()
+	size := new(big.Int).Lsh(big.NewInt(1), uint(bits-ones))
+	return size.Add(size, ipToInt(network.IP)).Sub(size, big.NewInt(1))
+}
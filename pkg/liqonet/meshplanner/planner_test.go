@@ -0,0 +1,125 @@
+package meshplanner
+
+import (
+	"net"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Planner", func() {
+	var mesh *Mesh
+
+	BeforeEach(func() {
+		// Three fully meshed clusters, sharing the same PodCIDR.
+		mesh = &Mesh{
+			Clusters: []Cluster{
+				{ID: "cluster-a", PodCIDR: "10.0.0.0/16", ExternalCIDR: "10.1.0.0/16"},
+				{ID: "cluster-b", PodCIDR: "10.0.0.0/16", ExternalCIDR: "10.2.0.0/16"},
+				{ID: "cluster-c", PodCIDR: "10.0.0.0/16", ExternalCIDR: "10.3.0.0/16"},
+			},
+			Pools: []string{"10.0.0.0/8"},
+		}
+	})
+
+	Context("in mesh mode", func() {
+		It("should remap the conflicting networks once for the whole mesh", func() {
+			plan, err := ComputePlan(mesh, MeshMode, nil)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(plan.Networks).To(Equal(map[string]Networks{
+				"cluster-a": {PodCIDR: "10.4.0.0/16", ExternalCIDR: "10.1.0.0/16"},
+				"cluster-b": {PodCIDR: "10.5.0.0/16", ExternalCIDR: "10.2.0.0/16"},
+				"cluster-c": {PodCIDR: "10.6.0.0/16", ExternalCIDR: "10.3.0.0/16"},
+			}))
+			Expect(plan.Tables["cluster-a"]).To(HaveKeyWithValue("cluster-c", plan.Networks["cluster-c"]))
+			Expect(plan.Tables["cluster-b"]).To(HaveKeyWithValue("cluster-c", plan.Networks["cluster-c"]))
+			Expect(plan.Tables["cluster-a"]).ToNot(HaveKey("cluster-a"))
+			Expect(Validate(mesh, plan)).To(BeEmpty())
+		})
+
+		It("should only list the declared peers in the tables", func() {
+			mesh.Clusters[1].Peers = []string{"cluster-a", "cluster-c"}
+			plan, err := ComputePlan(mesh, MeshMode, nil)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(plan.Tables["cluster-a"]).To(HaveLen(1))
+			Expect(plan.Tables["cluster-b"]).To(HaveLen(2))
+			Expect(plan.Tables["cluster-c"]).To(HaveKey("cluster-b"))
+			Expect(Validate(mesh, plan)).To(BeEmpty())
+		})
+	})
+
+	Context("in independent mode", func() {
+		It("should report the clusters reached with different networks by two peered clusters", func() {
+			plan, err := ComputePlan(mesh, IndependentMode, nil)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(plan.Networks).To(BeEmpty())
+			Expect(plan.Tables["cluster-a"]["cluster-b"]).ToNot(Equal(plan.Tables["cluster-c"]["cluster-b"]))
+			violations, err := Validate(mesh, plan)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(violations).To(ContainElement(WithTransform(func(v Violation) string { return v.ClusterID }, Equal("cluster-b"))))
+		})
+	})
+
+	Context("when validating a plan", func() {
+		It("should report the overlapping networks and the networks of the wrong size", func() {
+			plan := &Plan{Mode: MeshMode, Tables: map[string]map[string]Networks{
+				"cluster-a": {
+					"cluster-b": {PodCIDR: "10.1.0.0/16", ExternalCIDR: "10.2.0.0/16"},
+					"cluster-c": {PodCIDR: "10.6.0.0/24", ExternalCIDR: "10.3.0.0/16"},
+				},
+			}}
+			violations, err := Validate(mesh, plan)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(violations).To(ContainElement(Violation{
+				ClusterID: "cluster-a",
+				Message:   "local ExternalCIDR (10.1.0.0/16) overlaps with PodCIDR of cluster-b (10.1.0.0/16)",
+			}))
+			Expect(violations).To(ContainElement(Violation{
+				ClusterID: "cluster-a",
+				Message:   "PodCIDR of cluster-c is 10.6.0.0/24, which does not have the same size of 10.0.0.0/16",
+			}))
+			Expect(violations).To(ContainElement(Violation{
+				ClusterID: "cluster-b",
+				Message:   "the networks of peer cluster-a are missing",
+			}))
+		})
+	})
+
+	Context("when the input is not valid", func() {
+		It("should return an error", func() {
+			mesh.Clusters[0].Peers = []string{"cluster-d"}
+			_, err := ComputePlan(mesh, MeshMode, nil)
+			Expect(err).To(HaveOccurred())
+			mesh.Clusters[0].Peers = nil
+			mesh.Clusters[1].ID = "cluster-a"
+			_, err = ComputePlan(mesh, MeshMode, nil)
+			Expect(err).To(HaveOccurred())
+		})
+	})
+})
+
+var _ = Describe("allocate", func() {
+	parse := func(cidr string) *net.IPNet {
+		_, network, err := net.ParseCIDR(cidr)
+		Expect(err).ToNot(HaveOccurred())
+		return network
+	}
+
+	It("should skip the used networks larger than the requested one", func() {
+		network, err := allocate(parse("10.0.0.0/16"), []*net.IPNet{parse("10.0.0.0/8")}, []*net.IPNet{parse("10.0.0.0/9")})
+		Expect(err).ToNot(HaveOccurred())
+		Expect(network.String()).To(Equal("10.128.0.0/16"))
+	})
+
+	It("should only use the pools of the same IP family", func() {
+		pools := []*net.IPNet{parse("10.0.0.0/8"), parse("fd00::/8")}
+		network, err := allocate(parse("fd00::/64"), pools, []*net.IPNet{parse("fd00::/64")})
+		Expect(err).ToNot(HaveOccurred())
+		Expect(network.String()).To(Equal("fd00:0:0:1::/64"))
+	})
+
+	It("should fail if the pools are exhausted", func() {
+		_, err := allocate(parse("10.0.0.0/16"), []*net.IPNet{parse("10.0.0.0/15")}, []*net.IPNet{parse("10.0.0.0/15")})
+		Expect(err).To(HaveOccurred())
+	})
+})
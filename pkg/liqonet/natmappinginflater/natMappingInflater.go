@@ -35,6 +35,10 @@ type Interface interface {
 	AddMapping(oldIP, newIP, clusterID string) error
 	// RemoveMapping removes a NAT mapping.
 	RemoveMapping(oldIP, clusterID string) error
+	// AddMappings adds a set of NAT mappings, keyed by the old IP, with a single update of the resource.
+	AddMappings(mappings map[string]string, clusterID string) error
+	// RemoveMappings removes a set of NAT mappings with a single update of the resource.
+	RemoveMappings(oldIPs []string, clusterID string) error
 }
 
 // NatMappingInflater is an implementation of the NatMappingInflaterInterface
@@ -312,6 +316,87 @@ func (inflater *NatMappingInflater) removeMappingFromResource(oldIP, clusterID s
 	return nil
 }
 
+// AddMappings adds a set of mappings in the resource related to a remote cluster, with a single update.
+// The in-memory structure is updated only if the resource has been successfully updated.
+func (inflater *NatMappingInflater) AddMappings(newMappings map[string]string, clusterID string) error {
+	mappings, exists := inflater.natMappingsPerCluster[clusterID]
+	if !exists {
+		return &errors.MissingInit{
+			StructureName: fmt.Sprintf("%s for cluster %s", consts.NatMappingKind, clusterID),
+		}
+	}
+	toAdd := make(map[string]string)
+	for oldIP, newIP := range newMappings {
+		if existingIP, exists := mappings[oldIP]; !exists || existingIP != newIP {
+			toAdd[oldIP] = newIP
+		}
+	}
+	if len(toAdd) == 0 {
+		return nil // Mappings already exist, do nothing
+	}
+	if err := inflater.updateMappingsInResource(clusterID, func(resourceMappings netv1alpha1.Mappings) {
+		for oldIP, newIP := range toAdd {
+			resourceMappings[oldIP] = newIP
+		}
+	}); err != nil {
+		return fmt.Errorf("unable to add NatMappings to resource: %w", err)
+	}
+	for oldIP, newIP := range toAdd {
+		mappings[oldIP] = newIP
+	}
+	return nil
+}
+
+// RemoveMappings removes a set of mappings from the resource related to a remote cluster, with a single update.
+// The in-memory structure is updated only if the resource has been successfully updated.
+func (inflater *NatMappingInflater) RemoveMappings(oldIPs []string, clusterID string) error {
+	mappings, exists := inflater.natMappingsPerCluster[clusterID]
+	if !exists {
+		return &errors.MissingInit{
+			StructureName: fmt.Sprintf("%s for cluster %s", consts.NatMappingKind, clusterID),
+		}
+	}
+	var toRemove []string
+	for _, oldIP := range oldIPs {
+		if _, exists := mappings[oldIP]; exists {
+			toRemove = append(toRemove, oldIP)
+		}
+	}
+	if len(toRemove) == 0 {
+		return nil // Mappings already deleted, do nothing
+	}
+	if err := inflater.updateMappingsInResource(clusterID, func(resourceMappings netv1alpha1.Mappings) {
+		for _, oldIP := range toRemove {
+			delete(resourceMappings, oldIP)
+		}
+	}); err != nil {
+		return fmt.Errorf("cannot delete mappings from resource: %w", err)
+	}
+	for _, oldIP := range toRemove {
+		delete(mappings, oldIP)
+	}
+	return nil
+}
+
+// updateMappingsInResource applies the given function to the mappings of the resource related to a remote cluster,
+// and updates it, retrying in case of conflicts.
+func (inflater *NatMappingInflater) updateMappingsInResource(clusterID string, update func(netv1alpha1.Mappings)) error {
+	return retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		natMappings, err := inflater.getNatMappingResource(clusterID)
+		if err != nil {
+			return fmt.Errorf("cannot retrieve NatMapping resource for cluster %s: %w", clusterID, err)
+		}
+		if natMappings.Spec.ClusterMappings == nil {
+			natMappings.Spec.ClusterMappings = make(netv1alpha1.Mappings)
+		}
+		update(natMappings.Spec.ClusterMappings)
+		if err := inflater.updateNatMappingResource(natMappings); err != nil {
+			return fmt.Errorf("cannot update NatMapping resource for cluster %s: %w", clusterID, err)
+		}
+		return nil
+	})
+}
+
 // Updates the resource related to a remote cluster.
 func (inflater *NatMappingInflater) updateNatMappingResource(resource *netv1alpha1.NatMapping) error {
 	// Convert resource to unstructured type
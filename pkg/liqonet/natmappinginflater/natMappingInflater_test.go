@@ -410,4 +410,56 @@ var _ = Describe("NatMappingInflater", func() {
 			})
 		})
 	})
+	Describe("AddMappings", func() {
+		Context("Call func without initializing NAT mappings", func() {
+			It("should return a MissingInit error", func() {
+				err := inflater.AddMappings(map[string]string{oldIP: newIP}, clusterID1)
+				Expect(err).To(MatchError(fmt.Sprintf("%s for cluster %s must be %s", consts.NatMappingKind, clusterID1, liqoneterrors.Initialization)))
+			})
+		})
+		Context("Call func after correct initialization", func() {
+			It("should successfully add all the mappings", func() {
+				// Init
+				err := inflater.InitNatMappingsPerCluster(podCIDR, externalCIDR, clusterID1)
+				Expect(err).To(BeNil())
+
+				err = inflater.AddMappings(backedMappings, clusterID1)
+				Expect(err).To(BeNil())
+
+				// Check both in-memory structure and resource
+				mappings, err := inflater.GetNatMappings(clusterID1)
+				Expect(err).To(BeNil())
+				Expect(mappings).To(BeEquivalentTo(backedMappings))
+				nm, err := inflater.getNatMappingResource(clusterID1)
+				Expect(err).To(BeNil())
+				Expect(nm.Spec.ClusterMappings).To(BeEquivalentTo(backedMappings))
+			})
+		})
+	})
+	Describe("RemoveMappings", func() {
+		Context("Call func without initializing NAT mappings", func() {
+			It("should return a MissingInit error", func() {
+				err := inflater.RemoveMappings([]string{oldIP}, clusterID1)
+				Expect(err).To(MatchError(fmt.Sprintf("%s for cluster %s must be %s", consts.NatMappingKind, clusterID1, liqoneterrors.Initialization)))
+			})
+		})
+		Context("Call func after correct initialization", func() {
+			It("should remove only the given mappings", func() {
+				// Init
+				err := inflater.InitNatMappingsPerCluster(podCIDR, externalCIDR, clusterID1)
+				Expect(err).To(BeNil())
+
+				err = inflater.AddMappings(map[string]string{oldIP: newIP, "10.0.1.0": "10.0.0.0"}, clusterID1)
+				Expect(err).To(BeNil())
+
+				// Missing mappings are ignored
+				err = inflater.RemoveMappings([]string{oldIP, "10.0.1.4"}, clusterID1)
+				Expect(err).To(BeNil())
+
+				nm, err := inflater.getNatMappingResource(clusterID1)
+				Expect(err).To(BeNil())
+				Expect(nm.Spec.ClusterMappings).To(Equal(netv1alpha1.Mappings{"10.0.1.0": "10.0.0.0"}))
+			})
+		})
+	})
 })
@@ -4,6 +4,8 @@ import (
 	"context"
 
 	grpc "google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
 
 	liqonetIpam "github.com/liqotech/liqo/pkg/liqonet/ipam"
 	"github.com/liqotech/liqo/pkg/liqonet/utils"
@@ -36,6 +38,30 @@ func (mock *MockIpam) UnmapEndpointIP(
 	return &liqonetIpam.UnmapResponse{}, nil
 }
 
+// MapEndpointIPs mocks the corresponding func in IPAM.
+func (mock *MockIpam) MapEndpointIPs(
+	ctx context.Context,
+	in *liqonetIpam.MapEndpointIPsRequest,
+	opts ...grpc.CallOption) (*liqonetIpam.MapEndpointIPsResponse, error) {
+	newIPs := make([]string, len(in.GetIps()))
+	for i, oldIP := range in.GetIps() {
+		newIP, err := utils.MapIPToNetwork(mock.LocalRemappedPodCIDR, oldIP)
+		if err != nil {
+			return &liqonetIpam.MapEndpointIPsResponse{}, err
+		}
+		newIPs[i] = newIP
+	}
+	return &liqonetIpam.MapEndpointIPsResponse{Ips: newIPs}, nil
+}
+
+// UnmapEndpointIPs mocks the corresponding func in IPAM.
+func (mock *MockIpam) UnmapEndpointIPs(
+	ctx context.Context,
+	in *liqonetIpam.UnmapEndpointIPsRequest,
+	opts ...grpc.CallOption) (*liqonetIpam.UnmapEndpointIPsResponse, error) {
+	return &liqonetIpam.UnmapEndpointIPsResponse{}, nil
+}
+
 // GetHomePodIP mocks the corresponding func in IPAM.
 func (mock *MockIpam) GetHomePodIP(
 	ctx context.Context,
@@ -47,3 +73,35 @@ func (mock *MockIpam) GetHomePodIP(
 	}
 	return &liqonetIpam.GetHomePodIPResponse{HomeIP: homeIP}, nil
 }
+
+// ListClusters mocks the corresponding func in IPAM.
+func (mock *MockIpam) ListClusters(
+	ctx context.Context,
+	in *liqonetIpam.ListClustersRequest,
+	opts ...grpc.CallOption) (*liqonetIpam.ListClustersResponse, error) {
+	return &liqonetIpam.ListClustersResponse{}, nil
+}
+
+// ListEndpointMappings mocks the corresponding func in IPAM.
+func (mock *MockIpam) ListEndpointMappings(
+	ctx context.Context,
+	in *liqonetIpam.ListEndpointMappingsRequest,
+	opts ...grpc.CallOption) (*liqonetIpam.ListEndpointMappingsResponse, error) {
+	return &liqonetIpam.ListEndpointMappingsResponse{}, nil
+}
+
+// LookupIP mocks the corresponding func in IPAM.
+func (mock *MockIpam) LookupIP(
+	ctx context.Context,
+	in *liqonetIpam.LookupIPRequest,
+	opts ...grpc.CallOption) (*liqonetIpam.LookupIPResponse, error) {
+	return &liqonetIpam.LookupIPResponse{}, nil
+}
+
+// WatchMappings mocks the corresponding func in IPAM, which is not supported.
+func (mock *MockIpam) WatchMappings(
+	ctx context.Context,
+	in *liqonetIpam.WatchMappingsRequest,
+	opts ...grpc.CallOption) (liqonetIpam.Ipam_WatchMappingsClient, error) {
+	return nil, status.Error(codes.Unimplemented, "method WatchMappings not implemented")
+}
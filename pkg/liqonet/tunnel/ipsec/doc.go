@@ -0,0 +1,2 @@
+// Package ipsec implements the IPsec (XFRM) tunnels to be used as vpn technology to interconnect clusters.
+package ipsec
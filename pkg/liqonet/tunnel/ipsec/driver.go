@@ -0,0 +1,709 @@
+package ipsec
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"reflect"
+	"strconv"
+	"sync"
+	"syscall"
+
+	"github.com/vishvananda/netlink"
+	"github.com/vishvananda/netlink/nl"
+	"golang.org/x/sys/unix"
+	k8s "k8s.io/client-go/kubernetes"
+	"k8s.io/klog/v2"
+
+	netv1alpha1 "github.com/liqotech/liqo/apis/net/v1alpha1"
+	"github.com/liqotech/liqo/pkg/liqonet/tunnel"
+	"github.com/liqotech/liqo/pkg/liqonet/utils"
+)
+
+const (
+	// PublicKey is the key of publicKey entry in back-end map and also for the secret containing the ipsec keys.
+	PublicKey = "publicKey"
+	// PrivateKey is the key of private for the secret containing the ipsec keys.
+	PrivateKey = "privateKey"
+	// EndpointIP is the key of the endpointIP entry in back-end map.
+	EndpointIP = "endpointIP"
+	// ListeningPort is the key of the listeningPort entry in the back-end map.
+	ListeningPort = "port"
+	// AllowedIPs is the key of the allowedIPs entry in the back-end map.
+	AllowedIPs = "allowedIPs"
+	// Nonce is the key of the nonce entry in the back-end map.
+	Nonce = "nonce"
+	// NoncePrefix is the prefix of the keys of the secret containing the ipsec keys, under which the local nonce
+	// used with each remote cluster is stored.
+	NoncePrefix = "nonce."
+	// DeviceName name of the xfrm interface created on the custom network namespace.
+	// This interface is used to interconnect the local cluster with the remote ones.
+	DeviceName = "liqo.ipsec"
+	// DriverName name of the driver which is also used as the type of the backend in tunnelendpoint CRD.
+	DriverName = "ipsec"
+	// name of the secret that contains the keys used to derive the ipsec key material.
+	keysName = "ipsec-pubkey"
+	// KeysLabel label for the secret that contains the public key.
+	KeysLabel = "net.liqo.io/key"
+	// defaultPort is the port used to receive the ESP packets encapsulated in UDP (NAT traversal).
+	defaultPort = 4500
+	// MTU size of mtu for the xfrm interface, taking into account the ESP and UDP encapsulation overhead.
+	MTU = 1400
+	// ifID is the identifier binding the security associations and policies to the xfrm interface.
+	ifID = 0x4c51
+	// aeadAlgorithm is the algorithm used to encrypt and authenticate the ESP packets.
+	aeadAlgorithm = "rfc4106(gcm(aes))"
+	// aeadICVLen is the length of the integrity check value of the aeadAlgorithm.
+	aeadICVLen = 128
+	// replayWindow is the size of the anti-replay window of the security associations, which use extended
+	// (64 bits) sequence numbers.
+	replayWindow = 32
+	// rekeyPackets is the number of packets after which the security associations are rekeyed, well before the
+	// sequence number wraps. The security associations are removed by the kernel if not rekeyed within twice as many.
+	rekeyPackets = 1 << 32
+
+	// udpEncap and udpEncapESPinUDP are not exported by golang.org/x/sys/unix (see linux/udp.h).
+	udpEncap         = 100
+	udpEncapESPinUDP = 2
+)
+
+// Registering the driver as available.
+func init() {
+	tunnel.AddDriver(DriverName, NewDriver)
+}
+
+type ipsecConfig struct {
+	// listening port.
+	port int
+	// private key.
+	priKey key
+	// public key.
+	pubKey key
+}
+
+// peer contains the XFRM configuration for a remote cluster, needed to remove or rekey it.
+type peer struct {
+	states   []*netlink.XfrmState
+	policies []*netlink.XfrmPolicy
+
+	remoteKey   key
+	remoteNonce nonce
+	endpoint    *net.UDPAddr
+	allowedIPs  []net.IPNet
+}
+
+// localNonce is the nonce chosen by the local cluster for the connection with a remote one.
+type localNonce struct {
+	value nonce
+	// used is true once the security associations have been configured with the nonce and the remote parameters
+	// below. The same combination cannot be used again, since it would lead to the same SPIs and keys.
+	used        bool
+	remoteKey   key
+	remoteNonce nonce
+}
+
+// IPsec a wrapper for the xfrm interface and the IPsec configuration.
+type IPsec struct {
+	// mutex protects the configuration from the concurrent rekeys triggered by the kernel.
+	mutex       sync.Mutex
+	connections map[string]*netv1alpha1.Connection
+	peers       map[string]*peer
+	nonces      map[string]*localNonce
+	client      k8s.Interface
+	namespace   string
+	// done stops the monitoring of the expirations of the security associations.
+	done chan struct{}
+	// handle is bound to the host network namespace, where the security associations and policies are configured.
+	handle *netlink.Handle
+	// encapConn is the UDP socket used to receive the ESP packets encapsulated in UDP.
+	encapConn *net.UDPConn
+	link      netlink.Link
+	conf      ipsecConfig
+}
+
+// NewDriver creates a new IPsec driver.
+func NewDriver(k8sClient k8s.Interface, namespace string) (tunnel.Driver, error) {
+	var err error
+	i := IPsec{
+		connections: make(map[string]*netv1alpha1.Connection),
+		peers:       make(map[string]*peer),
+		nonces:      make(map[string]*localNonce),
+		client:      k8sClient,
+		namespace:   namespace,
+		conf: ipsecConfig{
+			port: defaultPort,
+		},
+	}
+	if err = i.setKeys(k8sClient, namespace); err != nil {
+		return nil, err
+	}
+	// the handle is bound to the current (i.e. host) network namespace, also when used from the gateway one.
+	if i.handle, err = netlink.NewHandle(unix.NETLINK_XFRM, unix.NETLINK_ROUTE); err != nil {
+		return nil, fmt.Errorf("failed to create netlink handle: %w", err)
+	}
+	if err = i.setXfrmLink(); err != nil {
+		i.handle.Delete()
+		return nil, fmt.Errorf("failed to setup %s link: %w", DriverName, err)
+	}
+	if err = i.setEncapSocket(); err != nil {
+		_ = i.Close()
+		return nil, fmt.Errorf("failed to setup %s encapsulation socket: %w", DriverName, err)
+	}
+	if err = i.monitorExpirations(); err != nil {
+		_ = i.Close()
+		return nil, fmt.Errorf("failed to monitor the expirations of the %s security associations: %w", DriverName, err)
+	}
+	klog.Infof("created %s interface named %s with publicKey %s", DriverName, DeviceName, i.conf.pubKey.String())
+	return &i, nil
+}
+
+// Init initializes the xfrm interface.
+func (i *IPsec) Init() error {
+	// ip link set $DeviceName up.
+	if err := netlink.LinkSetUp(i.link); err != nil {
+		return fmt.Errorf("failed to bring up %s device: %w", DriverName, err)
+	}
+
+	if err := netlink.LinkSetMTU(i.link, MTU); err != nil {
+		return fmt.Errorf("failed to set mtu for interface %s: %w", DeviceName, err)
+	}
+
+	klog.Infof("%s interface named %s, is up on i/f number %d, listening on port :%d, with key %s", DriverName,
+		i.link.Attrs().Name, i.link.Attrs().Index, i.conf.port, i.conf.pubKey)
+	return nil
+}
+
+// ConnectToEndpoint connects to a remote cluster described by the given tep.
+func (i *IPsec) ConnectToEndpoint(tep *netv1alpha1.TunnelEndpoint) (*netv1alpha1.Connection, error) {
+	i.mutex.Lock()
+	defer i.mutex.Unlock()
+
+	// parse allowed IPs.
+	allowedIPs, stringAllowedIPs, err := getAllowedIPs(tep)
+	if err != nil {
+		return newConnectionOnError(err.Error()), err
+	}
+
+	// parse remote public key.
+	remoteKey, err := getKey(tep)
+	if err != nil {
+		return newConnectionOnError(err.Error()), err
+	}
+
+	// parse remote nonce, which is missing until the remote cluster has chosen it.
+	remoteNonce, err := getNonce(tep)
+	if err != nil {
+		return newConnectionOnError(err.Error()), err
+	}
+
+	// parse remote endpoint.
+	endpoint, err := getEndpoint(tep)
+	if err != nil {
+		return newConnectionOnError(err.Error()), err
+	}
+
+	peerConfiguration := map[string]string{ListeningPort: strconv.Itoa(endpoint.Port), EndpointIP: endpoint.IP.String(),
+		AllowedIPs: stringAllowedIPs, PublicKey: remoteKey.String()}
+	if remoteNonce != nil {
+		peerConfiguration[Nonce] = remoteNonce.String()
+	}
+
+	// remove or keep the old configuration for ClusterID.
+	oldCon, found := i.connections[tep.Spec.ClusterID]
+	if found && reflect.DeepEqual(oldCon.PeerConfiguration, peerConfiguration) {
+		return oldCon, nil
+	}
+
+	// the local nonce is published before configuring the security associations, as the remote cluster needs it.
+	local, err := i.ensureLocalNonce(tep.Spec.ClusterID, remoteKey, remoteNonce)
+	if err != nil {
+		return newConnectionOnError(err.Error()), fmt.Errorf("failed to configure peer with clusterid %s: %w", tep.Spec.ClusterID, err)
+	}
+
+	if found {
+		klog.V(4).Infof("updating peer configuration for cluster %s", tep.Spec.ClusterID)
+		if err = i.removePeer(tep.Spec.ClusterID); err != nil {
+			return newConnectionOnError(err.Error()), fmt.Errorf("failed to configure peer with clusterid %s: %w", tep.Spec.ClusterID, err)
+		}
+	} else {
+		klog.V(4).Infof("Connecting cluster %s endpoint %s with publicKey %s",
+			tep.Spec.ClusterID, endpoint.IP.String(), remoteKey)
+	}
+
+	if remoteNonce == nil {
+		c := &netv1alpha1.Connection{
+			Status:            netv1alpha1.Connecting,
+			StatusMessage:     "Waiting for the nonce of the remote cluster",
+			PeerConfiguration: peerConfiguration,
+		}
+		i.connections[tep.Spec.ClusterID] = c
+		return c, nil
+	}
+
+	p, err := i.configurePeer(remoteKey, *remoteNonce, local, endpoint, allowedIPs)
+	if err != nil {
+		delete(i.connections, tep.Spec.ClusterID)
+		return newConnectionOnError(err.Error()), fmt.Errorf("failed to configure peer with clusterid %s: %w", tep.Spec.ClusterID, err)
+	}
+	i.peers[tep.Spec.ClusterID] = p
+
+	c := &netv1alpha1.Connection{
+		Status:            netv1alpha1.Connected,
+		StatusMessage:     "Cluster peer connected",
+		PeerConfiguration: peerConfiguration,
+	}
+	i.connections[tep.Spec.ClusterID] = c
+	klog.V(4).Infof("Done connecting cluster peer %s@%s", tep.Spec.ClusterID, endpoint.String())
+	return c, nil
+}
+
+// DisconnectFromEndpoint disconnects a remote cluster described by the given tep.
+func (i *IPsec) DisconnectFromEndpoint(tep *netv1alpha1.TunnelEndpoint) error {
+	i.mutex.Lock()
+	defer i.mutex.Unlock()
+
+	klog.V(4).Infof("Removing connection with cluster %s", tep.Spec.ClusterID)
+
+	if err := i.removePeer(tep.Spec.ClusterID); err != nil {
+		return fmt.Errorf("failed to remove %s peer with clusterid %s: %w", DriverName, tep.Spec.ClusterID, err)
+	}
+	// the local nonce is discarded, so that a new one is chosen in case the remote cluster is connected again.
+	if err := i.publishNonce(tep.Spec.ClusterID, nil); err != nil {
+		return fmt.Errorf("failed to remove the %s nonce for clusterid %s: %w", DriverName, tep.Spec.ClusterID, err)
+	}
+	delete(i.nonces, tep.Spec.ClusterID)
+
+	klog.V(4).Infof("Done removing %s peer with clusterid %s", DriverName, tep.Spec.ClusterID)
+	delete(i.connections, tep.Spec.ClusterID)
+	return nil
+}
+
+// GetLink returns the netlink.Link referred to the xfrm interface.
+func (i *IPsec) GetLink() netlink.Link {
+	return i.link
+}
+
+// Close removes the xfrm interface and the configured security associations and policies from the host.
+func (i *IPsec) Close() error {
+	i.mutex.Lock()
+	defer i.mutex.Unlock()
+
+	if i.done != nil {
+		close(i.done)
+		i.done = nil
+	}
+	for clusterID := range i.peers {
+		if err := i.removePeer(clusterID); err != nil {
+			return err
+		}
+	}
+	if i.encapConn != nil {
+		if err := i.encapConn.Close(); err != nil {
+			return fmt.Errorf("failed to close the %s encapsulation socket: %w", DriverName, err)
+		}
+		i.encapConn = nil
+	}
+	defer i.handle.Delete()
+	// it removes the xfrm interface.
+	link, err := netlink.LinkByName(DeviceName)
+	if err == nil {
+		if err := netlink.LinkDel(link); err != nil {
+			return fmt.Errorf("failed to delete existing %s device: %w", DriverName, err)
+		}
+		return nil
+	}
+	var notFound netlink.LinkNotFoundError
+	if errors.As(err, &notFound) || errors.Is(err, syscall.ENODEV) {
+		return nil
+	}
+	return fmt.Errorf("failed to delete existing %s device: %w", DriverName, err)
+}
+
+// configurePeer configures the security associations and the policies to reach the given remote endpoint.
+// The local nonce is marked as used with the given remote parameters, hence it is not used again with them.
+func (i *IPsec) configurePeer(remoteKey key, remoteNonce nonce, local *localNonce,
+	endpoint *net.UDPAddr, allowedIPs []net.IPNet) (*peer, error) {
+	localIP, err := i.getLocalIP(endpoint.IP)
+	if err != nil {
+		return nil, err
+	}
+	outKeys, err := deriveSAKeys(i.conf.priKey, remoteKey, i.conf.pubKey, remoteKey, local.value, remoteNonce)
+	if err != nil {
+		return nil, err
+	}
+	inKeys, err := deriveSAKeys(i.conf.priKey, remoteKey, remoteKey, i.conf.pubKey, remoteNonce, local.value)
+	if err != nil {
+		return nil, err
+	}
+	local.used, local.remoteKey, local.remoteNonce = true, remoteKey, remoteNonce
+
+	p := &peer{remoteKey: remoteKey, remoteNonce: remoteNonce, endpoint: endpoint, allowedIPs: allowedIPs}
+	// the configuration already applied is rolled back in case of errors.
+	defer func() {
+		if err != nil {
+			i.cleanPeer(p)
+		}
+	}()
+
+	// the security associations are always new, since their SPIs are derived from a new combination of nonces.
+	out := newState(localIP, endpoint.IP, outKeys, i.conf.port, endpoint.Port)
+	in := newState(endpoint.IP, localIP, inKeys, endpoint.Port, i.conf.port)
+	for _, state := range []*netlink.XfrmState{out, in} {
+		if err = i.handle.XfrmStateAdd(state); err != nil {
+			return nil, fmt.Errorf("failed to configure security association with spi %#x: %w", state.Spi, err)
+		}
+		p.states = append(p.states, state)
+	}
+
+	anyNet := &net.IPNet{IP: net.IPv4zero, Mask: net.CIDRMask(0, 32)}
+	for idx := range allowedIPs {
+		remoteNet := &allowedIPs[idx]
+		policies := []*netlink.XfrmPolicy{
+			newPolicy(anyNet, remoteNet, netlink.XFRM_DIR_OUT, out),
+			newPolicy(remoteNet, anyNet, netlink.XFRM_DIR_IN, in),
+		}
+		for _, policy := range policies {
+			if err = i.handle.XfrmPolicyUpdate(policy); err != nil {
+				return nil, fmt.Errorf("failed to configure policy for %s -> %s: %w", policy.Src, policy.Dst, err)
+			}
+			p.policies = append(p.policies, policy)
+		}
+	}
+	return p, nil
+}
+
+// removePeer removes the security associations and the policies configured for the given cluster.
+func (i *IPsec) removePeer(clusterID string) error {
+	p, found := i.peers[clusterID]
+	if !found {
+		return nil
+	}
+	for _, policy := range p.policies {
+		if err := i.handle.XfrmPolicyDel(policy); err != nil && !errors.Is(err, syscall.ENOENT) {
+			return fmt.Errorf("failed to remove policy for %s -> %s: %w", policy.Src, policy.Dst, err)
+		}
+	}
+	p.policies = nil
+	for _, state := range p.states {
+		if err := i.handle.XfrmStateDel(state); err != nil && !errors.Is(err, syscall.ESRCH) {
+			return fmt.Errorf("failed to remove security association with spi %#x: %w", state.Spi, err)
+		}
+	}
+	delete(i.peers, clusterID)
+	return nil
+}
+
+// ensureLocalNonce returns the nonce to be used with the given remote cluster. A new one is chosen and published
+// if none exists yet, or if the current one has already been used with the same remote key and nonce.
+func (i *IPsec) ensureLocalNonce(clusterID string, remoteKey key, remoteNonce *nonce) (*localNonce, error) {
+	local, found := i.nonces[clusterID]
+	if found && !(local.used && remoteNonce != nil && local.remoteKey == remoteKey && local.remoteNonce == *remoteNonce) {
+		return local, nil
+	}
+	return i.rotateLocalNonce(clusterID)
+}
+
+// rotateLocalNonce chooses and publishes a new nonce to be used with the given remote cluster.
+func (i *IPsec) rotateLocalNonce(clusterID string) (*localNonce, error) {
+	n, err := generateNonce()
+	if err != nil {
+		return nil, err
+	}
+	if err := i.publishNonce(clusterID, &n); err != nil {
+		return nil, fmt.Errorf("failed to publish the %s nonce: %w", DriverName, err)
+	}
+	local := &localNonce{value: n}
+	i.nonces[clusterID] = local
+	klog.V(4).Infof("chosen a new %s nonce for cluster %s", DriverName, clusterID)
+	return local, nil
+}
+
+// monitorExpirations starts monitoring the expirations of the security associations signaled by the kernel,
+// in order to rekey them before they are removed.
+func (i *IPsec) monitorExpirations() error {
+	ch := make(chan netlink.XfrmMsg)
+	errCh := make(chan error, 1)
+	i.done = make(chan struct{})
+	if err := netlink.XfrmMonitor(ch, i.done, errCh, nl.XFRM_MSG_EXPIRE); err != nil {
+		return err
+	}
+	go func() {
+		for {
+			select {
+			case msg, ok := <-ch:
+				if !ok {
+					return
+				}
+				if expire, ok := msg.(*netlink.XfrmMsgExpire); ok {
+					i.handleExpiration(expire)
+				}
+			case err := <-errCh:
+				klog.Errorf("stopped monitoring the expirations of the %s security associations: %v", DriverName, err)
+				return
+			}
+		}
+	}()
+	return nil
+}
+
+// handleExpiration rekeys the peer the expired security association belongs to. The outbound ones are rekeyed as
+// soon as the soft limit is reached, while the inbound ones are rekeyed by the remote cluster, unless removed.
+func (i *IPsec) handleExpiration(expire *netlink.XfrmMsgExpire) {
+	i.mutex.Lock()
+	defer i.mutex.Unlock()
+
+	for clusterID, p := range i.peers {
+		for idx, state := range p.states {
+			if state.Spi != expire.XfrmState.Spi || !state.Dst.Equal(expire.XfrmState.Dst) {
+				continue
+			}
+			// the first state is the outbound one.
+			if idx != 0 && !expire.Hard {
+				return
+			}
+			if err := i.rekeyPeer(clusterID, p); err != nil {
+				klog.Errorf("failed to rekey %s peer with clusterid %s: %v", DriverName, clusterID, err)
+			}
+			return
+		}
+	}
+}
+
+// rekeyPeer configures new security associations with the given peer, using a new local nonce. The remote cluster
+// follows as soon as it receives the new nonce.
+func (i *IPsec) rekeyPeer(clusterID string, p *peer) error {
+	klog.Infof("rekeying %s peer with clusterid %s", DriverName, clusterID)
+	local, err := i.rotateLocalNonce(clusterID)
+	if err != nil {
+		return err
+	}
+	if err := i.removePeer(clusterID); err != nil {
+		return err
+	}
+	newPeer, err := i.configurePeer(p.remoteKey, p.remoteNonce, local, p.endpoint, p.allowedIPs)
+	if err != nil {
+		delete(i.connections, clusterID)
+		return err
+	}
+	i.peers[clusterID] = newPeer
+	return nil
+}
+
+// cleanPeer removes the given partial configuration, logging the errors.
+func (i *IPsec) cleanPeer(p *peer) {
+	for _, policy := range p.policies {
+		if err := i.handle.XfrmPolicyDel(policy); err != nil {
+			klog.Errorf("failed to remove policy for %s -> %s: %v", policy.Src, policy.Dst, err)
+		}
+	}
+	for _, state := range p.states {
+		if err := i.handle.XfrmStateDel(state); err != nil {
+			klog.Errorf("failed to remove security association with spi %#x: %v", state.Spi, err)
+		}
+	}
+}
+
+// getLocalIP returns the IP address used by the host network namespace to reach the given remote IP.
+func (i *IPsec) getLocalIP(remoteIP net.IP) (net.IP, error) {
+	routes, err := i.handle.RouteGet(remoteIP)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get route for remote endpoint %s: %w", remoteIP, err)
+	}
+	for idx := range routes {
+		if routes[idx].Src != nil {
+			return routes[idx].Src, nil
+		}
+	}
+	return nil, fmt.Errorf("no source address found to reach remote endpoint %s", remoteIP)
+}
+
+// Create new xfrm link.
+func (i *IPsec) setXfrmLink() error {
+	// delete existing xfrm device if needed.
+	if link, err := i.handle.LinkByName(DeviceName); err == nil {
+		if err := i.handle.LinkDel(link); err != nil {
+			return fmt.Errorf("failed to delete existing %s device: %w", DriverName, err)
+		}
+	}
+	// the xfrm interface is bound to the interface holding the default route.
+	routes, err := i.handle.RouteList(nil, netlink.FAMILY_V4)
+	if err != nil {
+		return fmt.Errorf("failed to list routes: %w", err)
+	}
+	parentIndex := 0
+	for idx := range routes {
+		if routes[idx].Dst == nil {
+			parentIndex = routes[idx].LinkIndex
+			break
+		}
+	}
+	if parentIndex == 0 {
+		return fmt.Errorf("no default route found")
+	}
+	// create the xfrm device (ip link add $DeviceName type xfrm dev $parent if_id $ifID).
+	la := netlink.NewLinkAttrs()
+	la.Name = DeviceName
+	la.MTU = MTU
+	link := &netlink.Xfrmi{
+		LinkAttrs: la,
+		Ifid:      ifID,
+	}
+	link.ParentIndex = parentIndex
+	if err := i.handle.LinkAdd(link); err != nil {
+		return fmt.Errorf("failed to add %s device '%s': %w", DriverName, DeviceName, err)
+	}
+	i.link = link
+	return nil
+}
+
+// setEncapSocket opens the UDP socket used to receive the ESP packets encapsulated in UDP,
+// which are then decapsulated by the kernel and processed by the matching security association.
+func (i *IPsec) setEncapSocket() error {
+	conn, err := net.ListenUDP("udp4", &net.UDPAddr{Port: i.conf.port})
+	if err != nil {
+		return fmt.Errorf("failed to listen on port %d: %w", i.conf.port, err)
+	}
+	rawConn, err := conn.SyscallConn()
+	if err != nil {
+		_ = conn.Close()
+		return err
+	}
+	var sockErr error
+	err = rawConn.Control(func(fd uintptr) {
+		sockErr = unix.SetsockoptInt(int(fd), unix.IPPROTO_UDP, udpEncap, udpEncapESPinUDP)
+	})
+	if err == nil {
+		err = sockErr
+	}
+	if err != nil {
+		_ = conn.Close()
+		return fmt.Errorf("failed to enable the ESP in UDP encapsulation: %w", err)
+	}
+	i.encapConn = conn
+	return nil
+}
+
+func newState(src, dst net.IP, keys *saKeys, srcPort, dstPort int) *netlink.XfrmState {
+	return &netlink.XfrmState{
+		Src:   src,
+		Dst:   dst,
+		Proto: netlink.XFRM_PROTO_ESP,
+		Mode:  netlink.XFRM_MODE_TUNNEL,
+		Spi:   keys.spi,
+		Reqid: keys.spi,
+		Ifid:  ifID,
+		// extended sequence numbers are required not to wrap the sequence number before rekeying.
+		ESN:          true,
+		ReplayWindow: replayWindow,
+		Limits: netlink.XfrmStateLimits{
+			PacketSoft: rekeyPackets,
+			PacketHard: 2 * rekeyPackets,
+		},
+		Aead: &netlink.XfrmStateAlgo{
+			Name:   aeadAlgorithm,
+			Key:    keys.aead,
+			ICVLen: aeadICVLen,
+		},
+		Encap: &netlink.XfrmStateEncap{
+			Type:    netlink.XFRM_ENCAP_ESPINUDP,
+			SrcPort: srcPort,
+			DstPort: dstPort,
+		},
+	}
+}
+
+func newPolicy(src, dst *net.IPNet, dir netlink.Dir, state *netlink.XfrmState) *netlink.XfrmPolicy {
+	return &netlink.XfrmPolicy{
+		Src:  src,
+		Dst:  dst,
+		Dir:  dir,
+		Ifid: ifID,
+		Tmpls: []netlink.XfrmPolicyTmpl{{
+			Src:   state.Src,
+			Dst:   state.Dst,
+			Proto: state.Proto,
+			Mode:  state.Mode,
+			Reqid: state.Reqid,
+		}},
+	}
+}
+
+// Function that receives a TunnelEndpoint resource and extracts
+// the remote subnets. They are returned as []net.IPNet and
+// as a string (to accommodate comparison/storing on TEP resource).
+func getAllowedIPs(tep *netv1alpha1.TunnelEndpoint) ([]net.IPNet, string, error) {
+	_, remotePodCIDR := utils.GetPodCIDRS(tep)
+	_, remoteExternalCIDR := utils.GetExternalCIDRS(tep)
+
+	_, podCIDR, err := net.ParseCIDR(remotePodCIDR)
+	if err != nil {
+		return nil, "", fmt.Errorf("unable to parse podCIDR %s for cluster %s: %w", remotePodCIDR, tep.Spec.ClusterID, err)
+	}
+	_, externalCIDR, err := net.ParseCIDR(remoteExternalCIDR)
+	if err != nil {
+		return nil, "", fmt.Errorf("unable to parse externalCIDR %s for cluster %s: %w", remoteExternalCIDR, tep.Spec.ClusterID, err)
+	}
+	return []net.IPNet{*podCIDR, *externalCIDR}, fmt.Sprintf("%s,%s", remotePodCIDR, remoteExternalCIDR), nil
+}
+
+func getKey(tep *netv1alpha1.TunnelEndpoint) (key, error) {
+	s, found := tep.Spec.BackendConfig[PublicKey]
+	if !found {
+		return key{}, fmt.Errorf("endpoint is missing public key")
+	}
+
+	k, err := parseKey(s)
+	if err != nil {
+		return key{}, fmt.Errorf("failed to parse public key %s: %w", s, err)
+	}
+	return k, nil
+}
+
+func getNonce(tep *netv1alpha1.TunnelEndpoint) (*nonce, error) {
+	s, found := tep.Spec.BackendConfig[Nonce]
+	if !found {
+		return nil, nil
+	}
+
+	n, err := parseNonce(s)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse nonce %s: %w", s, err)
+	}
+	return &n, nil
+}
+
+// NonceKey returns the key of the secret containing the ipsec keys, under which the local nonce used with the
+// given remote cluster is stored.
+func NonceKey(clusterID string) string {
+	return NoncePrefix + clusterID
+}
+
+func getEndpoint(tep *netv1alpha1.TunnelEndpoint) (*net.UDPAddr, error) {
+	// get port
+	port, found := tep.Spec.BackendConfig[ListeningPort]
+	if !found {
+		return nil, fmt.Errorf("tunnelEndpoint is missing listening port")
+	}
+	// convert port from string to int
+	listeningPort, err := strconv.ParseInt(port, 10, 32)
+	if err != nil {
+		return nil, fmt.Errorf("error while converting port %s to int: %w", port, err)
+	}
+	// get endpoint ip.
+	remoteIP := net.ParseIP(tep.Spec.EndpointIP).To4()
+	if remoteIP == nil {
+		return nil, fmt.Errorf("failed to parse remote IPv4 %s", tep.Spec.EndpointIP)
+	}
+	return &net.UDPAddr{
+		IP:   remoteIP,
+		Port: int(listeningPort),
+	}, nil
+}
+
+func newConnectionOnError(msg string) *netv1alpha1.Connection {
+	return &netv1alpha1.Connection{
+		Status:            netv1alpha1.ConnectionError,
+		StatusMessage:     msg,
+		PeerConfiguration: nil,
+	}
+}
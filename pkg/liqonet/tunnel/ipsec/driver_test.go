@@ -0,0 +1,128 @@
+package ipsec
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+var _ = Describe("Nonce selection", func() {
+	const (
+		namespace = "liqo"
+		clusterID = "remote-cluster-id"
+	)
+
+	var (
+		i           *IPsec
+		remoteKey   key
+		remoteNonce nonce
+	)
+
+	// publishedNonce returns the nonce stored in the secret for the remote cluster, if any.
+	publishedNonce := func() (string, bool) {
+		s, err := i.client.CoreV1().Secrets(namespace).Get(context.TODO(), keysName, metav1.GetOptions{})
+		Expect(err).To(BeNil())
+		value, found := s.Data[NonceKey(clusterID)]
+		return string(value), found
+	}
+
+	BeforeEach(func() {
+		var err error
+		_, remoteKey, err = generateKeys()
+		Expect(err).To(BeNil())
+		remoteNonce, err = generateNonce()
+		Expect(err).To(BeNil())
+		i = &IPsec{
+			nonces:    map[string]*localNonce{},
+			namespace: namespace,
+			client: fake.NewSimpleClientset(&corev1.Secret{
+				ObjectMeta: metav1.ObjectMeta{Name: keysName, Namespace: namespace},
+			}),
+		}
+	})
+
+	It("should choose and publish a nonce if none exists yet", func() {
+		local, err := i.ensureLocalNonce(clusterID, remoteKey, nil)
+		Expect(err).To(BeNil())
+		Expect(local.used).To(BeFalse())
+		Expect(i.nonces).To(HaveKeyWithValue(clusterID, local))
+		value, found := publishedNonce()
+		Expect(found).To(BeTrue())
+		Expect(value).To(Equal(local.value.String()))
+	})
+
+	It("should keep the nonce until it is used", func() {
+		local, err := i.ensureLocalNonce(clusterID, remoteKey, nil)
+		Expect(err).To(BeNil())
+		// the remote nonce arrived in the meanwhile.
+		again, err := i.ensureLocalNonce(clusterID, remoteKey, &remoteNonce)
+		Expect(err).To(BeNil())
+		Expect(again).To(BeIdenticalTo(local))
+	})
+
+	Context("once the nonce has been used", func() {
+		var local *localNonce
+
+		BeforeEach(func() {
+			var err error
+			local, err = i.ensureLocalNonce(clusterID, remoteKey, &remoteNonce)
+			Expect(err).To(BeNil())
+			local.used, local.remoteKey, local.remoteNonce = true, remoteKey, remoteNonce
+		})
+
+		It("should choose a new nonce if the remote parameters did not change", func() {
+			again, err := i.ensureLocalNonce(clusterID, remoteKey, &remoteNonce)
+			Expect(err).To(BeNil())
+			Expect(again).ToNot(BeIdenticalTo(local))
+			Expect(again.value).ToNot(Equal(local.value))
+			Expect(again.used).To(BeFalse())
+			value, _ := publishedNonce()
+			Expect(value).To(Equal(again.value.String()))
+		})
+
+		It("should keep the nonce if the remote cluster chose a new one", func() {
+			newRemoteNonce, err := generateNonce()
+			Expect(err).To(BeNil())
+			again, err := i.ensureLocalNonce(clusterID, remoteKey, &newRemoteNonce)
+			Expect(err).To(BeNil())
+			Expect(again).To(BeIdenticalTo(local))
+		})
+
+		It("should keep the nonce if the remote cluster changed its key", func() {
+			_, newRemoteKey, err := generateKeys()
+			Expect(err).To(BeNil())
+			again, err := i.ensureLocalNonce(clusterID, newRemoteKey, &remoteNonce)
+			Expect(err).To(BeNil())
+			Expect(again).To(BeIdenticalTo(local))
+		})
+
+		It("should keep the nonce while waiting for the remote nonce", func() {
+			again, err := i.ensureLocalNonce(clusterID, remoteKey, nil)
+			Expect(err).To(BeNil())
+			Expect(again).To(BeIdenticalTo(local))
+		})
+	})
+
+	It("should always choose a new nonce when rotating", func() {
+		local, err := i.ensureLocalNonce(clusterID, remoteKey, nil)
+		Expect(err).To(BeNil())
+		rotated, err := i.rotateLocalNonce(clusterID)
+		Expect(err).To(BeNil())
+		Expect(rotated.value).ToNot(Equal(local.value))
+		Expect(i.nonces).To(HaveKeyWithValue(clusterID, rotated))
+		value, _ := publishedNonce()
+		Expect(value).To(Equal(rotated.value.String()))
+	})
+
+	It("should remove the published nonce", func() {
+		_, err := i.ensureLocalNonce(clusterID, remoteKey, nil)
+		Expect(err).To(BeNil())
+		Expect(i.publishNonce(clusterID, nil)).To(Succeed())
+		_, found := publishedNonce()
+		Expect(found).To(BeFalse())
+	})
+})
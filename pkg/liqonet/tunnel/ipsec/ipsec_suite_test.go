@@ -0,0 +1,13 @@
+package ipsec
+
+import (
+	"testing"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+func TestIPsec(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "IPsec Suite")
+}
@@ -0,0 +1,203 @@
+package ipsec
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/binary"
+	"fmt"
+	"io"
+
+	"golang.org/x/crypto/curve25519"
+	"golang.org/x/crypto/hkdf"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	k8s "k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/util/retry"
+)
+
+const (
+	// keyLen is the length of the curve25519 keys.
+	keyLen = curve25519.ScalarSize
+	// aeadKeyLen is the length of the key material of the rfc4106(gcm(aes)) algorithm: 256 bits key plus 32 bits salt.
+	aeadKeyLen = 36
+	// nonceLen is the length of the nonces mixed into the derivation of the key material.
+	nonceLen = 16
+	// spiLen is the length of the SPI of an ESP security association.
+	spiLen = 4
+	// minSPI is the lowest SPI value not reserved by IANA.
+	minSPI = 256
+	// hkdfInfo is the prefix of the info used to derive the key material of each direction.
+	hkdfInfo = "liqo ipsec"
+)
+
+// key is a curve25519 key.
+type key [keyLen]byte
+
+// String returns the base64 encoding of the key.
+func (k key) String() string {
+	return base64.StdEncoding.EncodeToString(k[:])
+}
+
+// parseKey parses a base64 encoded curve25519 key.
+func parseKey(s string) (key, error) {
+	var k key
+	b, err := base64.StdEncoding.DecodeString(s)
+	if err != nil {
+		return k, fmt.Errorf("failed to parse base64-encoded key: %w", err)
+	}
+	if len(b) != keyLen {
+		return k, fmt.Errorf("incorrect key size: %d", len(b))
+	}
+	copy(k[:], b)
+	return k, nil
+}
+
+// nonce is a random value chosen by each peer for a specific connection, and mixed into the derivation of the key
+// material, so that the security associations are never re-created with the same SPI and key.
+type nonce [nonceLen]byte
+
+// String returns the base64 encoding of the nonce.
+func (n nonce) String() string {
+	return base64.StdEncoding.EncodeToString(n[:])
+}
+
+// parseNonce parses a base64 encoded nonce.
+func parseNonce(s string) (nonce, error) {
+	var n nonce
+	b, err := base64.StdEncoding.DecodeString(s)
+	if err != nil {
+		return n, fmt.Errorf("failed to parse base64-encoded nonce: %w", err)
+	}
+	if len(b) != nonceLen {
+		return n, fmt.Errorf("incorrect nonce size: %d", len(b))
+	}
+	copy(n[:], b)
+	return n, nil
+}
+
+// generateNonce generates a new random nonce.
+func generateNonce() (n nonce, err error) {
+	if _, err = rand.Read(n[:]); err != nil {
+		return n, fmt.Errorf("failed to read random bytes: %w", err)
+	}
+	return n, nil
+}
+
+// generateKeys generates a new curve25519 key pair.
+func generateKeys() (priv, pub key, err error) {
+	if _, err = rand.Read(priv[:]); err != nil {
+		return priv, pub, fmt.Errorf("failed to read random bytes: %w", err)
+	}
+	// clamp the private key as specified by RFC 7748.
+	priv[0] &= 248
+	priv[31] = (priv[31] & 127) | 64
+	p, err := curve25519.X25519(priv[:], curve25519.Basepoint)
+	if err != nil {
+		return priv, pub, err
+	}
+	copy(pub[:], p)
+	return priv, pub, nil
+}
+
+// saKeys contains the key material of the security association of a single direction.
+type saKeys struct {
+	spi  int
+	aead []byte
+}
+
+// deriveSAKeys derives the key material of the security association going from the owner of srcPub
+// to the owner of dstPub. The shared secret is computed through X25519 from the local private key and the
+// remote public key, hence both peers obtain the same key material without exchanging any secret. The nonces
+// of the two peers are used as salt, so that both the SPI and the key change whenever either nonce changes.
+func deriveSAKeys(priv, remotePub, srcPub, dstPub key, srcNonce, dstNonce nonce) (*saKeys, error) {
+	shared, err := curve25519.X25519(priv[:], remotePub[:])
+	if err != nil {
+		return nil, fmt.Errorf("failed to compute the shared secret: %w", err)
+	}
+	info := append(append([]byte(hkdfInfo), srcPub[:]...), dstPub[:]...)
+	salt := append(append([]byte{}, srcNonce[:]...), dstNonce[:]...)
+	material := make([]byte, spiLen+aeadKeyLen)
+	if _, err := io.ReadFull(hkdf.New(sha256.New, shared, salt, info), material); err != nil {
+		return nil, fmt.Errorf("failed to derive the key material: %w", err)
+	}
+	spi := int(binary.BigEndian.Uint32(material[:spiLen]) >> 1)
+	if spi < minSPI {
+		spi += minSPI
+	}
+	return &saKeys{spi: spi, aead: material[spiLen:]}, nil
+}
+
+// publishNonce stores the local nonce used with the given remote cluster in the secret containing the keys, from
+// where it is propagated to the remote cluster through the backend configuration. A nil nonce removes the entry.
+func (i *IPsec) publishNonce(clusterID string, n *nonce) error {
+	return retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		s, err := i.client.CoreV1().Secrets(i.namespace).Get(context.Background(), keysName, metav1.GetOptions{})
+		if err != nil {
+			return err
+		}
+		if n == nil {
+			if _, found := s.Data[NonceKey(clusterID)]; !found {
+				return nil
+			}
+			delete(s.Data, NonceKey(clusterID))
+		} else {
+			if s.Data == nil {
+				s.Data = map[string][]byte{}
+			}
+			s.Data[NonceKey(clusterID)] = []byte(n.String())
+		}
+		_, err = i.client.CoreV1().Secrets(i.namespace).Update(context.Background(), s, metav1.UpdateOptions{})
+		return err
+	})
+}
+
+func (i *IPsec) setKeys(c k8s.Interface, namespace string) error {
+	var priv, pub key
+	// first we check if a secret containing valid keys already exists.
+	s, err := c.CoreV1().Secrets(namespace).Get(context.Background(), keysName, metav1.GetOptions{})
+	if err != nil && !apierrors.IsNotFound(err) {
+		return err
+	}
+	// if the secret does not exist then keys are generated and saved into a secret.
+	if apierrors.IsNotFound(err) {
+		if priv, pub, err = generateKeys(); err != nil {
+			return fmt.Errorf("error generating keys for ipsec backend: %w", err)
+		}
+		i.conf.pubKey = pub
+		i.conf.priKey = priv
+		pKey := corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      keysName,
+				Namespace: namespace,
+				Labels:    map[string]string{KeysLabel: DriverName},
+			},
+			StringData: map[string]string{PublicKey: pub.String(), PrivateKey: priv.String()},
+		}
+		_, err = c.CoreV1().Secrets(namespace).Create(context.Background(), &pKey, metav1.CreateOptions{})
+		if err != nil {
+			return fmt.Errorf("failed to create the secret with name %s: %w", keysName, err)
+		}
+		return nil
+	}
+	// get the keys from the existing secret and set them.
+	privKey, found := s.Data[PrivateKey]
+	if !found {
+		return fmt.Errorf("no data with key '%s' found in secret %s", PrivateKey, keysName)
+	}
+	if priv, err = parseKey(string(privKey)); err != nil {
+		return fmt.Errorf("an error occurred while parsing the private key for the ipsec driver: %w", err)
+	}
+	pubKey, found := s.Data[PublicKey]
+	if !found {
+		return fmt.Errorf("no data with key '%s' found in secret %s", PublicKey, keysName)
+	}
+	if pub, err = parseKey(string(pubKey)); err != nil {
+		return fmt.Errorf("an error occurred while parsing the public key for the ipsec driver: %w", err)
+	}
+	i.conf.pubKey = pub
+	i.conf.priKey = priv
+	return nil
+}
@@ -0,0 +1,101 @@
+package ipsec
+
+import (
+	"encoding/base64"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/ginkgo/extensions/table"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Keys", func() {
+	var (
+		privA, pubA, privB, pubB key
+		nonceA, nonceB           nonce
+	)
+
+	BeforeEach(func() {
+		var err error
+		privA, pubA, err = generateKeys()
+		Expect(err).To(BeNil())
+		privB, pubB, err = generateKeys()
+		Expect(err).To(BeNil())
+		nonceA, err = generateNonce()
+		Expect(err).To(BeNil())
+		nonceB, err = generateNonce()
+		Expect(err).To(BeNil())
+	})
+
+	Context("deriving the key material of the security associations", func() {
+		It("should derive the same SPI and key on both peers for each direction", func() {
+			// A -> B, as derived by A (outbound) and by B (inbound).
+			outA, err := deriveSAKeys(privA, pubB, pubA, pubB, nonceA, nonceB)
+			Expect(err).To(BeNil())
+			inB, err := deriveSAKeys(privB, pubA, pubA, pubB, nonceA, nonceB)
+			Expect(err).To(BeNil())
+			Expect(inB).To(Equal(outA))
+
+			// B -> A, as derived by B (outbound) and by A (inbound).
+			outB, err := deriveSAKeys(privB, pubA, pubB, pubA, nonceB, nonceA)
+			Expect(err).To(BeNil())
+			inA, err := deriveSAKeys(privA, pubB, pubB, pubA, nonceB, nonceA)
+			Expect(err).To(BeNil())
+			Expect(inA).To(Equal(outB))
+
+			// the two directions use different key material.
+			Expect(outB.spi).ToNot(Equal(outA.spi))
+			Expect(outB.aead).ToNot(Equal(outA.aead))
+			Expect(outA.spi).To(BeNumerically(">=", minSPI))
+			Expect(outA.aead).To(HaveLen(aeadKeyLen))
+		})
+
+		It("should change both the SPI and the key when either nonce changes", func() {
+			keys, err := deriveSAKeys(privA, pubB, pubA, pubB, nonceA, nonceB)
+			Expect(err).To(BeNil())
+
+			newNonce, err := generateNonce()
+			Expect(err).To(BeNil())
+			for _, nonces := range [][2]nonce{{newNonce, nonceB}, {nonceA, newNonce}} {
+				newKeys, err := deriveSAKeys(privA, pubB, pubA, pubB, nonces[0], nonces[1])
+				Expect(err).To(BeNil())
+				Expect(newKeys.spi).ToNot(Equal(keys.spi))
+				Expect(newKeys.aead).ToNot(Equal(keys.aead))
+			}
+		})
+	})
+
+	Context("parsing the keys and the nonces", func() {
+		encode := func(length int) string {
+			return base64.StdEncoding.EncodeToString(make([]byte, length))
+		}
+
+		It("should parse what it encoded", func() {
+			parsedKey, err := parseKey(pubA.String())
+			Expect(err).To(BeNil())
+			Expect(parsedKey).To(Equal(pubA))
+			parsedNonce, err := parseNonce(nonceA.String())
+			Expect(err).To(BeNil())
+			Expect(parsedNonce).To(Equal(nonceA))
+		})
+
+		DescribeTable("should reject the values with a wrong length or encoding",
+			func(value string) {
+				_, err := parseKey(value)
+				Expect(err).ToNot(BeNil())
+				_, err = parseNonce(value)
+				Expect(err).ToNot(BeNil())
+			},
+			Entry("empty value", ""),
+			Entry("too short value", encode(nonceLen-1)),
+			Entry("too long value", encode(keyLen+1)),
+			Entry("invalid base64", "not base64!"),
+		)
+
+		It("should not accept a key as a nonce and vice versa", func() {
+			_, err := parseNonce(encode(keyLen))
+			Expect(err).ToNot(BeNil())
+			_, err = parseKey(encode(nonceLen))
+			Expect(err).ToNot(BeNil())
+		})
+	})
+})
@@ -1,7 +1,6 @@
 package wireguard
 
 import (
-	"bytes"
 	"context"
 	"errors"
 	"fmt"
@@ -9,6 +8,8 @@ import (
 	"os"
 	"os/exec"
 	"strconv"
+	"strings"
+	"sync"
 	"syscall"
 	"time"
 
@@ -19,8 +20,10 @@ import (
 	corev1 "k8s.io/api/core/v1"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/wait"
 	k8s "k8s.io/client-go/kubernetes"
 	"k8s.io/klog/v2"
+	"k8s.io/kubernetes/pkg/util/slice"
 
 	netv1alpha1 "github.com/liqotech/liqo/apis/net/v1alpha1"
 	"github.com/liqotech/liqo/pkg/liqonet/tunnel"
@@ -32,6 +35,15 @@ const (
 	PublicKey = "publicKey"
 	// PrivateKey is the key of private for the secret containing the wireguard keys.
 	PrivateKey = "privateKey"
+	// NextPublicKey is the key of the nextPublicKey entry in back-end map and also for the secret containing the wireguard keys.
+	// It contains the public key which will replace the current one once the key rotation is completed.
+	NextPublicKey = "nextPublicKey"
+	// NextPrivateKey is the key of the private key which will replace the current one, in the secret containing the wireguard keys.
+	NextPrivateKey = "nextPrivateKey"
+	// RotateKeysAnnotation is the annotation which can be set on the secret containing the wireguard keys to request their rotation.
+	RotateKeysAnnotation = "net.liqo.io/rotate-keys"
+	// KeysRotatedAtAnnotation is the annotation of the secret containing the wireguard keys recording when they have been generated.
+	KeysRotatedAtAnnotation = "net.liqo.io/keys-rotated-at"
 	// EndpointIP is the key of the endpointIP entry in back-end map.
 	EndpointIP = "endpointIP"
 	// ListeningPort is the key of the listeningPort entry in the back-end map.
@@ -43,8 +55,8 @@ const (
 	DeviceName = "liqo.tunnel"
 	// DriverName  name of the driver which is also used as the type of the backend in tunnelendpoint CRD.
 	DriverName = "wireguard"
-	// name of the secret that contains the public key used by wireguard.
-	keysName = "wireguard-pubkey"
+	// KeysName name of the secret that contains the keys used by wireguard.
+	KeysName = "wireguard-pubkey"
 	// KeysLabel label for the secret that contains the public key.
 	KeysLabel   = "net.liqo.io/key"
 	defaultPort = 5871
@@ -52,6 +64,9 @@ const (
 	KeepAliveInterval = 10 * time.Second
 	// MTU size of mtu for wireguard interface.
 	MTU = 1415
+	// switchCheckInterval is the interval used to check whether the remote clusters rotating their keys
+	// have already switched to the next ones.
+	switchCheckInterval = time.Second
 )
 
 // Registering the driver as available.
@@ -74,6 +89,14 @@ type Wireguard struct {
 	client      *wgctrl.Client
 	link        netlink.Link
 	conf        wgConfig
+	// userspace is the process running the userspace implementation, if the kernel module is not available.
+	userspace *exec.Cmd
+	// mutex protects the configuration of the peers from the concurrent switches to the next keys.
+	mutex sync.Mutex
+	// switched contains the clusters which have already switched to the next key they announced.
+	switched map[string]bool
+	// done stops the checks of the switches to the next keys.
+	done chan struct{}
 }
 
 // NewDriver creates a new WireGuard driver.
@@ -81,6 +104,8 @@ func NewDriver(k8sClient k8s.Interface, namespace string) (tunnel.Driver, error)
 	var err error
 	w := Wireguard{
 		connections: make(map[string]*netv1alpha1.Connection),
+		switched:    make(map[string]bool),
+		done:        make(chan struct{}),
 		conf: wgConfig{
 			port: defaultPort,
 		},
@@ -122,6 +147,7 @@ func NewDriver(k8sClient k8s.Interface, namespace string) (tunnel.Driver, error)
 	if err = w.client.ConfigureDevice(DeviceName, cfg); err != nil {
 		return nil, fmt.Errorf("failed to configure WireGuard device: %w", err)
 	}
+	go wait.Until(w.checkSwitches, switchCheckInterval, w.done)
 	klog.Infof("created %s interface named %s with publicKey %s", DriverName, DeviceName, w.conf.pubKey.String())
 	return &w, nil
 }
@@ -144,6 +170,9 @@ func (w *Wireguard) Init() error {
 
 // ConnectToEndpoint connects to a remote cluster described by the given tep.
 func (w *Wireguard) ConnectToEndpoint(tep *netv1alpha1.TunnelEndpoint) (*netv1alpha1.Connection, error) {
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+
 	// parse allowed IPs.
 	allowedIPs, stringAllowedIPs, err := getAllowedIPs(tep)
 	if err != nil {
@@ -162,44 +191,42 @@ func (w *Wireguard) ConnectToEndpoint(tep *netv1alpha1.TunnelEndpoint) (*netv1al
 		return newConnectionOnError(err.Error()), err
 	}
 
+	// parse the next public key announced by the remote cluster during a key rotation, if any.
+	nextKey, err := getNextKey(tep)
+	if err != nil {
+		return newConnectionOnError(err.Error()), err
+	}
+	stringNextKey := ""
+	if nextKey != nil {
+		stringNextKey = nextKey.String()
+	}
+
 	// delete or update old peers for ClusterID.
 	oldCon, found := w.connections[tep.Spec.ClusterID]
 	if found {
 		// check if the peer configuration is updated.
 		if stringAllowedIPs == oldCon.PeerConfiguration[AllowedIPs] && remoteKey.String() == oldCon.PeerConfiguration[PublicKey] &&
-			endpoint.IP.String() == oldCon.PeerConfiguration[EndpointIP] && strconv.Itoa(endpoint.Port) == oldCon.PeerConfiguration[ListeningPort] {
+			endpoint.IP.String() == oldCon.PeerConfiguration[EndpointIP] && strconv.Itoa(endpoint.Port) == oldCon.PeerConfiguration[ListeningPort] &&
+			stringNextKey == oldCon.PeerConfiguration[NextPublicKey] {
 			return oldCon, nil
 		}
 		klog.V(4).Infof("updating peer configuration for cluster %s", tep.Spec.ClusterID)
-		err = w.client.ConfigureDevice(DeviceName, wgtypes.Config{
-			ReplacePeers: false,
-			Peers: []wgtypes.PeerConfig{{PublicKey: *remoteKey,
-				Remove: true,
-			}},
-		})
-		if err != nil {
+		// the peers whose keys are still announced by the remote cluster are updated in place, to preserve their sessions.
+		if err = w.removePeers(oldCon, remoteKey.String(), stringNextKey); err != nil {
 			return newConnectionOnError(err.Error()), fmt.Errorf("failed to configure peer with clusterid %s: %w", tep.Spec.ClusterID, err)
 		}
+		// the switch is preserved only as long as the same next key is announced.
+		if stringNextKey != oldCon.PeerConfiguration[NextPublicKey] || stringNextKey == remoteKey.String() {
+			delete(w.switched, tep.Spec.ClusterID)
+		}
 	} else {
 		klog.V(4).Infof("Connecting cluster %s endpoint %s with publicKey %s",
 			tep.Spec.ClusterID, endpoint.IP.String(), remoteKey)
 	}
 
-	ka := KeepAliveInterval
-	// configure peer.
-	peerCfg := []wgtypes.PeerConfig{{
-		PublicKey:                   *remoteKey,
-		Remove:                      false,
-		UpdateOnly:                  false,
-		Endpoint:                    endpoint,
-		PersistentKeepaliveInterval: &ka,
-		ReplaceAllowedIPs:           true,
-		AllowedIPs:                  allowedIPs,
-	}}
-
 	err = w.client.ConfigureDevice(DeviceName, wgtypes.Config{
 		ReplacePeers: false,
-		Peers:        peerCfg,
+		Peers:        getPeerConfigs(remoteKey, nextKey, endpoint, allowedIPs, w.switched[tep.Spec.ClusterID]),
 	})
 	if err != nil {
 		return newConnectionOnError(err.Error()), fmt.Errorf("failed to configure peer with clusterid %s: %w", tep.Spec.ClusterID, err)
@@ -211,6 +238,9 @@ func (w *Wireguard) ConnectToEndpoint(tep *netv1alpha1.TunnelEndpoint) (*netv1al
 		PeerConfiguration: map[string]string{ListeningPort: strconv.Itoa(endpoint.Port), EndpointIP: endpoint.IP.String(),
 			AllowedIPs: stringAllowedIPs, PublicKey: remoteKey.String()},
 	}
+	if nextKey != nil {
+		c.PeerConfiguration[NextPublicKey] = stringNextKey
+	}
 	w.connections[tep.Spec.ClusterID] = c
 	klog.V(4).Infof("Done connecting cluster peer %s@%s", tep.Spec.ClusterID, endpoint.String())
 	return c, nil
@@ -218,36 +248,169 @@ func (w *Wireguard) ConnectToEndpoint(tep *netv1alpha1.TunnelEndpoint) (*netv1al
 
 // DisconnectFromEndpoint disconnects a remote cluster described by the given tep.
 func (w *Wireguard) DisconnectFromEndpoint(tep *netv1alpha1.TunnelEndpoint) error {
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+
 	klog.V(4).Infof("Removing connection with cluster %s", tep.Spec.ClusterID)
 
-	s, found := tep.Status.Connection.PeerConfiguration[PublicKey]
-	if !found {
+	if _, found := tep.Status.Connection.PeerConfiguration[PublicKey]; !found {
 		klog.V(4).Infof("no tunnel configured for cluster %s, nothing to be removed", tep.Spec.ClusterID)
 		return nil
 	}
 
-	key, err := wgtypes.ParseKey(s)
+	if err := w.removePeers(&tep.Status.Connection); err != nil {
+		return fmt.Errorf("failed to remove WireGuard peer with clusterid %s: %w", tep.Spec.ClusterID, err)
+	}
+
+	klog.V(4).Infof("Done removing WireGuard peer with clusterid %s", tep.Spec.ClusterID)
+	delete(w.connections, tep.Spec.ClusterID)
+	delete(w.switched, tep.Spec.ClusterID)
+
+	return nil
+}
+
+// getPeerConfigs returns the configuration of the peers of a remote cluster. During a key rotation, the peer with
+// the next key is configured without allowed IPs, so that the remote cluster can complete the handshake with it as
+// soon as it switches key, while the traffic keeps flowing through the current one. Once the remote cluster has
+// switched, the allowed IPs are moved to the peer with the next key, which becomes the only one used for the traffic.
+func getPeerConfigs(remoteKey, nextKey *wgtypes.Key, endpoint *net.UDPAddr,
+	allowedIPs []net.IPNet, switched bool) []wgtypes.PeerConfig {
+	ka := KeepAliveInterval
+	current := wgtypes.PeerConfig{
+		PublicKey:                   *remoteKey,
+		Endpoint:                    endpoint,
+		PersistentKeepaliveInterval: &ka,
+		ReplaceAllowedIPs:           true,
+		AllowedIPs:                  allowedIPs,
+	}
+	if nextKey == nil || *nextKey == *remoteKey {
+		return []wgtypes.PeerConfig{current}
+	}
+
+	next := wgtypes.PeerConfig{
+		PublicKey:         *nextKey,
+		Endpoint:          endpoint,
+		ReplaceAllowedIPs: true,
+	}
+	if switched {
+		next.AllowedIPs, next.PersistentKeepaliveInterval = current.AllowedIPs, current.PersistentKeepaliveInterval
+		current.AllowedIPs, current.PersistentKeepaliveInterval = nil, nil
+	}
+	return []wgtypes.PeerConfig{current, next}
+}
+
+// hasSwitched returns true if a handshake has been completed with the peer with the given key, i.e. the remote
+// cluster announcing it as its next key has switched to it.
+func hasSwitched(peers []wgtypes.Peer, nextKey wgtypes.Key) bool {
+	for i := range peers {
+		if peers[i].PublicKey == nextKey {
+			return !peers[i].LastHandshakeTime.IsZero()
+		}
+	}
+	return false
+}
+
+// checkSwitches moves the allowed IPs to the peers with the next keys of the remote clusters which have just
+// switched to them, to restore the traffic without waiting for the rotation to be notified.
+func (w *Wireguard) checkSwitches() {
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+
+	var device *wgtypes.Device
+	for clusterID, con := range w.connections {
+		nextKey, found := con.PeerConfiguration[NextPublicKey]
+		if !found || nextKey == con.PeerConfiguration[PublicKey] || w.switched[clusterID] {
+			continue
+		}
+		if device == nil {
+			var err error
+			if device, err = w.client.Device(DeviceName); err != nil {
+				klog.Errorf("failed to retrieve the configuration of the %s device: %v", DriverName, err)
+				return
+			}
+		}
+		if err := w.switchPeer(clusterID, con, device.Peers); err != nil {
+			klog.Errorf("failed to switch to the next key of the %s peer with clusterid %s: %v", DriverName, clusterID, err)
+		}
+	}
+}
+
+// switchPeer moves the allowed IPs to the peer with the next key of the given connection, if already switched.
+func (w *Wireguard) switchPeer(clusterID string, con *netv1alpha1.Connection, peers []wgtypes.Peer) error {
+	remoteKey, err := wgtypes.ParseKey(con.PeerConfiguration[PublicKey])
 	if err != nil {
-		return fmt.Errorf("failed to parse public key %s: %w", s, err)
+		return err
+	}
+	nextKey, err := wgtypes.ParseKey(con.PeerConfiguration[NextPublicKey])
+	if err != nil {
+		return err
+	}
+	if !hasSwitched(peers, nextKey) {
+		return nil
 	}
 
-	peerCfg := []wgtypes.PeerConfig{
-		{
-			PublicKey: key,
-			Remove:    true,
-		},
+	allowedIPs, err := parseAllowedIPs(con.PeerConfiguration[AllowedIPs])
+	if err != nil {
+		return err
 	}
-	err = w.client.ConfigureDevice(DeviceName, wgtypes.Config{
+	port, err := strconv.Atoi(con.PeerConfiguration[ListeningPort])
+	if err != nil {
+		return err
+	}
+	endpoint := &net.UDPAddr{IP: net.ParseIP(con.PeerConfiguration[EndpointIP]), Port: port}
+	if err := w.client.ConfigureDevice(DeviceName, wgtypes.Config{
+		ReplacePeers: false,
+		Peers:        getPeerConfigs(&remoteKey, &nextKey, endpoint, allowedIPs, true),
+	}); err != nil {
+		return err
+	}
+	w.switched[clusterID] = true
+	klog.Infof("%s peer with clusterid %s switched to the next publicKey %s", DriverName, clusterID, nextKey)
+	return nil
+}
+
+// removePeers removes the peers configured for the given connection, except the ones with the keys to be preserved.
+func (w *Wireguard) removePeers(con *netv1alpha1.Connection, preserve ...string) error {
+	var peerCfg []wgtypes.PeerConfig
+	for _, entry := range []string{PublicKey, NextPublicKey} {
+		s, found := con.PeerConfiguration[entry]
+		if !found || slice.ContainsString(preserve, s, nil) {
+			continue
+		}
+		key, err := wgtypes.ParseKey(s)
+		if err != nil {
+			return fmt.Errorf("failed to parse public key %s: %w", s, err)
+		}
+		peerCfg = append(peerCfg, wgtypes.PeerConfig{PublicKey: key, Remove: true})
+	}
+	if len(peerCfg) == 0 {
+		return nil
+	}
+	return w.client.ConfigureDevice(DeviceName, wgtypes.Config{
 		ReplacePeers: false,
 		Peers:        peerCfg,
 	})
-	if err != nil {
-		return fmt.Errorf("failed to remove WireGuard peer with clusterid %s: %w", tep.Spec.ClusterID, err)
-	}
+}
 
-	klog.V(4).Infof("Done removing WireGuard peer with clusterid %s", tep.Spec.ClusterID)
-	delete(w.connections, tep.Spec.ClusterID)
+// UpdateKeys configures the WireGuard device with the keys contained in the given secret, if they have been rotated.
+// The peers are not affected, and they complete a new handshake as soon as they are aware of the new public key.
+func (w *Wireguard) UpdateKeys(s *corev1.Secret) error {
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
 
+	priv, pub, err := getKeys(s)
+	if err != nil {
+		return err
+	}
+	if priv == w.conf.priKey {
+		return nil
+	}
+	if err := w.client.ConfigureDevice(DeviceName, wgtypes.Config{PrivateKey: &priv}); err != nil {
+		return fmt.Errorf("failed to configure the rotated private key on WireGuard device: %w", err)
+	}
+	w.conf.priKey = priv
+	w.conf.pubKey = pub
+	klog.Infof("%s interface named %s configured with the rotated publicKey %s", DriverName, DeviceName, pub.String())
 	return nil
 }
 
@@ -258,6 +421,18 @@ func (w *Wireguard) GetLink() netlink.Link {
 
 // Close remove the wireguard device from the host.
 func (w *Wireguard) Close() error {
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+
+	select {
+	case <-w.done:
+	default:
+		close(w.done)
+	}
+	// the device created by the userspace implementation is removed when the process terminates.
+	if w.userspace != nil {
+		return w.stopUserspaceDevice()
+	}
 	// it removes the wireguard interface.
 	var err error
 	if link, err := netlink.LinkByName(DeviceName); err == nil {
@@ -297,19 +472,7 @@ func (w *Wireguard) setWGLink() error {
 	}
 	if errors.Is(err, unix.EOPNOTSUPP) {
 		klog.Warningf("wireguard kernel module not present, falling back to the userspace implementation")
-		cmd := exec.Command("/usr/bin/boringtun", DeviceName, "--disable-drop-privileges", "true")
-		var stdout, stderr bytes.Buffer
-		cmd.Stdout = &stdout
-		cmd.Stderr = &stderr
-		err = cmd.Run()
-		if err != nil {
-			outStr, errStr := stdout.String(), stderr.String()
-			fmt.Printf("out:\n%s\nerr:\n%s\n", outStr, errStr)
-			return fmt.Errorf("failed to add wireguard devices '%s': %w", DeviceName, err)
-		}
-		if w.link, err = netlink.LinkByName(DeviceName); err != nil {
-			return fmt.Errorf("failed to get wireguard device '%s': %w", DeviceName, err)
-		}
+		return w.startUserspaceDevice()
 	}
 	w.link = link
 	return nil
@@ -333,6 +496,19 @@ func getAllowedIPs(tep *netv1alpha1.TunnelEndpoint) ([]net.IPNet, string, error)
 	return []net.IPNet{*podCIDR, *externalCIDR}, fmt.Sprintf("%s,%s", remotePodCIDR, remoteExternalCIDR), nil
 }
 
+// parseAllowedIPs is the counterpart of getAllowedIPs, and parses the allowedIPs stored in the peer configuration.
+func parseAllowedIPs(s string) ([]net.IPNet, error) {
+	var allowedIPs []net.IPNet
+	for _, cidr := range strings.Split(s, ",") {
+		_, network, err := net.ParseCIDR(cidr)
+		if err != nil {
+			return nil, fmt.Errorf("unable to parse allowedIPs %s: %w", s, err)
+		}
+		allowedIPs = append(allowedIPs, *network)
+	}
+	return allowedIPs, nil
+}
+
 func getKey(tep *netv1alpha1.TunnelEndpoint) (*wgtypes.Key, error) {
 	s, found := tep.Spec.BackendConfig[PublicKey]
 	if !found {
@@ -347,6 +523,20 @@ func getKey(tep *netv1alpha1.TunnelEndpoint) (*wgtypes.Key, error) {
 	return &key, nil
 }
 
+func getNextKey(tep *netv1alpha1.TunnelEndpoint) (*wgtypes.Key, error) {
+	s, found := tep.Spec.BackendConfig[NextPublicKey]
+	if !found {
+		return nil, nil
+	}
+
+	key, err := wgtypes.ParseKey(s)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse next public key %s: %w", s, err)
+	}
+
+	return &key, nil
+}
+
 func getEndpoint(tep *netv1alpha1.TunnelEndpoint) (*net.UDPAddr, error) {
 	// get port
 	port, found := tep.Spec.BackendConfig[ListeningPort]
@@ -380,7 +570,7 @@ func newConnectionOnError(msg string) *netv1alpha1.Connection {
 func (w *Wireguard) setKeys(c k8s.Interface, namespace string) error {
 	var priv, pub wgtypes.Key
 	// first we check if a secret containing valid keys already exists.
-	s, err := c.CoreV1().Secrets(namespace).Get(context.Background(), keysName, metav1.GetOptions{})
+	s, err := c.CoreV1().Secrets(namespace).Get(context.Background(), KeysName, metav1.GetOptions{})
 	if err != nil && !apierrors.IsNotFound(err) {
 		return err
 	}
@@ -395,42 +585,56 @@ func (w *Wireguard) setKeys(c k8s.Interface, namespace string) error {
 		w.conf.priKey = priv
 		pKey := corev1.Secret{
 			ObjectMeta: metav1.ObjectMeta{
-				Name:      keysName,
+				Name:      KeysName,
 				Namespace: namespace,
 				Labels:    map[string]string{KeysLabel: DriverName},
+				Annotations: map[string]string{
+					KeysRotatedAtAnnotation: time.Now().UTC().Format(time.RFC3339),
+				},
 			},
 			StringData: map[string]string{PublicKey: pub.String(), PrivateKey: priv.String()},
 		}
 		_, err = c.CoreV1().Secrets(namespace).Create(context.Background(), &pKey, metav1.CreateOptions{})
 		if err != nil {
-			return fmt.Errorf("failed to create the secret with name %s: %w", keysName, err)
+			return fmt.Errorf("failed to create the secret with name %s: %w", KeysName, err)
 		}
 		return nil
 	}
 	// get the keys from the existing secret and set them.
+	if priv, pub, err = getKeys(s); err != nil {
+		return err
+	}
+	w.conf.pubKey = pub
+	w.conf.priKey = priv
+	return nil
+}
+
+// getKeys returns the current keys contained in the given secret.
+func getKeys(s *corev1.Secret) (priv, pub wgtypes.Key, err error) {
 	privKey, found := s.Data[PrivateKey]
 	if !found {
-		return fmt.Errorf("no data with key '%s' found in secret %s", PrivateKey, keysName)
+		return priv, pub, fmt.Errorf("no data with key '%s' found in secret %s", PrivateKey, s.GetName())
 	}
 	priv, err = wgtypes.ParseKey(string(privKey))
 	if err != nil {
-		return fmt.Errorf("an error occurred while parsing the private key for the wireguard driver :%w", err)
+		return priv, pub, fmt.Errorf("an error occurred while parsing the private key for the wireguard driver :%w", err)
 	}
 	pubKey, found := s.Data[PublicKey]
 	if !found {
-		return fmt.Errorf("no data with key '%s' found in secret %s", PublicKey, keysName)
+		return priv, pub, fmt.Errorf("no data with key '%s' found in secret %s", PublicKey, s.GetName())
 	}
 	pub, err = wgtypes.ParseKey(string(pubKey))
 	if err != nil {
-		return fmt.Errorf("an error occurred while parsing the public key for the wireguard driver :%w", err)
+		return priv, pub, fmt.Errorf("an error occurred while parsing the public key for the wireguard driver :%w", err)
 	}
-	w.conf.pubKey = pub
-	w.conf.priKey = priv
-	return nil
+	return priv, pub, nil
 }
 
 // SetNewClient set a new client used to interact with the wireguard device.
 func (w *Wireguard) SetNewClient() error {
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+
 	c, err := wgctrl.New()
 	if err != nil {
 		if os.IsNotExist(err) {
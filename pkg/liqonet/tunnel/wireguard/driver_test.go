@@ -0,0 +1,72 @@
+package wireguard
+
+import (
+	"net"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"golang.zx2c4.com/wireguard/wgctrl/wgtypes"
+)
+
+var _ = Describe("Key rotation", func() {
+	var (
+		remoteKey, nextKey wgtypes.Key
+		endpoint           *net.UDPAddr
+		allowedIPs         []net.IPNet
+	)
+
+	BeforeEach(func() {
+		priv, err := wgtypes.GeneratePrivateKey()
+		Expect(err).To(BeNil())
+		remoteKey = priv.PublicKey()
+		priv, err = wgtypes.GeneratePrivateKey()
+		Expect(err).To(BeNil())
+		nextKey = priv.PublicKey()
+		endpoint = &net.UDPAddr{IP: net.ParseIP("172.16.0.1"), Port: defaultPort}
+		allowedIPs, err = parseAllowedIPs("10.0.0.0/16,10.1.0.0/16")
+		Expect(err).To(BeNil())
+	})
+
+	Context("if no key rotation is in progress", func() {
+		It("should configure only the current peer with the allowed IPs", func() {
+			peers := getPeerConfigs(&remoteKey, nil, endpoint, allowedIPs, false)
+			Expect(peers).To(HaveLen(1))
+			Expect(peers[0].PublicKey).To(Equal(remoteKey))
+			Expect(peers[0].AllowedIPs).To(Equal(allowedIPs))
+		})
+	})
+
+	Context("if the remote cluster announced its next key", func() {
+		It("should keep the allowed IPs on the current peer until the remote cluster switches", func() {
+			peers := getPeerConfigs(&remoteKey, &nextKey, endpoint, allowedIPs, false)
+			Expect(peers).To(HaveLen(2))
+			Expect(peers[0].PublicKey).To(Equal(remoteKey))
+			Expect(peers[0].AllowedIPs).To(Equal(allowedIPs))
+			Expect(peers[1].PublicKey).To(Equal(nextKey))
+			Expect(peers[1].ReplaceAllowedIPs).To(BeTrue())
+			Expect(peers[1].AllowedIPs).To(BeEmpty())
+		})
+
+		It("should move the allowed IPs to the next peer once the remote cluster switched", func() {
+			peers := getPeerConfigs(&remoteKey, &nextKey, endpoint, allowedIPs, true)
+			Expect(peers).To(HaveLen(2))
+			Expect(peers[0].PublicKey).To(Equal(remoteKey))
+			Expect(peers[0].ReplaceAllowedIPs).To(BeTrue())
+			Expect(peers[0].AllowedIPs).To(BeEmpty())
+			Expect(peers[1].PublicKey).To(Equal(nextKey))
+			Expect(peers[1].ReplaceAllowedIPs).To(BeTrue())
+			Expect(peers[1].AllowedIPs).To(Equal(allowedIPs))
+			Expect(peers[1].Endpoint).To(Equal(endpoint))
+			Expect(peers[1].PersistentKeepaliveInterval).NotTo(BeNil())
+		})
+
+		It("should detect the switch from the handshake completed with the next peer", func() {
+			peers := []wgtypes.Peer{{PublicKey: remoteKey, LastHandshakeTime: time.Now()}, {PublicKey: nextKey}}
+			Expect(hasSwitched(peers, nextKey)).To(BeFalse())
+			peers[1].LastHandshakeTime = time.Now()
+			Expect(hasSwitched(peers, nextKey)).To(BeTrue())
+			Expect(hasSwitched(peers[:1], nextKey)).To(BeFalse())
+		})
+	})
+})
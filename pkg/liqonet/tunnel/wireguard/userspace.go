@@ -0,0 +1,118 @@
+package wireguard
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"syscall"
+	"time"
+
+	"github.com/vishvananda/netlink"
+	"golang.zx2c4.com/wireguard/device"
+	"golang.zx2c4.com/wireguard/ipc"
+	"golang.zx2c4.com/wireguard/tun"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/klog/v2"
+
+	liqoconst "github.com/liqotech/liqo/pkg/consts"
+)
+
+const (
+	// userspaceStartTimeout is the maximum time waited for the userspace implementation to create the device.
+	userspaceStartTimeout = 10 * time.Second
+	// userspaceStopTimeout is the maximum time waited for the userspace implementation to terminate.
+	userspaceStopTimeout = 5 * time.Second
+)
+
+// RunUserspaceDevice creates a TUN device with the given name and runs the userspace WireGuard implementation
+// (wireguard-go) on top of it, exposing the configuration socket used by wgctrl. It blocks until the stop channel
+// is closed or the device is closed.
+func RunUserspaceDevice(name string, mtu int, stop <-chan struct{}) error {
+	tunDevice, err := tun.CreateTUN(name, mtu)
+	if err != nil {
+		return fmt.Errorf("failed to create TUN device %s: %w", name, err)
+	}
+
+	wgDevice := device.NewDevice(tunDevice, device.NewLogger(device.LogLevelError, fmt.Sprintf("(%s) ", name)))
+	defer wgDevice.Close()
+
+	uapiFile, err := ipc.UAPIOpen(name)
+	if err != nil {
+		return fmt.Errorf("failed to open the configuration socket of device %s: %w", name, err)
+	}
+	uapi, err := ipc.UAPIListen(name, uapiFile)
+	if err != nil {
+		return fmt.Errorf("failed to listen on the configuration socket of device %s: %w", name, err)
+	}
+	defer uapi.Close()
+
+	go func() {
+		for {
+			conn, err := uapi.Accept()
+			if err != nil {
+				return
+			}
+			go wgDevice.IpcHandle(conn)
+		}
+	}()
+
+	klog.Infof("userspace %s device %s is running", DriverName, name)
+	select {
+	case <-stop:
+	case <-wgDevice.Wait():
+	}
+	klog.Infof("userspace %s device %s has been stopped", DriverName, name)
+	return nil
+}
+
+// startUserspaceDevice spawns a new process running the userspace WireGuard implementation, and waits for
+// the device to be created. A separate process is used to guarantee that the UDP sockets are always opened in
+// the current network namespace, also after the device is moved to the gateway network namespace.
+func (w *Wireguard) startUserspaceDevice() error {
+	executable, err := os.Executable()
+	if err != nil {
+		return fmt.Errorf("failed to retrieve the current executable: %w", err)
+	}
+	cmd := exec.Command(executable, "-run-as="+liqoconst.LiqoWireguardUserspaceName)
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	if err = cmd.Start(); err != nil {
+		return fmt.Errorf("failed to start the userspace %s implementation: %w", DriverName, err)
+	}
+	w.userspace = cmd
+
+	err = wait.PollImmediate(100*time.Millisecond, userspaceStartTimeout, func() (bool, error) {
+		w.link, err = netlink.LinkByName(DeviceName)
+		return err == nil, nil
+	})
+	if err != nil {
+		_ = w.stopUserspaceDevice()
+		return fmt.Errorf("failed to get wireguard device '%s': %w", DeviceName, err)
+	}
+	return nil
+}
+
+// stopUserspaceDevice terminates the process running the userspace WireGuard implementation, if any.
+func (w *Wireguard) stopUserspaceDevice() error {
+	if w.userspace == nil {
+		return nil
+	}
+	cmd := w.userspace
+	w.userspace = nil
+
+	if err := cmd.Process.Signal(syscall.SIGTERM); err != nil {
+		return fmt.Errorf("failed to stop the userspace %s implementation: %w", DriverName, err)
+	}
+	done := make(chan struct{})
+	go func() {
+		_ = cmd.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(userspaceStopTimeout):
+		klog.Warningf("userspace %s implementation not terminated after %v, killing it", DriverName, userspaceStopTimeout)
+		return cmd.Process.Kill()
+	}
+	return nil
+}
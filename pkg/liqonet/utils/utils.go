@@ -31,6 +31,7 @@ var (
 )
 
 // MapIPToNetwork creates a new IP address obtained by means of the old IP address and the new network.
+// Both IPv4 and IPv6 are supported, as long as the IP address and the network belong to the same family.
 func MapIPToNetwork(newNetwork, oldIP string) (newIP string, err error) {
 	if newNetwork == consts.DefaultCIDRValue {
 		return oldIP, nil
@@ -42,16 +43,19 @@ func MapIPToNetwork(newNetwork, oldIP string) (newIP string, err error) {
 	}
 	// Get mask
 	mask := network.Mask
-	// Get slice of bytes for newNetwork
-	// Type net.IP has underlying type []byte
-	parsedNewIP := ip.To4()
 	// Get oldIP as slice of bytes
 	parsedOldIP := net.ParseIP(oldIP)
 	if parsedOldIP == nil {
 		return "", fmt.Errorf("cannot parse oldIP")
 	}
-	parsedOldIP = parsedOldIP.To4()
-	// Substitute the last 32-mask bits of newNetwork with bits taken by the old ip
+	// Get slices of bytes for newNetwork and oldIP, with the same length of the mask.
+	// Type net.IP has underlying type []byte
+	parsedNewIP := toFamilyLen(ip, len(mask))
+	parsedOldIP = toFamilyLen(parsedOldIP, len(mask))
+	if parsedOldIP == nil {
+		return "", fmt.Errorf("oldIP %s and network %s belong to different IP families", oldIP, newNetwork)
+	}
+	// Substitute the last len(mask)*8-mask bits of newNetwork with bits taken by the old ip
 	for i := 0; i < len(mask); i++ {
 		// Step 1: NOT(mask[i]) = mask[i] ^ 0xff. They are the 'host' bits
 		// Step 2: BITWISE AND between the host bits and parsedOldIP[i] zeroes the network bits in parsedOldIP[i]
@@ -116,6 +120,24 @@ func GetClusterID(client kubernetes.Interface, cmName, namespace string, backoff
 	return clusterID, nil
 }
 
+// toFamilyLen returns the representation of the given IP address with the given length in bytes,
+// or nil if the address does not belong to the corresponding family.
+func toFamilyLen(ip net.IP, length int) net.IP {
+	if length == net.IPv4len {
+		return ip.To4()
+	}
+	if ip.To4() != nil {
+		return nil
+	}
+	return ip.To16()
+}
+
+// IsIPv6CIDR returns true if the given network is an IPv6 one.
+func IsIPv6CIDR(network string) bool {
+	ip, _, err := net.ParseCIDR(network)
+	return err == nil && ip.To4() == nil
+}
+
 // GetMask retrieves the mask from a CIDR.
 func GetMask(network string) uint8 {
 	_, net, _ := net.ParseCIDR(network)
@@ -129,7 +151,8 @@ func SetMask(network string, mask uint8) (string, error) {
 	if err != nil {
 		return "", err
 	}
-	newMask := net.CIDRMask(int(mask), 32)
+	_, bits := n.Mask.Size()
+	newMask := net.CIDRMask(int(mask), bits)
 	n.Mask = newMask
 	return n.String(), nil
 }
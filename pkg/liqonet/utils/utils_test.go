@@ -61,6 +61,22 @@ var _ = Describe("Liqonet", func() {
 		Entry("Mapping 10.2.128.128 to 10.0.126.0/25", "10.0.126.0/25", "10.2.128.128", "10.0.126.0", ""),
 		Entry("Using an invalid newPodCidr", "10.0..0/25", "10.2.128.128", "", "invalid CIDR address: 10.0..0/25"),
 		Entry("Using an invalid oldIp", "10.0.0.0/25", "10.2...128", "", "cannot parse oldIP"),
+		Entry("Mapping fd00:2::1:3 to fd00:1::/64", "fd00:1::/64", "fd00:2::1:3", "fd00:1::1:3", ""),
+		Entry("Mapping fd00:2::ffff:3 to fd00:1:0:0:8000::/65", "fd00:1:0:0:8000::/65", "fd00:2::ffff:3", "fd00:1::8000:0:ffff:3", ""),
+		Entry("Mapping an IPv4 address to an IPv6 network", "fd00:1::/64", "10.2.1.3", "",
+			"oldIP 10.2.1.3 and network fd00:1::/64 belong to different IP families"),
+		Entry("Mapping an IPv6 address to an IPv4 network", "10.0.4.0/24", "fd00:2::1:3", "",
+			"oldIP fd00:2::1:3 and network 10.0.4.0/24 belong to different IP families"),
+	)
+
+	DescribeTable("SetMask",
+		func(network string, mask uint8, expectedNetwork string) {
+			n, err := utils.SetMask(network, mask)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(n).To(Equal(expectedNetwork))
+		},
+		Entry("Setting mask 9 on 10.0.0.0/8", "10.0.0.0/8", uint8(9), "10.0.0.0/9"),
+		Entry("Setting mask 9 on fd00::/8", "fd00::/8", uint8(9), "fd00::/9"),
 	)
 
 	DescribeTable("GetFirstIP",
@@ -8,6 +8,7 @@ import (
 
 	offv1alpha1 "github.com/liqotech/liqo/apis/offloading/v1alpha1"
 	liqoconst "github.com/liqotech/liqo/pkg/consts"
+	liqoutils "github.com/liqotech/liqo/pkg/utils"
 )
 
 // getVirtualNodeToleration returns a new Toleration for the Liqo's virtual-nodes.
@@ -115,15 +116,49 @@ func fillPodWithTheNewNodeSelector(imposedNodeSelector *corev1.NodeSelector, pod
 	}
 }
 
+// addReflectionReadinessGate adds to the pod the readiness gate satisfied by the virtual kubelet once the reflection
+// of the pod namespace in the remote cluster is ready, if not already present. It is not added to the pods which may
+// be scheduled on the local nodes, since no one would set the corresponding condition.
+func addReflectionReadinessGate(pod *corev1.Pod) {
+	for i := range pod.Spec.ReadinessGates {
+		if pod.Spec.ReadinessGates[i].ConditionType == liqoconst.ReflectionReadyConditionType {
+			return
+		}
+	}
+	pod.Spec.ReadinessGates = append(pod.Spec.ReadinessGates,
+		corev1.PodReadinessGate{ConditionType: liqoconst.ReflectionReadyConditionType})
+}
+
 // mutatePod checks the NamespaceOffloading CR associated with the Pod's Namespace.
 // The pod is modified in different ways according to the PodOffloadingStrategyType
 // chosen in the CR. Two possible modifications:
 // - The VirtualNodeToleration is added to the Pod Toleration if necessary.
 // - The old Pod NodeSelector is substituted with a new one according to the PodOffloadingStrategyType.
+// The pods forced to be offloaded also get a readiness gate, satisfied once the reflection of the namespace is ready.
+// Additionally, the placement preferences are translated into preferred node affinities and topology spread
+// constraints, and the pod is annotated to enable the cost-aware scheduling, if requested.
+// The fields of the most specific offloading profile matching the pod labels, if any, override the top-level ones.
 func mutatePod(namespaceOffloading *offv1alpha1.NamespaceOffloading, pod *corev1.Pod) error {
+	profile, err := liqoutils.SelectOffloadingProfile(namespaceOffloading, pod)
+	if err != nil {
+		klog.Errorf("%s --> The NamespaceOffloading in namespace '%s' has an invalid offloading profile",
+			err, namespaceOffloading.Namespace)
+		return err
+	}
+	profileName := offv1alpha1.DefaultOffloadingProfileName
+	if profile != nil {
+		profileName = profile.Name
+	}
+	namespaceOffloading = liqoutils.EffectiveNamespaceOffloading(namespaceOffloading, profile)
+
+	if pod.Annotations == nil {
+		pod.Annotations = map[string]string{}
+	}
+	pod.Annotations[liqoconst.OffloadingProfileAnnotation] = profileName
+
 	// The NamespaceOffloading CR contains information about the PodOffloadingStrategy and
 	// the NodeSelector inserted by the user (ClusterSelector field).
-	klog.V(5).Infof("Chosen strategy: %s", namespaceOffloading.Spec.PodOffloadingStrategy)
+	klog.V(5).Infof("Chosen profile: %s, strategy: %s", profileName, namespaceOffloading.Spec.PodOffloadingStrategy)
 
 	// If strategy is equal to LocalPodOffloadingStrategy there is nothing to do
 	if namespaceOffloading.Spec.PodOffloadingStrategy == offv1alpha1.LocalPodOffloadingStrategyType {
@@ -154,5 +189,18 @@ func mutatePod(namespaceOffloading *offv1alpha1.NamespaceOffloading, pod *corev1
 	// Enforce the new NodeSelector policy imposed by the NamespaceOffloading creator.
 	fillPodWithTheNewNodeSelector(&imposedNodeSelector, pod)
 	klog.V(5).Infof("Pod NodeSelector: %s", imposedNodeSelector)
+
+	// Add the soft constraints expressing the placement preferences.
+	fillPodWithPlacementPreferences(namespaceOffloading.Spec.Placement, pod)
+
+	// The pods forced to be offloaded become ready only once the reflection of their namespace is complete.
+	if namespaceOffloading.Spec.PodOffloadingStrategy == offv1alpha1.RemotePodOffloadingStrategyType {
+		addReflectionReadinessGate(pod)
+	}
+
+	// Signal to the scheduler extender that the cheapest nodes have to be preferred.
+	if namespaceOffloading.Spec.CostAwareScheduling {
+		pod.Annotations[liqoconst.CostAwareSchedulingAnnotation] = "true"
+	}
 	return nil
 }
@@ -13,11 +13,17 @@ import (
 
 	offv1alpha1 "github.com/liqotech/liqo/apis/offloading/v1alpha1"
 	liqoconst "github.com/liqotech/liqo/pkg/consts"
+	liqoutils "github.com/liqotech/liqo/pkg/utils"
 )
 
 // cluster-role
 // +kubebuilder:rbac:groups=admissionregistration.k8s.io,resources=mutatingwebhookconfigurations,verbs=get;list;update;patch
+// +kubebuilder:rbac:groups=admissionregistration.k8s.io,resources=validatingwebhookconfigurations,verbs=get;list;update;patch
 // +kubebuilder:rbac:groups=offloading.liqo.io,resources=namespaceoffloadings,verbs=get;list;watch
+// +kubebuilder:rbac:groups=offloading.liqo.io,resources=clusteroffloadingpolicies,verbs=get;list;watch
+// +kubebuilder:rbac:groups=core,resources=namespaces,verbs=get;list;watch
+// +kubebuilder:rbac:groups=config.liqo.io,resources=clusterconfigs,verbs=get;list;watch
+// +kubebuilder:rbac:groups=authorization.k8s.io,resources=subjectaccessreviews,verbs=create
 //role
 // +kubebuilder:rbac:groups=core,namespace="do-not-care",resources=secrets,verbs=create;get;list;watch
 
@@ -74,6 +80,17 @@ func (s *MutationServer) Mutate(body []byte) ([]byte, error) {
 	}
 
 	klog.V(5).Infof("The namespace '%s' has a NamespaceOffloading resource", pod.Namespace)
+
+	// Prevent the pod from being scheduled on the clusters the namespace is not allowed to be offloaded to.
+	allowed, restricted, err := liqoutils.GetAllowedClusters(s.ctx, s.webhookClient, admissionReviewRequest.Namespace)
+	if err != nil {
+		return nil, fmt.Errorf("%w -> unable to get the clusters allowed for the Namespace: %s",
+			err, admissionReviewRequest.Namespace)
+	}
+	if restricted {
+		namespaceOffloading = restrictToAllowedClusters(namespaceOffloading, allowed)
+	}
+
 	if err = mutatePod(namespaceOffloading, pod); err != nil {
 		return nil, err
 	}
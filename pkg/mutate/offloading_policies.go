@@ -0,0 +1,52 @@
+package mutate
+
+import (
+	corev1 "k8s.io/api/core/v1"
+
+	offv1alpha1 "github.com/liqotech/liqo/apis/offloading/v1alpha1"
+	liqoutils "github.com/liqotech/liqo/pkg/utils"
+	"github.com/liqotech/liqo/pkg/virtualKubelet"
+)
+
+// restrictToAllowedClusters returns a copy of the NamespaceOffloading whose ClusterSelectors (both the top-level and
+// the offloading profile ones) select only the virtual nodes of the clusters allowed by the ClusterOffloadingPolicies.
+// The local nodes are still selected by the additional term added in case of LocalAndRemote strategy.
+func restrictToAllowedClusters(noff *offv1alpha1.NamespaceOffloading,
+	allowed map[string]struct{}) *offv1alpha1.NamespaceOffloading {
+	restricted := noff.DeepCopy()
+	requirement := allowedClustersRequirement(allowed)
+	// An empty top-level ClusterSelector selects all the clusters, hence it is replaced by a term selecting the
+	// allowed ones only, instead of being left unrestricted.
+	if len(restricted.Spec.ClusterSelector.NodeSelectorTerms) == 0 {
+		restricted.Spec.ClusterSelector.NodeSelectorTerms = []corev1.NodeSelectorTerm{{}}
+	}
+	restrictClusterSelector(&restricted.Spec.ClusterSelector, requirement)
+	// The profiles without ClusterSelector inherit the top-level one, which is already restricted.
+	for i := range restricted.Spec.Profiles {
+		restrictClusterSelector(&restricted.Spec.Profiles[i].ClusterSelector, requirement)
+	}
+	return restricted
+}
+
+// allowedClustersRequirement returns the requirement selecting the virtual nodes of the given clusters, whose names
+// are derived from the cluster IDs. If no cluster is allowed, the returned requirement is never satisfied by
+// virtual nodes, since they are always labeled with their hostname.
+func allowedClustersRequirement(allowed map[string]struct{}) corev1.NodeSelectorRequirement {
+	if len(allowed) == 0 {
+		return corev1.NodeSelectorRequirement{Key: corev1.LabelHostname, Operator: corev1.NodeSelectorOpDoesNotExist}
+	}
+
+	nodeNames := liqoutils.SortedClusterIDs(allowed)
+	for i := range nodeNames {
+		nodeNames[i] = virtualKubelet.VirtualNodePrefix + nodeNames[i]
+	}
+	return corev1.NodeSelectorRequirement{Key: corev1.LabelHostname, Operator: corev1.NodeSelectorOpIn, Values: nodeNames}
+}
+
+// restrictClusterSelector adds the given requirement to every term of the ClusterSelector.
+func restrictClusterSelector(selector *corev1.NodeSelector, requirement corev1.NodeSelectorRequirement) {
+	for i := range selector.NodeSelectorTerms {
+		selector.NodeSelectorTerms[i].MatchExpressions = append(selector.NodeSelectorTerms[i].MatchExpressions,
+			*requirement.DeepCopy())
+	}
+}
@@ -0,0 +1,114 @@
+package mutate
+
+import (
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/klog/v2"
+
+	offv1alpha1 "github.com/liqotech/liqo/apis/offloading/v1alpha1"
+	liqoconst "github.com/liqotech/liqo/pkg/consts"
+)
+
+// preferLocalWeight is the weight of the preferred affinity term favoring the local nodes.
+const preferLocalWeight = 100
+
+// clusterTopologyKey is the topology key used to spread the replicas among clusters. Its value is the ID of the
+// remote cluster on each virtual node, and the ID of the local cluster on all the local nodes.
+const clusterTopologyKey = liqoconst.ClusterIDNodeLabel
+
+// createPreferredSchedulingTerms creates the preferred node affinity terms enforcing the placement preferences.
+func createPreferredSchedulingTerms(placement *offv1alpha1.PlacementPreferences) []corev1.PreferredSchedulingTerm {
+	terms := make([]corev1.PreferredSchedulingTerm, 0, len(placement.PreferredClusters)+1)
+	for i := range placement.PreferredClusters {
+		// The terms are restricted to the virtual nodes, since they refer to the remote clusters.
+		term := *placement.PreferredClusters[i].DeepCopy()
+		term.Preference.MatchExpressions = append(term.Preference.MatchExpressions, corev1.NodeSelectorRequirement{
+			Key:      liqoconst.TypeLabel,
+			Operator: corev1.NodeSelectorOpIn,
+			Values:   []string{liqoconst.TypeNode},
+		})
+		terms = append(terms, term)
+	}
+
+	if placement.PreferLocal {
+		terms = append(terms, corev1.PreferredSchedulingTerm{
+			Weight: preferLocalWeight,
+			Preference: corev1.NodeSelectorTerm{
+				MatchExpressions: []corev1.NodeSelectorRequirement{{
+					Key:      liqoconst.TypeLabel,
+					Operator: corev1.NodeSelectorOpNotIn,
+					Values:   []string{liqoconst.TypeNode},
+				}},
+			},
+		})
+	}
+	return terms
+}
+
+// createClusterSpreadConstraint creates the topology spread constraint bounding the share of replicas scheduled on
+// each cluster. The replicas of the same workload are identified by the labels of the pod, hence nil is returned
+// in case the pod has no labels.
+func createClusterSpreadConstraint(placement *offv1alpha1.PlacementPreferences,
+	pod *corev1.Pod) *corev1.TopologySpreadConstraint {
+	if placement.MaxClusterSkew == nil || len(pod.Labels) == 0 {
+		return nil
+	}
+
+	whenUnsatisfiable := placement.WhenUnsatisfiable
+	if whenUnsatisfiable == "" {
+		whenUnsatisfiable = corev1.ScheduleAnyway
+	}
+
+	podLabels := make(map[string]string, len(pod.Labels))
+	for key, value := range pod.Labels {
+		// The label identifying the single StatefulSet replica would prevent matching the other ones.
+		if key != appsv1.StatefulSetPodNameLabel {
+			podLabels[key] = value
+		}
+	}
+
+	return &corev1.TopologySpreadConstraint{
+		MaxSkew:           *placement.MaxClusterSkew,
+		TopologyKey:       clusterTopologyKey,
+		WhenUnsatisfiable: whenUnsatisfiable,
+		LabelSelector:     &metav1.LabelSelector{MatchLabels: podLabels},
+	}
+}
+
+// fillPodWithPlacementPreferences adds to the pod the preferred node affinity terms and the topology spread
+// constraint enforcing the placement preferences, preserving the ones already specified by the user.
+func fillPodWithPlacementPreferences(placement *offv1alpha1.PlacementPreferences, pod *corev1.Pod) {
+	if placement == nil {
+		return
+	}
+
+	if terms := createPreferredSchedulingTerms(placement); len(terms) > 0 {
+		if pod.Spec.Affinity == nil {
+			pod.Spec.Affinity = &corev1.Affinity{}
+		}
+		if pod.Spec.Affinity.NodeAffinity == nil {
+			pod.Spec.Affinity.NodeAffinity = &corev1.NodeAffinity{}
+		}
+		pod.Spec.Affinity.NodeAffinity.PreferredDuringSchedulingIgnoredDuringExecution = append(
+			pod.Spec.Affinity.NodeAffinity.PreferredDuringSchedulingIgnoredDuringExecution, terms...)
+	}
+
+	constraint := createClusterSpreadConstraint(placement, pod)
+	if constraint == nil {
+		if placement.MaxClusterSkew != nil {
+			klog.Warningf("Unable to bound the replicas per cluster of the pod '%s/%s', since it has no labels",
+				pod.Namespace, pod.Name)
+		}
+		return
+	}
+
+	// Two constraints with the same topology key and action are not allowed, hence the user defined one prevails.
+	for i := range pod.Spec.TopologySpreadConstraints {
+		if pod.Spec.TopologySpreadConstraints[i].TopologyKey == constraint.TopologyKey &&
+			pod.Spec.TopologySpreadConstraints[i].WhenUnsatisfiable == constraint.WhenUnsatisfiable {
+			return
+		}
+	}
+	pod.Spec.TopologySpreadConstraints = append(pod.Spec.TopologySpreadConstraints, *constraint)
+}
@@ -8,10 +8,14 @@ import (
 	"net/http"
 	"time"
 
+	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes"
 	"k8s.io/klog/v2"
+	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 
+	configv1alpha1 "github.com/liqotech/liqo/apis/config/v1alpha1"
 	offv1alpha1 "github.com/liqotech/liqo/apis/offloading/v1alpha1"
 	cachedclient "github.com/liqotech/liqo/pkg/utils/cachedClient"
 )
@@ -19,6 +23,9 @@ import (
 type MutationConfig struct {
 	CertFile string
 	KeyFile  string
+	// OffloadingPolicyLabels are the namespace labels the ClusterOffloadingPolicies may refer to, whose changes
+	// are validated by a dedicated webhook failing closed.
+	OffloadingPolicyLabels []string
 }
 
 type MutationServer struct {
@@ -26,6 +33,7 @@ type MutationServer struct {
 	server *http.Server
 
 	webhookClient client.Client
+	clientset     kubernetes.Interface
 	config        *MutationConfig
 	ctx           context.Context
 }
@@ -39,14 +47,21 @@ func NewMutationServer(ctx context.Context, c *MutationConfig) (*MutationServer,
 	// This scheme is necessary for the WebhookClient.
 	scheme := runtime.NewScheme()
 	_ = offv1alpha1.AddToScheme(scheme)
+	_ = configv1alpha1.AddToScheme(scheme)
+	_ = corev1.AddToScheme(scheme)
 
 	var err error
 	if s.webhookClient, err = cachedclient.GetCachedClient(ctx, scheme); err != nil {
 		return nil, err
 	}
 
+	if s.clientset, err = kubernetes.NewForConfig(ctrl.GetConfigOrDie()); err != nil {
+		return nil, err
+	}
+
 	s.mux = http.NewServeMux()
 	s.mux.HandleFunc("/mutate", s.handleMutate)
+	s.mux.HandleFunc("/validate", s.handleValidate)
 
 	s.server = &http.Server{
 		Addr:           ":8443",
@@ -87,6 +102,34 @@ func (s *MutationServer) handleMutate(w http.ResponseWriter, r *http.Request) {
 	}
 }
 
+func (s *MutationServer) handleValidate(w http.ResponseWriter, r *http.Request) {
+	// read the body / request
+	body, err := ioutil.ReadAll(r.Body)
+	if err != nil {
+		klog.Error(err)
+		standardErrMessage := fmt.Errorf("unable to correctly read the body of the request")
+		s.sendError(standardErrMessage, w)
+		return
+	}
+
+	// validate the request
+	validated, err := s.Validate(body)
+	if err != nil {
+		klog.Error(err)
+		standardErrMessage := fmt.Errorf("unable to correctly validate the request")
+		s.sendError(standardErrMessage, w)
+		return
+	}
+
+	// and write it back
+	w.WriteHeader(http.StatusOK)
+	_, _ = w.Write(validated)
+
+	if err := r.Body.Close(); err != nil {
+		klog.Error("error in body closing")
+	}
+}
+
 func (s *MutationServer) sendError(err error, w http.ResponseWriter) {
 	klog.Error(err)
 	w.WriteHeader(http.StatusInternalServerError)
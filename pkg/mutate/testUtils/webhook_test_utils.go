@@ -34,7 +34,7 @@ func GetImposedNodeSelector(strategy offv1alpha1.PodOffloadingStrategyType) core
 					{
 						Key:      "region",
 						Operator: corev1.NodeSelectorOpIn,
-						Values:   []string{"A,B"},
+						Values:   []string{"A", "B"},
 					},
 					{
 						Key:      "provider",
@@ -52,7 +52,7 @@ func GetImposedNodeSelector(strategy offv1alpha1.PodOffloadingStrategyType) core
 					{
 						Key:      "region",
 						Operator: corev1.NodeSelectorOpIn,
-						Values:   []string{"C,D"},
+						Values:   []string{"C", "D"},
 					},
 					{
 						Key:      "NotProvider",
@@ -73,7 +73,7 @@ func GetImposedNodeSelector(strategy offv1alpha1.PodOffloadingStrategyType) core
 					{
 						Key:      "region",
 						Operator: corev1.NodeSelectorOpIn,
-						Values:   []string{"A,B"},
+						Values:   []string{"A", "B"},
 					},
 					{
 						Key:      "provider",
@@ -86,7 +86,7 @@ func GetImposedNodeSelector(strategy offv1alpha1.PodOffloadingStrategyType) core
 					{
 						Key:      "region",
 						Operator: corev1.NodeSelectorOpIn,
-						Values:   []string{"C,D"},
+						Values:   []string{"C", "D"},
 					},
 					{
 						Key:      "NotProvider",
@@ -112,7 +112,7 @@ func GetImposedNodeSelector(strategy offv1alpha1.PodOffloadingStrategyType) core
 					{
 						Key:      "region",
 						Operator: corev1.NodeSelectorOpIn,
-						Values:   []string{"A,B"},
+						Values:   []string{"A", "B"},
 					},
 					{
 						Key:      "provider",
@@ -125,7 +125,7 @@ func GetImposedNodeSelector(strategy offv1alpha1.PodOffloadingStrategyType) core
 					{
 						Key:      "region",
 						Operator: corev1.NodeSelectorOpIn,
-						Values:   []string{"C,D"},
+						Values:   []string{"C", "D"},
 					}, {
 						Key:      "NotProvider",
 						Operator: corev1.NodeSelectorOpExists,
@@ -171,7 +171,7 @@ func GetMergedNodeSelector(strategy offv1alpha1.PodOffloadingStrategyType) corev
 					{
 						Key:      "region",
 						Operator: corev1.NodeSelectorOpIn,
-						Values:   []string{"A,B"},
+						Values:   []string{"A", "B"},
 					},
 					{
 						Key:      "provider",
@@ -193,7 +193,7 @@ func GetMergedNodeSelector(strategy offv1alpha1.PodOffloadingStrategyType) corev
 					{
 						Key:      "region",
 						Operator: corev1.NodeSelectorOpIn,
-						Values:   []string{"C,D"},
+						Values:   []string{"C", "D"},
 					},
 					{
 						Key:      "NotProvider",
@@ -215,7 +215,7 @@ func GetMergedNodeSelector(strategy offv1alpha1.PodOffloadingStrategyType) corev
 					{
 						Key:      "region",
 						Operator: corev1.NodeSelectorOpIn,
-						Values:   []string{"A,B"},
+						Values:   []string{"A", "B"},
 					},
 					{
 						Key:      "provider",
@@ -238,7 +238,7 @@ func GetMergedNodeSelector(strategy offv1alpha1.PodOffloadingStrategyType) corev
 					{
 						Key:      "region",
 						Operator: corev1.NodeSelectorOpIn,
-						Values:   []string{"C,D"},
+						Values:   []string{"C", "D"},
 					},
 					{
 						Key:      "NotProvider",
@@ -264,7 +264,7 @@ func GetMergedNodeSelector(strategy offv1alpha1.PodOffloadingStrategyType) corev
 					{
 						Key:      "region",
 						Operator: corev1.NodeSelectorOpIn,
-						Values:   []string{"A,B"},
+						Values:   []string{"A", "B"},
 					},
 					{
 						Key:      "provider",
@@ -281,7 +281,7 @@ func GetMergedNodeSelector(strategy offv1alpha1.PodOffloadingStrategyType) corev
 					{
 						Key:      "region",
 						Operator: corev1.NodeSelectorOpIn,
-						Values:   []string{"C,D"},
+						Values:   []string{"C", "D"},
 					},
 					{
 						Key:      "NotProvider",
@@ -311,7 +311,7 @@ func GetMergedNodeSelector(strategy offv1alpha1.PodOffloadingStrategyType) corev
 					{
 						Key:      "region",
 						Operator: corev1.NodeSelectorOpIn,
-						Values:   []string{"A,B"},
+						Values:   []string{"A", "B"},
 					},
 					{
 						Key:      "provider",
@@ -329,7 +329,7 @@ func GetMergedNodeSelector(strategy offv1alpha1.PodOffloadingStrategyType) corev
 					{
 						Key:      "region",
 						Operator: corev1.NodeSelectorOpIn,
-						Values:   []string{"C,D"},
+						Values:   []string{"C", "D"},
 					},
 					{
 						Key:      "NotProvider",
@@ -364,7 +364,7 @@ func GetMergedNodeSelector(strategy offv1alpha1.PodOffloadingStrategyType) corev
 					{
 						Key:      "region",
 						Operator: corev1.NodeSelectorOpIn,
-						Values:   []string{"A,B"},
+						Values:   []string{"A", "B"},
 					},
 					{
 						Key:      "provider",
@@ -381,7 +381,7 @@ func GetMergedNodeSelector(strategy offv1alpha1.PodOffloadingStrategyType) corev
 					{
 						Key:      "region",
 						Operator: corev1.NodeSelectorOpIn,
-						Values:   []string{"C,D"},
+						Values:   []string{"C", "D"},
 					},
 					{
 						Key:      "NotProvider",
@@ -398,7 +398,7 @@ func GetMergedNodeSelector(strategy offv1alpha1.PodOffloadingStrategyType) corev
 					{
 						Key:      "region",
 						Operator: corev1.NodeSelectorOpIn,
-						Values:   []string{"A,B"},
+						Values:   []string{"A", "B"},
 					},
 					{
 						Key:      "provider",
@@ -416,7 +416,7 @@ func GetMergedNodeSelector(strategy offv1alpha1.PodOffloadingStrategyType) corev
 					{
 						Key:      "region",
 						Operator: corev1.NodeSelectorOpIn,
-						Values:   []string{"C,D"},
+						Values:   []string{"C", "D"},
 					},
 					{
 						Key:      "NotProvider",
@@ -0,0 +1,336 @@
+package mutate
+
+import (
+	"encoding/json"
+	"fmt"
+
+	admissionv1beta1 "k8s.io/api/admission/v1beta1"
+	corev1 "k8s.io/api/core/v1"
+	rbacv1 "k8s.io/api/rbac/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	"k8s.io/component-helpers/scheduling/corev1/nodeaffinity"
+	"k8s.io/klog/v2"
+
+	configv1alpha1 "github.com/liqotech/liqo/apis/config/v1alpha1"
+	discoveryv1alpha1 "github.com/liqotech/liqo/apis/discovery/v1alpha1"
+	offv1alpha1 "github.com/liqotech/liqo/apis/offloading/v1alpha1"
+	sharingv1alpha1 "github.com/liqotech/liqo/apis/sharing/v1alpha1"
+	liqoconst "github.com/liqotech/liqo/pkg/consts"
+	namespacenaming "github.com/liqotech/liqo/pkg/namespaceNaming"
+)
+
+// Validate validates the Liqo resource received via admReview and creates a response
+// that allows or denies the request.
+func (s *MutationServer) Validate(body []byte) ([]byte, error) {
+	// Unmarshal request into AdmissionReview struct.
+	admReview := admissionv1beta1.AdmissionReview{}
+	if err := json.Unmarshal(body, &admReview); err != nil {
+		return nil, fmt.Errorf("unmarshaling request failed with %s", err)
+	}
+
+	admissionReviewRequest := admReview.Request
+	if admissionReviewRequest == nil {
+		return nil, fmt.Errorf("received admissionReview with empty request")
+	}
+
+	resp := admissionv1beta1.AdmissionResponse{
+		UID:     admissionReviewRequest.UID,
+		Allowed: true,
+		Result:  &metav1.Status{Status: metav1.StatusSuccess},
+	}
+
+	if err := s.validateRequest(admissionReviewRequest); err != nil {
+		klog.Infof("%s '%s' refused: %s", admissionReviewRequest.Kind.Kind,
+			requestName(admissionReviewRequest), err)
+		resp.Allowed = false
+		resp.Result = &metav1.Status{
+			Status:  metav1.StatusFailure,
+			Reason:  metav1.StatusReasonInvalid,
+			Message: err.Error(),
+		}
+	}
+
+	admReview.Response = &resp
+	responseBody, err := json.Marshal(admReview)
+	if err != nil {
+		return nil, err
+	}
+	klog.V(8).Infof("response: %s", string(responseBody))
+	return responseBody, nil
+}
+
+// validateRequest decodes the object (and the old one, in case of updates) carried by the request,
+// and validates it according to its kind. The objects of unknown kinds are always allowed.
+func (s *MutationServer) validateRequest(req *admissionv1beta1.AdmissionRequest) error {
+	switch schema.GroupVersionKind(req.Kind) {
+	case corev1.SchemeGroupVersion.WithKind("Namespace"):
+		var namespace, oldNamespace corev1.Namespace
+		if err := decodeObjects(req, &namespace, &oldNamespace); err != nil {
+			return err
+		}
+		if err := s.validateRemoteNamespace(&req.UserInfo, &namespace, &oldNamespace); err != nil {
+			return err
+		}
+		return s.validateNamespaceLabels(&req.UserInfo, &namespace, &oldNamespace)
+	case offv1alpha1.GroupVersion.WithKind("NamespaceOffloading"):
+		var noff, oldNoff offv1alpha1.NamespaceOffloading
+		if err := decodeObjects(req, &noff, &oldNoff); err != nil {
+			return err
+		}
+		if err := validateNamespaceOffloading(&noff); err != nil {
+			return err
+		}
+		if req.Operation == admissionv1beta1.Update {
+			return validateNamespaceOffloadingUpdate(&noff, &oldNoff)
+		}
+	case offv1alpha1.GroupVersion.WithKind("ClusterOffloadingPolicy"):
+		var policy, oldPolicy offv1alpha1.ClusterOffloadingPolicy
+		if err := decodeObjects(req, &policy, &oldPolicy); err != nil {
+			return err
+		}
+		return validateClusterOffloadingPolicy(&policy, s.config.OffloadingPolicyLabels)
+	case discoveryv1alpha1.GroupVersion.WithKind("ForeignCluster"):
+		var fc, oldFc discoveryv1alpha1.ForeignCluster
+		if err := decodeObjects(req, &fc, &oldFc); err != nil {
+			return err
+		}
+		if err := validateForeignCluster(&fc); err != nil {
+			return err
+		}
+		if req.Operation == admissionv1beta1.Update {
+			return validateForeignClusterUpdate(&fc, &oldFc)
+		}
+	case discoveryv1alpha1.GroupVersion.WithKind("SearchDomain"):
+		var sd, oldSd discoveryv1alpha1.SearchDomain
+		if err := decodeObjects(req, &sd, &oldSd); err != nil {
+			return err
+		}
+		return validateSearchDomain(&sd)
+	case configv1alpha1.GroupVersion.WithKind("ClusterConfig"):
+		var config, oldConfig configv1alpha1.ClusterConfig
+		if err := decodeObjects(req, &config, &oldConfig); err != nil {
+			return err
+		}
+		if err := validateClusterConfig(&config); err != nil {
+			return err
+		}
+		if req.Operation == admissionv1beta1.Update {
+			return validateClusterConfigUpdate(&config, &oldConfig)
+		}
+	case sharingv1alpha1.GroupVersion.WithKind("ResourceOffer"):
+		var offer, oldOffer sharingv1alpha1.ResourceOffer
+		if err := decodeObjects(req, &offer, &oldOffer); err != nil {
+			return err
+		}
+		if err := validateResourceOffer(&offer); err != nil {
+			return err
+		}
+		if req.Operation == admissionv1beta1.Update {
+			return validateResourceOfferUpdate(&offer, &oldOffer)
+		}
+	default:
+		klog.Warningf("Received unexpected admission request for kind %s", req.Kind.String())
+	}
+	return nil
+}
+
+// decodeObjects unmarshals the object carried by the request, and the old one in case of updates.
+func decodeObjects(req *admissionv1beta1.AdmissionRequest, obj, oldObj interface{}) error {
+	if err := json.Unmarshal(req.Object.Raw, obj); err != nil {
+		return fmt.Errorf("unable to unmarshal %s json object: %w", req.Kind.Kind, err)
+	}
+	if req.Operation != admissionv1beta1.Update {
+		return nil
+	}
+	if err := json.Unmarshal(req.OldObject.Raw, oldObj); err != nil {
+		return fmt.Errorf("unable to unmarshal the old %s json object: %w", req.Kind.Kind, err)
+	}
+	return nil
+}
+
+// requestName returns the name of the object carried by the request, including the namespace if any.
+func requestName(req *admissionv1beta1.AdmissionRequest) string {
+	if req.Namespace == "" {
+		return req.Name
+	}
+	return req.Namespace + "/" + req.Name
+}
+
+// validateNamespaceOffloading checks that the PodOffloadingStrategy and the ClusterSelector are valid, that the
+// NamespaceNameTemplate is set only along with the Template NamespaceMappingStrategy, that it generates a valid
+// namespace name, and that the offloading profiles are valid.
+func validateNamespaceOffloading(noff *offv1alpha1.NamespaceOffloading) error {
+	if err := validatePodOffloadingStrategy(noff.Spec.PodOffloadingStrategy); err != nil {
+		return err
+	}
+	if err := validateClusterSelector(&noff.Spec.ClusterSelector); err != nil {
+		return err
+	}
+	if err := validateOffloadingProfiles(noff.Spec.Profiles); err != nil {
+		return err
+	}
+	if err := validatePlacement(noff.Spec.Placement, noff.Spec.PodOffloadingStrategy); err != nil {
+		return err
+	}
+
+	if noff.Spec.NamespaceMappingStrategy != offv1alpha1.TemplateNameMappingStrategyType {
+		if noff.Spec.NamespaceNameTemplate != "" {
+			return fmt.Errorf("the namespaceNameTemplate field can be set only with the '%s' namespaceMappingStrategy",
+				offv1alpha1.TemplateNameMappingStrategyType)
+		}
+		return nil
+	}
+
+	// The name of the local cluster is not known here, hence placeholders are used. The NamespaceOffloading
+	// controller renders the template with the actual values.
+	return namespacenaming.ValidateTemplate(noff.Spec.NamespaceNameTemplate, noff.Namespace, "", "")
+}
+
+// validateOffloadingProfiles checks that the names of the offloading profiles are unique and do not collide with
+// the default one, and that their PodSelectors, strategies, ClusterSelectors and placement preferences are valid.
+func validateOffloadingProfiles(profiles []offv1alpha1.OffloadingProfile) error {
+	names := make(map[string]struct{}, len(profiles))
+	for i := range profiles {
+		name := profiles[i].Name
+		if name == offv1alpha1.DefaultOffloadingProfileName {
+			return fmt.Errorf("the offloading profile name '%s' is reserved", name)
+		}
+		if _, found := names[name]; found {
+			return fmt.Errorf("the offloading profile name '%s' is duplicated", name)
+		}
+		names[name] = struct{}{}
+
+		if _, err := metav1.LabelSelectorAsSelector(&profiles[i].PodSelector); err != nil {
+			return fmt.Errorf("the offloading profile '%s' has an invalid podSelector: %w", name, err)
+		}
+
+		if err := validatePodOffloadingStrategy(profiles[i].PodOffloadingStrategy); err != nil {
+			return fmt.Errorf("the offloading profile '%s' is invalid: %w", name, err)
+		}
+
+		if err := validateClusterSelector(&profiles[i].ClusterSelector); err != nil {
+			return fmt.Errorf("the offloading profile '%s' is invalid: %w", name, err)
+		}
+
+		if err := validatePlacement(profiles[i].Placement, profiles[i].PodOffloadingStrategy); err != nil {
+			return fmt.Errorf("the offloading profile '%s' has invalid placement preferences: %w", name, err)
+		}
+	}
+	return nil
+}
+
+// validatePlacement checks that the preferred clusters are valid and weighted in the allowed range, and that the
+// local cluster is preferred only if the pods can be scheduled both locally and remotely.
+func validatePlacement(placement *offv1alpha1.PlacementPreferences, strategy offv1alpha1.PodOffloadingStrategyType) error {
+	if placement == nil {
+		return nil
+	}
+
+	for i := range placement.PreferredClusters {
+		if weight := placement.PreferredClusters[i].Weight; weight < 1 || weight > 100 {
+			return fmt.Errorf("the weight of the preferred clusters must be in the range 1-100, found %d", weight)
+		}
+	}
+	if _, err := nodeaffinity.NewPreferredSchedulingTerms(placement.PreferredClusters); err != nil {
+		return fmt.Errorf("the preferred clusters are invalid: %w", err)
+	}
+
+	if placement.PreferLocal && strategy != offv1alpha1.LocalAndRemotePodOffloadingStrategyType {
+		return fmt.Errorf("the local cluster can be preferred only with the '%s' podOffloadingStrategy",
+			offv1alpha1.LocalAndRemotePodOffloadingStrategyType)
+	}
+	return nil
+}
+
+// validatePodOffloadingStrategy checks that the PodOffloadingStrategy is one of the supported ones.
+func validatePodOffloadingStrategy(strategy offv1alpha1.PodOffloadingStrategyType) error {
+	switch strategy {
+	case offv1alpha1.LocalPodOffloadingStrategyType, offv1alpha1.RemotePodOffloadingStrategyType,
+		offv1alpha1.LocalAndRemotePodOffloadingStrategyType:
+		return nil
+	default:
+		return fmt.Errorf("unsupported podOffloadingStrategy '%s': must be one of '%s', '%s' or '%s'", strategy,
+			offv1alpha1.LocalPodOffloadingStrategyType, offv1alpha1.RemotePodOffloadingStrategyType,
+			offv1alpha1.LocalAndRemotePodOffloadingStrategyType)
+	}
+}
+
+// validateClusterSelector checks that the operators and the values of the ClusterSelector requirements are valid.
+func validateClusterSelector(selector *corev1.NodeSelector) error {
+	if len(selector.NodeSelectorTerms) == 0 {
+		return nil
+	}
+	if _, err := nodeaffinity.NewNodeSelector(selector, field.WithPath(field.NewPath("clusterSelector"))); err != nil {
+		return fmt.Errorf("the clusterSelector is invalid: %w", err)
+	}
+	return nil
+}
+
+// validateNamespaceOffloadingUpdate checks that the fields determining the name of the remote namespaces,
+// which cannot be changed once created, are not modified.
+func validateNamespaceOffloadingUpdate(noff, oldNoff *offv1alpha1.NamespaceOffloading) error {
+	if noff.Spec.NamespaceMappingStrategy != oldNoff.Spec.NamespaceMappingStrategy {
+		return fmt.Errorf("the namespaceMappingStrategy field is immutable (current value: '%s'): "+
+			"delete and recreate the NamespaceOffloading to change it", oldNoff.Spec.NamespaceMappingStrategy)
+	}
+	if noff.Spec.NamespaceNameTemplate != oldNoff.Spec.NamespaceNameTemplate {
+		return fmt.Errorf("the namespaceNameTemplate field is immutable (current value: '%s'): "+
+			"delete and recreate the NamespaceOffloading to change it", oldNoff.Spec.NamespaceNameTemplate)
+	}
+	return nil
+}
+
+// validateClusterOffloadingPolicy checks that the NamespaceSelector is valid and refers only to the given protected
+// labels, and that the allowed clusters are neither empty nor duplicated.
+func validateClusterOffloadingPolicy(policy *offv1alpha1.ClusterOffloadingPolicy, protectedLabels []string) error {
+	if _, err := metav1.LabelSelectorAsSelector(&policy.Spec.NamespaceSelector); err != nil {
+		return fmt.Errorf("the namespaceSelector is invalid: %w", err)
+	}
+
+	clusterIDs := make(map[string]struct{}, len(policy.Spec.AllowedClusters))
+	for _, clusterID := range policy.Spec.AllowedClusters {
+		if clusterID == "" {
+			return fmt.Errorf("the allowedClusters field contains an empty cluster ID")
+		}
+		if _, found := clusterIDs[clusterID]; found {
+			return fmt.Errorf("the allowedClusters field contains the duplicated cluster ID '%s'", clusterID)
+		}
+		clusterIDs[clusterID] = struct{}{}
+	}
+
+	// The scheduling label is managed by Liqo, hence it cannot be protected from changes.
+	if _, found := selectorKey(&policy.Spec.NamespaceSelector,
+		map[string]struct{}{liqoconst.SchedulingLiqoLabel: {}}); found {
+		return fmt.Errorf("the namespaceSelector cannot refer to the '%s' label, which is managed by Liqo",
+			liqoconst.SchedulingLiqoLabel)
+	}
+
+	// The changes of the labels are validated by the webhook only for the protected ones, while the name label
+	// is set by the API server and cannot be changed.
+	protected := map[string]struct{}{corev1.LabelMetadataName: {}}
+	for _, key := range protectedLabels {
+		protected[key] = struct{}{}
+	}
+	if key, found := unprotectedSelectorKey(&policy.Spec.NamespaceSelector, protected); found {
+		return fmt.Errorf("the namespaceSelector cannot refer to the '%s' label, whose changes are not validated", key)
+	}
+
+	for i := range policy.Spec.Subjects {
+		subject := &policy.Spec.Subjects[i]
+		if subject.Name == "" {
+			return fmt.Errorf("the subjects field contains a subject with an empty name")
+		}
+		switch subject.Kind {
+		case rbacv1.UserKind, rbacv1.GroupKind:
+		case rbacv1.ServiceAccountKind:
+			if subject.Namespace == "" {
+				return fmt.Errorf("the service account subject '%s' has an empty namespace", subject.Name)
+			}
+		default:
+			return fmt.Errorf("the subject '%s' has the unsupported kind '%s'", subject.Name, subject.Kind)
+		}
+	}
+	return nil
+}
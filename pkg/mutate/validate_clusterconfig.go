@@ -0,0 +1,107 @@
+package mutate
+
+import (
+	"fmt"
+	"net"
+
+	configv1alpha1 "github.com/liqotech/liqo/apis/config/v1alpha1"
+)
+
+// namedNetwork associates a network with the name of the field it is configured by.
+type namedNetwork struct {
+	field   string
+	network *net.IPNet
+}
+
+// validateClusterConfig checks that the networks configured in the LiqonetConfig are valid CIDRs, and that the
+// reserved subnets and the additional pools do not overlap with each other and with the pod and service CIDRs.
+func validateClusterConfig(config *configv1alpha1.ClusterConfig) error {
+	liqonetConfig := &config.Spec.LiqonetConfig
+	if interval := liqonetConfig.WireguardKeyRotationInterval; interval != nil && interval.Duration < 0 {
+		return fmt.Errorf("the wireguardKeyRotationInterval field cannot be negative (current value: '%s')", interval.Duration)
+	}
+
+	var clusterNetworks []namedNetwork
+	for field, cidr := range map[string]string{"podCIDR": liqonetConfig.PodCIDR, "serviceCIDR": liqonetConfig.ServiceCIDR} {
+		if cidr == "" {
+			continue
+		}
+		network, err := parseCIDR(field, cidr)
+		if err != nil {
+			return err
+		}
+		clusterNetworks = append(clusterNetworks, namedNetwork{field: field, network: network})
+	}
+	if err := checkOverlaps(clusterNetworks); err != nil {
+		return err
+	}
+
+	reservedSubnets, err := parseCIDRs("reservedSubnets", liqonetConfig.ReservedSubnets)
+	if err != nil {
+		return err
+	}
+	if err := checkOverlaps(append(reservedSubnets, clusterNetworks...)); err != nil {
+		return err
+	}
+
+	additionalPools, err := parseCIDRs("additionalPools", liqonetConfig.AdditionalPools)
+	if err != nil {
+		return err
+	}
+	return checkOverlaps(append(additionalPools, clusterNetworks...))
+}
+
+// validateClusterConfigUpdate checks that the pod and service CIDRs, which are acquired by the IPAM once
+// configured, are not modified.
+func validateClusterConfigUpdate(config, oldConfig *configv1alpha1.ClusterConfig) error {
+	current, old := &config.Spec.LiqonetConfig, &oldConfig.Spec.LiqonetConfig
+	if old.PodCIDR != "" && current.PodCIDR != old.PodCIDR {
+		return fmt.Errorf("the podCIDR field is immutable once set (current value: '%s')", old.PodCIDR)
+	}
+	if old.ServiceCIDR != "" && current.ServiceCIDR != old.ServiceCIDR {
+		return fmt.Errorf("the serviceCIDR field is immutable once set (current value: '%s')", old.ServiceCIDR)
+	}
+	return nil
+}
+
+// parseCIDRs parses the given list of CIDRs, configured by the given field.
+func parseCIDRs(field string, cidrs []configv1alpha1.CIDR) ([]namedNetwork, error) {
+	networks := make([]namedNetwork, 0, len(cidrs))
+	for _, cidr := range cidrs {
+		network, err := parseCIDR(field, string(cidr))
+		if err != nil {
+			return nil, err
+		}
+		networks = append(networks, namedNetwork{field: field, network: network})
+	}
+	return networks, nil
+}
+
+// parseCIDR parses the given IPv4 CIDR, configured by the given field, and checks that it identifies a network
+// address (i.e. the host bits are not set). IPv6 CIDRs are rejected, since the NAT rules and the tunnel drivers
+// support only IPv4 networks.
+func parseCIDR(field, cidr string) (*net.IPNet, error) {
+	ip, network, err := net.ParseCIDR(cidr)
+	if err != nil || ip.To4() == nil {
+		return nil, fmt.Errorf("the %s field contains the invalid IPv4 CIDR '%s'", field, cidr)
+	}
+	if !ip.Equal(network.IP) {
+		return nil, fmt.Errorf("the %s field contains the CIDR '%s', which is not a network address (did you mean '%s'?)",
+			field, cidr, network.String())
+	}
+	return network, nil
+}
+
+// checkOverlaps returns an error if any of the given networks overlap with each other.
+func checkOverlaps(networks []namedNetwork) error {
+	for i := range networks {
+		for j := i + 1; j < len(networks); j++ {
+			first, second := networks[i], networks[j]
+			if first.network.Contains(second.network.IP) || second.network.Contains(first.network.IP) {
+				return fmt.Errorf("the network '%s' (%s) overlaps with the network '%s' (%s)",
+					first.network, first.field, second.network, second.field)
+			}
+		}
+	}
+	return nil
+}
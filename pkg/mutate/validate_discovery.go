@@ -0,0 +1,49 @@
+package mutate
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/util/validation"
+
+	discoveryv1alpha1 "github.com/liqotech/liqo/apis/discovery/v1alpha1"
+)
+
+// validateForeignCluster checks that the AuthURL of the ForeignCluster is an absolute HTTP(S) URL.
+func validateForeignCluster(fc *discoveryv1alpha1.ForeignCluster) error {
+	if fc.Spec.AuthURL == "" {
+		return nil
+	}
+
+	authURL, err := url.Parse(fc.Spec.AuthURL)
+	if err != nil {
+		return fmt.Errorf("the authUrl field contains the invalid URL '%s': %w", fc.Spec.AuthURL, err)
+	}
+	if authURL.Scheme != "https" && authURL.Scheme != "http" || authURL.Host == "" {
+		return fmt.Errorf("the authUrl field must be an absolute URL in the form 'https://<host>:<port>', found '%s'",
+			fc.Spec.AuthURL)
+	}
+	return nil
+}
+
+// validateForeignClusterUpdate checks that the identifier of the foreign cluster, which is defaulted if
+// not specified at creation time, is not modified once set.
+func validateForeignClusterUpdate(fc, oldFc *discoveryv1alpha1.ForeignCluster) error {
+	oldClusterID := oldFc.Spec.ClusterIdentity.ClusterID
+	if oldClusterID != "" && fc.Spec.ClusterIdentity.ClusterID != oldClusterID {
+		return fmt.Errorf("the clusterIdentity.clusterID field is immutable once set (current value: '%s'): "+
+			"create a new ForeignCluster to peer with a different cluster", oldClusterID)
+	}
+	return nil
+}
+
+// validateSearchDomain checks that the domain of the SearchDomain is a valid DNS name.
+func validateSearchDomain(sd *discoveryv1alpha1.SearchDomain) error {
+	// The fully qualified form (i.e. with the trailing dot) is accepted as well.
+	domain := strings.TrimSuffix(sd.Spec.Domain, ".")
+	if errs := validation.IsDNS1123Subdomain(domain); len(errs) > 0 {
+		return fmt.Errorf("the domain field contains the invalid DNS name '%s': %s", sd.Spec.Domain, strings.Join(errs, ", "))
+	}
+	return nil
+}
@@ -0,0 +1,211 @@
+package mutate
+
+import (
+	"context"
+	"fmt"
+
+	authenticationv1 "k8s.io/api/authentication/v1"
+	authorizationv1 "k8s.io/api/authorization/v1"
+	corev1 "k8s.io/api/core/v1"
+	rbacv1 "k8s.io/api/rbac/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	configv1alpha1 "github.com/liqotech/liqo/apis/config/v1alpha1"
+	offv1alpha1 "github.com/liqotech/liqo/apis/offloading/v1alpha1"
+	liqoconst "github.com/liqotech/liqo/pkg/consts"
+	identitymanager "github.com/liqotech/liqo/pkg/identityManager"
+	namespacenaming "github.com/liqotech/liqo/pkg/namespaceNaming"
+)
+
+// validateRemoteNamespace checks that the namespaces created by the remote clusters satisfy the policy configured
+// in the ClusterConfig. The policy is communicated to the remote clusters during the peering, but it is enforced here,
+// since they may not honor it, and since it may have been changed afterwards. The namespaces are recognized through
+// the remote namespace label, which is set at creation by the remote clusters and forced anyway by their Tenants:
+// the name is checked whenever the label is added, while the remote clusters can set it only to their own ID.
+func (s *MutationServer) validateRemoteNamespace(userInfo *authenticationv1.UserInfo,
+	namespace, oldNamespace *corev1.Namespace) error {
+	clusterID, found := namespace.Labels[liqoconst.RemoteNamespaceLabelKey]
+	oldClusterID, oldFound := oldNamespace.Labels[liqoconst.RemoteNamespaceLabelKey]
+	if isRemoteCluster(userInfo) && (found != oldFound || clusterID != oldClusterID) && clusterID != userInfo.Username {
+		return fmt.Errorf("the remote cluster '%s' can set the label '%s' only to its own ID",
+			userInfo.Username, liqoconst.RemoteNamespaceLabelKey)
+	}
+	if !found || oldFound {
+		return nil
+	}
+
+	policy, err := s.getRemoteNamespacePolicy()
+	if err != nil {
+		return fmt.Errorf("unable to get the policy on the names of the namespaces of the remote clusters: %w", err)
+	}
+	if err := namespacenaming.CheckPolicy(policy, namespace.Name); err != nil {
+		return fmt.Errorf("the namespace of the remote cluster '%s' is refused: %w", clusterID, err)
+	}
+	return nil
+}
+
+// getRemoteNamespacePolicy returns the policy on the names of the namespaces of the remote clusters,
+// as currently set in the ClusterConfig.
+func (s *MutationServer) getRemoteNamespacePolicy() (*configv1alpha1.RemoteNamespacePolicy, error) {
+	if s.webhookClient == nil {
+		return nil, fmt.Errorf("the client is not initialized")
+	}
+	var clusterConfigs configv1alpha1.ClusterConfigList
+	if err := s.webhookClient.List(context.TODO(), &clusterConfigs); err != nil {
+		return nil, err
+	}
+	if len(clusterConfigs.Items) != 1 {
+		return nil, fmt.Errorf("expected exactly one ClusterConfig, found %d", len(clusterConfigs.Items))
+	}
+	return clusterConfigs.Items[0].Spec.AuthConfig.RemoteNamespacePolicy, nil
+}
+
+// isRemoteCluster checks whether the request has been performed by a remote cluster, using the identity it has
+// been issued during the peering.
+func isRemoteCluster(userInfo *authenticationv1.UserInfo) bool {
+	for _, group := range userInfo.Groups {
+		if group == identitymanager.RemoteClusterGroup {
+			return true
+		}
+	}
+	return false
+}
+
+// validateNamespaceLabels checks that the labels referred to by the NamespaceSelectors of the ClusterOffloadingPolicies
+// are set, changed or removed only by the subjects the policies are granted to, or by the users authorized to update
+// the policies. Otherwise, the namespace owners could make their namespaces match a more permissive policy.
+func (s *MutationServer) validateNamespaceLabels(userInfo *authenticationv1.UserInfo,
+	namespace, oldNamespace *corev1.Namespace) error {
+	changed := changedLabels(namespace.Labels, oldNamespace.Labels)
+	if len(changed) == 0 {
+		return nil
+	}
+
+	var policies offv1alpha1.ClusterOffloadingPolicyList
+	if err := s.webhookClient.List(context.TODO(), &policies); err != nil {
+		return fmt.Errorf("unable to list the ClusterOffloadingPolicies: %w", err)
+	}
+
+	var authorized *bool
+	for i := range policies.Items {
+		policy := &policies.Items[i]
+		key, found := selectorKey(&policy.Spec.NamespaceSelector, changed)
+		if !found || isGranted(policy.Spec.Subjects, userInfo) {
+			continue
+		}
+
+		// The authorization is checked at most once, and only if actually necessary.
+		if authorized == nil {
+			allowed, err := s.canUpdatePolicies(userInfo)
+			if err != nil {
+				return fmt.Errorf("unable to check the permissions of '%s': %w", userInfo.Username, err)
+			}
+			authorized = &allowed
+		}
+		if !*authorized {
+			return fmt.Errorf("the label '%s' is referred to by the ClusterOffloadingPolicy '%s', which is not granted to '%s'",
+				key, policy.Name, userInfo.Username)
+		}
+	}
+	return nil
+}
+
+// changedLabels returns the keys of the labels set, changed or removed with respect to the old ones. The
+// "kubernetes.io/metadata.name" label is ignored, since it is managed by the API server and always equals the name.
+func changedLabels(labels, oldLabels map[string]string) map[string]struct{} {
+	changed := map[string]struct{}{}
+	for key, value := range labels {
+		if oldValue, found := oldLabels[key]; !found || oldValue != value {
+			changed[key] = struct{}{}
+		}
+	}
+	for key := range oldLabels {
+		if _, found := labels[key]; !found {
+			changed[key] = struct{}{}
+		}
+	}
+	delete(changed, corev1.LabelMetadataName)
+	return changed
+}
+
+// selectorKey returns one of the given label keys the selector refers to, if any.
+func selectorKey(selector *metav1.LabelSelector, keys map[string]struct{}) (string, bool) {
+	for key := range selector.MatchLabels {
+		if _, found := keys[key]; found {
+			return key, true
+		}
+	}
+	for i := range selector.MatchExpressions {
+		if _, found := keys[selector.MatchExpressions[i].Key]; found {
+			return selector.MatchExpressions[i].Key, true
+		}
+	}
+	return "", false
+}
+
+// unprotectedSelectorKey returns one of the label keys the selector refers to which is not among the given ones, if any.
+func unprotectedSelectorKey(selector *metav1.LabelSelector, keys map[string]struct{}) (string, bool) {
+	for key := range selector.MatchLabels {
+		if _, found := keys[key]; !found {
+			return key, true
+		}
+	}
+	for i := range selector.MatchExpressions {
+		if _, found := keys[selector.MatchExpressions[i].Key]; !found {
+			return selector.MatchExpressions[i].Key, true
+		}
+	}
+	return "", false
+}
+
+// isGranted checks whether the user the request has been performed by matches one of the given subjects.
+func isGranted(subjects []rbacv1.Subject, userInfo *authenticationv1.UserInfo) bool {
+	for i := range subjects {
+		switch subjects[i].Kind {
+		case rbacv1.UserKind:
+			if subjects[i].Name == userInfo.Username {
+				return true
+			}
+		case rbacv1.ServiceAccountKind:
+			if fmt.Sprintf("system:serviceaccount:%s:%s", subjects[i].Namespace, subjects[i].Name) == userInfo.Username {
+				return true
+			}
+		case rbacv1.GroupKind:
+			for _, group := range userInfo.Groups {
+				if subjects[i].Name == group {
+					return true
+				}
+			}
+		}
+	}
+	return false
+}
+
+// canUpdatePolicies checks, by means of a SubjectAccessReview, whether the user the request has been performed by
+// is authorized to update the ClusterOffloadingPolicies, hence it is entitled to change the labels they refer to.
+func (s *MutationServer) canUpdatePolicies(userInfo *authenticationv1.UserInfo) (bool, error) {
+	if s.clientset == nil {
+		return false, fmt.Errorf("the clientset is not initialized")
+	}
+
+	extra := make(map[string]authorizationv1.ExtraValue, len(userInfo.Extra))
+	for key, value := range userInfo.Extra {
+		extra[key] = authorizationv1.ExtraValue(value)
+	}
+	review := &authorizationv1.SubjectAccessReview{Spec: authorizationv1.SubjectAccessReviewSpec{
+		ResourceAttributes: &authorizationv1.ResourceAttributes{
+			Group:    offv1alpha1.GroupVersion.Group,
+			Resource: "clusteroffloadingpolicies",
+			Verb:     "update",
+		},
+		User:   userInfo.Username,
+		Groups: userInfo.Groups,
+		UID:    userInfo.UID,
+		Extra:  extra,
+	}}
+	review, err := s.clientset.AuthorizationV1().SubjectAccessReviews().Create(context.TODO(), review, metav1.CreateOptions{})
+	if err != nil {
+		return false, err
+	}
+	return review.Status.Allowed, nil
+}
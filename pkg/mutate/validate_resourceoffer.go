@@ -0,0 +1,40 @@
+package mutate
+
+import (
+	"fmt"
+
+	corev1 "k8s.io/api/core/v1"
+
+	sharingv1alpha1 "github.com/liqotech/liqo/apis/sharing/v1alpha1"
+)
+
+// validateResourceOffer checks that the ResourceOffer identifies the cluster it comes from, and that the offered
+// resources and their prices are not negative.
+func validateResourceOffer(offer *sharingv1alpha1.ResourceOffer) error {
+	if offer.Spec.ClusterId == "" {
+		return fmt.Errorf("the clusterId field is required")
+	}
+	if err := validateNonNegative("resourceQuota.hard", offer.Spec.ResourceQuota.Hard); err != nil {
+		return err
+	}
+	return validateNonNegative("prices", offer.Spec.Prices)
+}
+
+// validateResourceOfferUpdate checks that the cluster the ResourceOffer comes from is not modified.
+func validateResourceOfferUpdate(offer, oldOffer *sharingv1alpha1.ResourceOffer) error {
+	if offer.Spec.ClusterId != oldOffer.Spec.ClusterId {
+		return fmt.Errorf("the clusterId field is immutable (current value: '%s')", oldOffer.Spec.ClusterId)
+	}
+	return nil
+}
+
+// validateNonNegative checks that the quantities in the given list, configured by the given field, are not negative.
+func validateNonNegative(field string, resources corev1.ResourceList) error {
+	for name, quantity := range resources {
+		if quantity.Sign() < 0 {
+			return fmt.Errorf("the %s field contains the negative quantity '%s' for the resource '%s'",
+				field, quantity.String(), name)
+		}
+	}
+	return nil
+}
@@ -1,18 +1,36 @@
 package mutate
 
 import (
+	"encoding/json"
 	"fmt"
 	"testing"
+	"time"
 
 	. "github.com/onsi/ginkgo"
 	. "github.com/onsi/ginkgo/extensions/table"
 	. "github.com/onsi/gomega"
+	admissionv1beta1 "k8s.io/api/admission/v1beta1"
+	appsv1 "k8s.io/api/apps/v1"
+	authenticationv1 "k8s.io/api/authentication/v1"
+	authorizationv1 "k8s.io/api/authorization/v1"
 	corev1 "k8s.io/api/core/v1"
+	rbacv1 "k8s.io/api/rbac/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	k8sfake "k8s.io/client-go/kubernetes/fake"
+	k8stesting "k8s.io/client-go/testing"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
 
+	configv1alpha1 "github.com/liqotech/liqo/apis/config/v1alpha1"
+	discoveryv1alpha1 "github.com/liqotech/liqo/apis/discovery/v1alpha1"
 	offv1alpha1 "github.com/liqotech/liqo/apis/offloading/v1alpha1"
+	sharingv1alpha1 "github.com/liqotech/liqo/apis/sharing/v1alpha1"
 	liqoconst "github.com/liqotech/liqo/pkg/consts"
+	identitymanager "github.com/liqotech/liqo/pkg/identityManager"
 	testutils "github.com/liqotech/liqo/pkg/mutate/testUtils"
+	liqoutils "github.com/liqotech/liqo/pkg/utils"
 )
 
 func TestWebhookManager(t *testing.T) {
@@ -255,5 +273,656 @@ var _ = Describe("Webhook", func() {
 			Expect(len(podTest.Spec.Tolerations) == 1).To(BeTrue())
 			Expect(*podTest.Spec.Affinity.NodeAffinity.RequiredDuringSchedulingIgnoredDuringExecution).To(Equal(oldPodNodeSelector))
 		})
+
+		It("With CostAwareScheduling check that the pod is annotated", func() {
+			namespaceOffloading := testutils.GetNamespaceOffloading(offv1alpha1.LocalAndRemotePodOffloadingStrategyType)
+			podTest := pod.DeepCopy()
+			Expect(mutatePod(&namespaceOffloading, podTest)).To(Succeed())
+			Expect(podTest.Annotations).NotTo(HaveKey(liqoconst.CostAwareSchedulingAnnotation))
+
+			namespaceOffloading.Spec.CostAwareScheduling = true
+			podTest = pod.DeepCopy()
+			Expect(mutatePod(&namespaceOffloading, podTest)).To(Succeed())
+			Expect(podTest.Annotations).To(HaveKeyWithValue(liqoconst.CostAwareSchedulingAnnotation, "true"))
+		})
+	})
+
+	Context("6 - Check the validation of the NamespaceOffloading", func() {
+		type validationTestcase struct {
+			strategy      offv1alpha1.NamespaceMappingStrategyType
+			nameTemplate  string
+			expectedError bool
+		}
+
+		DescribeTable("Different NamespaceMappingStrategies and NamespaceNameTemplates",
+			func(c validationTestcase) {
+				namespaceOffloading := testutils.GetNamespaceOffloading(offv1alpha1.LocalAndRemotePodOffloadingStrategyType)
+				namespaceOffloading.Spec.NamespaceMappingStrategy = c.strategy
+				namespaceOffloading.Spec.NamespaceNameTemplate = c.nameTemplate
+				err := validateNamespaceOffloading(&namespaceOffloading)
+				Expect(err != nil).To(Equal(c.expectedError))
+			},
+			Entry("DefaultName strategy without template", validationTestcase{
+				strategy: offv1alpha1.DefaultNameMappingStrategyType,
+			}),
+			Entry("DefaultName strategy with template", validationTestcase{
+				strategy:      offv1alpha1.DefaultNameMappingStrategyType,
+				nameTemplate:  "{{.Namespace}}",
+				expectedError: true,
+			}),
+			Entry("Template strategy with valid template", validationTestcase{
+				strategy:     offv1alpha1.TemplateNameMappingStrategyType,
+				nameTemplate: "tenant-{{.Namespace}}-{{.LocalClusterName}}",
+			}),
+			Entry("Template strategy without template", validationTestcase{
+				strategy:      offv1alpha1.TemplateNameMappingStrategyType,
+				expectedError: true,
+			}),
+			Entry("Template strategy with unknown field", validationTestcase{
+				strategy:      offv1alpha1.TemplateNameMappingStrategyType,
+				nameTemplate:  "{{.Tenant}}",
+				expectedError: true,
+			}),
+			Entry("Template strategy generating an invalid name", validationTestcase{
+				strategy:      offv1alpha1.TemplateNameMappingStrategyType,
+				nameTemplate:  "{{.Namespace}}_remote",
+				expectedError: true,
+			}),
+		)
+	})
+
+	Context("7 - Check the selection of the offloading profiles", func() {
+		var namespaceOffloading offv1alpha1.NamespaceOffloading
+
+		BeforeEach(func() {
+			namespaceOffloading = testutils.GetNamespaceOffloading(offv1alpha1.LocalAndRemotePodOffloadingStrategyType)
+			namespaceOffloading.Spec.Profiles = []offv1alpha1.OffloadingProfile{{
+				Name:                  "frontend",
+				PodSelector:           metav1.LabelSelector{MatchLabels: map[string]string{"tier": "frontend"}},
+				PodOffloadingStrategy: offv1alpha1.RemotePodOffloadingStrategyType,
+			}, {
+				Name: "frontend-batch",
+				PodSelector: metav1.LabelSelector{MatchLabels: map[string]string{"tier": "frontend"},
+					MatchExpressions: []metav1.LabelSelectorRequirement{{
+						Key: "batch", Operator: metav1.LabelSelectorOpExists,
+					}}},
+				PodOffloadingStrategy: offv1alpha1.LocalPodOffloadingStrategyType,
+			}, {
+				Name:                  "frontend-duplicate",
+				PodSelector:           metav1.LabelSelector{MatchLabels: map[string]string{"tier": "frontend"}},
+				PodOffloadingStrategy: offv1alpha1.LocalPodOffloadingStrategyType,
+			}}
+		})
+
+		DescribeTable("Pods with different labels",
+			func(podLabels map[string]string, expectedProfile string) {
+				pod := &corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "pod", Namespace: "test", Labels: podLabels}}
+				Expect(mutatePod(&namespaceOffloading, pod)).To(Succeed())
+				Expect(pod.Annotations).To(HaveKeyWithValue(liqoconst.OffloadingProfileAnnotation, expectedProfile))
+			},
+			Entry("Pod without labels", nil, offv1alpha1.DefaultOffloadingProfileName),
+			Entry("Pod not matching any profile", map[string]string{"tier": "backend"}, offv1alpha1.DefaultOffloadingProfileName),
+			Entry("Pod matching a single profile", map[string]string{"tier": "frontend"}, "frontend"),
+			Entry("Pod matching multiple profiles", map[string]string{"tier": "frontend", "batch": "true"}, "frontend-batch"),
+		)
+
+		It("The strategy of the selected profile is enforced", func() {
+			pod := &corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "pod", Namespace: "test",
+				Labels: map[string]string{"tier": "frontend"}}}
+			Expect(mutatePod(&namespaceOffloading, pod)).To(Succeed())
+			Expect(pod.Spec.Tolerations).To(ConsistOf(virtualNodeToleration))
+			Expect(*pod.Spec.Affinity.NodeAffinity.RequiredDuringSchedulingIgnoredDuringExecution).To(
+				Equal(testutils.GetImposedNodeSelector(offv1alpha1.RemotePodOffloadingStrategyType)))
+
+			pod.Labels["batch"] = "true"
+			pod.Spec = corev1.PodSpec{}
+			Expect(mutatePod(&namespaceOffloading, pod)).To(Succeed())
+			Expect(pod.Spec.Tolerations).To(BeEmpty())
+			Expect(pod.Spec.Affinity).To(BeNil())
+		})
+
+		DescribeTable("Validation of the offloading profiles",
+			func(mutator func(profiles []offv1alpha1.OffloadingProfile), expectedError bool) {
+				mutator(namespaceOffloading.Spec.Profiles)
+				err := validateNamespaceOffloading(&namespaceOffloading)
+				Expect(err != nil).To(Equal(expectedError))
+			},
+			Entry("Valid profiles", func(profiles []offv1alpha1.OffloadingProfile) {}, false),
+			Entry("Profile with the reserved name", func(profiles []offv1alpha1.OffloadingProfile) {
+				profiles[0].Name = offv1alpha1.DefaultOffloadingProfileName
+			}, true),
+			Entry("Profiles with duplicated names", func(profiles []offv1alpha1.OffloadingProfile) {
+				profiles[1].Name = profiles[0].Name
+			}, true),
+			Entry("Profile with an invalid podSelector", func(profiles []offv1alpha1.OffloadingProfile) {
+				profiles[0].PodSelector.MatchExpressions = []metav1.LabelSelectorRequirement{{
+					Key: "tier", Operator: metav1.LabelSelectorOpIn,
+				}}
+			}, true),
+		)
+	})
+
+	Context("8 - Check the translation of the placement preferences", func() {
+		var (
+			namespaceOffloading offv1alpha1.NamespaceOffloading
+			pod                 *corev1.Pod
+			maxClusterSkew      int32 = 2
+
+			preferredTerm = corev1.PreferredSchedulingTerm{
+				Weight: 50,
+				Preference: corev1.NodeSelectorTerm{MatchExpressions: []corev1.NodeSelectorRequirement{{
+					Key: "region", Operator: corev1.NodeSelectorOpIn, Values: []string{"eu"},
+				}}},
+			}
+			virtualNodeRequirement = corev1.NodeSelectorRequirement{
+				Key: liqoconst.TypeLabel, Operator: corev1.NodeSelectorOpIn, Values: []string{liqoconst.TypeNode},
+			}
+			localNodeRequirement = corev1.NodeSelectorRequirement{
+				Key: liqoconst.TypeLabel, Operator: corev1.NodeSelectorOpNotIn, Values: []string{liqoconst.TypeNode},
+			}
+		)
+
+		BeforeEach(func() {
+			namespaceOffloading = testutils.GetNamespaceOffloading(offv1alpha1.LocalAndRemotePodOffloadingStrategyType)
+			pod = &corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "pod", Namespace: "test",
+				Labels: map[string]string{"app": "test"}}}
+		})
+
+		It("Without placement preferences the pod has no soft constraints", func() {
+			Expect(mutatePod(&namespaceOffloading, pod)).To(Succeed())
+			Expect(pod.Spec.Affinity.NodeAffinity.PreferredDuringSchedulingIgnoredDuringExecution).To(BeEmpty())
+			Expect(pod.Spec.TopologySpreadConstraints).To(BeEmpty())
+		})
+
+		It("The preferred clusters and the local cluster are weighted", func() {
+			namespaceOffloading.Spec.Placement = &offv1alpha1.PlacementPreferences{
+				PreferredClusters: []corev1.PreferredSchedulingTerm{preferredTerm},
+				PreferLocal:       true,
+			}
+			Expect(mutatePod(&namespaceOffloading, pod)).To(Succeed())
+
+			terms := pod.Spec.Affinity.NodeAffinity.PreferredDuringSchedulingIgnoredDuringExecution
+			Expect(terms).To(HaveLen(2))
+			Expect(terms[0].Weight).To(BeNumerically("==", 50))
+			Expect(terms[0].Preference.MatchExpressions).To(ConsistOf(
+				preferredTerm.Preference.MatchExpressions[0], virtualNodeRequirement))
+			Expect(terms[1].Weight).To(BeNumerically("==", preferLocalWeight))
+			Expect(terms[1].Preference.MatchExpressions).To(ConsistOf(localNodeRequirement))
+			// The NamespaceOffloading must not be modified.
+			Expect(namespaceOffloading.Spec.Placement.PreferredClusters[0].Preference.MatchExpressions).To(HaveLen(1))
+		})
+
+		It("The replicas per cluster are bounded", func() {
+			namespaceOffloading.Spec.Placement = &offv1alpha1.PlacementPreferences{MaxClusterSkew: &maxClusterSkew}
+			pod.Labels[appsv1.StatefulSetPodNameLabel] = "test-0"
+			Expect(mutatePod(&namespaceOffloading, pod)).To(Succeed())
+			Expect(pod.Spec.TopologySpreadConstraints).To(ConsistOf(corev1.TopologySpreadConstraint{
+				MaxSkew:           maxClusterSkew,
+				TopologyKey:       liqoconst.ClusterIDNodeLabel,
+				WhenUnsatisfiable: corev1.ScheduleAnyway,
+				LabelSelector:     &metav1.LabelSelector{MatchLabels: map[string]string{"app": "test"}},
+			}))
+		})
+
+		It("The replicas per cluster are not bounded for pods without labels", func() {
+			namespaceOffloading.Spec.Placement = &offv1alpha1.PlacementPreferences{MaxClusterSkew: &maxClusterSkew}
+			pod.Labels = nil
+			Expect(mutatePod(&namespaceOffloading, pod)).To(Succeed())
+			Expect(pod.Spec.TopologySpreadConstraints).To(BeEmpty())
+		})
+
+		It("The topology spread constraints defined by the user prevail", func() {
+			namespaceOffloading.Spec.Placement = &offv1alpha1.PlacementPreferences{
+				MaxClusterSkew: &maxClusterSkew, WhenUnsatisfiable: corev1.DoNotSchedule}
+			userConstraint := corev1.TopologySpreadConstraint{
+				MaxSkew: 1, TopologyKey: liqoconst.ClusterIDNodeLabel, WhenUnsatisfiable: corev1.DoNotSchedule}
+			pod.Spec.TopologySpreadConstraints = []corev1.TopologySpreadConstraint{userConstraint}
+			Expect(mutatePod(&namespaceOffloading, pod)).To(Succeed())
+			Expect(pod.Spec.TopologySpreadConstraints).To(ConsistOf(userConstraint))
+		})
+
+		It("The placement preferences of the selected profile are enforced", func() {
+			namespaceOffloading.Spec.Placement = &offv1alpha1.PlacementPreferences{PreferLocal: true}
+			namespaceOffloading.Spec.Profiles = []offv1alpha1.OffloadingProfile{{
+				Name:                  "test",
+				PodSelector:           metav1.LabelSelector{MatchLabels: map[string]string{"app": "test"}},
+				PodOffloadingStrategy: offv1alpha1.RemotePodOffloadingStrategyType,
+				Placement:             &offv1alpha1.PlacementPreferences{MaxClusterSkew: &maxClusterSkew},
+			}}
+			Expect(mutatePod(&namespaceOffloading, pod)).To(Succeed())
+			Expect(pod.Spec.Affinity.NodeAffinity.PreferredDuringSchedulingIgnoredDuringExecution).To(BeEmpty())
+			Expect(pod.Spec.TopologySpreadConstraints).To(HaveLen(1))
+		})
+
+		DescribeTable("Validation of the placement preferences",
+			func(strategy offv1alpha1.PodOffloadingStrategyType, placement *offv1alpha1.PlacementPreferences, expectedError bool) {
+				namespaceOffloading.Spec.PodOffloadingStrategy = strategy
+				namespaceOffloading.Spec.Placement = placement
+				err := validateNamespaceOffloading(&namespaceOffloading)
+				Expect(err != nil).To(Equal(expectedError))
+			},
+			Entry("No placement preferences", offv1alpha1.RemotePodOffloadingStrategyType, nil, false),
+			Entry("Valid preferred clusters", offv1alpha1.RemotePodOffloadingStrategyType, &offv1alpha1.PlacementPreferences{
+				PreferredClusters: []corev1.PreferredSchedulingTerm{preferredTerm}}, false),
+			Entry("Preferred clusters with an invalid weight", offv1alpha1.RemotePodOffloadingStrategyType,
+				&offv1alpha1.PlacementPreferences{PreferredClusters: []corev1.PreferredSchedulingTerm{{Weight: 101}}}, true),
+			Entry("Local cluster preferred with LocalAndRemote strategy", offv1alpha1.LocalAndRemotePodOffloadingStrategyType,
+				&offv1alpha1.PlacementPreferences{PreferLocal: true}, false),
+			Entry("Local cluster preferred with Remote strategy", offv1alpha1.RemotePodOffloadingStrategyType,
+				&offv1alpha1.PlacementPreferences{PreferLocal: true}, true),
+		)
+	})
+
+	Context("9 - Check the validation of the Liqo resources", func() {
+		admissionRequest := func(kind schema.GroupVersionKind, operation admissionv1beta1.Operation,
+			obj, oldObj interface{}) []byte {
+			req := &admissionv1beta1.AdmissionRequest{
+				UID:       "test",
+				Kind:      metav1.GroupVersionKind(kind),
+				Operation: operation,
+			}
+			raw, err := json.Marshal(obj)
+			Expect(err).ToNot(HaveOccurred())
+			req.Object.Raw = raw
+			if oldObj != nil {
+				raw, err = json.Marshal(oldObj)
+				Expect(err).ToNot(HaveOccurred())
+				req.OldObject.Raw = raw
+			}
+			body, err := json.Marshal(admissionv1beta1.AdmissionReview{Request: req})
+			Expect(err).ToNot(HaveOccurred())
+			return body
+		}
+
+		validate := func(body []byte) bool {
+			response, err := (&MutationServer{}).Validate(body)
+			Expect(err).ToNot(HaveOccurred())
+			review := admissionv1beta1.AdmissionReview{}
+			Expect(json.Unmarshal(response, &review)).To(Succeed())
+			Expect(review.Response.UID).To(BeEquivalentTo("test"))
+			return review.Response.Allowed
+		}
+
+		DescribeTable("Validation of the ClusterConfig",
+			func(mutator func(config *configv1alpha1.LiqonetConfig), expectedError bool) {
+				config := &configv1alpha1.ClusterConfig{Spec: configv1alpha1.ClusterConfigSpec{
+					LiqonetConfig: configv1alpha1.LiqonetConfig{
+						PodCIDR:         "10.200.0.0/16",
+						ServiceCIDR:     "10.100.0.0/16",
+						ReservedSubnets: []configv1alpha1.CIDR{"10.0.0.0/16"},
+						AdditionalPools: []configv1alpha1.CIDR{"11.0.0.0/8"},
+					},
+				}}
+				mutator(&config.Spec.LiqonetConfig)
+				err := validateClusterConfig(config)
+				Expect(err != nil).To(Equal(expectedError))
+			},
+			Entry("Valid configuration", func(config *configv1alpha1.LiqonetConfig) {}, false),
+			Entry("Malformed reserved subnet", func(config *configv1alpha1.LiqonetConfig) {
+				config.ReservedSubnets = append(config.ReservedSubnets, "10.1.0.0/33")
+			}, true),
+			Entry("Reserved subnet which is not a network address", func(config *configv1alpha1.LiqonetConfig) {
+				config.ReservedSubnets = append(config.ReservedSubnets, "10.1.0.1/16")
+			}, true),
+			Entry("Overlapping reserved subnets", func(config *configv1alpha1.LiqonetConfig) {
+				config.ReservedSubnets = append(config.ReservedSubnets, "10.0.128.0/24")
+			}, true),
+			Entry("Reserved subnet overlapping with the PodCIDR", func(config *configv1alpha1.LiqonetConfig) {
+				config.ReservedSubnets = append(config.ReservedSubnets, "10.200.0.0/24")
+			}, true),
+			Entry("Additional pool overlapping with the ServiceCIDR", func(config *configv1alpha1.LiqonetConfig) {
+				config.AdditionalPools = append(config.AdditionalPools, "10.100.0.0/15")
+			}, true),
+			Entry("Overlapping PodCIDR and ServiceCIDR", func(config *configv1alpha1.LiqonetConfig) {
+				config.ServiceCIDR = "10.200.128.0/24"
+			}, true),
+			Entry("IPv6 PodCIDR and ServiceCIDR", func(config *configv1alpha1.LiqonetConfig) {
+				config.PodCIDR, config.ServiceCIDR = "fd00:10:200::/56", "fd00:10:100::/112"
+			}, true),
+			Entry("IPv6 reserved subnet", func(config *configv1alpha1.LiqonetConfig) {
+				config.ReservedSubnets = append(config.ReservedSubnets, "fd00:10:1::/64")
+			}, true),
+			Entry("Valid key rotation interval", func(config *configv1alpha1.LiqonetConfig) {
+				config.WireguardKeyRotationInterval = &metav1.Duration{Duration: 24 * time.Hour}
+			}, false),
+			Entry("Negative key rotation interval", func(config *configv1alpha1.LiqonetConfig) {
+				config.WireguardKeyRotationInterval = &metav1.Duration{Duration: -time.Hour}
+			}, true),
+		)
+
+		It("The PodCIDR of the ClusterConfig cannot be changed once set", func() {
+			kind := configv1alpha1.GroupVersion.WithKind("ClusterConfig")
+			oldConfig := &configv1alpha1.ClusterConfig{Spec: configv1alpha1.ClusterConfigSpec{
+				LiqonetConfig: configv1alpha1.LiqonetConfig{ServiceCIDR: "10.100.0.0/16"}}}
+			config := oldConfig.DeepCopy()
+			config.Spec.LiqonetConfig.PodCIDR = "10.200.0.0/16"
+			Expect(validate(admissionRequest(kind, admissionv1beta1.Create, config, nil))).To(BeTrue())
+			Expect(validate(admissionRequest(kind, admissionv1beta1.Update, config, oldConfig))).To(BeTrue())
+
+			oldConfig = config.DeepCopy()
+			config.Spec.LiqonetConfig.PodCIDR = "10.201.0.0/16"
+			Expect(validate(admissionRequest(kind, admissionv1beta1.Update, config, oldConfig))).To(BeFalse())
+		})
+
+		It("The mapping strategy of the NamespaceOffloading cannot be changed", func() {
+			kind := offv1alpha1.GroupVersion.WithKind("NamespaceOffloading")
+			oldNoff := testutils.GetNamespaceOffloading(offv1alpha1.LocalAndRemotePodOffloadingStrategyType)
+			oldNoff.Spec.NamespaceMappingStrategy = offv1alpha1.DefaultNameMappingStrategyType
+			noff := oldNoff.DeepCopy()
+			noff.Spec.PodOffloadingStrategy = offv1alpha1.RemotePodOffloadingStrategyType
+			Expect(validate(admissionRequest(kind, admissionv1beta1.Update, noff, &oldNoff))).To(BeTrue())
+
+			noff.Spec.NamespaceMappingStrategy = offv1alpha1.EnforceSameNameMappingStrategyType
+			Expect(validate(admissionRequest(kind, admissionv1beta1.Update, noff, &oldNoff))).To(BeFalse())
+		})
+
+		It("The NamespaceOffloading with an invalid strategy or ClusterSelector is refused", func() {
+			noff := testutils.GetNamespaceOffloading("Remotely")
+			Expect(validateNamespaceOffloading(&noff)).ToNot(Succeed())
+
+			noff = testutils.GetNamespaceOffloading(offv1alpha1.RemotePodOffloadingStrategyType)
+			noff.Spec.ClusterSelector.NodeSelectorTerms[0].MatchExpressions[0].Operator = corev1.NodeSelectorOpExists
+			Expect(validateNamespaceOffloading(&noff)).ToNot(Succeed())
+		})
+
+		DescribeTable("Validation of the ForeignCluster",
+			func(authURL string, expectedError bool) {
+				fc := &discoveryv1alpha1.ForeignCluster{Spec: discoveryv1alpha1.ForeignClusterSpec{AuthURL: authURL}}
+				err := validateForeignCluster(fc)
+				Expect(err != nil).To(Equal(expectedError))
+			},
+			Entry("Valid AuthURL", "https://10.0.0.1:30001", false),
+			Entry("AuthURL without scheme", "10.0.0.1:30001", true),
+			Entry("AuthURL with unsupported scheme", "ftp://10.0.0.1", true),
+		)
+
+		It("The ClusterID of the ForeignCluster cannot be changed once set", func() {
+			oldFc := &discoveryv1alpha1.ForeignCluster{}
+			fc := oldFc.DeepCopy()
+			fc.Spec.ClusterIdentity.ClusterID = "cluster-1"
+			Expect(validateForeignClusterUpdate(fc, oldFc)).To(Succeed())
+
+			oldFc = fc.DeepCopy()
+			fc.Spec.ClusterIdentity.ClusterID = "cluster-2"
+			Expect(validateForeignClusterUpdate(fc, oldFc)).ToNot(Succeed())
+		})
+
+		DescribeTable("Validation of the SearchDomain",
+			func(domain string, expectedError bool) {
+				sd := &discoveryv1alpha1.SearchDomain{Spec: discoveryv1alpha1.SearchDomainSpec{Domain: domain}}
+				err := validateSearchDomain(sd)
+				Expect(err != nil).To(Equal(expectedError))
+			},
+			Entry("Valid domain", "liqo.io", false),
+			Entry("Fully qualified domain", "liqo.io.", false),
+			Entry("Invalid domain", "liqo_io", true),
+		)
+
+		It("The ResourceOffer with negative resources or a different ClusterID is refused", func() {
+			oldOffer := &sharingv1alpha1.ResourceOffer{Spec: sharingv1alpha1.ResourceOfferSpec{
+				ClusterId: "cluster-1",
+				ResourceQuota: corev1.ResourceQuotaSpec{Hard: corev1.ResourceList{
+					corev1.ResourceCPU: resource.MustParse("2"),
+				}},
+			}}
+			Expect(validateResourceOffer(oldOffer)).To(Succeed())
+
+			offer := oldOffer.DeepCopy()
+			offer.Spec.ResourceQuota.Hard[corev1.ResourceCPU] = resource.MustParse("-1")
+			Expect(validateResourceOffer(offer)).ToNot(Succeed())
+
+			offer = oldOffer.DeepCopy()
+			offer.Spec.ClusterId = "cluster-2"
+			Expect(validateResourceOfferUpdate(offer, oldOffer)).ToNot(Succeed())
+		})
+
+		DescribeTable("Validation of the namespaces created by the remote clusters",
+			func(groups []string, name string, labels, oldLabels map[string]string, clusterConfigs int, expectedError bool) {
+				scheme := runtime.NewScheme()
+				Expect(configv1alpha1.AddToScheme(scheme)).To(Succeed())
+				builder := fake.NewClientBuilder().WithScheme(scheme)
+				for i := 0; i < clusterConfigs; i++ {
+					builder = builder.WithObjects(&configv1alpha1.ClusterConfig{
+						ObjectMeta: metav1.ObjectMeta{Name: fmt.Sprintf("configuration-%d", i)},
+						Spec: configv1alpha1.ClusterConfigSpec{AuthConfig: configv1alpha1.AuthConfig{
+							RemoteNamespacePolicy: &configv1alpha1.RemoteNamespacePolicy{Prefix: "tenant-"},
+						}},
+					})
+				}
+				server := &MutationServer{webhookClient: builder.Build()}
+				userInfo := &authenticationv1.UserInfo{Username: "remote-cluster-id", Groups: groups}
+				namespace := &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: name, Labels: labels}}
+				oldNamespace := &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: name, Labels: oldLabels}}
+				err := server.validateRemoteNamespace(userInfo, namespace, oldNamespace)
+				Expect(err != nil).To(Equal(expectedError))
+			},
+			Entry("Namespace created by a remote cluster satisfying the policy", []string{identitymanager.RemoteClusterGroup},
+				"tenant-foo", map[string]string{liqoconst.RemoteNamespaceLabelKey: "remote-cluster-id"}, nil, 1, false),
+			Entry("Namespace created by a remote cluster not satisfying the policy", []string{identitymanager.RemoteClusterGroup},
+				"foo", map[string]string{liqoconst.RemoteNamespaceLabelKey: "remote-cluster-id"}, nil, 1, true),
+			Entry("Namespace created by a remote cluster with the ID of another cluster", []string{identitymanager.RemoteClusterGroup},
+				"tenant-foo", map[string]string{liqoconst.RemoteNamespaceLabelKey: "other-cluster-id"}, nil, 1, true),
+			Entry("Namespace labeled by the Tenant not satisfying the policy", []string{"system:serviceaccounts"},
+				"foo", map[string]string{liqoconst.RemoteNamespaceLabelKey: "remote-cluster-id"}, nil, 1, true),
+			Entry("Already labeled namespace updated after a policy change", []string{identitymanager.RemoteClusterGroup}, "foo",
+				map[string]string{liqoconst.RemoteNamespaceLabelKey: "remote-cluster-id", "env": "prod"},
+				map[string]string{liqoconst.RemoteNamespaceLabelKey: "remote-cluster-id"}, 1, false),
+			Entry("Label removed by a remote cluster", []string{identitymanager.RemoteClusterGroup}, "tenant-foo",
+				nil, map[string]string{liqoconst.RemoteNamespaceLabelKey: "remote-cluster-id"}, 1, true),
+			Entry("Namespace created by a local user", []string{"system:authenticated"}, "foo", nil, nil, 1, false),
+			Entry("No ClusterConfig", []string{identitymanager.RemoteClusterGroup},
+				"tenant-foo", map[string]string{liqoconst.RemoteNamespaceLabelKey: "remote-cluster-id"}, nil, 0, true),
+			Entry("Multiple ClusterConfigs", []string{identitymanager.RemoteClusterGroup},
+				"tenant-foo", map[string]string{liqoconst.RemoteNamespaceLabelKey: "remote-cluster-id"}, nil, 2, true),
+		)
+	})
+
+	Context("10 - Check the enforcement of the ClusterOffloadingPolicies", func() {
+		policies := []offv1alpha1.ClusterOffloadingPolicy{{
+			Spec: offv1alpha1.ClusterOffloadingPolicySpec{
+				NamespaceSelector: metav1.LabelSelector{MatchLabels: map[string]string{"team": "a"}},
+				AllowedClusters:   []string{"cluster-1", "cluster-2"},
+			},
+		}, {
+			Spec: offv1alpha1.ClusterOffloadingPolicySpec{
+				NamespaceSelector: metav1.LabelSelector{MatchLabels: map[string]string{"kubernetes.io/metadata.name": "test"}},
+				AllowedClusters:   []string{"cluster-3"},
+			},
+		}}
+
+		DescribeTable("Computation of the allowed clusters",
+			func(policies []offv1alpha1.ClusterOffloadingPolicy, namespaceLabels map[string]string,
+				expectedRestricted bool, expectedAllowed []string) {
+				namespace := &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Labels: namespaceLabels}}
+				allowed, restricted, err := liqoutils.AllowedClusters(policies, namespace)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(restricted).To(Equal(expectedRestricted))
+				Expect(allowed).To(HaveLen(len(expectedAllowed)))
+				for _, clusterID := range expectedAllowed {
+					Expect(allowed).To(HaveKey(clusterID))
+				}
+			},
+			Entry("No policies", nil, map[string]string{"team": "a"}, false, nil),
+			Entry("Namespace not selected by any policy", policies, map[string]string{"team": "b"}, true, nil),
+			Entry("Namespace selected by a group policy", policies, map[string]string{"team": "a"},
+				true, []string{"cluster-1", "cluster-2"}),
+			Entry("Namespace selected by multiple policies", policies,
+				map[string]string{"team": "a", "kubernetes.io/metadata.name": "test"},
+				true, []string{"cluster-1", "cluster-2", "cluster-3"}),
+		)
+
+		It("The ClusterSelectors are restricted to the virtual nodes of the allowed clusters", func() {
+			namespaceOffloading := testutils.GetNamespaceOffloading(offv1alpha1.LocalAndRemotePodOffloadingStrategyType)
+			namespaceOffloading.Spec.Profiles = []offv1alpha1.OffloadingProfile{{
+				Name:                  "inherited",
+				PodSelector:           metav1.LabelSelector{MatchLabels: map[string]string{"tier": "batch"}},
+				PodOffloadingStrategy: offv1alpha1.RemotePodOffloadingStrategyType,
+			}}
+
+			restricted := restrictToAllowedClusters(&namespaceOffloading, map[string]struct{}{"cluster-2": {}, "cluster-1": {}})
+			requirement := corev1.NodeSelectorRequirement{
+				Key:      corev1.LabelHostname,
+				Operator: corev1.NodeSelectorOpIn,
+				Values:   []string{"liqo-cluster-1", "liqo-cluster-2"},
+			}
+			Expect(restricted.Spec.ClusterSelector.NodeSelectorTerms).To(HaveLen(len(namespaceOffloading.Spec.ClusterSelector.NodeSelectorTerms)))
+			for i := range restricted.Spec.ClusterSelector.NodeSelectorTerms {
+				Expect(restricted.Spec.ClusterSelector.NodeSelectorTerms[i].MatchExpressions).To(ContainElement(requirement))
+			}
+			Expect(restricted.Spec.Profiles[0].ClusterSelector.NodeSelectorTerms).To(BeEmpty())
+			// The original NamespaceOffloading is not modified.
+			Expect(namespaceOffloading.Spec.ClusterSelector).To(Equal(testutils.GetImposedNodeSelector("")))
+
+			pod := &corev1.Pod{}
+			Expect(mutatePod(restricted, pod)).To(Succeed())
+			// The local nodes can still be selected through the additional term.
+			terms := pod.Spec.Affinity.NodeAffinity.RequiredDuringSchedulingIgnoredDuringExecution.NodeSelectorTerms
+			Expect(terms[len(terms)-1].MatchExpressions).ToNot(ContainElement(requirement))
+		})
+
+		It("An empty ClusterSelector is restricted to the virtual nodes of the allowed clusters", func() {
+			namespaceOffloading := testutils.GetNamespaceOffloading(offv1alpha1.RemotePodOffloadingStrategyType)
+			namespaceOffloading.Spec.ClusterSelector = corev1.NodeSelector{}
+			namespaceOffloading.Spec.Profiles = []offv1alpha1.OffloadingProfile{{
+				Name:                  "inherited",
+				PodSelector:           metav1.LabelSelector{MatchLabels: map[string]string{"tier": "batch"}},
+				PodOffloadingStrategy: offv1alpha1.RemotePodOffloadingStrategyType,
+			}}
+
+			restricted := restrictToAllowedClusters(&namespaceOffloading, map[string]struct{}{"cluster-1": {}})
+			requirement := corev1.NodeSelectorRequirement{
+				Key:      corev1.LabelHostname,
+				Operator: corev1.NodeSelectorOpIn,
+				Values:   []string{"liqo-cluster-1"},
+			}
+			Expect(restricted.Spec.ClusterSelector.NodeSelectorTerms).To(ConsistOf(
+				corev1.NodeSelectorTerm{MatchExpressions: []corev1.NodeSelectorRequirement{requirement}}))
+			// The profile still inherits the top-level ClusterSelector, which is restricted.
+			Expect(restricted.Spec.Profiles[0].ClusterSelector.NodeSelectorTerms).To(BeEmpty())
+			Expect(namespaceOffloading.Spec.ClusterSelector.NodeSelectorTerms).To(BeEmpty())
+
+			nodeSelector, err := createNodeSelectorFromNamespaceOffloading(restricted)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(nodeSelector.NodeSelectorTerms).To(HaveLen(1))
+			Expect(nodeSelector.NodeSelectorTerms[0].MatchExpressions).To(ContainElement(requirement))
+		})
+
+		It("The virtual nodes cannot be selected if no cluster is allowed", func() {
+			namespaceOffloading := testutils.GetNamespaceOffloading(offv1alpha1.RemotePodOffloadingStrategyType)
+			restricted := restrictToAllowedClusters(&namespaceOffloading, map[string]struct{}{})
+			for i := range restricted.Spec.ClusterSelector.NodeSelectorTerms {
+				Expect(restricted.Spec.ClusterSelector.NodeSelectorTerms[i].MatchExpressions).To(ContainElement(
+					corev1.NodeSelectorRequirement{Key: corev1.LabelHostname, Operator: corev1.NodeSelectorOpDoesNotExist}))
+			}
+		})
+
+		DescribeTable("Validation of the ClusterOffloadingPolicy",
+			func(allowedClusters []string, expectedError bool) {
+				policy := &offv1alpha1.ClusterOffloadingPolicy{Spec: offv1alpha1.ClusterOffloadingPolicySpec{
+					AllowedClusters: allowedClusters}}
+				err := validateClusterOffloadingPolicy(policy, nil)
+				Expect(err != nil).To(Equal(expectedError))
+			},
+			Entry("Valid policy", []string{"cluster-1", "cluster-2"}, false),
+			Entry("Policy with an empty cluster ID", []string{""}, true),
+			Entry("Policy with a duplicated cluster ID", []string{"cluster-1", "cluster-1"}, true),
+		)
+
+		DescribeTable("Validation of the subjects and of the selector of the ClusterOffloadingPolicy",
+			func(selector metav1.LabelSelector, subjects []rbacv1.Subject, expectedError bool) {
+				policy := &offv1alpha1.ClusterOffloadingPolicy{Spec: offv1alpha1.ClusterOffloadingPolicySpec{
+					NamespaceSelector: selector, Subjects: subjects, AllowedClusters: []string{"cluster-1"}}}
+				err := validateClusterOffloadingPolicy(policy, []string{"team", liqoconst.SchedulingLiqoLabel})
+				Expect(err != nil).To(Equal(expectedError))
+			},
+			Entry("Valid subjects", metav1.LabelSelector{}, []rbacv1.Subject{
+				{Kind: rbacv1.UserKind, Name: "alice"}, {Kind: rbacv1.GroupKind, Name: "team-a"},
+				{Kind: rbacv1.ServiceAccountKind, Namespace: "ci", Name: "deployer"}}, false),
+			Entry("Subject with an empty name", metav1.LabelSelector{}, []rbacv1.Subject{{Kind: rbacv1.UserKind}}, true),
+			Entry("Service account subject without namespace", metav1.LabelSelector{},
+				[]rbacv1.Subject{{Kind: rbacv1.ServiceAccountKind, Name: "deployer"}}, true),
+			Entry("Subject with an unsupported kind", metav1.LabelSelector{},
+				[]rbacv1.Subject{{Kind: "Role", Name: "admin"}}, true),
+			Entry("Selector referring to the scheduling label",
+				metav1.LabelSelector{MatchLabels: map[string]string{liqoconst.SchedulingLiqoLabel: "true"}}, nil, true),
+			Entry("Selector referring to a protected label", metav1.LabelSelector{MatchExpressions: []metav1.LabelSelectorRequirement{
+				{Key: "team", Operator: metav1.LabelSelectorOpIn, Values: []string{"a"}}}}, nil, false),
+			Entry("Selector referring to the name label",
+				metav1.LabelSelector{MatchLabels: map[string]string{corev1.LabelMetadataName: "foo"}}, nil, false),
+			Entry("Selector referring to an unprotected label",
+				metav1.LabelSelector{MatchLabels: map[string]string{"env": "prod"}}, nil, true),
+		)
+
+		DescribeTable("Protection of the namespace labels referred to by the ClusterOffloadingPolicies",
+			func(userInfo authenticationv1.UserInfo, authorized bool, labels, oldLabels map[string]string, expectedError bool) {
+				scheme := runtime.NewScheme()
+				Expect(offv1alpha1.AddToScheme(scheme)).To(Succeed())
+				policy := &offv1alpha1.ClusterOffloadingPolicy{
+					ObjectMeta: metav1.ObjectMeta{Name: "team-a"},
+					Spec: offv1alpha1.ClusterOffloadingPolicySpec{
+						NamespaceSelector: metav1.LabelSelector{MatchExpressions: []metav1.LabelSelectorRequirement{
+							{Key: "team", Operator: metav1.LabelSelectorOpIn, Values: []string{"a"}}}},
+						Subjects:        []rbacv1.Subject{{Kind: rbacv1.GroupKind, Name: "team-a"}},
+						AllowedClusters: []string{"cluster-1"},
+					},
+				}
+				clientset := k8sfake.NewSimpleClientset()
+				clientset.PrependReactor("create", "subjectaccessreviews",
+					func(action k8stesting.Action) (bool, runtime.Object, error) {
+						review := action.(k8stesting.CreateAction).GetObject().(*authorizationv1.SubjectAccessReview)
+						Expect(review.Spec.User).To(Equal(userInfo.Username))
+						review.Status.Allowed = authorized
+						return true, review, nil
+					})
+				server := &MutationServer{
+					webhookClient: fake.NewClientBuilder().WithScheme(scheme).WithObjects(policy).Build(),
+					clientset:     clientset,
+				}
+
+				namespace := &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "foo", Labels: labels}}
+				oldNamespace := &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "foo", Labels: oldLabels}}
+				err := server.validateNamespaceLabels(&userInfo, namespace, oldNamespace)
+				Expect(err != nil).To(Equal(expectedError))
+			},
+			Entry("Unrelated label changed by a namespace owner", authenticationv1.UserInfo{Username: "bob"}, false,
+				map[string]string{"env": "prod"}, nil, false),
+			Entry("Automatic name label set by a namespace owner", authenticationv1.UserInfo{Username: "bob"}, false,
+				map[string]string{corev1.LabelMetadataName: "foo"}, nil, false),
+			Entry("Protected label set by a namespace owner", authenticationv1.UserInfo{Username: "bob"}, false,
+				map[string]string{"team": "a"}, nil, true),
+			Entry("Protected label removed by a namespace owner", authenticationv1.UserInfo{Username: "bob"}, false,
+				nil, map[string]string{"team": "b"}, true),
+			Entry("Protected label set by a granted subject",
+				authenticationv1.UserInfo{Username: "alice", Groups: []string{"team-a"}}, false,
+				map[string]string{"team": "a"}, nil, false),
+			Entry("Protected label set by an administrator", authenticationv1.UserInfo{Username: "admin"}, true,
+				map[string]string{"team": "a"}, map[string]string{"team": "b"}, false),
+		)
+	})
+
+	Context("11 - Check the reflection readiness gate", func() {
+		DescribeTable("The readiness gate is added only to the pods forced to be offloaded",
+			func(strategy offv1alpha1.PodOffloadingStrategyType, expected bool) {
+				namespaceOffloading := testutils.GetNamespaceOffloading(strategy)
+				pod := &corev1.Pod{}
+				Expect(mutatePod(&namespaceOffloading, pod)).To(Succeed())
+				gate := corev1.PodReadinessGate{ConditionType: liqoconst.ReflectionReadyConditionType}
+				if expected {
+					Expect(pod.Spec.ReadinessGates).To(ConsistOf(gate))
+				} else {
+					Expect(pod.Spec.ReadinessGates).ToNot(ContainElement(gate))
+				}
+			},
+			Entry("Remote strategy", offv1alpha1.RemotePodOffloadingStrategyType, true),
+			Entry("LocalAndRemote strategy", offv1alpha1.LocalAndRemotePodOffloadingStrategyType, false),
+			Entry("Local strategy", offv1alpha1.LocalPodOffloadingStrategyType, false),
+		)
+
+		It("The readiness gate is not duplicated", func() {
+			pod := &corev1.Pod{}
+			addReflectionReadinessGate(pod)
+			addReflectionReadinessGate(pod)
+			Expect(pod.Spec.ReadinessGates).To(HaveLen(1))
+		})
 	})
 })
@@ -0,0 +1,3 @@
+// Package namespacenaming provides the functions to generate the names of the remote namespaces, according to the
+// NamespaceMappingStrategy, and to check them against the policies enforced by the remote clusters.
+package namespacenaming
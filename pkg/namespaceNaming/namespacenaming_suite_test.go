@@ -0,0 +1,13 @@
+package namespacenaming
+
+import (
+	"testing"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+func TestNamespaceNaming(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "NamespaceNaming Suite")
+}
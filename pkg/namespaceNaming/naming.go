@@ -0,0 +1,88 @@
+package namespacenaming
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"text/template"
+
+	"k8s.io/apimachinery/pkg/util/validation"
+
+	offv1alpha1 "github.com/liqotech/liqo/apis/offloading/v1alpha1"
+)
+
+const (
+	// placeholderClusterID is the cluster ID used to validate the templates, having the same length of the real ones.
+	placeholderClusterID = "00000000-0000-0000-0000-000000000000"
+	// placeholderClusterName is the cluster name used to validate the templates.
+	placeholderClusterName = "cluster"
+)
+
+// TemplateData contains the fields that can be used in a NamespaceNameTemplate.
+type TemplateData struct {
+	// Namespace is the name of the local namespace.
+	Namespace string
+	// LocalClusterID is the cluster ID of the local cluster.
+	LocalClusterID string
+	// LocalClusterName is the name of the local cluster.
+	LocalClusterName string
+}
+
+// RemoteNamespaceName returns the name of the remote namespace associated with the given NamespaceOffloading,
+// according to its NamespaceMappingStrategy.
+func RemoteNamespaceName(noff *offv1alpha1.NamespaceOffloading, localClusterID, localClusterName string) (string, error) {
+	switch noff.Spec.NamespaceMappingStrategy {
+	case offv1alpha1.EnforceSameNameMappingStrategyType:
+		return noff.Namespace, nil
+	case offv1alpha1.TemplateNameMappingStrategyType:
+		if localClusterName == "" && strings.Contains(noff.Spec.NamespaceNameTemplate, "LocalClusterName") {
+			return "", fmt.Errorf("the namespace name template refers to the cluster name, which is not set in the ClusterConfig")
+		}
+		return RenderTemplate(noff.Spec.NamespaceNameTemplate, &TemplateData{
+			Namespace:        noff.Namespace,
+			LocalClusterID:   localClusterID,
+			LocalClusterName: localClusterName,
+		})
+	default:
+		return fmt.Sprintf("%s-%s", noff.Namespace, localClusterID), nil
+	}
+}
+
+// RenderTemplate generates a namespace name from the given template, and checks that it is a valid one.
+func RenderTemplate(nameTemplate string, data *TemplateData) (string, error) {
+	if strings.TrimSpace(nameTemplate) == "" {
+		return "", fmt.Errorf("the namespace name template is empty")
+	}
+
+	tmpl, err := template.New("namespace").Option("missingkey=error").Parse(nameTemplate)
+	if err != nil {
+		return "", fmt.Errorf("invalid namespace name template: %w", err)
+	}
+
+	var name bytes.Buffer
+	if err = tmpl.Execute(&name, data); err != nil {
+		return "", fmt.Errorf("invalid namespace name template: %w", err)
+	}
+
+	if errs := validation.IsDNS1123Label(name.String()); len(errs) > 0 {
+		return "", fmt.Errorf("the generated namespace name %q is not valid: %s", name.String(), strings.Join(errs, ", "))
+	}
+	return name.String(), nil
+}
+
+// ValidateTemplate checks that the template generates a valid name for the given namespace, using placeholder
+// values for the local cluster ID and name when they are not known.
+func ValidateTemplate(nameTemplate, namespace, localClusterID, localClusterName string) error {
+	if localClusterID == "" {
+		localClusterID = placeholderClusterID
+	}
+	if localClusterName == "" {
+		localClusterName = placeholderClusterName
+	}
+	_, err := RenderTemplate(nameTemplate, &TemplateData{
+		Namespace:        namespace,
+		LocalClusterID:   localClusterID,
+		LocalClusterName: localClusterName,
+	})
+	return err
+}
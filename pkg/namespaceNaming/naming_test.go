@@ -0,0 +1,143 @@
+package namespacenaming
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/ginkgo/extensions/table"
+	. "github.com/onsi/gomega"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	configv1alpha1 "github.com/liqotech/liqo/apis/config/v1alpha1"
+	offv1alpha1 "github.com/liqotech/liqo/apis/offloading/v1alpha1"
+)
+
+var _ = Describe("Namespace naming", func() {
+
+	const (
+		localClusterID   = "a1b2c3d4-0000-1111-2222-333344445555"
+		localClusterName = "milan"
+	)
+
+	type remoteNameTestcase struct {
+		strategy         offv1alpha1.NamespaceMappingStrategyType
+		nameTemplate     string
+		emptyClusterName bool
+		expectedName     string
+		expectedError    bool
+	}
+
+	DescribeTable("generate the remote namespace name according to the NamespaceMappingStrategy",
+		func(c remoteNameTestcase) {
+			noff := &offv1alpha1.NamespaceOffloading{
+				ObjectMeta: metav1.ObjectMeta{Name: "offloading", Namespace: "foo"},
+				Spec: offv1alpha1.NamespaceOffloadingSpec{
+					NamespaceMappingStrategy: c.strategy,
+					NamespaceNameTemplate:    c.nameTemplate,
+				},
+			}
+			clusterName := localClusterName
+			if c.emptyClusterName {
+				clusterName = ""
+			}
+			name, err := RemoteNamespaceName(noff, localClusterID, clusterName)
+			if c.expectedError {
+				Expect(err).To(HaveOccurred())
+				return
+			}
+			Expect(err).ToNot(HaveOccurred())
+			Expect(name).To(Equal(c.expectedName))
+		},
+
+		Entry("EnforceSameName", remoteNameTestcase{
+			strategy:     offv1alpha1.EnforceSameNameMappingStrategyType,
+			expectedName: "foo",
+		}),
+		Entry("DefaultName", remoteNameTestcase{
+			strategy:     offv1alpha1.DefaultNameMappingStrategyType,
+			expectedName: "foo-" + localClusterID,
+		}),
+		Entry("Template with the cluster name", remoteNameTestcase{
+			strategy:     offv1alpha1.TemplateNameMappingStrategyType,
+			nameTemplate: "tenant-{{.Namespace}}-{{.LocalClusterName}}",
+			expectedName: "tenant-foo-milan",
+		}),
+		Entry("Template with the cluster name not set", remoteNameTestcase{
+			strategy:         offv1alpha1.TemplateNameMappingStrategyType,
+			nameTemplate:     "tenant-{{.Namespace}}-{{.LocalClusterName}}",
+			emptyClusterName: true,
+			expectedError:    true,
+		}),
+		Entry("Template with the cluster ID", remoteNameTestcase{
+			strategy:     offv1alpha1.TemplateNameMappingStrategyType,
+			nameTemplate: "{{.LocalClusterID}}",
+			expectedName: localClusterID,
+		}),
+		Entry("Template with an unknown field", remoteNameTestcase{
+			strategy:      offv1alpha1.TemplateNameMappingStrategyType,
+			nameTemplate:  "{{.Tenant}}-{{.Namespace}}",
+			expectedError: true,
+		}),
+		Entry("Template with a syntax error", remoteNameTestcase{
+			strategy:      offv1alpha1.TemplateNameMappingStrategyType,
+			nameTemplate:  "{{.Namespace",
+			expectedError: true,
+		}),
+		Entry("Template generating a name too long", remoteNameTestcase{
+			strategy:      offv1alpha1.TemplateNameMappingStrategyType,
+			nameTemplate:  "{{.Namespace}}-{{.LocalClusterID}}-{{.LocalClusterID}}",
+			expectedError: true,
+		}),
+		Entry("Empty template", remoteNameTestcase{
+			strategy:      offv1alpha1.TemplateNameMappingStrategyType,
+			expectedError: true,
+		}),
+	)
+
+	It("should validate the templates using placeholders for the unknown values", func() {
+		Expect(ValidateTemplate("{{.Namespace}}-{{.LocalClusterName}}", "foo", "", "")).To(Succeed())
+		Expect(ValidateTemplate("{{.Namespace}}-{{.LocalClusterName}}", "foo", localClusterID, "")).To(Succeed())
+		Expect(ValidateTemplate("{{.Namespace}}.remote", "foo", "", "")).ToNot(Succeed())
+	})
+
+	type policyTestcase struct {
+		policy        *configv1alpha1.RemoteNamespacePolicy
+		name          string
+		expectedError bool
+	}
+
+	DescribeTable("check the names against the policy of the remote cluster",
+		func(c policyTestcase) {
+			err := CheckPolicy(c.policy, c.name)
+			Expect(err != nil).To(Equal(c.expectedError))
+		},
+
+		Entry("no policy", policyTestcase{name: "foo"}),
+		Entry("matching prefix", policyTestcase{
+			policy: &configv1alpha1.RemoteNamespacePolicy{Prefix: "tenant-"},
+			name:   "tenant-foo",
+		}),
+		Entry("missing prefix", policyTestcase{
+			policy:        &configv1alpha1.RemoteNamespacePolicy{Prefix: "tenant-"},
+			name:          "foo",
+			expectedError: true,
+		}),
+		Entry("name too long", policyTestcase{
+			policy:        &configv1alpha1.RemoteNamespacePolicy{MaxLength: 5},
+			name:          "foo-bar",
+			expectedError: true,
+		}),
+		Entry("matching pattern", policyTestcase{
+			policy: &configv1alpha1.RemoteNamespacePolicy{Pattern: "[a-z]+-milan"},
+			name:   "foo-milan",
+		}),
+		Entry("pattern matching only part of the name", policyTestcase{
+			policy:        &configv1alpha1.RemoteNamespacePolicy{Pattern: "[a-z]+-milan"},
+			name:          "foo-milan-2",
+			expectedError: true,
+		}),
+		Entry("invalid pattern", policyTestcase{
+			policy:        &configv1alpha1.RemoteNamespacePolicy{Pattern: "[a-z"},
+			name:          "foo",
+			expectedError: true,
+		}),
+	)
+})
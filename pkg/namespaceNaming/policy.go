@@ -0,0 +1,37 @@
+package namespacenaming
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	configv1alpha1 "github.com/liqotech/liqo/apis/config/v1alpha1"
+)
+
+// CheckPolicy checks that the given remote namespace name satisfies the policy enforced by the remote cluster.
+// A nil policy allows every name.
+func CheckPolicy(policy *configv1alpha1.RemoteNamespacePolicy, name string) error {
+	if policy == nil {
+		return nil
+	}
+
+	if policy.Prefix != "" && !strings.HasPrefix(name, policy.Prefix) {
+		return fmt.Errorf("the namespace name %q does not start with the required prefix %q", name, policy.Prefix)
+	}
+
+	if policy.MaxLength > 0 && len(name) > int(policy.MaxLength) {
+		return fmt.Errorf("the namespace name %q is longer than %d characters", name, policy.MaxLength)
+	}
+
+	if policy.Pattern != "" {
+		// The pattern is anchored, to match the whole name.
+		pattern, err := regexp.Compile(fmt.Sprintf("^(?:%s)$", policy.Pattern))
+		if err != nil {
+			return fmt.Errorf("invalid namespace name pattern %q: %w", policy.Pattern, err)
+		}
+		if !pattern.MatchString(name) {
+			return fmt.Errorf("the namespace name %q does not match the pattern %q", name, policy.Pattern)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,104 @@
+package schedulerextender
+
+import (
+	"encoding/json"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	"k8s.io/klog/v2"
+	resourcehelper "k8s.io/kubectl/pkg/util/resource"
+
+	liqoconst "github.com/liqotech/liqo/pkg/consts"
+)
+
+// isCostAware checks if the cheapest nodes have to be preferred when scheduling the given pod.
+func isCostAware(pod *corev1.Pod) bool {
+	return pod.Annotations[liqoconst.CostAwareSchedulingAnnotation] == "true"
+}
+
+// isVirtualNode checks if the given node is a Liqo virtual node.
+func isVirtualNode(node *corev1.Node) bool {
+	return node.Labels[liqoconst.TypeLabel] == liqoconst.TypeNode
+}
+
+// getNodePrices returns the prices published on the given node. The local nodes are considered free.
+func getNodePrices(node *corev1.Node) corev1.ResourceList {
+	if !isVirtualNode(node) {
+		return nil
+	}
+
+	encoded, ok := node.Annotations[liqoconst.ResourcePricesAnnotation]
+	if !ok {
+		return nil
+	}
+	var prices corev1.ResourceList
+	if err := json.Unmarshal([]byte(encoded), &prices); err != nil {
+		klog.Errorf("invalid prices on node %v: %v", node.Name, err)
+		return nil
+	}
+	return prices
+}
+
+// getPodCost returns the cost of running the pod with the given prices.
+// CPU is priced per core, memory per GiB, and the other resources per unit.
+func getPodCost(requests, prices corev1.ResourceList) float64 {
+	var cost float64
+	for resourceName, price := range prices {
+		request, ok := requests[resourceName]
+		if !ok {
+			continue
+		}
+
+		var amount float64
+		switch resourceName {
+		case corev1.ResourceMemory:
+			amount = float64(request.Value()) / float64(1<<30)
+		default:
+			amount = float64(request.MilliValue()) / 1000
+		}
+		cost += amount * quantityToFloat(&price)
+	}
+	return cost
+}
+
+func quantityToFloat(quantity *resource.Quantity) float64 {
+	return float64(quantity.MilliValue()) / 1000
+}
+
+// prioritize assigns a score to each node, inversely proportional to the cost of running the pod on it:
+// the cheapest node receives the maximum score, the most expensive one the minimum.
+func prioritize(pod *corev1.Pod, nodes []corev1.Node) HostPriorityList {
+	priorities := make(HostPriorityList, len(nodes))
+	if !isCostAware(pod) {
+		// neutral score, the decision is left to the other priorities.
+		for i := range nodes {
+			priorities[i] = HostPriority{Host: nodes[i].Name}
+		}
+		return priorities
+	}
+
+	requests, _ := resourcehelper.PodRequestsAndLimits(pod)
+	costs := make([]float64, len(nodes))
+	for i := range nodes {
+		costs[i] = getPodCost(requests, getNodePrices(&nodes[i]))
+	}
+
+	minCost, maxCost := 0.0, 0.0
+	for i := range costs {
+		if i == 0 || costs[i] < minCost {
+			minCost = costs[i]
+		}
+		if i == 0 || costs[i] > maxCost {
+			maxCost = costs[i]
+		}
+	}
+
+	for i := range nodes {
+		score := MaxExtenderPriority
+		if maxCost > minCost {
+			score = int64(float64(MaxExtenderPriority) * (maxCost - costs[i]) / (maxCost - minCost))
+		}
+		priorities[i] = HostPriority{Host: nodes[i].Name, Score: score}
+	}
+	return priorities
+}
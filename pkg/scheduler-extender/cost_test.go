@@ -0,0 +1,110 @@
+package schedulerextender
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/ginkgo/extensions/table"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	liqoconst "github.com/liqotech/liqo/pkg/consts"
+)
+
+var _ = Describe("Cost-aware prioritization", func() {
+
+	newVirtualNode := func(name, prices string) corev1.Node {
+		node := corev1.Node{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:   name,
+				Labels: map[string]string{liqoconst.TypeLabel: liqoconst.TypeNode},
+			},
+		}
+		if prices != "" {
+			node.Annotations = map[string]string{liqoconst.ResourcePricesAnnotation: prices}
+		}
+		return node
+	}
+
+	newPod := func(costAware bool) *corev1.Pod {
+		pod := &corev1.Pod{
+			Spec: corev1.PodSpec{
+				Containers: []corev1.Container{{
+					Resources: corev1.ResourceRequirements{
+						Requests: corev1.ResourceList{
+							corev1.ResourceCPU:    resource.MustParse("2"),
+							corev1.ResourceMemory: resource.MustParse("4Gi"),
+						},
+					},
+				}},
+			},
+		}
+		if costAware {
+			pod.Annotations = map[string]string{liqoconst.CostAwareSchedulingAnnotation: "true"}
+		}
+		return pod
+	}
+
+	localNode := corev1.Node{ObjectMeta: metav1.ObjectMeta{Name: "local"}}
+	cheapNode := newVirtualNode("cheap", `{"cpu":"1","memory":"1"}`)
+	expensiveNode := newVirtualNode("expensive", `{"cpu":"3","memory":"2"}`)
+
+	type prioritizeTestcase struct {
+		pod      *corev1.Pod
+		nodes    []corev1.Node
+		expected HostPriorityList
+	}
+
+	DescribeTable("prioritize the nodes",
+		func(c prioritizeTestcase) {
+			Expect(prioritize(c.pod, c.nodes)).To(Equal(c.expected))
+		},
+
+		Entry("pod not requiring cost-aware scheduling", prioritizeTestcase{
+			pod:      newPod(false),
+			nodes:    []corev1.Node{cheapNode, expensiveNode},
+			expected: HostPriorityList{{Host: "cheap"}, {Host: "expensive"}},
+		}),
+
+		Entry("virtual nodes with different prices", prioritizeTestcase{
+			pod:   newPod(true),
+			nodes: []corev1.Node{expensiveNode, cheapNode},
+			expected: HostPriorityList{
+				{Host: "expensive", Score: 0},
+				{Host: "cheap", Score: MaxExtenderPriority},
+			},
+		}),
+
+		Entry("local node and virtual nodes", prioritizeTestcase{
+			pod:   newPod(true),
+			nodes: []corev1.Node{localNode, cheapNode, expensiveNode},
+			expected: HostPriorityList{
+				{Host: "local", Score: MaxExtenderPriority},
+				// cost 6 out of 14
+				{Host: "cheap", Score: 5},
+				{Host: "expensive", Score: 0},
+			},
+		}),
+
+		Entry("virtual node without prices", prioritizeTestcase{
+			pod:   newPod(true),
+			nodes: []corev1.Node{newVirtualNode("free", ""), cheapNode},
+			expected: HostPriorityList{
+				{Host: "free", Score: MaxExtenderPriority},
+				{Host: "cheap", Score: 0},
+			},
+		}),
+	)
+
+	It("should compute the cost of a pod", func() {
+		requests := corev1.ResourceList{
+			corev1.ResourceCPU:    resource.MustParse("500m"),
+			corev1.ResourceMemory: resource.MustParse("2Gi"),
+		}
+		prices := corev1.ResourceList{
+			corev1.ResourceCPU:    resource.MustParse("2"),
+			corev1.ResourceMemory: resource.MustParse("500m"),
+		}
+		Expect(getPodCost(requests, prices)).To(BeNumerically("~", 2.0))
+	})
+})
@@ -0,0 +1,3 @@
+// Package schedulerextender implements a Kubernetes scheduler extender which prefers the cheapest nodes,
+// according to the prices published by the remote clusters, for the pods requiring a cost-aware scheduling.
+package schedulerextender
@@ -0,0 +1,95 @@
+package schedulerextender
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"k8s.io/klog/v2"
+)
+
+// ExtenderConfig contains the configuration of the scheduler extender.
+type ExtenderConfig struct {
+	Address  string
+	CertFile string
+	KeyFile  string
+}
+
+// ExtenderServer is the HTTP server invoked by the kube-scheduler to prioritize the nodes.
+type ExtenderServer struct {
+	mux    *http.ServeMux
+	server *http.Server
+	config *ExtenderConfig
+}
+
+// NewExtenderServer creates a new scheduler extender server.
+func NewExtenderServer(c *ExtenderConfig) *ExtenderServer {
+	s := &ExtenderServer{config: c}
+
+	s.mux = http.NewServeMux()
+	s.mux.HandleFunc("/prioritize", s.handlePrioritize)
+
+	s.server = &http.Server{
+		Addr:           c.Address,
+		Handler:        s.mux,
+		ReadTimeout:    10 * time.Second,
+		WriteTimeout:   10 * time.Second,
+		MaxHeaderBytes: 1 << 20, // 1048576
+	}
+
+	return s
+}
+
+func (s *ExtenderServer) handlePrioritize(w http.ResponseWriter, r *http.Request) {
+	var args ExtenderArgs
+	if err := json.NewDecoder(r.Body).Decode(&args); err != nil {
+		klog.Error(err)
+		s.sendError(fmt.Errorf("unable to correctly read the body of the request"), w)
+		return
+	}
+
+	if args.Pod == nil || args.Nodes == nil {
+		// the extender has to be configured with nodeCacheCapable=false, to receive the whole node objects.
+		s.sendError(fmt.Errorf("the request does not contain the pod and the candidate nodes"), w)
+		return
+	}
+
+	priorities := prioritize(args.Pod, args.Nodes.Items)
+	klog.V(5).Infof("Pod %v/%v -> priorities: %v", args.Pod.Namespace, args.Pod.Name, priorities)
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(priorities); err != nil {
+		klog.Error(err)
+	}
+}
+
+func (s *ExtenderServer) sendError(err error, w http.ResponseWriter) {
+	klog.Error(err)
+	w.WriteHeader(http.StatusBadRequest)
+	_, _ = fmt.Fprintf(w, "%s", err)
+}
+
+// Serve starts the server, using TLS if a certificate has been configured.
+func (s *ExtenderServer) Serve() {
+	var err error
+	if s.config.CertFile != "" && s.config.KeyFile != "" {
+		err = s.server.ListenAndServeTLS(s.config.CertFile, s.config.KeyFile)
+	} else {
+		err = s.server.ListenAndServe()
+	}
+	if !errors.Is(err, http.ErrServerClosed) {
+		// Error starting or closing listener:
+		klog.Fatalf("HTTP server ListenAndServe: %v", err)
+	}
+}
+
+// Shutdown gracefully shuts down the server without interrupting any active connections.
+func (s *ExtenderServer) Shutdown(ctx context.Context) {
+	if err := s.server.Shutdown(ctx); err != nil {
+		// Error from closing listeners, or context timeout:
+		klog.Errorf("HTTP server Shutdown: %v", err)
+	}
+}
@@ -0,0 +1,34 @@
+package schedulerextender
+
+import (
+	corev1 "k8s.io/api/core/v1"
+)
+
+// The following types mirror the ones defined in k8s.io/kube-scheduler/extender/v1,
+// which are exchanged with the kube-scheduler.
+
+// MaxExtenderPriority defines the max priority value for an extender.
+const MaxExtenderPriority int64 = 10
+
+// ExtenderArgs represents the arguments needed by the extender to prioritize nodes for a pod.
+type ExtenderArgs struct {
+	// Pod being scheduled
+	Pod *corev1.Pod
+	// List of candidate nodes where the pod can be scheduled; to be populated
+	// only if Extender.NodeCacheCapable == false
+	Nodes *corev1.NodeList
+	// List of candidate node names where the pod can be scheduled; to be
+	// populated only if Extender.NodeCacheCapable == true
+	NodeNames *[]string
+}
+
+// HostPriority represents the priority of scheduling to a particular host, higher priority is better.
+type HostPriority struct {
+	// Name of the host
+	Host string
+	// Score associated with the host
+	Score int64
+}
+
+// HostPriorityList declares a []HostPriority type.
+type HostPriorityList []HostPriority
@@ -0,0 +1,68 @@
+package utils
+
+import (
+	"context"
+	"sort"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	offv1alpha1 "github.com/liqotech/liqo/apis/offloading/v1alpha1"
+)
+
+// AllowedClusters returns the cluster IDs of the remote clusters the given namespace is allowed to be offloaded to,
+// according to the given ClusterOffloadingPolicies, and whether the offloading is restricted at all. In case no
+// policy exists, the offloading is not restricted and a nil set is returned.
+func AllowedClusters(policies []offv1alpha1.ClusterOffloadingPolicy,
+	namespace *corev1.Namespace) (allowed map[string]struct{}, restricted bool, err error) {
+	if len(policies) == 0 {
+		return nil, false, nil
+	}
+
+	allowed = map[string]struct{}{}
+	for i := range policies {
+		selector, err := metav1.LabelSelectorAsSelector(&policies[i].Spec.NamespaceSelector)
+		if err != nil {
+			return nil, true, err
+		}
+		if !selector.Matches(labels.Set(namespace.Labels)) {
+			continue
+		}
+		for _, clusterID := range policies[i].Spec.AllowedClusters {
+			allowed[clusterID] = struct{}{}
+		}
+	}
+	return allowed, true, nil
+}
+
+// GetAllowedClusters retrieves the ClusterOffloadingPolicies and the given namespace, and returns the cluster IDs
+// of the remote clusters it is allowed to be offloaded to, as well as whether the offloading is restricted at all.
+func GetAllowedClusters(ctx context.Context, c client.Client,
+	namespaceName string) (allowed map[string]struct{}, restricted bool, err error) {
+	policies := &offv1alpha1.ClusterOffloadingPolicyList{}
+	if err := c.List(ctx, policies); err != nil {
+		return nil, false, err
+	}
+	if len(policies.Items) == 0 {
+		return nil, false, nil
+	}
+
+	namespace := &corev1.Namespace{}
+	if err := c.Get(ctx, types.NamespacedName{Name: namespaceName}, namespace); err != nil {
+		return nil, true, err
+	}
+	return AllowedClusters(policies.Items, namespace)
+}
+
+// SortedClusterIDs returns the given set of cluster IDs as a sorted slice.
+func SortedClusterIDs(clusterIDs map[string]struct{}) []string {
+	sorted := make([]string, 0, len(clusterIDs))
+	for clusterID := range clusterIDs {
+		sorted = append(sorted, clusterID)
+	}
+	sort.Strings(sorted)
+	return sorted
+}
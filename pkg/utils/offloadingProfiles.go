@@ -0,0 +1,55 @@
+package utils
+
+import (
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+
+	offv1alpha1 "github.com/liqotech/liqo/apis/offloading/v1alpha1"
+)
+
+// SelectOffloadingProfile returns the most specific offloading profile whose PodSelector matches the labels
+// of the given pod, i.e. the one with the highest number of requirements. Ties are resolved in favor of the
+// profile defined first. Nil is returned if no profile matches, hence the top-level fields apply.
+func SelectOffloadingProfile(noff *offv1alpha1.NamespaceOffloading, pod *corev1.Pod) (*offv1alpha1.OffloadingProfile, error) {
+	var selected *offv1alpha1.OffloadingProfile
+	specificity := -1
+
+	for i := range noff.Spec.Profiles {
+		profile := &noff.Spec.Profiles[i]
+		selector, err := metav1.LabelSelectorAsSelector(&profile.PodSelector)
+		if err != nil {
+			return nil, err
+		}
+		if !selector.Matches(labels.Set(pod.Labels)) {
+			continue
+		}
+
+		current := len(profile.PodSelector.MatchLabels) + len(profile.PodSelector.MatchExpressions)
+		if current > specificity {
+			selected, specificity = profile, current
+		}
+	}
+	return selected, nil
+}
+
+// EffectiveNamespaceOffloading returns a copy of the given NamespaceOffloading, whose top-level fields are
+// overridden by the ones of the offloading profile. The profiles without ClusterSelector or Placement inherit
+// the top-level ones.
+func EffectiveNamespaceOffloading(noff *offv1alpha1.NamespaceOffloading,
+	profile *offv1alpha1.OffloadingProfile) *offv1alpha1.NamespaceOffloading {
+	effective := noff.DeepCopy()
+	if profile == nil {
+		return effective
+	}
+
+	effective.Spec.PodOffloadingStrategy = profile.PodOffloadingStrategy
+	effective.Spec.CostAwareScheduling = profile.CostAwareScheduling
+	if profile.Placement != nil {
+		effective.Spec.Placement = profile.Placement.DeepCopy()
+	}
+	if len(profile.ClusterSelector.NodeSelectorTerms) > 0 {
+		effective.Spec.ClusterSelector = *profile.ClusterSelector.DeepCopy()
+	}
+	return effective
+}
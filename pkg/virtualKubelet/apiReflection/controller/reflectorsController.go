@@ -1,8 +1,12 @@
 package controller
 
 import (
+	"context"
 	"sync"
+	"time"
 
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/wait"
 	"k8s.io/client-go/kubernetes"
 	"k8s.io/klog"
 
@@ -15,6 +19,13 @@ import (
 	reflectionCache "github.com/liqotech/liqo/pkg/virtualKubelet/storage"
 )
 
+// reflectionCheckPeriod is the period after which the completion of the reflection of a namespace is checked again.
+const reflectionCheckPeriod = 2 * time.Second
+
+// readinessAPIs are the APIs whose objects have to be reflected in the remote namespace before considering the
+// reflection of the namespace ready, since the offloaded pods may depend on them.
+var readinessAPIs = []apimgmt.ApiType{apimgmt.Configmaps, apimgmt.Secrets, apimgmt.Services}
+
 type APIReflectorsController interface {
 	Stop()
 	DispatchEvent(event apimgmt.ApiEvent)
@@ -79,6 +90,7 @@ func (c *ReflectorsController) startNamespaceReflection(namespace string) {
 	if c.reflectionType == ri.OutgoingReflection {
 		if err := c.cacheManager.AddHomeNamespace(namespace); err != nil {
 			klog.Errorf("error while reflecting new namespace - ERR: %v", err)
+			c.setNamespaceReflectionNotReady(namespace)
 			return
 		}
 
@@ -88,8 +100,11 @@ func (c *ReflectorsController) startNamespaceReflection(namespace string) {
 
 		if err := c.cacheManager.StartHomeNamespace(namespace, c.namespacedStops[namespace]); err != nil {
 			klog.Errorf("error while starting namespace caching - ERR: %v", err)
+			c.setNamespaceReflectionNotReady(namespace)
 			return
 		}
+
+		go c.waitNamespaceReflection(namespace, nattedNs, c.namespacedStops[namespace])
 	}
 
 	c.reflectionGroup.Add(1)
@@ -102,6 +117,61 @@ func (c *ReflectorsController) startNamespaceReflection(namespace string) {
 	}()
 }
 
+// waitNamespaceReflection waits until the objects of the home namespace have been reflected in the foreign one,
+// and then signals that the reflection of the namespace is ready.
+func (c *ReflectorsController) waitNamespaceReflection(namespace, nattedNs string, stop chan struct{}) {
+	if err := wait.PollImmediateUntil(reflectionCheckPeriod, func() (bool, error) {
+		return c.isNamespaceReflected(namespace, nattedNs), nil
+	}, stop); err != nil {
+		// The reflection of the namespace has been stopped in the meanwhile.
+		return
+	}
+
+	if err := wait.PollImmediateUntil(reflectionCheckPeriod, func() (bool, error) {
+		return c.namespaceNatting.SetNamespaceReflectionReady(namespace) == nil, nil
+	}, stop); err != nil {
+		return
+	}
+	klog.Infof("reflection of namespace %v towards namespace %v is ready", namespace, nattedNs)
+}
+
+// setNamespaceReflectionNotReady signals that the reflection of the namespace failed, hence the offloaded pods
+// depending on it are no longer ready.
+func (c *ReflectorsController) setNamespaceReflectionNotReady(namespace string) {
+	if err := c.namespaceNatting.SetNamespaceReflectionNotReady(namespace); err != nil {
+		klog.Errorf("error while signaling the failed reflection of namespace %v - ERR: %v", namespace, err)
+	}
+}
+
+// isNamespaceReflected returns whether all the objects of the home namespace allowed to be reflected have
+// a counterpart in the foreign namespace.
+func (c *ReflectorsController) isNamespaceReflected(namespace, nattedNs string) bool {
+	for _, api := range readinessAPIs {
+		reflector, ok := c.apiReflectors[api]
+		if !ok {
+			continue
+		}
+
+		homeObjects, err := c.cacheManager.ListHomeNamespacedObject(api, namespace)
+		if err != nil {
+			klog.V(4).Infof("reflection of %v in namespace %v not ready - ERR: %v", apimgmt.ApiNames[api], namespace, err)
+			return false
+		}
+
+		for _, obj := range homeObjects {
+			if !reflector.PreProcessIsAllowed(context.TODO(), obj) {
+				continue
+			}
+			name := obj.(metav1.Object).GetName()
+			if _, err := c.cacheManager.GetForeignNamespacedObject(api, nattedNs, name); err != nil {
+				klog.V(4).Infof("reflection of %v %v/%v not completed yet", apimgmt.ApiNames[api], namespace, name)
+				return false
+			}
+		}
+	}
+	return true
+}
+
 func (c *ReflectorsController) DispatchEvent(event apimgmt.ApiEvent) {
 	c.apiReflectors[event.Api].(ri.SpecializedAPIReflector).HandleEvent(event.Event)
 }
@@ -1,6 +1,7 @@
 package outgoing
 
 import (
+	"context"
 	"fmt"
 
 	"google.golang.org/grpc"
@@ -8,6 +9,7 @@ import (
 
 	liqoconst "github.com/liqotech/liqo/pkg/consts"
 	liqonetIpam "github.com/liqotech/liqo/pkg/liqonet/ipam"
+	"github.com/liqotech/liqo/pkg/utils"
 	apimgmt "github.com/liqotech/liqo/pkg/virtualKubelet/apiReflection"
 	ri "github.com/liqotech/liqo/pkg/virtualKubelet/apiReflection/reflectors/reflectorsInterfaces"
 	"github.com/liqotech/liqo/pkg/virtualKubelet/options"
@@ -32,7 +34,10 @@ func endpointslicesReflectorBuilder(reflector ri.APIReflector, opts map[options.
 	if err != nil {
 		klog.Error(err)
 	}
-	ipamClient := liqonetIpam.NewIpamClient(conn)
+	// The endpoint mappings are cached, to avoid a round-trip to the IPAM for each endpoint.
+	clusterID := utils.GetClusterIDFromNodeName(string(opts[types.VirtualNodeName].Value()))
+	ipamClient := liqonetIpam.NewCachedClient(liqonetIpam.NewIpamClient(conn), clusterID)
+	go ipamClient.Start(context.Background())
 
 	return &EndpointSlicesReflector{
 		APIReflector:    reflector,
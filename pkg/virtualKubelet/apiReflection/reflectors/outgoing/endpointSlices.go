@@ -123,6 +123,12 @@ func (r *EndpointSlicesReflector) PreAdd(obj interface{}) (interface{}, watch.Ev
 		},
 	}
 
+	endpoints, err := filterEndpoints(epLocal, r.IpamClient, string(r.VirtualNodeName.Value()))
+	if err != nil {
+		klog.Errorf("cannot map the endpoints of endpointslice %v/%v - ERR: %v", epLocal.Namespace, epLocal.Name, err)
+		return nil, watch.Added
+	}
+
 	epsRemote := &discoveryv1beta1.EndpointSlice{
 		ObjectMeta: metav1.ObjectMeta{
 			Name:            epLocal.Name,
@@ -131,7 +137,7 @@ func (r *EndpointSlicesReflector) PreAdd(obj interface{}) (interface{}, watch.Ev
 			OwnerReferences: svcOwnerRef,
 		},
 		AddressType: discoveryv1beta1.AddressTypeIPv4,
-		Endpoints:   filterEndpoints(epLocal, r.IpamClient, string(r.VirtualNodeName.Value())),
+		Endpoints:   endpoints,
 		Ports:       epLocal.Ports,
 	}
 
@@ -160,7 +166,12 @@ func (r *EndpointSlicesReflector) PreUpdate(newObj, _ interface{}) (interface{},
 	}
 	RemoteEpSlice := oldRemoteObj.(*discoveryv1beta1.EndpointSlice).DeepCopy()
 
-	RemoteEpSlice.Endpoints = filterEndpoints(endpointSliceHome, r.IpamClient, string(r.VirtualNodeName.Value()))
+	endpoints, err := filterEndpoints(endpointSliceHome, r.IpamClient, string(r.VirtualNodeName.Value()))
+	if err != nil {
+		klog.Errorf("cannot map the endpoints of endpointslice %v/%v - ERR: %v", endpointSliceHome.Namespace, endpointSliceName, err)
+		return nil, watch.Modified
+	}
+	RemoteEpSlice.Endpoints = endpoints
 	RemoteEpSlice.Ports = endpointSliceHome.Ports
 
 	return RemoteEpSlice, watch.Modified
@@ -176,9 +187,15 @@ func (r *EndpointSlicesReflector) PreDelete(obj interface{}) (interface{}, watch
 	}
 	endpointSliceLocal.Namespace = nattedNs
 
+	var ips []string
 	for _, endpoint := range endpointSliceLocal.Endpoints {
-		_, err := r.IpamClient.UnmapEndpointIP(context.Background(), &liqonetIpam.UnmapRequest{ClusterID: clusterID, Ip: endpoint.Addresses[0]})
-		if err != nil {
+		if len(endpoint.Addresses) > 0 {
+			ips = append(ips, endpoint.Addresses[0])
+		}
+	}
+	if len(ips) > 0 {
+		if _, err := r.IpamClient.UnmapEndpointIPs(context.Background(),
+			&liqonetIpam.UnmapEndpointIPsRequest{ClusterID: clusterID, Ips: ips}); err != nil {
 			klog.Error(err)
 		}
 	}
@@ -186,28 +203,41 @@ func (r *EndpointSlicesReflector) PreDelete(obj interface{}) (interface{}, watch
 	return endpointSliceLocal, watch.Deleted
 }
 
-func filterEndpoints(slice *discoveryv1beta1.EndpointSlice, ipamClient liqonetIpam.IpamClient, nodeName string) []discoveryv1beta1.Endpoint {
+// filterEndpoints returns the endpoints of the slice not hosted by the virtual node, with their IPs mapped
+// for the remote cluster through a single request to the IPAM.
+func filterEndpoints(slice *discoveryv1beta1.EndpointSlice, ipamClient liqonetIpam.IpamClient,
+	nodeName string) ([]discoveryv1beta1.Endpoint, error) {
 	var epList []discoveryv1beta1.Endpoint
+	var ips []string
 	// Two possibilities: (1) exclude all virtual nodes (2)
 	for _, v := range slice.Endpoints {
 		t := v.Topology["kubernetes.io/hostname"]
-		if t != nodeName {
-			response, err := ipamClient.MapEndpointIP(context.Background(),
-				&liqonetIpam.MapRequest{ClusterID: utils.GetClusterIDFromNodeName(nodeName), Ip: v.Addresses[0]})
-			if err != nil {
-				klog.Error(err)
-			}
-			newEp := discoveryv1beta1.Endpoint{
-				Addresses:  []string{response.GetIp()},
+		if t != nodeName && len(v.Addresses) > 0 {
+			ips = append(ips, v.Addresses[0])
+			epList = append(epList, discoveryv1beta1.Endpoint{
 				Conditions: v.Conditions,
 				Hostname:   nil,
 				TargetRef:  nil,
 				Topology:   nil,
-			}
-			epList = append(epList, newEp)
+			})
 		}
 	}
-	return epList
+	if len(ips) == 0 {
+		return epList, nil
+	}
+
+	response, err := ipamClient.MapEndpointIPs(context.Background(),
+		&liqonetIpam.MapEndpointIPsRequest{ClusterID: utils.GetClusterIDFromNodeName(nodeName), Ips: ips})
+	if err != nil {
+		return nil, err
+	}
+	if len(response.GetIps()) != len(ips) {
+		return nil, errors.Errorf("the IPAM mapped %d endpoint IPs out of %d", len(response.GetIps()), len(ips))
+	}
+	for i := range epList {
+		epList[i].Addresses = []string{response.GetIps()[i]}
+	}
+	return epList, nil
 }
 
 func (r *EndpointSlicesReflector) CleanupNamespace(localNamespace string) {
@@ -1,8 +1,10 @@
 package forge
 
 import (
+	"context"
 	"fmt"
 	"reflect"
+	"strings"
 
 	"github.com/pkg/errors"
 	"google.golang.org/grpc"
@@ -14,6 +16,7 @@ import (
 
 	"github.com/liqotech/liqo/pkg/consts"
 	liqonetIpam "github.com/liqotech/liqo/pkg/liqonet/ipam"
+	"github.com/liqotech/liqo/pkg/virtualKubelet"
 	"github.com/liqotech/liqo/pkg/virtualKubelet/namespacesmapping"
 	"github.com/liqotech/liqo/pkg/virtualKubelet/options"
 	"github.com/liqotech/liqo/pkg/virtualKubelet/options/types"
@@ -82,9 +85,12 @@ func InitForger(nattingTable namespacesmapping.NamespaceNatter, opts ...options.
 			forger.offloadClusterID = opt
 		case types.LiqoIpamServer:
 			forger.liqoIpamServer = opt
-			initIpamClient()
 		}
 	}
+	// The client is initialized once all the options are known, since it depends on the virtual node name.
+	if forger.liqoIpamServer != nil {
+		initIpamClient()
+	}
 }
 
 func initIpamClient() {
@@ -94,5 +100,10 @@ func initIpamClient() {
 	if err != nil {
 		klog.Error(err)
 	}
-	forger.ipamClient = liqonetIpam.NewIpamClient(conn)
+	// The home pod IPs are computed from the cached subnets of the remote cluster,
+	// to avoid a round-trip to the IPAM for each pod status update.
+	clusterID := strings.TrimPrefix(forger.virtualNodeName.Value().ToString(), virtualKubelet.VirtualNodePrefix)
+	ipamClient := liqonetIpam.NewCachedClient(liqonetIpam.NewIpamClient(conn), clusterID)
+	go ipamClient.Start(context.Background())
+	forger.ipamClient = ipamClient
 }
@@ -5,6 +5,7 @@ import (
 	. "github.com/onsi/gomega"
 	corev1 "k8s.io/api/core/v1"
 
+	liqoconst "github.com/liqotech/liqo/pkg/consts"
 	"github.com/liqotech/liqo/pkg/virtualKubelet/namespacesmapping/test"
 	"github.com/liqotech/liqo/pkg/virtualKubelet/options"
 	"github.com/liqotech/liqo/pkg/virtualKubelet/options/types"
@@ -38,4 +39,23 @@ var _ = Describe("Virtual Kubelet labels test", func() {
 
 	})
 
+	Context("Testing the reflection readiness condition", func() {
+		ready := []corev1.PodCondition{{Type: corev1.PodReady, Status: corev1.ConditionTrue}}
+
+		It("satisfies the readiness gate once the reflection is ready", func() {
+			conditions := setReflectionReadyCondition(ready, true)
+			Expect(conditions).To(ContainElement(corev1.PodCondition{
+				Type: liqoconst.ReflectionReadyConditionType, Status: corev1.ConditionTrue, Reason: "ReflectionReady"}))
+			Expect(conditions[0].Status).To(Equal(corev1.ConditionTrue))
+		})
+
+		It("marks the pod as not ready while the reflection is in progress", func() {
+			conditions := setReflectionReadyCondition(ready, false)
+			Expect(conditions).To(HaveLen(2))
+			Expect(conditions[0].Status).To(Equal(corev1.ConditionFalse))
+			Expect(conditions[1].Status).To(Equal(corev1.ConditionFalse))
+			// The original conditions are not modified.
+			Expect(ready[0].Status).To(Equal(corev1.ConditionTrue))
+		})
+	})
 })
@@ -68,6 +68,11 @@ func (f *apiForger) podStatusForeignToHome(foreignObj, homeObj runtime.Object) *
 		homePod.Status.PodIPs[0].IP = response.GetHomeIP()
 	}
 
+	if hasReflectionReadinessGate(homePod) {
+		homePod.Status.Conditions = setReflectionReadyCondition(homePod.Status.Conditions,
+			f.nattingTable.IsNamespaceReflectionReady(homePod.Namespace))
+	}
+
 	if foreignPod.DeletionTimestamp != nil {
 		homePod.DeletionTimestamp = nil
 		foreignKey := fmt.Sprintf("%s/%s", foreignPod.Namespace, foreignPod.Name)
@@ -78,6 +83,39 @@ func (f *apiForger) podStatusForeignToHome(foreignObj, homeObj runtime.Object) *
 	return homePod
 }
 
+// hasReflectionReadinessGate returns whether the pod readiness depends on the reflection of its namespace.
+func hasReflectionReadinessGate(pod *corev1.Pod) bool {
+	for i := range pod.Spec.ReadinessGates {
+		if pod.Spec.ReadinessGates[i].ConditionType == liqoconst.ReflectionReadyConditionType {
+			return true
+		}
+	}
+	return false
+}
+
+// setReflectionReadyCondition adds to the given pod conditions the one satisfying the reflection readiness gate.
+// Since the readiness gates are evaluated by the kubelet, the pod is also marked as not ready if the reflection is not.
+func setReflectionReadyCondition(conditions []corev1.PodCondition, ready bool) []corev1.PodCondition {
+	// The conditions are copied, since they may be shared with the cached foreign pod.
+	conditions = append([]corev1.PodCondition(nil), conditions...)
+	condition := corev1.PodCondition{
+		Type:   liqoconst.ReflectionReadyConditionType,
+		Status: corev1.ConditionTrue,
+		Reason: "ReflectionReady",
+	}
+	if !ready {
+		condition.Status = corev1.ConditionFalse
+		condition.Reason = "ReflectionInProgress"
+		for i := range conditions {
+			if conditions[i].Type == corev1.PodReady {
+				conditions[i].Status = corev1.ConditionFalse
+				conditions[i].Reason = "ReadinessGatesNotReady"
+			}
+		}
+	}
+	return append(conditions, condition)
+}
+
 // Set pod's container statutes to terminated so that the pod can be deleted.
 func (f *apiForger) setPodToBeDeleted(pod *corev1.Pod) *corev1.Pod {
 	pod.Status.Phase = corev1.PodUnknown
@@ -19,3 +19,16 @@ func (p *LiqoNodeProvider) cordonNode(ctx context.Context) error {
 
 	return nil
 }
+
+// uncordonNode uncordons the controlled node setting it in the schedulable state.
+func (p *LiqoNodeProvider) uncordonNode(ctx context.Context) error {
+	if err := p.patchNode(func(node *v1.Node) error {
+		node.Spec.Unschedulable = false
+		return nil
+	}); err != nil {
+		klog.Error(err)
+		return err
+	}
+
+	return nil
+}
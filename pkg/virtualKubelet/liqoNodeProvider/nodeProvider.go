@@ -17,7 +17,9 @@ type LiqoNodeProvider struct {
 
 	node              *corev1.Node
 	terminating       bool
+	offerExpired      bool
 	lastAppliedLabels map[string]string
+	lastAppliedPrices string
 
 	nodeName         string
 	foreignClusterID string
@@ -388,6 +388,47 @@ var _ = Describe("NodeProvider", func() {
 		Expect(ok).To(BeFalse())
 	})
 
+	It("Prices patch", func() {
+
+		client := kubernetes.NewForConfigOrDie(cluster.GetCfg())
+		getPrices := func() (string, bool) {
+			node, err := client.CoreV1().Nodes().Get(ctx, nodeName, metav1.GetOptions{})
+			Expect(err).ToNot(HaveOccurred())
+			prices, ok := node.GetAnnotations()[consts.ResourcePricesAnnotation]
+			return prices, ok
+		}
+
+		By("Add prices")
+
+		prices := v1.ResourceList{v1.ResourceCPU: resource.MustParse("2")}
+		err := nodeProvider.patchPrices(prices)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(nodeProvider.lastAppliedPrices).To(Equal(`{"cpu":"2"}`))
+
+		current, ok := getPrices()
+		Expect(ok).To(BeTrue())
+		Expect(current).To(Equal(`{"cpu":"2"}`))
+
+		By("Skip the patch if the prices did not change")
+
+		node, err := client.CoreV1().Nodes().Get(ctx, nodeName, metav1.GetOptions{})
+		Expect(err).ToNot(HaveOccurred())
+		err = nodeProvider.patchPrices(prices)
+		Expect(err).ToNot(HaveOccurred())
+		unchanged, err := client.CoreV1().Nodes().Get(ctx, nodeName, metav1.GetOptions{})
+		Expect(err).ToNot(HaveOccurred())
+		Expect(unchanged.ResourceVersion).To(Equal(node.ResourceVersion))
+
+		By("Delete prices")
+
+		err = nodeProvider.patchPrices(nil)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(nodeProvider.lastAppliedPrices).To(BeEmpty())
+
+		_, ok = getPrices()
+		Expect(ok).To(BeFalse())
+	})
+
 	Context("Node Cleanup", func() {
 
 		It("Cordon Node", func() {
@@ -26,7 +26,8 @@ import (
 func isResourceOfferTerminating(resourceOffer *sharingv1alpha1.ResourceOffer) bool {
 	hasTimestamp := !resourceOffer.DeletionTimestamp.IsZero()
 	desiredDelete := !resourceOffer.Spec.WithdrawalTimestamp.IsZero()
-	return hasTimestamp || desiredDelete
+	withdrawn := resourceOffer.Status.ExpirationStatus == sharingv1alpha1.ResourceOfferWithdrawn
+	return hasTimestamp || desiredDelete || withdrawn
 }
 
 // The reconciliation function; every time this function is called,
@@ -159,11 +160,21 @@ func (p *LiqoNodeProvider) updateFromResourceOffer(resourceOffer *sharingv1alpha
 	p.updateMutex.Lock()
 	defer p.updateMutex.Unlock()
 
+	if err := p.handleResourceOfferExpiration(resourceOffer); err != nil {
+		klog.Error(err)
+		return err
+	}
+
 	if err := p.patchLabels(resourceOffer.Spec.Labels); err != nil {
 		klog.Error(err)
 		return err
 	}
 
+	if err := p.patchPrices(resourceOffer.Spec.Prices); err != nil {
+		klog.Error(err)
+		return err
+	}
+
 	if p.node.Status.Capacity == nil {
 		p.node.Status.Capacity = v1.ResourceList{}
 	}
@@ -290,6 +301,37 @@ func (p *LiqoNodeProvider) handleResourceOfferDelete(resourceOffer *sharingv1alp
 	return nil
 }
 
+// handleResourceOfferExpiration cordons the node when the ResourceOffer expires, preventing new pods from being
+// scheduled on it, and uncordons it when the ResourceOffer is renewed, unless the node is being drained. In that case,
+// the ResourceOffer is kept withdrawn until the VirtualKubelet is deleted, and a new one is started on renewal.
+func (p *LiqoNodeProvider) handleResourceOfferExpiration(resourceOffer *sharingv1alpha1.ResourceOffer) error {
+	expired := resourceOffer.Status.ExpirationStatus == sharingv1alpha1.ResourceOfferExpired
+	if expired == p.offerExpired {
+		return nil
+	}
+	if !expired && p.terminating {
+		klog.Infof("resourceOffer %v renewed, but the node is terminating... keep it cordoned", resourceOffer.Name)
+		return nil
+	}
+
+	if expired {
+		klog.Infof("resourceOffer %v expired... cordon the node", resourceOffer.Name)
+		if err := client.IgnoreNotFound(p.cordonNode(context.TODO())); err != nil {
+			klog.Errorf("error cordoning node: %v", err)
+			return err
+		}
+	} else {
+		klog.Infof("resourceOffer %v renewed... uncordon the node", resourceOffer.Name)
+		if err := client.IgnoreNotFound(p.uncordonNode(context.TODO())); err != nil {
+			klog.Errorf("error uncordoning node: %v", err)
+			return err
+		}
+	}
+
+	p.offerExpired = expired
+	return nil
+}
+
 func (p *LiqoNodeProvider) patchLabels(labels map[string]string) error {
 	if reflect.DeepEqual(labels, p.lastAppliedLabels) {
 		return nil
@@ -313,6 +355,41 @@ func (p *LiqoNodeProvider) patchLabels(labels map[string]string) error {
 	return nil
 }
 
+// patchPrices sets the prices of the offered resources in the annotations of the node,
+// to make them available to the scheduler. The node is patched only if the prices changed.
+func (p *LiqoNodeProvider) patchPrices(prices v1.ResourceList) error {
+	var encoded string
+	if len(prices) > 0 {
+		bytes, err := json.Marshal(prices)
+		if err != nil {
+			klog.Error(err)
+			return err
+		}
+		encoded = string(bytes)
+	}
+	if encoded == p.lastAppliedPrices {
+		return nil
+	}
+
+	if err := p.patchNode(func(node *v1.Node) error {
+		if encoded == "" {
+			delete(node.Annotations, consts.ResourcePricesAnnotation)
+			return nil
+		}
+		if node.Annotations == nil {
+			node.Annotations = map[string]string{}
+		}
+		node.Annotations[consts.ResourcePricesAnnotation] = encoded
+		return nil
+	}); err != nil {
+		klog.Error(err)
+		return err
+	}
+
+	p.lastAppliedPrices = encoded
+	return nil
+}
+
 func isChanOpen(ch chan struct{}) bool {
 	open := true
 	select {
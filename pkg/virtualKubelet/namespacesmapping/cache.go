@@ -35,3 +35,25 @@ func (n *namespaceReadyMapCache) readAll() map[string]string {
 	defer n.RUnlock()
 	return n.mappings
 }
+
+type namespaceReflectionCache struct {
+	sync.RWMutex
+	ready map[string]struct{}
+}
+
+func (n *namespaceReflectionCache) set(local string, ready bool) {
+	n.Lock()
+	defer n.Unlock()
+	if ready {
+		n.ready[local] = struct{}{}
+		return
+	}
+	delete(n.ready, local)
+}
+
+func (n *namespaceReflectionCache) isReady(local string) bool {
+	n.RLock()
+	defer n.RUnlock()
+	_, ready := n.ready[local]
+	return ready
+}
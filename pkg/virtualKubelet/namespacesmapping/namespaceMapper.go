@@ -2,12 +2,15 @@ package namespacesmapping
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
 	"time"
 
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/client-go/dynamic"
 	"k8s.io/client-go/dynamic/dynamicinformer"
 	"k8s.io/client-go/rest"
@@ -29,6 +32,7 @@ type NamespaceMapper struct {
 	foreignClusterID       string
 	homeClusterID          string
 	namespaceReadyMapCache namespaceReadyMapCache
+	reflectionCache        namespaceReflectionCache
 	namespace              string
 
 	startOutgoingReflection chan string
@@ -49,6 +53,7 @@ func (m *NamespaceMapper) init(ctx context.Context, config *rest.Config) error {
 	}
 
 	m.namespaceReadyMapCache.mappings = map[string]string{}
+	m.reflectionCache.ready = map[string]struct{}{}
 
 	gvr := vkalpha1.NamespaceMapGroupVersionResource
 
@@ -119,6 +124,72 @@ func (m *NamespaceMapper) MappedNamespaces() map[string]string {
 	return m.namespaceReadyMapCache.readAll()
 }
 
+// IsNamespaceReflectionReady returns whether the reflection of the given local namespace has completed its initial
+// synchronization, hence the pods can be offloaded in the corresponding remote namespace.
+func (m *NamespaceMapper) IsNamespaceReflectionReady(namespaceName string) bool {
+	return m.reflectionCache.isReady(namespaceName)
+}
+
+// SetNamespaceReflectionReady marks the reflection of the given local namespace as ready, and signals it to the home
+// cluster through the ReflectionStatus of the NamespaceMap. Nothing is done if it is already known to be ready.
+func (m *NamespaceMapper) SetNamespaceReflectionReady(namespaceName string) error {
+	if m.reflectionCache.isReady(namespaceName) {
+		return nil
+	}
+	if err := m.patchReflectionStatus(namespaceName, &vkalpha1.NamespaceReflectionStatus{
+		Ready:              true,
+		LastTransitionTime: metav1.Now(),
+	}); err != nil {
+		return err
+	}
+	m.reflectionCache.set(namespaceName, true)
+	return nil
+}
+
+// SetNamespaceReflectionNotReady marks the reflection of the given local namespace as not ready, since it failed,
+// and signals it to the home cluster through the ReflectionStatus of the NamespaceMap.
+func (m *NamespaceMapper) SetNamespaceReflectionNotReady(namespaceName string) error {
+	m.reflectionCache.set(namespaceName, false)
+	return m.patchReflectionStatus(namespaceName, &vkalpha1.NamespaceReflectionStatus{
+		Ready:              false,
+		LastTransitionTime: metav1.Now(),
+	})
+}
+
+// patchReflectionStatus sets the ReflectionStatus entry of the NamespaceMap associated with the given local namespace,
+// or removes it if status is nil.
+func (m *NamespaceMapper) patchReflectionStatus(namespaceName string, status *vkalpha1.NamespaceReflectionStatus) error {
+	var labelSelector = labels.Set(map[string]string{liqoconst.RemoteClusterID: m.foreignClusterID}).AsSelector()
+	namespaceMaps, err := m.lister.ByNamespace(m.namespace).List(labelSelector)
+	if err != nil {
+		klog.Errorf("%s -> unable to list the NamespaceMaps associated with the cluster %s", err, m.foreignClusterID)
+		return err
+	}
+	if len(namespaceMaps) != 1 {
+		return errors.New("unable to find a unique NamespaceMap associated with the remote cluster")
+	}
+	namespaceMap := namespaceMaps[0].(*unstructured.Unstructured)
+
+	patch, err := json.Marshal(map[string]interface{}{
+		"status": map[string]interface{}{
+			"reflectionStatus": map[string]interface{}{namespaceName: status},
+		},
+	})
+	if err != nil {
+		klog.Error(err)
+		return err
+	}
+
+	if _, err = m.client.Resource(vkalpha1.NamespaceMapGroupVersionResource).Namespace(namespaceMap.GetNamespace()).Patch(
+		context.TODO(), namespaceMap.GetName(), types.MergePatchType, patch, metav1.PatchOptions{}); err != nil {
+		klog.Errorf("%s -> unable to patch the reflection status of the namespace %s in the NamespaceMap %s",
+			err, namespaceName, namespaceMap.GetName())
+		return err
+	}
+	klog.V(3).Infof("Reflection status of the namespace %s updated in the NamespaceMap %s", namespaceName, namespaceMap.GetName())
+	return nil
+}
+
 // manageReflections handles updates in namespaceMap. It adds/removes new mappings to the namespaceReadyMapCache and
 // starts/stop outgoing/incoming reflection routines by comparing oldNamespaceMapObject and newNamespaceMapObject.
 func (m *NamespaceMapper) manageReflections(oldNamespaceMapObject, newNamespaceMapObject interface{}) {
@@ -163,20 +234,33 @@ func (m *NamespaceMapper) handleMapperAdditions(oldNamespaceMap, newNamespaceMap
 		}
 		// When it is a creation event and is Accepted, we always have to add the namespace.
 		if creationEvent {
-			klog.V(3).Infof("Enabling reflection for remote namespace %s for local namespace %s", newMapping.RemoteNamespace, localNs)
-			m.namespaceReadyMapCache.write(localNs, newMapping.RemoteNamespace)
-			m.startOutgoingReflection <- localNs
-			m.startIncomingReflection <- localNs
+			m.enableReflection(newNamespaceMap, localNs, newMapping.RemoteNamespace)
 			// When there is an update event, we add to the cache if the element was not present before or if we had a change to MappingAccepted.
 		} else if oldRemoteNs, ok := oldNamespaceMap.Status.CurrentMapping[localNs]; !ok || oldRemoteNs.Phase != vkalpha1.MappingAccepted {
-			klog.V(3).Infof("Enabling reflection for remote namespace %s for local namespace %s", newMapping.RemoteNamespace, localNs)
-			m.namespaceReadyMapCache.write(localNs, newMapping.RemoteNamespace)
-			m.startOutgoingReflection <- localNs
-			m.startIncomingReflection <- localNs
+			m.enableReflection(newNamespaceMap, localNs, newMapping.RemoteNamespace)
 		}
 	}
 }
 
+// enableReflection adds the mapping to the namespaceReadyMapCache and starts the outgoing/incoming reflection routines.
+// The reflection status is set to not ready if not present yet, to signal that the objects are being synchronized.
+// Instead, the ready state left by a previous execution (e.g. before a restart) is kept, since the objects have
+// already been reflected, and the readiness of the offloaded pods would be otherwise reset for no reason.
+func (m *NamespaceMapper) enableReflection(namespaceMap *vkalpha1.NamespaceMap, localNs, remoteNs string) {
+	klog.V(3).Infof("Enabling reflection for remote namespace %s for local namespace %s", remoteNs, localNs)
+	status, found := namespaceMap.Status.ReflectionStatus[localNs]
+	switch {
+	case !found:
+		// The error is already logged, and the entry is anyhow overwritten once the reflection is ready.
+		_ = m.patchReflectionStatus(localNs, &vkalpha1.NamespaceReflectionStatus{Ready: false, LastTransitionTime: metav1.Now()})
+	case status.Ready:
+		m.reflectionCache.set(localNs, true)
+	}
+	m.namespaceReadyMapCache.write(localNs, remoteNs)
+	m.startOutgoingReflection <- localNs
+	m.startIncomingReflection <- localNs
+}
+
 func (m *NamespaceMapper) handleMapperDeletions(oldNamespaceMap, newNamespaceMap *vkalpha1.NamespaceMap, deletionEvent bool) {
 	for localNs, oldMapping := range oldNamespaceMap.Status.CurrentMapping {
 		// If the old resource was not Accepted, the namespace reflection was not enabled. So, no need to process this item
@@ -185,12 +269,18 @@ func (m *NamespaceMapper) handleMapperDeletions(oldNamespaceMap, newNamespaceMap
 		}
 		if deletionEvent {
 			klog.V(3).Infof("Stopping reflection for remote namespace %s for local namespace %s", oldMapping.RemoteNamespace, localNs)
+			m.reflectionCache.set(localNs, false)
 			m.stopOutgoingReflection <- localNs
 			m.stopIncomingReflection <- localNs
 		} else if newRemoteNs, ok := newNamespaceMap.Status.CurrentMapping[localNs]; !ok || newRemoteNs.Phase != vkalpha1.MappingAccepted {
 			klog.V(3).Infof("Stopping reflection for remote namespace %s for local namespace %s", oldMapping.RemoteNamespace, localNs)
+			m.reflectionCache.set(localNs, false)
 			m.stopOutgoingReflection <- localNs
 			m.stopIncomingReflection <- localNs
+			if _, found := newNamespaceMap.Status.ReflectionStatus[localNs]; found {
+				// The error is already logged, and the entry is anyhow overwritten if the reflection is started again.
+				_ = m.patchReflectionStatus(localNs, nil)
+			}
 		}
 	}
 }
@@ -25,6 +25,8 @@ type MapperController interface {
 type NamespaceReflectionController interface {
 	PollStartOutgoingReflection() chan string
 	PollStopOutgoingReflection() chan string
+	SetNamespaceReflectionReady(namespace string) error
+	SetNamespaceReflectionNotReady(namespace string) error
 }
 
 // NamespaceMirroringController defines the interface incoming reflection (mirroring), defining methods to extract items to start/stop
@@ -39,6 +41,7 @@ type NamespaceNatter interface {
 	NatNamespace(namespace string) (string, error)
 	DeNatNamespace(namespace string) (string, error)
 	MappedNamespaces() map[string]string
+	IsNamespaceReflectionReady(namespace string) bool
 }
 
 // NamespaceMapperController handles namespace translation and reflection by implementing NamespaceNatter,NamespaceMirroringController
@@ -130,3 +133,20 @@ func (c *NamespaceMapperController) WaitForSync() {
 func (c *NamespaceMapperController) MappedNamespaces() map[string]string {
 	return c.mapper.MappedNamespaces()
 }
+
+// SetNamespaceReflectionReady signals that the outgoing reflection of the given local namespace has completed
+// its initial synchronization.
+func (c *NamespaceMapperController) SetNamespaceReflectionReady(namespace string) error {
+	return c.mapper.SetNamespaceReflectionReady(namespace)
+}
+
+// SetNamespaceReflectionNotReady signals that the outgoing reflection of the given local namespace has failed.
+func (c *NamespaceMapperController) SetNamespaceReflectionNotReady(namespace string) error {
+	return c.mapper.SetNamespaceReflectionNotReady(namespace)
+}
+
+// IsNamespaceReflectionReady returns whether the outgoing reflection of the given local namespace has completed
+// its initial synchronization.
+func (c *NamespaceMapperController) IsNamespaceReflectionReady(namespace string) bool {
+	return c.mapper.IsNamespaceReflectionReady(namespace)
+}
@@ -94,6 +94,26 @@ var _ = Describe("Test that NamespaceMapper", func() {
 				}),
 		)
 	})
+	Context("keeps the reflection status across restarts", func() {
+		It("Keeps the namespace ready if the reflection was ready before the restart", func() {
+			m := NamespaceMapper{
+				namespaceReadyMapCache:  namespaceReadyMapCache{mappings: map[string]string{}},
+				reflectionCache:         namespaceReflectionCache{ready: map[string]struct{}{}},
+				startOutgoingReflection: make(chan string, 1),
+				startIncomingReflection: make(chan string, 1),
+			}
+			readyMap := namespaceMap.DeepCopy()
+			readyMap.Status.ReflectionStatus = map[string]vkalpha1.NamespaceReflectionStatus{
+				localValidNamespace: {Ready: true, LastTransitionTime: metav1.Now()},
+			}
+
+			// No patch is performed (which would fail, given the missing lister), since the status is kept.
+			m.enableReflection(readyMap, localValidNamespace, foreignValidNamespace)
+			Expect(m.IsNamespaceReflectionReady(localValidNamespace)).To(BeTrue())
+			Expect(m.SetNamespaceReflectionReady(localValidNamespace)).To(Succeed())
+			Expect(<-m.startOutgoingReflection).To(Equal(localValidNamespace))
+		})
+	})
 	Context("correctly initializes the namespaceMapper module", func() {
 		m := NamespaceMapper{
 			homeClusterID:           homeClusterID,
@@ -33,6 +33,13 @@ func (m *MockNamespaceMapper) MappedNamespaces() map[string]string {
 	panic("implement me")
 }
 
+// IsNamespaceReflectionReady returns whether the outgoing reflection of the given local namespace is ready,
+// which is always true for the namespaces in the local cache.
+func (m *MockNamespaceMapper) IsNamespaceReflectionReady(namespace string) bool {
+	_, ok := m.Cache[namespace]
+	return ok
+}
+
 // NewNamespace creates a new namespace in the local cache.
 func (m *MockNamespaceMapper) NewNamespace(namespace string) {
 	m.Cache[namespace] = namespace + "-natted"
@@ -76,3 +76,18 @@ func (c *MockNamespaceMapperController) MappedNamespaces() map[string]string {
 func (c *MockNamespaceMapperController) WaitForSync() {
 	panic("implement me")
 }
+
+// SetNamespaceReflectionReady signals that the outgoing reflection of the given local namespace is ready.
+func (c *MockNamespaceMapperController) SetNamespaceReflectionReady(namespace string) error {
+	panic("to implement")
+}
+
+// SetNamespaceReflectionNotReady signals that the outgoing reflection of the given local namespace has failed.
+func (c *MockNamespaceMapperController) SetNamespaceReflectionNotReady(namespace string) error {
+	panic("to implement")
+}
+
+// IsNamespaceReflectionReady returns whether the outgoing reflection of the given local namespace is ready.
+func (c *MockNamespaceMapperController) IsNamespaceReflectionReady(namespace string) bool {
+	return c.Mapper.IsNamespaceReflectionReady(namespace)
+}
@@ -28,6 +28,7 @@ func (p *LiqoProvider) ConfigureNode(ctx context.Context, n *v1.Node) {
 	n.ObjectMeta.Labels["alpha.service-controller.kubernetes.io/exclude-balancer"] = "true"
 	n.ObjectMeta.Labels["node.kubernetes.io/exclude-from-external-load-balancers"] = "true"
 	n.Labels[liqoconst.TypeLabel] = liqoconst.TypeNode
+	n.Labels[liqoconst.ClusterIDNodeLabel] = p.foreignClusterID
 	if n.Annotations == nil {
 		n.Annotations = map[string]string{}
 	}
@@ -40,6 +40,11 @@ func (p *LiqoProvider) CreatePod(ctx context.Context, homePod *corev1.Pod) error
 		return nil
 	}
 
+	// The pod is not offloaded until the objects it may depend on have been reflected in the remote namespace.
+	if !p.namespaceMapper.IsNamespaceReflectionReady(homePod.Namespace) {
+		return kerror.NewServiceUnavailable(fmt.Sprintf("reflection of namespace %s not ready yet", homePod.Namespace))
+	}
+
 	foreignObj, err := forge.HomeToForeign(homePod, nil, forge.LiqoOutgoingKey)
 	if err != nil {
 		klog.V(4).Infof("PROVIDER: error while forging remote pod %s/%s because of error %v", homePod.Namespace, homePod.Name, err)
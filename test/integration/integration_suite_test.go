@@ -136,7 +136,11 @@ func initNatMappingController() error {
 		MetricsBindAddress: "0",
 	})
 
-	controller, err = tunneloperator.NewNatMappingController(mgr.GetClient(), &readyClustersMutex, readyClusters, iptNetns)
+	natHandler, err := iptables.NewIPTHandler()
+	if err != nil {
+		return err
+	}
+	controller, err = tunneloperator.NewNatMappingController(mgr.GetClient(), &readyClustersMutex, readyClusters, iptNetns, natHandler)
 	if err != nil {
 		return err
 	}